@@ -0,0 +1,258 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/request"
+	"github.com/jacky-htg/inventory/payloads/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// ApprovalRules : struct for set ApprovalRules Dependency Injection
+type ApprovalRules struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// List : http handler for returning list of approval rules
+func (u *ApprovalRules) List(w http.ResponseWriter, r *http.Request) {
+	var approvalRule models.ApprovalRule
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	list, err := approvalRule.List(r.Context(), tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting approval rules list: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var listResponse []*response.ApprovalRuleResponse
+	for _, rule := range list {
+		var approvalRuleResponse response.ApprovalRuleResponse
+		approvalRuleResponse.Transform(&rule)
+		listResponse = append(listResponse, &approvalRuleResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// View : http handler for retrieve approval rule by id
+func (u *ApprovalRules) View(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting: %v", err))
+		return
+	}
+
+	var approvalRule models.ApprovalRule
+	approvalRule.ID = uint32(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = approvalRule.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get approval rule: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.ApprovalRuleResponse
+	response.Transform(&approvalRule)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Create : http handler for create new approval rule
+func (u *ApprovalRules) Create(w http.ResponseWriter, r *http.Request) {
+	var approvalRuleRequest request.NewApprovalRuleRequest
+	err := api.Decode(r, &approvalRuleRequest)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("decode approval rule: %v", err))
+		return
+	}
+
+	approvalRule := approvalRuleRequest.Transform()
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("begin transaction: %v", err))
+		return
+	}
+
+	err = approvalRule.Create(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Create approval rule: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.ApprovalRuleResponse
+	response.Transform(approvalRule)
+	api.ResponseOK(w, response, http.StatusCreated)
+}
+
+// Update : http handler for update approval rule by id
+func (u *ApprovalRules) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var approvalRule models.ApprovalRule
+	approvalRule.ID = uint32(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = approvalRule.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get approval rule: %v", err))
+		return
+	}
+
+	var approvalRuleRequest request.ApprovalRuleRequest
+	err = api.Decode(r, &approvalRuleRequest)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Decode approval rule: %v", err))
+		return
+	}
+
+	approvalRuleUpdate := approvalRuleRequest.Transform(&approvalRule)
+	err = approvalRuleUpdate.Update(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Update approval rule: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.ApprovalRuleResponse
+	response.Transform(approvalRuleUpdate)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Delete : http handler for delete approval rule by id
+func (u *ApprovalRules) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var approvalRule models.ApprovalRule
+	approvalRule.ID = uint32(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = approvalRule.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get approval rule: %v", err))
+		return
+	}
+
+	err = approvalRule.Delete(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Delete approval rule: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	api.ResponseOK(w, nil, http.StatusNoContent)
+}
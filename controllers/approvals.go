@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/request"
+	"github.com/jacky-htg/inventory/payloads/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Approvals : struct for set Approvals Dependency Injection
+type Approvals struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// List : http handler for returning pending approvals which can be approved by user login
+func (u *Approvals) List(w http.ResponseWriter, r *http.Request) {
+	var approval models.Approval
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	list, err := approval.Inbox(r.Context(), tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting approvals list: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var listResponse []*response.ApprovalResponse
+	for _, a := range list {
+		var approvalResponse response.ApprovalResponse
+		approvalResponse.Transform(&a)
+		listResponse = append(listResponse, &approvalResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// View : http handler for retrieve approval by id
+func (u *Approvals) View(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting: %v", err))
+		return
+	}
+
+	var approval models.Approval
+	approval.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = approval.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get approval: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.ApprovalResponse
+	response.Transform(&approval)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Approve : http handler for approving current step of approval
+func (u *Approvals) Approve(w http.ResponseWriter, r *http.Request) {
+	u.takeAction(w, r, "Approve", (*models.Approval).Approve)
+}
+
+// Reject : http handler for rejecting approval
+func (u *Approvals) Reject(w http.ResponseWriter, r *http.Request) {
+	u.takeAction(w, r, "Reject", (*models.Approval).Reject)
+}
+
+// takeAction get approval by id and apply action of user login with remark from request to it
+func (u *Approvals) takeAction(w http.ResponseWriter, r *http.Request, action string, apply func(*models.Approval, context.Context, *sql.Tx, string) error) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var actionRequest request.ApprovalActionRequest
+	if r.ContentLength != 0 {
+		err = api.Decode(r, &actionRequest)
+		if err != nil {
+			u.Log.Printf("ERROR : %+v", err)
+			api.ResponseError(w, fmt.Errorf("Decode approval: %v", err))
+			return
+		}
+	}
+
+	var approval models.Approval
+	approval.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = approval.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get approval: %v", err))
+		return
+	}
+
+	err = apply(&approval, ctx, tx, actionRequest.Remark)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("%s approval: %v", action, err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.ApprovalResponse
+	response.Transform(&approval)
+	api.ResponseOK(w, response, http.StatusOK)
+}
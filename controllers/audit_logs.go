@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/response"
+)
+
+// AuditLogs : struct for set AuditLogs Dependency Injection
+type AuditLogs struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// List : http handler for returning audit logs between start_date, default first date of month, and end_date, default today.
+// Audit logs can be filtered by user, entity, entity_id and action.
+func (u *AuditLogs) List(w http.ResponseWriter, r *http.Request) {
+	var auditLog models.AuditLog
+	query := r.URL.Query()
+	auditLog.Entity = query.Get("entity")
+	auditLog.Action = query.Get("action")
+
+	if paramUser := query.Get("user"); len(paramUser) > 0 {
+		id, err := strconv.ParseUint(paramUser, 10, 64)
+		if err != nil {
+			u.Log.Printf("ERROR : %+v", err)
+			api.ResponseError(w, api.ErrBadRequest(errors.New("user must be a number"), ""))
+			return
+		}
+		auditLog.User.ID = id
+	}
+
+	if paramEntityID := query.Get("entity_id"); len(paramEntityID) > 0 {
+		id, err := strconv.ParseUint(paramEntityID, 10, 64)
+		if err != nil {
+			u.Log.Printf("ERROR : %+v", err)
+			api.ResponseError(w, api.ErrBadRequest(errors.New("entity_id must be a number"), ""))
+			return
+		}
+		auditLog.EntityID = id
+	}
+
+	now := time.Now()
+	startDate, err := u.getDate(r, "start_date", time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	endDate, err := u.getDate(r, "end_date", now)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	list, err := auditLog.List(r.Context(), tx, startDate, endDate)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting audit logs list: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	listResponse := []*response.AuditLogResponse{}
+	for _, a := range list {
+		var auditLogResponse response.AuditLogResponse
+		auditLogResponse.Transform(&a)
+		listResponse = append(listResponse, &auditLogResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// getDate parse date of query parameter key formatted as yyyy-mm-dd, def is returned when it is empty
+func (u *AuditLogs) getDate(r *http.Request, key string, def time.Time) (time.Time, error) {
+	param := r.URL.Query().Get(key)
+	if len(param) == 0 {
+		return time.Date(def.Year(), def.Month(), def.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+
+	date, err := time.Parse("2006-01-02", param)
+	if err != nil {
+		return date, api.ErrBadRequest(fmt.Errorf("%s must be formatted as yyyy-mm-dd", key), "")
+	}
+
+	return date, nil
+}
@@ -32,6 +32,10 @@ func (u *Auths) Login(w http.ResponseWriter, r *http.Request) {
 
 	uLogin := models.User{Username: loginRequest.Username}
 	err = uLogin.GetByUsername(r.Context(), u.Db)
+	if err == sql.ErrNoRows {
+		u.auditLoginFailed(r, models.User{Username: loginRequest.Username})
+	}
+
 	if err != nil {
 		err = fmt.Errorf("call login: %v", err)
 		u.Log.Printf("ERROR : %+v", err)
@@ -41,6 +45,7 @@ func (u *Auths) Login(w http.ResponseWriter, r *http.Request) {
 
 	err = bcrypt.CompareHashAndPassword([]byte(uLogin.Password), []byte(loginRequest.Password))
 	if err != nil {
+		u.auditLoginFailed(r, uLogin)
 		u.Log.Printf("ERROR : %+v", err)
 		api.ResponseError(w, api.ErrBadRequest(fmt.Errorf("compare password: %v", err), ""))
 		return
@@ -58,3 +63,20 @@ func (u *Auths) Login(w http.ResponseWriter, r *http.Request) {
 
 	api.ResponseOK(w, response, http.StatusOK)
 }
+
+// auditLoginFailed write audit log of failed login of user, user of unknown username has no id and company.
+// It is best-effort, error of writing is only logged and the failed login response is kept.
+func (u *Auths) auditLoginFailed(r *http.Request, user models.User) {
+	auditLog := models.AuditLog{
+		Company:  user.Company,
+		User:     user,
+		Action:   models.AuditLoginFailed,
+		Route:    http.MethodPost + " /login",
+		Entity:   "users",
+		EntityID: user.ID,
+	}
+
+	if err := auditLog.Create(r.Context(), u.Db); err != nil {
+		u.Log.Printf("ERROR : audit log: %+v", err)
+	}
+}
@@ -2,12 +2,16 @@ package controllers
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/jacky-htg/inventory/libraries/api"
 	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/request"
+	"github.com/jacky-htg/inventory/payloads/response"
 )
 
 // ClosingStocks : struct for set ClosingStocks Dependency Injection
@@ -16,16 +20,189 @@ type ClosingStocks struct {
 	Log *log.Logger
 }
 
-// Closing : http handler for closing stock
+// List : http handler for returning closed periods, filtered by year and month query
+func (u *ClosingStocks) List(w http.ResponseWriter, r *http.Request) {
+	closingStock, err := u.getPeriod(r)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("begin transaction: %v", err))
+		return
+	}
+
+	list, err := closingStock.List(r.Context(), tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting closing stocks list: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	listResponse := []*response.ClosingStockResponse{}
+	for _, c := range list {
+		var closingStockResponse response.ClosingStockResponse
+		closingStockResponse.Transform(&c)
+		listResponse = append(listResponse, &closingStockResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// Closing : http handler for closing stock.
+// The period can be selected by year and month query, and dry_run=true query only preview the closing.
+// Closing of every period is committed at once, so a failed closing leaves no period half closed.
 func (u *ClosingStocks) Closing(w http.ResponseWriter, r *http.Request) {
-	var closingStock models.ClosingStock
+	closingStock, err := u.getPeriod(r)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
 
-	err := closingStock.Closing(r.Context(), u.Db)
+	tx, err := u.Db.Begin()
 	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("begin transaction: %v", err))
+		return
+	}
+
+	if r.URL.Query().Get("dry_run") == "true" {
+		err = closingStock.Preview(r.Context(), tx)
+	} else {
+		err = closingStock.Closing(r.Context(), tx)
+	}
+
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
 		u.Log.Printf("ERROR : %+v", err)
 		api.ResponseError(w, fmt.Errorf("closing stock: %v", err))
 		return
 	}
 
-	api.ResponseOK(w, nil, http.StatusOK)
+	tx.Commit()
+
+	var response response.ClosingStockResponse
+	response.Transform(closingStock)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// OpeningBalances : http handler for importing unit codes on hand before the first closing
+func (u *ClosingStocks) OpeningBalances(w http.ResponseWriter, r *http.Request) {
+	var openingBalanceRequest request.OpeningBalanceRequest
+	err := api.Decode(r, &openingBalanceRequest)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	date, balances := openingBalanceRequest.Transform()
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("begin transaction: %v", err))
+		return
+	}
+
+	var closingStock models.ClosingStock
+	err = closingStock.ImportOpeningBalances(r.Context(), tx, date, balances)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("import opening balances: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	api.ResponseOK(w, nil, http.StatusCreated)
+}
+
+// Reopen : http handler for reopening closed period, the reopened periods are closed again by closing stock
+func (u *ClosingStocks) Reopen(w http.ResponseWriter, r *http.Request) {
+	var closingStockRequest request.ReopenClosingStockRequest
+	err := api.Decode(r, &closingStockRequest)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	closingStock := closingStockRequest.Transform()
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("begin transaction: %v", err))
+		return
+	}
+
+	list, err := closingStock.Reopen(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("reopen closing stock: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	listResponse := []*response.ClosingStockResponse{}
+	for _, c := range list {
+		var closingStockResponse response.ClosingStockResponse
+		closingStockResponse.Transform(&c)
+		listResponse = append(listResponse, &closingStockResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+func (u *ClosingStocks) getPeriod(r *http.Request) (*models.ClosingStock, error) {
+	var closingStock models.ClosingStock
+	var err error
+
+	query := r.URL.Query()
+	if year := query.Get("year"); len(year) > 0 {
+		closingStock.Year, err = strconv.Atoi(year)
+		if err != nil {
+			return &closingStock, api.ErrBadRequest(errors.New("year must be a number"), "")
+		}
+	}
+
+	if month := query.Get("month"); len(month) > 0 {
+		closingStock.Month, err = strconv.Atoi(month)
+		if err != nil || closingStock.Month < 1 || closingStock.Month > 12 {
+			return &closingStock, api.ErrBadRequest(errors.New("month must be between 1 and 12"), "")
+		}
+	}
+
+	return &closingStock, nil
 }
@@ -113,6 +113,13 @@ func (u *Deliveries) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = delivery.Create(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		u.Log.Printf("ERROR : %+v", err)
 		tx.Rollback()
@@ -174,6 +181,13 @@ func (u *Deliveries) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	deliveryUpdate := deliveryRequest.Transform(&delivery)
 	err = deliveryUpdate.Update(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		tx.Rollback()
 		u.Log.Printf("ERROR : %+v", err)
@@ -187,3 +201,70 @@ func (u *Deliveries) Update(w http.ResponseWriter, r *http.Request) {
 	response.Transform(deliveryUpdate)
 	api.ResponseOK(w, response, http.StatusOK)
 }
+
+// Void : http handler for voiding posted delivery by id, its movements are reversed in inventories
+func (u *Deliveries) Void(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var voidRequest request.VoidRequest
+	err = api.Decode(r, &voidRequest)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Decode void: %v", err))
+		return
+	}
+
+	var delivery models.Delivery
+	delivery.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = delivery.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get Delivery: %v", err))
+		return
+	}
+
+	voidRequest.Transform(&delivery.Voided)
+	err = delivery.Void(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Void Delivery: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.DeliveryResponse
+	response.Transform(&delivery)
+	api.ResponseOK(w, response, http.StatusOK)
+}
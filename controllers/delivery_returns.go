@@ -113,6 +113,13 @@ func (u *DeliveryReturns) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = deliveryReturn.Create(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		u.Log.Printf("ERROR : %+v", err)
 		tx.Rollback()
@@ -173,6 +180,13 @@ func (u *DeliveryReturns) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	deliveryReturnUpdate := deliveryReturnRequest.Transform(&deliveryReturn)
 	err = deliveryReturnUpdate.Update(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		tx.Rollback()
 		u.Log.Printf("ERROR : %+v", err)
@@ -186,3 +200,128 @@ func (u *DeliveryReturns) Update(w http.ResponseWriter, r *http.Request) {
 	response.Transform(deliveryReturnUpdate)
 	api.ResponseOK(w, response, http.StatusOK)
 }
+
+// Post : http handler for posting approved delivery return into inventories
+func (u *DeliveryReturns) Post(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var deliveryReturn models.DeliveryReturn
+	deliveryReturn.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = deliveryReturn.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get DeliveryReturn: %v", err))
+		return
+	}
+
+	err = deliveryReturn.Post(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Post DeliveryReturn: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.DeliveryReturnResponse
+	response.Transform(&deliveryReturn)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Void : http handler for voiding posted delivery return by id, its movements are reversed in inventories
+func (u *DeliveryReturns) Void(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var voidRequest request.VoidRequest
+	err = api.Decode(r, &voidRequest)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Decode void: %v", err))
+		return
+	}
+
+	var deliveryReturn models.DeliveryReturn
+	deliveryReturn.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = deliveryReturn.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get DeliveryReturn: %v", err))
+		return
+	}
+
+	voidRequest.Transform(&deliveryReturn.Voided)
+	err = deliveryReturn.Void(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Void DeliveryReturn: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.DeliveryReturnResponse
+	response.Transform(&deliveryReturn)
+	api.ResponseOK(w, response, http.StatusOK)
+}
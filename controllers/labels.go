@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/libraries/label"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Labels : struct for set Labels Dependency Injection
+type Labels struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// Product : http handler for rendering barcode of product code as png or svg
+func (u *Labels) Product(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var product models.Product
+	product.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = product.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get product: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	u.renderBarcode(w, r, product.Code)
+}
+
+// Unit : http handler for rendering barcode of unit code as png or svg
+func (u *Labels) Unit(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	unitLabel := models.Label{Code: ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("code")}
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = unitLabel.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get unit code: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	u.renderBarcode(w, r, unitLabel.Code)
+}
+
+// Receive : http handler for rendering label sheet of every unit code on good receiving as pdf or zpl
+func (u *Labels) Receive(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if len(format) == 0 {
+		format = label.FormatPDF
+	}
+
+	if format != label.FormatPDF && format != label.FormatZPL {
+		err = fmt.Errorf("Unknown label sheet format %s", format)
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrBadRequest(err, ""))
+		return
+	}
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	var unitLabel models.Label
+	list, err := unitLabel.ListByReceive(ctx, tx, &models.Receive{ID: uint64(id)})
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting labels of receive: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var labels []label.Label
+	for _, l := range list {
+		caption := l.Product.Code + " " + l.Product.Name
+		if len(l.Lot) > 0 {
+			caption += " / " + l.Lot
+		}
+
+		labels = append(labels, label.Label{Code: l.Code, Caption: caption})
+	}
+
+	var body bytes.Buffer
+	if format == label.FormatZPL {
+		err = label.ZPL(&body, u.getSymbology(r), labels)
+	} else {
+		err = label.PDF(&body, u.getSymbology(r), labels)
+	}
+
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrBadRequest(err, err.Error()))
+		return
+	}
+
+	api.ResponseRaw(w, body.Bytes(), label.ContentTypes[format], http.StatusOK)
+}
+
+func (u *Labels) renderBarcode(w http.ResponseWriter, r *http.Request, content string) {
+	bc, err := label.Encode(u.getSymbology(r), content)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrBadRequest(err, err.Error()))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if len(format) == 0 {
+		format = label.FormatPNG
+	}
+
+	var body bytes.Buffer
+	switch format {
+	case label.FormatPNG:
+		err = label.PNG(&body, bc, 400, 120)
+	case label.FormatSVG:
+		err = label.SVG(&body, bc, 400, 120)
+	default:
+		err = api.ErrBadRequest(fmt.Errorf("Unknown barcode format %s", format), "")
+	}
+
+	if _, ok := err.(*api.Error); ok {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("rendering barcode: %v", err))
+		return
+	}
+
+	api.ResponseRaw(w, body.Bytes(), label.ContentTypes[format], http.StatusOK)
+}
+
+func (u *Labels) getSymbology(r *http.Request) string {
+	symbology := r.URL.Query().Get("type")
+	if len(symbology) == 0 {
+		return label.Code128
+	}
+
+	return symbology
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Lots : struct for set Lots Dependency Injection
+type Lots struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// Trace : http handler for returning customers who received units of a lot
+func (u *Lots) Trace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramLot := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("lot")
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	lotTrace := models.LotTrace{Lot: paramLot}
+	list, err := lotTrace.List(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("tracing lot: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var lotTraceResponse response.LotTraceResponse
+	lotTraceResponse.Transform(paramLot, list)
+	api.ResponseOK(w, lotTraceResponse, http.StatusOK)
+}
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/request"
+	"github.com/jacky-htg/inventory/payloads/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Mutations : struct for set Mutations Dependency Injection
+type Mutations struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// List : http handler for returning list of Mutations
+func (u *Mutations) List(w http.ResponseWriter, r *http.Request) {
+	var mutation models.Mutation
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	list, err := mutation.List(r.Context(), tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting Mutations list: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var listResponse []*response.MutationListResponse
+	for _, r := range list {
+		var mutationResponse response.MutationListResponse
+		mutationResponse.Transform(&r)
+		listResponse = append(listResponse, &mutationResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// View : http handler for retrieve Mutation by id
+func (u *Mutations) View(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting: %v", err))
+		return
+	}
+
+	var mutation models.Mutation
+	mutation.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = mutation.Get(ctx, tx)
+
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get Mutation: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.MutationResponse
+	response.Transform(&mutation)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Create : http handler for create new Mutation
+func (u *Mutations) Create(w http.ResponseWriter, r *http.Request) {
+	var mutationRequest request.NewMutationRequest
+	err := api.Decode(r, &mutationRequest)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("decode Mutation: %v", err))
+		return
+	}
+
+	mutation := mutationRequest.Transform()
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("begin transaction: %v", err))
+		return
+	}
+
+	err = mutation.Create(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		u.Log.Printf("ERROR : %+v", err)
+		tx.Rollback()
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		tx.Rollback()
+		api.ResponseError(w, fmt.Errorf("Create Mutation: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.MutationResponse
+	response.Transform(mutation)
+	api.ResponseOK(w, response, http.StatusCreated)
+}
+
+// Update : http handler for update Mutation by id
+func (u *Mutations) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var mutation models.Mutation
+	mutation.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = mutation.Get(ctx, tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get Mutation: %v", err))
+		return
+	}
+
+	var mutationRequest request.MutationRequest
+	err = api.Decode(r, &mutationRequest)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Decode Mutation: %v", err))
+		return
+	}
+
+	if mutationRequest.ID <= 0 {
+		mutationRequest.ID = mutation.ID
+	}
+	mutationUpdate := mutationRequest.Transform(&mutation)
+	err = mutationUpdate.Update(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Update Mutation: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.MutationResponse
+	response.Transform(mutationUpdate)
+	api.ResponseOK(w, response, http.StatusOK)
+}
@@ -0,0 +1,325 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/request"
+	"github.com/jacky-htg/inventory/payloads/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// ProductTemplates : struct for set ProductTemplates Dependency Injection
+type ProductTemplates struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// List : http handler for returning list of product templates
+func (u *ProductTemplates) List(w http.ResponseWriter, r *http.Request) {
+	var template models.ProductTemplate
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("begin transaction: %v", err))
+		return
+	}
+
+	list, err := template.List(r.Context(), tx)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		tx.Rollback()
+		api.ResponseError(w, fmt.Errorf("getting product templates list: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	listResponse := []*response.ProductTemplateResponse{}
+	for _, template := range list {
+		var templateResponse response.ProductTemplateResponse
+		templateResponse.Transform(&template)
+		listResponse = append(listResponse, &templateResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// View : http handler for retrieve product template by id, including its attributes and variants
+func (u *ProductTemplates) View(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting: %v", err))
+		return
+	}
+
+	var template models.ProductTemplate
+	template.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = template.Get(ctx, tx)
+
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get product template: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.ProductTemplateResponse
+	response.Transform(&template)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Create : http handler for create new product template
+func (u *ProductTemplates) Create(w http.ResponseWriter, r *http.Request) {
+	var templateRequest request.NewProductTemplateRequest
+	err := api.Decode(r, &templateRequest)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("decode product template: %v", err))
+		return
+	}
+
+	template := templateRequest.Transform()
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = template.Create(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Create product template: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.ProductTemplateResponse
+	response.Transform(template)
+	api.ResponseOK(w, response, http.StatusCreated)
+}
+
+// Update : http handler for update product template by id
+func (u *ProductTemplates) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var template models.ProductTemplate
+	template.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = template.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get product template: %v", err))
+		return
+	}
+
+	var templateRequest request.ProductTemplateRequest
+	err = api.Decode(r, &templateRequest)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Decode product template: %v", err))
+		return
+	}
+
+	if templateRequest.ID <= 0 {
+		templateRequest.ID = template.ID
+	}
+	templateUpdate := templateRequest.Transform(&template)
+	err = templateUpdate.Update(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Update product template: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.ProductTemplateResponse
+	response.Transform(templateUpdate)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Delete : http handler for delete product template by id
+func (u *ProductTemplates) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var template models.ProductTemplate
+	template.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = template.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get product template: %v", err))
+		return
+	}
+
+	err = template.Delete(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Delete product template: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	api.ResponseOK(w, nil, http.StatusNoContent)
+}
+
+// GenerateVariants : http handler for generating variant products of product template
+func (u *ProductTemplates) GenerateVariants(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var template models.ProductTemplate
+	template.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = template.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get product template: %v", err))
+		return
+	}
+
+	list, err := template.GenerateVariants(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Generate variants: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	listResponse := []*response.ProductResponse{}
+	for _, product := range list {
+		var productResponse response.ProductResponse
+		productResponse.Transform(&product)
+		listResponse = append(listResponse, &productResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusCreated)
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/request"
+	"github.com/jacky-htg/inventory/payloads/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// ProductUoms : struct for set ProductUoms Dependency Injection
+type ProductUoms struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// List : http handler for returning unit of measure conversions of product
+func (u *ProductUoms) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	product, err := u.getProduct(r, tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	productUom := models.ProductUom{Product: *product}
+	list, err := productUom.List(ctx, tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting product uoms: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	listResponse := []*response.ProductUomResponse{}
+	for _, c := range list {
+		var productUomResponse response.ProductUomResponse
+		productUomResponse.Transform(&c)
+		listResponse = append(listResponse, &productUomResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// Save : http handler for saving unit of measure conversion of product
+func (u *ProductUoms) Save(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var productUomRequest request.ProductUomRequest
+	err := api.Decode(r, &productUomRequest)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("decode product uom: %v", err))
+		return
+	}
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	product, err := u.getProduct(r, tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	productUom := productUomRequest.Transform(product)
+	err = productUom.Save(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Save product uom: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.ProductUomResponse
+	response.Transform(productUom)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Delete : http handler for deleting unit of measure conversion of product
+func (u *ProductUoms) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramUomID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("uom_id")
+	uomID, err := strconv.Atoi(paramUomID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramUomID: %v", err))
+		return
+	}
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	product, err := u.getProduct(r, tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	productUom := models.ProductUom{Product: *product}
+	productUom.Uom.ID = uint64(uomID)
+	err = productUom.Delete(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Delete product uom: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	api.ResponseOK(w, nil, http.StatusNoContent)
+}
+
+func (u *ProductUoms) getProduct(r *http.Request, tx *sql.Tx) (*models.Product, error) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		return nil, fmt.Errorf("type casting paramID: %v", err)
+	}
+
+	product := models.Product{ID: uint64(id)}
+	err = product.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		return nil, api.ErrNotFound(err, "")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Get product: %v", err)
+	}
+
+	return &product, nil
+}
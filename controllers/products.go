@@ -113,6 +113,13 @@ func (u *Products) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = product.Create(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		tx.Rollback()
 		u.Log.Printf("ERROR : %+v", err)
@@ -170,6 +177,13 @@ func (u *Products) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	productUpdate := productRequest.Transform(&product)
 	err = productUpdate.Update(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		tx.Rollback()
 		u.Log.Printf("ERROR : %+v", err)
@@ -225,3 +239,46 @@ func (u *Products) Delete(w http.ResponseWriter, r *http.Request) {
 
 	api.ResponseOK(w, nil, http.StatusNoContent)
 }
+
+// Availability : http handler for returning available to promise quantity of product in every branch
+func (u *Products) Availability(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var availability models.Availability
+	availability.Product.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	list, err := availability.List(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting product availability: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var res response.ProductAvailabilityResponse
+	res.Transform(&availability.Product, list)
+	api.ResponseOK(w, res, http.StatusOK)
+}
@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// PurchaseApprovals : struct for set Purchase Approvals Dependency Injection.
+// Approving purchase has its own access, apart from access of maintaining purchases.
+type PurchaseApprovals struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// Approve : http handler for approving submitted purchase
+func (u *PurchaseApprovals) Approve(w http.ResponseWriter, r *http.Request) {
+	purchases := Purchases{Db: u.Db, Log: u.Log}
+	purchases.changeStatus(w, r, "Approve", (*models.Purchase).Approve)
+}
+
+// Reject : http handler for rejecting submitted purchase back to draft
+func (u *PurchaseApprovals) Reject(w http.ResponseWriter, r *http.Request) {
+	purchases := Purchases{Db: u.Db, Log: u.Log}
+	purchases.changeStatus(w, r, "Reject", (*models.Purchase).Reject)
+}
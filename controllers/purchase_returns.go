@@ -116,6 +116,13 @@ func (u *PurchaseReturns) Create(w http.ResponseWriter, r *http.Request) {
 	// todo check PurchaseID isvalid open to return
 
 	err = purchaseReturn.Create(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		u.Log.Printf("ERROR : %+v", err)
 		tx.Rollback()
@@ -177,6 +184,13 @@ func (u *PurchaseReturns) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	purchaseReturnUpdate := purchaseReturnRequest.Transform(&purchaseReturn)
 	err = purchaseReturnUpdate.Update(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		tx.Rollback()
 		u.Log.Printf("ERROR : %+v", err)
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/request"
+	"github.com/jacky-htg/inventory/payloads/response"
+)
+
+// PurchaseSuggestions : struct for set PurchaseSuggestions Dependency Injection
+type PurchaseSuggestions struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// List : http handler for returning purchase suggestions grouped by supplier
+func (u *PurchaseSuggestions) List(w http.ResponseWriter, r *http.Request) {
+	var purchaseSuggestion models.PurchaseSuggestion
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	list, err := purchaseSuggestion.List(r.Context(), tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting PurchaseSuggestions list: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	listResponse := []*response.PurchaseSuggestionResponse{}
+	for _, s := range list {
+		var purchaseSuggestionResponse response.PurchaseSuggestionResponse
+		purchaseSuggestionResponse.Transform(&s)
+		listResponse = append(listResponse, &purchaseSuggestionResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// Create : http handler for create new purchase from purchase suggestion
+func (u *PurchaseSuggestions) Create(w http.ResponseWriter, r *http.Request) {
+	var purchaseSuggestionRequest request.NewPurchaseSuggestionRequest
+	err := api.Decode(r, &purchaseSuggestionRequest)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("decode PurchaseSuggestion: %v", err))
+		return
+	}
+
+	purchaseSuggestion, date := purchaseSuggestionRequest.Transform()
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("begin transaction: %v", err))
+		return
+	}
+
+	purchase, err := purchaseSuggestion.CreatePurchase(r.Context(), tx, date, purchaseSuggestionRequest.ProductIDs)
+	if _, ok := err.(*api.Error); ok {
+		u.Log.Printf("ERROR : %+v", err)
+		tx.Rollback()
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		tx.Rollback()
+		api.ResponseError(w, fmt.Errorf("Create Purchase from suggestion: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.PurchaseResponse
+	response.Transform(purchase)
+	api.ResponseOK(w, response, http.StatusCreated)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -113,6 +114,13 @@ func (u *Purchases) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = purchase.Create(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		u.Log.Printf("ERROR : %+v", err)
 		tx.Rollback()
@@ -176,6 +184,13 @@ func (u *Purchases) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	purchaseUpdate := purchaseRequest.Transform(&purchase)
 	err = purchaseUpdate.Update(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		tx.Rollback()
 		u.Log.Printf("ERROR : %+v", err)
@@ -189,3 +204,76 @@ func (u *Purchases) Update(w http.ResponseWriter, r *http.Request) {
 	response.Transform(purchaseUpdate)
 	api.ResponseOK(w, response, http.StatusOK)
 }
+
+// Submit : http handler for submitting draft purchase for approval
+func (u *Purchases) Submit(w http.ResponseWriter, r *http.Request) {
+	u.changeStatus(w, r, "Submit", (*models.Purchase).Submit)
+}
+
+// Close : http handler for closing approved purchase
+func (u *Purchases) Close(w http.ResponseWriter, r *http.Request) {
+	u.changeStatus(w, r, "Close", (*models.Purchase).Close)
+}
+
+// Cancel : http handler for cancelling purchase which has not been received
+func (u *Purchases) Cancel(w http.ResponseWriter, r *http.Request) {
+	u.changeStatus(w, r, "Cancel", (*models.Purchase).Cancel)
+}
+
+// changeStatus get purchase by id and apply status transition to it
+func (u *Purchases) changeStatus(w http.ResponseWriter, r *http.Request, action string, transition func(*models.Purchase, context.Context, *sql.Tx) error) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var purchase models.Purchase
+	purchase.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = purchase.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get purchase: %v", err))
+		return
+	}
+
+	err = transition(&purchase, ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("%s purchase: %v", action, err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.PurchaseResponse
+	response.Transform(&purchase)
+	api.ResponseOK(w, response, http.StatusOK)
+}
@@ -113,6 +113,13 @@ func (u *ReceiveReturns) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = receiveReturn.Create(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		u.Log.Printf("ERROR : %+v", err)
 		tx.Rollback()
@@ -174,6 +181,13 @@ func (u *ReceiveReturns) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	receiveReturnUpdate := receiveReturnRequest.Transform(&receiveReturn)
 	err = receiveReturnUpdate.Update(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		tx.Rollback()
 		u.Log.Printf("ERROR : %+v", err)
@@ -187,3 +201,128 @@ func (u *ReceiveReturns) Update(w http.ResponseWriter, r *http.Request) {
 	response.Transform(receiveReturnUpdate)
 	api.ResponseOK(w, response, http.StatusOK)
 }
+
+// Post : http handler for posting approved receive return into inventories
+func (u *ReceiveReturns) Post(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var receiveReturn models.ReceiveReturn
+	receiveReturn.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = receiveReturn.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get ReceiveReturn: %v", err))
+		return
+	}
+
+	err = receiveReturn.Post(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Post ReceiveReturn: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.ReceiveReturnResponse
+	response.Transform(&receiveReturn)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Void : http handler for voiding posted receive return by id, its movements are reversed in inventories
+func (u *ReceiveReturns) Void(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var voidRequest request.VoidRequest
+	err = api.Decode(r, &voidRequest)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Decode void: %v", err))
+		return
+	}
+
+	var receiveReturn models.ReceiveReturn
+	receiveReturn.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = receiveReturn.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get ReceiveReturn: %v", err))
+		return
+	}
+
+	voidRequest.Transform(&receiveReturn.Voided)
+	err = receiveReturn.Void(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Void ReceiveReturn: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.ReceiveReturnResponse
+	response.Transform(&receiveReturn)
+	api.ResponseOK(w, response, http.StatusOK)
+}
@@ -113,6 +113,13 @@ func (u *Receives) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = receive.Create(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		u.Log.Printf("ERROR : %+v", err)
 		tx.Rollback()
@@ -174,6 +181,13 @@ func (u *Receives) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	receiveUpdate := receiveRequest.Transform(&receive)
 	err = receiveUpdate.Update(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		tx.Rollback()
 		u.Log.Printf("ERROR : %+v", err)
@@ -187,3 +201,70 @@ func (u *Receives) Update(w http.ResponseWriter, r *http.Request) {
 	response.Transform(receiveUpdate)
 	api.ResponseOK(w, response, http.StatusOK)
 }
+
+// Void : http handler for voiding posted receive by id, its movements are reversed in inventories
+func (u *Receives) Void(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var voidRequest request.VoidRequest
+	err = api.Decode(r, &voidRequest)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Decode void: %v", err))
+		return
+	}
+
+	var receive models.Receive
+	receive.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = receive.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get Receive: %v", err))
+		return
+	}
+
+	voidRequest.Transform(&receive.Voided)
+	err = receive.Void(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Void Receive: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.ReceiveResponse
+	response.Transform(&receive)
+	api.ResponseOK(w, response, http.StatusOK)
+}
@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/response"
+)
+
+// Reports : struct for set Reports Dependency Injection
+type Reports struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// Expiring : http handler for returning unit codes on hand which expire within days, default 30 days
+func (u *Reports) Expiring(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if paramDays := r.URL.Query().Get("days"); len(paramDays) > 0 {
+		var err error
+		days, err = strconv.Atoi(paramDays)
+		if err != nil || days < 0 {
+			u.Log.Printf("ERROR : %+v", err)
+			api.ResponseError(w, api.ErrBadRequest(errors.New("days must be a positive number"), ""))
+			return
+		}
+	}
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	var stock models.Stock
+	list, err := stock.ListExpiring(r.Context(), tx, days)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting expiring stocks: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	listResponse := []*response.ExpiringStockResponse{}
+	for _, s := range list {
+		var expiringStockResponse response.ExpiringStockResponse
+		expiringStockResponse.Transform(&s)
+		listResponse = append(listResponse, &expiringStockResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// Shelves : http handler for returning shelves utilization per branch
+func (u *Reports) Shelves(w http.ResponseWriter, r *http.Request) {
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	var stock models.Stock
+	list, err := stock.ListShelveUtilization(r.Context(), tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting shelves utilization: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	listResponse := []*response.ShelveUtilizationResponse{}
+	for _, s := range list {
+		var shelveUtilizationResponse response.ShelveUtilizationResponse
+		shelveUtilizationResponse.Transform(&s)
+		listResponse = append(listResponse, &shelveUtilizationResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// StockValuation : http handler for returning value of stock on hand at date, default today, rolled up to product template when group=template
+func (u *Reports) StockValuation(w http.ResponseWriter, r *http.Request) {
+	date, err := u.getDate(r, "date", time.Now())
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	var stock models.Stock
+	list, err := stock.ListValuation(r.Context(), tx, date)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting stock valuation: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	if r.URL.Query().Get("group") == "template" {
+		templateResponse := []*response.TemplateStockValuationResponse{}
+		for _, s := range list {
+			var stockValuationResponse response.TemplateStockValuationResponse
+			stockValuationResponse.Transform(&s)
+			templateResponse = append(templateResponse, &stockValuationResponse)
+		}
+
+		api.ResponseOK(w, templateResponse, http.StatusOK)
+		return
+	}
+
+	listResponse := []*response.StockValuationResponse{}
+	for _, s := range list {
+		var stockValuationResponse response.StockValuationResponse
+		stockValuationResponse.Transform(&s)
+		listResponse = append(listResponse, &stockValuationResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// GrossMargin : http handler for returning gross margin per delivery between start_date and end_date, default current month
+func (u *Reports) GrossMargin(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	startDate, err := u.getDate(r, "start_date", time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	endDate, err := u.getDate(r, "end_date", now)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	var delivery models.Delivery
+	list, err := delivery.ListMargins(r.Context(), tx, startDate, endDate)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting gross margin: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	listResponse := []*response.DeliveryMarginResponse{}
+	for _, m := range list {
+		var deliveryMarginResponse response.DeliveryMarginResponse
+		deliveryMarginResponse.Transform(&m)
+		listResponse = append(listResponse, &deliveryMarginResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// Backorders : http handler for returning outstanding quantity of open sales orders grouped by customer and product
+func (u *Reports) Backorders(w http.ResponseWriter, r *http.Request) {
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	var salesOrder models.SalesOrder
+	list, err := salesOrder.ListBackorders(r.Context(), tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting backorders: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	listResponse := []*response.CustomerBackorderResponse{}
+	for _, b := range list {
+		var customerBackorderResponse response.CustomerBackorderResponse
+		customerBackorderResponse.Transform(&b)
+		listResponse = append(listResponse, &customerBackorderResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// getDate parse query param formatted as 2006-01-02, return def if param is empty
+func (u *Reports) getDate(r *http.Request, key string, def time.Time) (time.Time, error) {
+	param := r.URL.Query().Get(key)
+	if len(param) == 0 {
+		return time.Date(def.Year(), def.Month(), def.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+
+	date, err := time.Parse("2006-01-02", param)
+	if err != nil {
+		return date, api.ErrBadRequest(fmt.Errorf("%s must be formatted as yyyy-mm-dd", key), "")
+	}
+
+	return date, nil
+}
@@ -116,6 +116,13 @@ func (u *SalesOrderReturns) Create(w http.ResponseWriter, r *http.Request) {
 	// todo check SalesOrderID isvalid open to return
 
 	err = salesOrderReturn.Create(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		u.Log.Printf("ERROR : %+v", err)
 		tx.Rollback()
@@ -177,6 +184,13 @@ func (u *SalesOrderReturns) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	salesOrderReturnUpdate := salesOrderReturnRequest.Transform(&salesOrderReturn)
 	err = salesOrderReturnUpdate.Update(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		tx.Rollback()
 		u.Log.Printf("ERROR : %+v", err)
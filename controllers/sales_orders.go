@@ -113,6 +113,13 @@ func (u *SalesOrders) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = salesOrder.Create(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		u.Log.Printf("ERROR : %+v", err)
 		tx.Rollback()
@@ -176,6 +183,13 @@ func (u *SalesOrders) Update(w http.ResponseWriter, r *http.Request) {
 	}
 	salesOrderUpdate := salesOrderRequest.Transform(&salesOrder)
 	err = salesOrderUpdate.Update(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
 	if err != nil {
 		tx.Rollback()
 		u.Log.Printf("ERROR : %+v", err)
@@ -189,3 +203,61 @@ func (u *SalesOrders) Update(w http.ResponseWriter, r *http.Request) {
 	res.Transform(salesOrderUpdate)
 	api.ResponseOK(w, res, http.StatusOK)
 }
+
+// Cancel : http handler for cancel SalesOrder by id, releasing its reserved quantity
+func (u *SalesOrders) Cancel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var salesOrder models.SalesOrder
+	salesOrder.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = salesOrder.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get SalesOrder: %v", err))
+		return
+	}
+
+	err = salesOrder.Cancel(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Cancel SalesOrder: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var res response.SalesOrderResponse
+	res.Transform(&salesOrder)
+	api.ResponseOK(w, res, http.StatusOK)
+}
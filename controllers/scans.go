@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Scans : struct for set Scans Dependency Injection
+type Scans struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// Lookup : http handler for resolving scanned code into unit codes, products and transactions
+func (u *Scans) Lookup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramCode := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("code")
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	scan := models.Scan{Code: paramCode}
+	list, err := scan.List(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("scanning code: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	listResponse := []*response.ScanResponse{}
+	for _, s := range list {
+		var scanResponse response.ScanResponse
+		scanResponse.Transform(&s)
+		listResponse = append(listResponse, &scanResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
@@ -0,0 +1,447 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/request"
+	"github.com/jacky-htg/inventory/payloads/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// StockOpnames : struct for set StockOpnames Dependency Injection
+type StockOpnames struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// List : http handler for returning list of StockOpnames
+func (u *StockOpnames) List(w http.ResponseWriter, r *http.Request) {
+	var stockOpname models.StockOpname
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	list, err := stockOpname.List(r.Context(), tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting StockOpnames list: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var listResponse []*response.StockOpnameListResponse
+	for _, r := range list {
+		var stockOpnameResponse response.StockOpnameListResponse
+		stockOpnameResponse.Transform(&r)
+		listResponse = append(listResponse, &stockOpnameResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// View : http handler for retrieve StockOpname by id
+func (u *StockOpnames) View(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting: %v", err))
+		return
+	}
+
+	var stockOpname models.StockOpname
+	stockOpname.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = stockOpname.Get(ctx, tx)
+
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get StockOpname: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.StockOpnameResponse
+	response.Transform(&stockOpname)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Create : http handler for create new StockOpname
+func (u *StockOpnames) Create(w http.ResponseWriter, r *http.Request) {
+	var stockOpnameRequest request.NewStockOpnameRequest
+	err := api.Decode(r, &stockOpnameRequest)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("decode StockOpname: %v", err))
+		return
+	}
+
+	stockOpname := stockOpnameRequest.Transform()
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("begin transaction: %v", err))
+		return
+	}
+
+	err = stockOpname.Create(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		u.Log.Printf("ERROR : %+v", err)
+		tx.Rollback()
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		tx.Rollback()
+		api.ResponseError(w, fmt.Errorf("Create StockOpname: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.StockOpnameResponse
+	response.Transform(stockOpname)
+	api.ResponseOK(w, response, http.StatusCreated)
+}
+
+// Update : http handler for update StockOpname by id
+func (u *StockOpnames) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var stockOpname models.StockOpname
+	stockOpname.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = stockOpname.Get(ctx, tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get StockOpname: %v", err))
+		return
+	}
+
+	var stockOpnameRequest request.StockOpnameRequest
+	err = api.Decode(r, &stockOpnameRequest)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Decode StockOpname: %v", err))
+		return
+	}
+
+	if stockOpnameRequest.ID <= 0 {
+		stockOpnameRequest.ID = stockOpname.ID
+	}
+	stockOpnameUpdate := stockOpnameRequest.Transform(&stockOpname)
+	err = stockOpnameUpdate.Update(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Update StockOpname: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.StockOpnameResponse
+	response.Transform(stockOpnameUpdate)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Count : http handler for storing counted unit codes of StockOpname
+func (u *StockOpnames) Count(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var countRequest request.StockOpnameCountRequest
+	err = api.Decode(r, &countRequest)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Decode StockOpname count: %v", err))
+		return
+	}
+
+	var stockOpname models.StockOpname
+	stockOpname.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = stockOpname.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get StockOpname: %v", err))
+		return
+	}
+
+	err = stockOpname.Count(ctx, tx, countRequest.Transform())
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Count StockOpname: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.StockOpnameResponse
+	response.Transform(&stockOpname)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Upload : http handler for storing bulk scan upload of StockOpname.
+// The csv is sent as file field of multipart form or as raw request body.
+func (u *StockOpnames) Upload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var uploadRequest request.StockOpnameUploadRequest
+	uploadRequest.Body = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			u.Log.Printf("ERROR : %+v", err)
+			api.ResponseError(w, api.ErrBadRequest(err, ""))
+			return
+		}
+
+		defer file.Close()
+		uploadRequest.Body = file
+	}
+
+	scans, err := uploadRequest.Transform()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrBadRequest(err, err.Error()))
+		return
+	}
+
+	var stockOpname models.StockOpname
+	stockOpname.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = stockOpname.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get StockOpname: %v", err))
+		return
+	}
+
+	err = stockOpname.Upload(ctx, tx, scans)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Upload StockOpname: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.StockOpnameResponse
+	response.Transform(&stockOpname)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Approve : http handler for approving StockOpname and posting its variances
+func (u *StockOpnames) Approve(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var stockOpname models.StockOpname
+	stockOpname.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = stockOpname.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get StockOpname: %v", err))
+		return
+	}
+
+	err = stockOpname.Approve(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Approve StockOpname: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.StockOpnameResponse
+	response.Transform(&stockOpname)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Report : http handler for report of StockOpname variances
+func (u *StockOpnames) Report(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting: %v", err))
+		return
+	}
+
+	var stockOpname models.StockOpname
+	stockOpname.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = stockOpname.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get StockOpname: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.StockOpnameReportResponse
+	response.Transform(&stockOpname)
+	api.ResponseOK(w, response, http.StatusOK)
+}
@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Stocks : struct for set Stocks Dependency Injection
+type Stocks struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// List : http handler for returning stock on hand of all products, rolled up to product template when group=template
+func (u *Stocks) List(w http.ResponseWriter, r *http.Request) {
+	var stock models.Stock
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	list, err := stock.List(r.Context(), tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting Stocks list: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	if r.URL.Query().Get("group") == "template" {
+		templateResponse := []*response.TemplateStockResponse{}
+		for _, t := range stock.RollupTemplates(list) {
+			var stockResponse response.TemplateStockResponse
+			stockResponse.Transform(&t)
+			templateResponse = append(templateResponse, &stockResponse)
+		}
+
+		api.ResponseOK(w, templateResponse, http.StatusOK)
+		return
+	}
+
+	listResponse := []*response.StockResponse{}
+	for _, s := range list {
+		var stockResponse response.StockResponse
+		stockResponse.Transform(&s)
+		listResponse = append(listResponse, &stockResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// View : http handler for retrieve stock on hand of a product
+func (u *Stocks) View(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("product_id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting: %v", err))
+		return
+	}
+
+	var stock models.Stock
+	stock.Product.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = stock.Get(ctx, tx)
+
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get Stock: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.StockResponse
+	response.Transform(&stock)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// ListByBranch : http handler for returning stock on hand of all products in a branch, rolled up to product template when group=template
+func (u *Stocks) ListByBranch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting: %v", err))
+		return
+	}
+
+	var stock models.Stock
+	stock.Branch.ID = uint32(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	list, err := stock.ListByBranch(ctx, tx)
+
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting branch Stocks list: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	if r.URL.Query().Get("group") == "template" {
+		templateResponse := []*response.BranchTemplateStockResponse{}
+		for _, t := range stock.RollupTemplates(list) {
+			var stockResponse response.BranchTemplateStockResponse
+			stockResponse.Transform(&t)
+			templateResponse = append(templateResponse, &stockResponse)
+		}
+
+		api.ResponseOK(w, templateResponse, http.StatusOK)
+		return
+	}
+
+	listResponse := []*response.BranchStockResponse{}
+	for _, s := range list {
+		var stockResponse response.BranchStockResponse
+		stockResponse.Transform(&s)
+		listResponse = append(listResponse, &stockResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// Units : http handler for returning unit codes on hand of a product in a branch
+func (u *Stocks) Units(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	params := ctx.Value(api.Ctx("ps")).(httprouter.Params)
+
+	id, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting: %v", err))
+		return
+	}
+
+	productID, err := strconv.Atoi(params.ByName("product_id"))
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting: %v", err))
+		return
+	}
+
+	var stock models.Stock
+	stock.Branch.ID = uint32(id)
+	stock.Product.ID = uint64(productID)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = stock.ListUnits(ctx, tx)
+
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting Stock units: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.StockUnitsResponse
+	response.Transform(&stock)
+	api.ResponseOK(w, response, http.StatusOK)
+}
@@ -0,0 +1,225 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// ApprovalRules : struct for set ApprovalRules Dependency Injection
+type ApprovalRules struct {
+	App   http.Handler
+	Token string
+}
+
+// Run : http handler for run approval rules testing
+func (u *ApprovalRules) Run(t *testing.T) {
+	created := u.Create(t)
+	id := created["data"].(map[string]interface{})["id"].(float64)
+	u.List(t, id)
+	u.View(t, id)
+	u.Update(t, id)
+	u.Delete(t, id)
+}
+
+// List : http handler for returning list of approval rules
+func (u *ApprovalRules) List(t *testing.T, id float64) {
+	req := httptest.NewRequest("GET", "/approval-rules", nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Token", u.Token)
+	resp := httptest.NewRecorder()
+
+	u.App.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("getting: expected status code %v, got %v", http.StatusOK, resp.Code)
+	}
+
+	var list map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("decoding: %s", err)
+	}
+
+	want := map[string]interface{}{
+		"status_code":    string("REBEL-200"),
+		"status_message": string("OK"),
+		"data": []interface{}{
+			u.rule(id, "Sales order discount", float64(0)),
+		},
+	}
+
+	if diff := cmp.Diff(want, list); diff != "" {
+		t.Fatalf("Response did not match expected. Diff:\n%s", diff)
+	}
+}
+
+// Create : http handler for create new approval rule
+func (u *ApprovalRules) Create(t *testing.T) map[string]interface{} {
+	var created map[string]interface{}
+	jsonBody := `
+		{
+			"document_type": "SO",
+			"name": "Sales order discount",
+			"min_discount": 10,
+			"steps": [1]
+		}
+	`
+	body := strings.NewReader(jsonBody)
+
+	req := httptest.NewRequest("POST", "/approval-rules", body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Token", u.Token)
+	resp := httptest.NewRecorder()
+
+	u.App.ServeHTTP(resp, req)
+
+	if http.StatusCreated != resp.Code {
+		t.Fatalf("posting: expected status code %v, got %v", http.StatusCreated, resp.Code)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("decoding: %s", err)
+	}
+
+	c := created["data"].(map[string]interface{})
+
+	if c["id"] == "" || c["id"] == nil {
+		t.Fatal("expected non-empty approval rule id")
+	}
+
+	want := map[string]interface{}{
+		"status_code":    "REBEL-200",
+		"status_message": "OK",
+		"data":           u.rule(c["id"].(float64), "Sales order discount", float64(0)),
+	}
+
+	if diff := cmp.Diff(want, created); diff != "" {
+		t.Fatalf("Response did not match expected. Diff:\n%s", diff)
+	}
+
+	return created
+}
+
+// View : http handler for retrieve approval rule by id
+func (u *ApprovalRules) View(t *testing.T, id float64) {
+	req := httptest.NewRequest("GET", "/approval-rules/"+fmt.Sprintf("%d", int(id)), nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Token", u.Token)
+	resp := httptest.NewRecorder()
+
+	u.App.ServeHTTP(resp, req)
+
+	if http.StatusOK != resp.Code {
+		t.Fatalf("retrieving: expected status code %v, got %v", http.StatusOK, resp.Code)
+	}
+
+	var fetched map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&fetched); err != nil {
+		t.Fatalf("decoding: %s", err)
+	}
+
+	want := map[string]interface{}{
+		"status_code":    "REBEL-200",
+		"status_message": "OK",
+		"data":           u.rule(id, "Sales order discount", float64(0)),
+	}
+
+	// Fetched approval rule should match the one we created.
+	if diff := cmp.Diff(want, fetched); diff != "" {
+		t.Fatalf("Retrieved approval rule should match created. Diff:\n%s", diff)
+	}
+}
+
+// Update : http handler for update approval rule by id
+func (u *ApprovalRules) Update(t *testing.T, id float64) {
+	var updated map[string]interface{}
+	jsonBody := `
+		{
+			"id": %s,
+			"document_type": "SO",
+			"name": "Large sales order discount",
+			"min_amount": 1000,
+			"min_discount": 10,
+			"steps": [1]
+		}
+	`
+	body := strings.NewReader(fmt.Sprintf(jsonBody, fmt.Sprintf("%d", int(id))))
+
+	req := httptest.NewRequest("PUT", "/approval-rules/"+fmt.Sprintf("%d", int(id)), body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Token", u.Token)
+	resp := httptest.NewRecorder()
+
+	u.App.ServeHTTP(resp, req)
+
+	if http.StatusOK != resp.Code {
+		t.Fatalf("posting: expected status code %v, got %v", http.StatusOK, resp.Code)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		t.Fatalf("decoding: %s", err)
+	}
+
+	want := map[string]interface{}{
+		"status_code":    "REBEL-200",
+		"status_message": "OK",
+		"data":           u.rule(id, "Large sales order discount", float64(1000)),
+	}
+
+	if diff := cmp.Diff(want, updated); diff != "" {
+		t.Fatalf("Response did not match expected. Diff:\n%s", diff)
+	}
+}
+
+// Delete approval rule
+func (u *ApprovalRules) Delete(t *testing.T, id float64) {
+	req := httptest.NewRequest("DELETE", "/approval-rules/"+fmt.Sprintf("%d", int(id)), nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Token", u.Token)
+	resp := httptest.NewRecorder()
+
+	u.App.ServeHTTP(resp, req)
+
+	if http.StatusNoContent != resp.Code {
+		t.Fatalf("retrieving: expected status code %v, got %v", http.StatusNoContent, resp.Code)
+	}
+
+	var deleted map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&deleted); err != nil {
+		t.Fatalf("decoding: %s", err)
+	}
+
+	want := map[string]interface{}{
+		"status_code":    "REBEL-200",
+		"status_message": "OK",
+		"data":           nil,
+	}
+
+	if diff := cmp.Diff(want, deleted); diff != "" {
+		t.Fatalf("Response did not match expected. Diff:\n%s", diff)
+	}
+}
+
+func (u *ApprovalRules) rule(id float64, name string, minAmount float64) map[string]interface{} {
+	return map[string]interface{}{
+		"id":            id,
+		"document_type": "SO",
+		"name":          name,
+		"min_amount":    minAmount,
+		"min_discount":  float64(10),
+		"steps": []interface{}{
+			map[string]interface{}{
+				"step": float64(1),
+				"role": map[string]interface{}{
+					"id":   float64(1),
+					"name": "superadmin",
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// AuditLogs : struct for set AuditLogs Dependency Injection
+type AuditLogs struct {
+	App   http.Handler
+	Db    *sql.DB
+	Token string
+}
+
+// Run : http handler for run audit logs testing
+func (u *AuditLogs) Run(t *testing.T) {
+	u.List(t)
+	u.Nested(t)
+	u.InvalidToken(t)
+}
+
+// List : http handler for returning audit logs of brands written by brands crud testing
+func (u *AuditLogs) List(t *testing.T) {
+	req := httptest.NewRequest("GET", "/audit-logs?entity=brands", nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Token", u.Token)
+	resp := httptest.NewRecorder()
+
+	u.App.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("getting: expected status code %v, got %v", http.StatusOK, resp.Code)
+	}
+
+	var list map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("decoding: %s", err)
+	}
+
+	var actions, routes []interface{}
+	for _, l := range list["data"].([]interface{}) {
+		auditLog := l.(map[string]interface{})
+		actions = append(actions, auditLog["action"])
+		routes = append(routes, auditLog["route"])
+
+		if auditLog["entity_id"] == float64(0) {
+			t.Fatal("expected non-empty entity id")
+		}
+	}
+
+	want := []interface{}{"delete", "update", "create"}
+	if diff := cmp.Diff(want, actions); diff != "" {
+		t.Fatalf("Actions did not match expected. Diff:\n%s", diff)
+	}
+
+	want = []interface{}{"DELETE /brands/:id", "PUT /brands/:id", "POST /brands"}
+	if diff := cmp.Diff(want, routes); diff != "" {
+		t.Fatalf("Routes did not match expected. Diff:\n%s", diff)
+	}
+}
+
+// Nested : http handler for returning audit log of nested route, its entity is the resource of the last parameter
+func (u *AuditLogs) Nested(t *testing.T) {
+	body := strings.NewReader(`{"code": "AU", "name": "Audit"}`)
+	req := httptest.NewRequest("POST", "/regions", body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Token", u.Token)
+	resp := httptest.NewRecorder()
+
+	u.App.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("posting: expected status code %v, got %v", http.StatusCreated, resp.Code)
+	}
+
+	var created map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("decoding: %s", err)
+	}
+
+	id := created["data"].(map[string]interface{})["id"].(float64)
+
+	req = httptest.NewRequest("POST", fmt.Sprintf("/regions/%d/branches/1", int(id)), nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Token", u.Token)
+	resp = httptest.NewRecorder()
+
+	u.App.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("adding branch: expected status code %v, got %v", http.StatusOK, resp.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/audit-logs?entity=branches&entity_id=1", nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Token", u.Token)
+	resp = httptest.NewRecorder()
+
+	u.App.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("getting: expected status code %v, got %v", http.StatusOK, resp.Code)
+	}
+
+	var list map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("decoding: %s", err)
+	}
+
+	data := list["data"].([]interface{})
+	if len(data) == 0 {
+		t.Fatal("expected audit log of nested route")
+	}
+
+	auditLog := data[0].(map[string]interface{})
+	if auditLog["route"] != "POST /regions/:id/branches/:branch_id" {
+		t.Fatalf("expected route of nested route, got %v", auditLog["route"])
+	}
+
+	if auditLog["before"] == nil {
+		t.Fatal("expected branch before it is added to region")
+	}
+}
+
+// InvalidToken : http handler for returning audit log of request with invalid token, it is recorded as denial
+func (u *AuditLogs) InvalidToken(t *testing.T) {
+	req := httptest.NewRequest("DELETE", "/brands/999999", nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Token", "invalid-token")
+	resp := httptest.NewRecorder()
+
+	u.App.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("deleting: expected status code %v, got %v", http.StatusBadRequest, resp.Code)
+	}
+
+	// denial without user login has no company, so it is not listed by audit logs of company
+	var count int
+	err := u.Db.QueryRow(`SELECT COUNT(*) FROM audit_logs WHERE company_id IS NULL AND action = 'forbidden' AND route = 'DELETE /brands/:id' AND entity_id = 999999`).Scan(&count)
+	if err != nil {
+		t.Fatalf("counting audit logs: %s", err)
+	}
+
+	if count == 0 {
+		t.Fatal("expected audit log of invalid token")
+	}
+}
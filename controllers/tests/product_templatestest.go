@@ -0,0 +1,233 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// ProductTemplates : struct for set ProductTemplates Dependency Injection
+type ProductTemplates struct {
+	App   http.Handler
+	Token string
+}
+
+// Run : http handler for run product templates testing
+func (u *ProductTemplates) Run(t *testing.T) {
+	created := u.Create(t)
+	id := created["data"].(map[string]interface{})["id"].(float64)
+	u.View(t, id)
+	u.GenerateVariants(t, id)
+	u.Delete(t, id)
+}
+
+// Create : http handler for create new product template
+func (u *ProductTemplates) Create(t *testing.T) map[string]interface{} {
+	var created map[string]interface{}
+	jsonBody := `
+		{
+			"code": "TSHIRT",
+			"name": "Kaos",
+			"price": 10,
+			"minimum_stock" : "5",
+			"brand": "1",
+			"product_category": "1",
+			"serialized": false,
+			"attributes": [
+				{"name": "Size", "values": ["S", "M"]},
+				{"name": "Color", "values": ["Red"]}
+			]
+		}
+	`
+	body := strings.NewReader(jsonBody)
+
+	req := httptest.NewRequest("POST", "/product-templates", body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Token", u.Token)
+	resp := httptest.NewRecorder()
+
+	u.App.ServeHTTP(resp, req)
+
+	if http.StatusCreated != resp.Code {
+		t.Fatalf("posting: expected status code %v, got %v", http.StatusCreated, resp.Code)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("decoding: %s", err)
+	}
+
+	c := created["data"].(map[string]interface{})
+
+	if c["id"] == "" || c["id"] == nil {
+		t.Fatal("expected non-empty product template id")
+	}
+
+	want := map[string]interface{}{
+		"status_code":    "REBEL-200",
+		"status_message": "OK",
+		"data":           u.template(c["id"]),
+	}
+
+	if diff := cmp.Diff(want, created); diff != "" {
+		t.Fatalf("Response did not match expected. Diff:\n%s", diff)
+	}
+
+	return created
+}
+
+// View : http handler for retrieve product template by id
+func (u *ProductTemplates) View(t *testing.T, id float64) {
+	req := httptest.NewRequest("GET", "/product-templates/"+fmt.Sprintf("%d", int(id)), nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Token", u.Token)
+	resp := httptest.NewRecorder()
+
+	u.App.ServeHTTP(resp, req)
+
+	if http.StatusOK != resp.Code {
+		t.Fatalf("retrieving: expected status code %v, got %v", http.StatusOK, resp.Code)
+	}
+
+	var fetched map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&fetched); err != nil {
+		t.Fatalf("decoding: %s", err)
+	}
+
+	want := map[string]interface{}{
+		"status_code":    "REBEL-200",
+		"status_message": "OK",
+		"data":           u.template(id),
+	}
+
+	if diff := cmp.Diff(want, fetched); diff != "" {
+		t.Fatalf("Retrieved product template should match created. Diff:\n%s", diff)
+	}
+}
+
+// GenerateVariants : http handler for generate variant products of product template
+func (u *ProductTemplates) GenerateVariants(t *testing.T, id float64) {
+	req := httptest.NewRequest("POST", "/product-templates/"+fmt.Sprintf("%d", int(id))+"/variants", nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Token", u.Token)
+	resp := httptest.NewRecorder()
+
+	u.App.ServeHTTP(resp, req)
+
+	if http.StatusCreated != resp.Code {
+		t.Fatalf("posting: expected status code %v, got %v", http.StatusCreated, resp.Code)
+	}
+
+	var generated map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&generated); err != nil {
+		t.Fatalf("decoding: %s", err)
+	}
+
+	list := generated["data"].([]interface{})
+	if len(list) != 2 {
+		t.Fatalf("expected 2 variants, got %d", len(list))
+	}
+
+	var data []interface{}
+	for i, v := range []string{"S", "M"} {
+		data = append(data, map[string]interface{}{
+			"id":            list[i].(map[string]interface{})["id"],
+			"code":          "TSHIRT-" + v + "-Red",
+			"name":          "Kaos (" + v + ", Red)",
+			"price":         float64(10),
+			"minimum_stock": float64(5),
+			"serialized":    false,
+			"company": map[string]interface{}{
+				"id":      float64(1),
+				"code":    "DM",
+				"name":    "Dummy",
+				"address": "",
+			},
+			"brand": map[string]interface{}{
+				"id":   float64(1),
+				"code": "BRAND-01",
+				"name": "TOP",
+			},
+			"product_category": map[string]interface{}{
+				"id":   float64(1),
+				"name": "Lemari",
+			},
+			"template": map[string]interface{}{
+				"id":   id,
+				"code": "TSHIRT",
+				"name": "Kaos",
+			},
+		})
+	}
+
+	want := map[string]interface{}{
+		"status_code":    "REBEL-200",
+		"status_message": "OK",
+		"data":           data,
+	}
+
+	if diff := cmp.Diff(want, generated); diff != "" {
+		t.Fatalf("Response did not match expected. Diff:\n%s", diff)
+	}
+}
+
+// Delete product template which already has variants must be rejected
+func (u *ProductTemplates) Delete(t *testing.T, id float64) {
+	req := httptest.NewRequest("DELETE", "/product-templates/"+fmt.Sprintf("%d", int(id)), nil)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Token", u.Token)
+	resp := httptest.NewRecorder()
+
+	u.App.ServeHTTP(resp, req)
+
+	if http.StatusBadRequest != resp.Code {
+		t.Fatalf("deleting: expected status code %v, got %v", http.StatusBadRequest, resp.Code)
+	}
+}
+
+func (u *ProductTemplates) template(id interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"id":            id,
+		"code":          "TSHIRT",
+		"name":          "Kaos",
+		"price":         float64(10),
+		"minimum_stock": float64(5),
+		"serialized":    false,
+		"company": map[string]interface{}{
+			"id":      float64(1),
+			"code":    "DM",
+			"name":    "Dummy",
+			"address": "",
+		},
+		"brand": map[string]interface{}{
+			"id":   float64(1),
+			"code": "BRAND-01",
+			"name": "TOP",
+		},
+		"product_category": map[string]interface{}{
+			"id":   float64(1),
+			"name": "Lemari",
+		},
+		"attributes": []interface{}{
+			map[string]interface{}{
+				"id":   float64(1),
+				"name": "Size",
+				"values": []interface{}{
+					map[string]interface{}{"id": float64(1), "value": "S"},
+					map[string]interface{}{"id": float64(2), "value": "M"},
+				},
+			},
+			map[string]interface{}{
+				"id":   float64(2),
+				"name": "Color",
+				"values": []interface{}{
+					map[string]interface{}{"id": float64(3), "value": "Red"},
+				},
+			},
+		},
+	}
+}
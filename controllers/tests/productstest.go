@@ -55,6 +55,7 @@ func (u *Products) List(t *testing.T) {
 				"name":          "Tes",
 				"price":         float64(1),
 				"minimum_stock": float64(25),
+				"serialized":    true,
 				"company": map[string]interface{}{
 					"id":      float64(1),
 					"code":    "DM",
@@ -124,6 +125,7 @@ func (u *Products) Create(t *testing.T) map[string]interface{} {
 			"name":          "Tes",
 			"price":         float64(1),
 			"minimum_stock": float64(25),
+			"serialized":    true,
 			"company": map[string]interface{}{
 				"id":      float64(1),
 				"code":    "DM",
@@ -176,6 +178,7 @@ func (u *Products) View(t *testing.T, id float64) {
 			"name":          "Tes",
 			"price":         float64(1),
 			"minimum_stock": float64(25),
+			"serialized":    true,
 			"company": map[string]interface{}{
 				"id":      float64(1),
 				"code":    "DM",
@@ -239,6 +242,7 @@ func (u *Products) Update(t *testing.T, id float64) {
 			"name":          "Test",
 			"price":         float64(2),
 			"minimum_stock": float64(50),
+			"serialized":    true,
 			"company": map[string]interface{}{
 				"id":      float64(1),
 				"code":    "DM",
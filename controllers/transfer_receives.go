@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/request"
+	"github.com/jacky-htg/inventory/payloads/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// TransferReceives : struct for set TransferReceives Dependency Injection
+type TransferReceives struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// List : http handler for returning list of TransferReceives
+func (u *TransferReceives) List(w http.ResponseWriter, r *http.Request) {
+	var transferReceive models.TransferReceive
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	list, err := transferReceive.List(r.Context(), tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting TransferReceives list: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var listResponse []*response.TransferReceiveListResponse
+	for _, r := range list {
+		var transferReceiveResponse response.TransferReceiveListResponse
+		transferReceiveResponse.Transform(&r)
+		listResponse = append(listResponse, &transferReceiveResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// View : http handler for retrieve TransferReceive by id
+func (u *TransferReceives) View(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting: %v", err))
+		return
+	}
+
+	var transferReceive models.TransferReceive
+	transferReceive.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = transferReceive.Get(ctx, tx)
+
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get TransferReceive: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.TransferReceiveResponse
+	response.Transform(&transferReceive)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Create : http handler for create new TransferReceive
+func (u *TransferReceives) Create(w http.ResponseWriter, r *http.Request) {
+	var transferReceiveRequest request.NewTransferReceiveRequest
+	err := api.Decode(r, &transferReceiveRequest)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("decode TransferReceive: %v", err))
+		return
+	}
+
+	transferReceive := transferReceiveRequest.Transform()
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("begin transaction: %v", err))
+		return
+	}
+
+	err = transferReceive.Create(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		u.Log.Printf("ERROR : %+v", err)
+		tx.Rollback()
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		tx.Rollback()
+		api.ResponseError(w, fmt.Errorf("Create TransferReceive: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.TransferReceiveResponse
+	response.Transform(transferReceive)
+	api.ResponseOK(w, response, http.StatusCreated)
+}
+
+// Update : http handler for update TransferReceive by id
+func (u *TransferReceives) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var transferReceive models.TransferReceive
+	transferReceive.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = transferReceive.Get(ctx, tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get TransferReceive: %v", err))
+		return
+	}
+
+	var transferReceiveRequest request.TransferReceiveRequest
+	err = api.Decode(r, &transferReceiveRequest)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Decode TransferReceive: %v", err))
+		return
+	}
+
+	if transferReceiveRequest.ID <= 0 {
+		transferReceiveRequest.ID = transferReceive.ID
+	}
+	transferReceiveUpdate := transferReceiveRequest.Transform(&transferReceive)
+	err = transferReceiveUpdate.Update(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Update TransferReceive: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.TransferReceiveResponse
+	response.Transform(transferReceiveUpdate)
+	api.ResponseOK(w, response, http.StatusOK)
+}
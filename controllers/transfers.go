@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/request"
+	"github.com/jacky-htg/inventory/payloads/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Transfers : struct for set Transfers Dependency Injection
+type Transfers struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// List : http handler for returning list of Transfers
+func (u *Transfers) List(w http.ResponseWriter, r *http.Request) {
+	var transfer models.Transfer
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	list, err := transfer.List(r.Context(), tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting Transfers list: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var listResponse []*response.TransferListResponse
+	for _, r := range list {
+		var transferResponse response.TransferListResponse
+		transferResponse.Transform(&r)
+		listResponse = append(listResponse, &transferResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
+
+// View : http handler for retrieve Transfer by id
+func (u *Transfers) View(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting: %v", err))
+		return
+	}
+
+	var transfer models.Transfer
+	transfer.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = transfer.Get(ctx, tx)
+
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get Transfer: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.TransferResponse
+	response.Transform(&transfer)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Create : http handler for create new Transfer
+func (u *Transfers) Create(w http.ResponseWriter, r *http.Request) {
+	var transferRequest request.NewTransferRequest
+	err := api.Decode(r, &transferRequest)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("decode Transfer: %v", err))
+		return
+	}
+
+	transfer := transferRequest.Transform()
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("begin transaction: %v", err))
+		return
+	}
+
+	err = transfer.Create(r.Context(), tx)
+	if _, ok := err.(*api.Error); ok {
+		u.Log.Printf("ERROR : %+v", err)
+		tx.Rollback()
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		tx.Rollback()
+		api.ResponseError(w, fmt.Errorf("Create Transfer: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.TransferResponse
+	response.Transform(transfer)
+	api.ResponseOK(w, response, http.StatusCreated)
+}
+
+// Update : http handler for update Transfer by id
+func (u *Transfers) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting paramID: %v", err))
+		return
+	}
+
+	var transfer models.Transfer
+	transfer.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = transfer.Get(ctx, tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get Transfer: %v", err))
+		return
+	}
+
+	var transferRequest request.TransferRequest
+	err = api.Decode(r, &transferRequest)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Decode Transfer: %v", err))
+		return
+	}
+
+	if transferRequest.ID <= 0 {
+		transferRequest.ID = transfer.ID
+	}
+	transferUpdate := transferRequest.Transform(&transfer)
+	err = transferUpdate.Update(ctx, tx)
+	if _, ok := err.(*api.Error); ok {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Update Transfer: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.TransferResponse
+	response.Transform(transferUpdate)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Discrepancies : http handler for returning units of Transfer which have not been received by destination branch
+func (u *Transfers) Discrepancies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("type casting: %v", err))
+		return
+	}
+
+	var transfer models.Transfer
+	transfer.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	err = transfer.Get(ctx, tx)
+
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Get Transfer: %v", err))
+		return
+	}
+
+	inTransit, err := transfer.ListInTransit(ctx, tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting Transfer in transit: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	var response response.TransferDiscrepancyResponse
+	response.Transform(&transfer, inTransit)
+	api.ResponseOK(w, response, http.StatusOK)
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Units : struct for set Units Dependency Injection
+type Units struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// History : http handler for returning movement history of unit code
+func (u *Units) History(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramCode := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("code")
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("Begin tx: %v", err))
+		return
+	}
+
+	unitHistory := models.UnitHistory{ProductCode: paramCode}
+	list, err := unitHistory.List(ctx, tx)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, api.ErrNotFound(err, ""))
+		return
+	}
+
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("ERROR : %+v", err)
+		api.ResponseError(w, fmt.Errorf("getting unit history: %v", err))
+		return
+	}
+
+	tx.Commit()
+
+	listResponse := []*response.UnitHistoryResponse{}
+	for _, h := range list {
+		var unitHistoryResponse response.UnitHistoryResponse
+		unitHistoryResponse.Transform(&h)
+		listResponse = append(listResponse, &unitHistoryResponse)
+	}
+
+	api.ResponseOK(w, listResponse, http.StatusOK)
+}
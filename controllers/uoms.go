@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/request"
+	"github.com/jacky-htg/inventory/payloads/response"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Uoms type for handling dependency injection
+type Uoms struct {
+	Db  *sql.DB
+	Log *log.Logger
+}
+
+// List of Uoms
+func (u *Uoms) List(w http.ResponseWriter, r *http.Request) {
+	var Uom models.Uom
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("Error begin tx : %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	list, err := Uom.List(r.Context(), tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("get Uoms list : %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	tx.Commit()
+
+	var UomResponse []response.UomResponse
+	for _, r := range list {
+		var res response.UomResponse
+		res.Transform(&r)
+		UomResponse = append(UomResponse, res)
+	}
+
+	api.ResponseOK(w, UomResponse, http.StatusOK)
+}
+
+// Create new Uom
+func (u *Uoms) Create(w http.ResponseWriter, r *http.Request) {
+	var UomRequest request.NewUomRequest
+	err := api.Decode(r, &UomRequest)
+	if err != nil {
+		u.Log.Printf("Error : %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	Uom := UomRequest.Transform()
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("Error begin tx : %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+	err = Uom.Create(r.Context(), tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("create new Uom tx : %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+	tx.Commit()
+
+	var response response.UomResponse
+	response.Transform(&Uom)
+	api.ResponseOK(w, response, http.StatusCreated)
+}
+
+// View of Uom by id
+func (u *Uoms) View(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("casting paramID : %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	var Uom models.Uom
+	Uom.ID = uint64(id)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		u.Log.Printf("Begin tx : %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	err = Uom.View(ctx, tx)
+	if err != nil {
+		u.Log.Printf("Get Uom: %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	var response response.UomResponse
+	response.Transform(&Uom)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Update Uom by id
+func (u *Uoms) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("casting paramID : %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	var UomRequest request.UomRequest
+	err = api.Decode(r, &UomRequest)
+	if err != nil {
+		u.Log.Printf("Error : %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("Begin tx : %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	var Uom models.Uom
+	Uom.ID = uint64(id)
+	err = Uom.View(ctx, tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("Get Uom: %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	UomUpdate := UomRequest.Transform(&Uom)
+
+	err = UomUpdate.Update(ctx, tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("Update Uom: %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+	tx.Commit()
+
+	var response response.UomResponse
+	response.Transform(UomUpdate)
+	api.ResponseOK(w, response, http.StatusOK)
+}
+
+// Delete Uom by id
+func (u *Uoms) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	paramID := ctx.Value(api.Ctx("ps")).(httprouter.Params).ByName("id")
+	id, err := strconv.Atoi(paramID)
+	if err != nil {
+		u.Log.Printf("casting paramID : %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("Begin tx : %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	var Uom models.Uom
+	Uom.ID = uint64(id)
+	err = Uom.View(ctx, tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("Get Uom: %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+
+	err = Uom.Delete(ctx, tx)
+	if err != nil {
+		tx.Rollback()
+		u.Log.Printf("Update Uom: %v", err)
+		api.ResponseError(w, err)
+		return
+	}
+	tx.Commit()
+
+	api.ResponseOK(w, nil, http.StatusNoContent)
+}
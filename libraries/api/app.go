@@ -36,6 +36,11 @@ func (a *App) Handle(method, url string, h Handler) {
 	a.mux.Handle(method, url, fn)
 }
 
+// Use appends middleware which wraps handlers of routes registered after it, inside the middleware of NewApp.
+func (a *App) Use(mw ...Middleware) {
+	a.mw = append(a.mw, mw...)
+}
+
 // ServeHTTP implements the http.Handler interface.
 func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	a.mux.ServeHTTP(w, r)
@@ -54,3 +54,14 @@ func ResponseError(w http.ResponseWriter, err error) error {
 	}
 	return nil
 }
+
+// ResponseRaw sends a non JSON body, such as image or printable document, to the client.
+func ResponseRaw(w http.ResponseWriter, body []byte, contentType string, httpCode int) error {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(httpCode)
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+
+	return nil
+}
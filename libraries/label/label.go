@@ -0,0 +1,220 @@
+package label
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Symbology of barcode
+const (
+	Code128 = "code128"
+	EAN13   = "ean13"
+	QR      = "qr"
+)
+
+// Format of rendered barcode or label sheet
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+	FormatPDF = "pdf"
+	FormatZPL = "zpl"
+)
+
+// ContentTypes of every format
+var ContentTypes = map[string]string{
+	FormatPNG: "image/png",
+	FormatSVG: "image/svg+xml",
+	FormatPDF: "application/pdf",
+	FormatZPL: "application/zpl",
+}
+
+// Label : struct of code and caption printed on a label
+type Label struct {
+	Code    string
+	Caption string
+}
+
+// Encode content into barcode of symbology
+func Encode(symbology, content string) (barcode.Barcode, error) {
+	switch symbology {
+	case Code128:
+		return code128.Encode(content)
+	case EAN13:
+		if len(content) != 12 && len(content) != 13 {
+			return nil, errors.New("EAN-13 needs 12 or 13 digits")
+		}
+		return ean.Encode(content)
+	case QR:
+		return qr.Encode(content, qr.M, qr.Auto)
+	}
+
+	return nil, fmt.Errorf("Unknown barcode type %s", symbology)
+}
+
+// PNG write barcode scaled to width x height pixels as 8-bit grayscale png image
+func PNG(w io.Writer, bc barcode.Barcode, width, height int) error {
+	if bc.Metadata().Dimensions == 2 {
+		height = width
+	}
+
+	// barcode can not be scaled below its own module count, it is a bad size requested by caller
+	scaled, err := barcode.Scale(bc, width, height)
+	if err != nil {
+		return api.ErrBadRequest(err, "")
+	}
+
+	img := image.NewGray(scaled.Bounds())
+	draw.Draw(img, img.Bounds(), scaled, scaled.Bounds().Min, draw.Src)
+	return png.Encode(w, img)
+}
+
+// SVG write barcode as svg image of width x height, a rect for every run of dark modules
+func SVG(w io.Writer, bc barcode.Barcode, width, height int) error {
+	bounds := bc.Bounds()
+	if bc.Metadata().Dimensions == 2 {
+		height = width
+	}
+
+	moduleWidth := width / bounds.Dx()
+	moduleHeight := height / bounds.Dy()
+	if moduleWidth < 1 || moduleHeight < 1 {
+		return api.ErrBadRequest(errors.New("Size is too small for barcode"), "")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		moduleWidth*bounds.Dx(), moduleHeight*bounds.Dy(), moduleWidth*bounds.Dx(), moduleHeight*bounds.Dy())
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="#fff"/>`)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if !isDark(bc, x, y) {
+				continue
+			}
+
+			run := 1
+			for x+run < bounds.Max.X && isDark(bc, x+run, y) {
+				run++
+			}
+
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#000"/>`,
+				(x-bounds.Min.X)*moduleWidth, (y-bounds.Min.Y)*moduleHeight, run*moduleWidth, moduleHeight)
+			x += run - 1
+		}
+	}
+
+	b.WriteString(`</svg>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// PDF write printable A4 label sheet of 3 columns x 8 rows, caption above barcode and code below it
+func PDF(w io.Writer, symbology string, labels []Label) error {
+	const (
+		columns      = 3
+		rows         = 8
+		margin       = 10.0
+		labelWidth   = (210 - 2*margin) / columns
+		labelHeight  = (297 - 2*margin) / rows
+		barcodeWidth = labelWidth - 10
+	)
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(false, margin)
+	pdf.SetFont("Helvetica", "", 8)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	for i, l := range labels {
+		if i%(columns*rows) == 0 {
+			pdf.AddPage()
+		}
+
+		pos := i % (columns * rows)
+		x := margin + float64(pos%columns)*labelWidth
+		y := margin + float64(pos/columns)*labelHeight
+
+		bc, err := Encode(symbology, l.Code)
+		if err != nil {
+			return fmt.Errorf("label %s: %v", l.Code, err)
+		}
+
+		var img bytes.Buffer
+		err = PNG(&img, bc, 600, 150)
+		if err != nil {
+			return fmt.Errorf("label %s: %v", l.Code, err)
+		}
+
+		name := fmt.Sprintf("label-%d", i)
+		options := gofpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader(name, options, &img)
+
+		pdf.SetXY(x+5, y+2)
+		pdf.CellFormat(barcodeWidth, 4, tr(l.Caption), "", 0, "C", false, 0, "")
+
+		imageWidth, imageHeight := barcodeWidth, 16.0
+		if bc.Metadata().Dimensions == 2 {
+			imageWidth, imageHeight = 20, 20
+		}
+
+		pdf.ImageOptions(name, x+(labelWidth-imageWidth)/2, y+7, imageWidth, imageHeight, false, options, 0, "")
+
+		pdf.SetXY(x+5, y+8+imageHeight)
+		pdf.CellFormat(barcodeWidth, 4, l.Code, "", 0, "C", false, 0, "")
+	}
+
+	if len(labels) == 0 {
+		pdf.AddPage()
+	}
+
+	return pdf.Output(w)
+}
+
+// ZPL write labels as zpl commands for thermal printer, one label format for every code
+func ZPL(w io.Writer, symbology string, labels []Label) error {
+	var b strings.Builder
+	for _, l := range labels {
+		b.WriteString("^XA^CI28\n")
+		fmt.Fprintf(&b, "^FO30,20^A0N,24,24^FD%s^FS\n", zplEscape(l.Caption))
+
+		switch symbology {
+		case Code128:
+			fmt.Fprintf(&b, "^FO30,55^BY2^BCN,80,Y,N,N^FD%s^FS\n", zplEscape(l.Code))
+		case EAN13:
+			fmt.Fprintf(&b, "^FO30,55^BY2^BEN,80,Y,N^FD%s^FS\n", zplEscape(l.Code))
+		case QR:
+			fmt.Fprintf(&b, "^FO30,55^BQN,2,5^FDQA,%s^FS\n", zplEscape(l.Code))
+			fmt.Fprintf(&b, "^FO200,90^A0N,24,24^FD%s^FS\n", zplEscape(l.Code))
+		default:
+			return fmt.Errorf("Unknown barcode type %s", symbology)
+		}
+
+		b.WriteString("^XZ\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func isDark(bc barcode.Barcode, x, y int) bool {
+	return color.GrayModel.Convert(bc.At(x, y)).(color.Gray).Y < 128
+}
+
+// zplEscape remove the command prefixes which would break the field data
+func zplEscape(s string) string {
+	return strings.NewReplacer("^", "", "~", "").Replace(s)
+}
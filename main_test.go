@@ -69,6 +69,12 @@ func TestMain(t *testing.T) {
 		t.Run("APiBrandsCrud", brands.Run)
 	}
 
+	// api test for uoms
+	{
+		uoms := apiTest.Uoms{App: routing.API(db, log), Token: token}
+		t.Run("APiUomsCrud", uoms.Run)
+	}
+
 	// api test for product categories
 	{
 		productCategories := apiTest.ProductCategories{App: routing.API(db, log), Token: token}
@@ -80,4 +86,22 @@ func TestMain(t *testing.T) {
 		products := apiTest.Products{App: routing.API(db, log), Token: token}
 		t.Run("APiProductsCrud", products.Run)
 	}
+
+	// api test for product templates
+	{
+		productTemplates := apiTest.ProductTemplates{App: routing.API(db, log), Token: token}
+		t.Run("APiProductTemplatesCrud", productTemplates.Run)
+	}
+
+	// api test for approval rules
+	{
+		approvalRules := apiTest.ApprovalRules{App: routing.API(db, log), Token: token}
+		t.Run("APiApprovalRulesCrud", approvalRules.Run)
+	}
+
+	// api test for audit logs
+	{
+		auditLogs := apiTest.AuditLogs{App: routing.API(db, log), Db: db, Token: token}
+		t.Run("APiAuditLogsList", auditLogs.Run)
+	}
 }
@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/julienschmidt/httprouter"
+)
+
+// AuditGetter return entity with id as it is rendered by its view response, to be written as entity before the audited request
+type AuditGetter func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error)
+
+// Audits middleware to write audit log of succeeded create, update and delete request of user login.
+// Entity before the request is retrieved by getter of its entity just before the request is handled, in its own transaction,
+// so a change committed by another request in between is not caught. Entity after is data of the response.
+// Audit log is best-effort, failure of writing it is only logged and does not fail the audited request.
+func Audits(db *sql.DB, log *log.Logger, getters map[string]AuditGetter) api.Middleware {
+	fn := func(before api.Handler) api.Handler {
+		h := func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			user, _ := ctx.Value(api.Ctx("auth")).(models.User)
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || user.ID == 0 {
+				before(w, r)
+				return
+			}
+
+			auditLog := newAuditLog(r, user, auditAction(r))
+			if get, ok := getters[auditLog.Entity]; ok && auditLog.EntityID > 0 {
+				before, err := auditSnapshot(ctx, db, get, auditLog.EntityID)
+				if err != nil {
+					log.Printf("ERROR : audit snapshot: %+v", err)
+				}
+
+				auditLog.Before = before
+			}
+
+			aw := &auditWriter{ResponseWriter: w}
+			before(aw, r)
+
+			if aw.status == 0 || aw.status >= http.StatusMultipleChoices {
+				return
+			}
+
+			if auditLog.Action != models.AuditDelete {
+				auditLog.After = responseData(aw.body.Bytes())
+			}
+
+			if auditLog.EntityID == 0 && auditLog.After.Valid {
+				var data struct {
+					ID uint64 `json:"id"`
+				}
+				if err := json.Unmarshal([]byte(auditLog.After.String), &data); err == nil {
+					auditLog.EntityID = data.ID
+				}
+			}
+
+			if err := auditLog.Create(ctx, db); err != nil {
+				log.Printf("ERROR : audit log: %+v", err)
+			}
+		}
+
+		return h
+	}
+
+	return fn
+}
+
+// newAuditLog return audit log of request by user with action. Entity is the resource of the last parameter of the route,
+// such as uoms of /products/:id/uoms/:uom_id, or the controller of route without parameter.
+func newAuditLog(r *http.Request, user models.User, action string) models.AuditLog {
+	ctx := r.Context()
+	auditLog := models.AuditLog{
+		Company: user.Company,
+		User:    user,
+		Action:  action,
+		Route:   strings.ToUpper(r.Method) + " " + r.URL.Path,
+		Entity:  strings.Split(r.URL.Path, "/")[1],
+	}
+
+	curRoute, ok := ctx.Value(api.Ctx("url")).(string)
+	if !ok {
+		return auditLog
+	}
+
+	auditLog.Route = strings.ToUpper(r.Method) + " " + curRoute
+	patterns := strings.Split(curRoute, "/")
+	auditLog.Entity = patterns[1]
+
+	for i := len(patterns) - 1; i > 1; i-- {
+		if !strings.HasPrefix(patterns[i], ":") {
+			continue
+		}
+
+		auditLog.Entity = patterns[i-1]
+		if ps, ok := ctx.Value(api.Ctx("ps")).(httprouter.Params); ok {
+			if id, err := strconv.ParseUint(ps.ByName(patterns[i][1:]), 10, 64); err == nil {
+				auditLog.EntityID = id
+			}
+		}
+
+		break
+	}
+
+	return auditLog
+}
+
+// auditAction return action of request, post to route of an entity such as posting or voiding updates the entity
+func auditAction(r *http.Request) string {
+	switch r.Method {
+	case http.MethodDelete:
+		return models.AuditDelete
+	case http.MethodPut, http.MethodPatch:
+		return models.AuditUpdate
+	}
+
+	curRoute, _ := r.Context().Value(api.Ctx("url")).(string)
+	if strings.Contains(curRoute, "/:") {
+		return models.AuditUpdate
+	}
+
+	return models.AuditCreate
+}
+
+// auditSnapshot return json of entity with id retrieved by get in its own transaction, which is rolled back as nothing is changed
+func auditSnapshot(ctx context.Context, db *sql.DB, get AuditGetter, id uint64) (sql.NullString, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	defer tx.Rollback()
+
+	entity, err := get(ctx, tx, id)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// responseData return data of json response body, it is NULL for response without data
+func responseData(body []byte) sql.NullString {
+	var res struct {
+		Data json.RawMessage `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &res); err != nil || len(res.Data) == 0 || string(res.Data) == "null" {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: string(res.Data), Valid: true}
+}
+
+// recordAudit write audit log of permission denial. It is best-effort, error of writing is only logged and the denial response is kept.
+func recordAudit(r *http.Request, db *sql.DB, log *log.Logger, user models.User, action string) {
+	auditLog := newAuditLog(r, user, action)
+	if err := auditLog.Create(r.Context(), db); err != nil {
+		log.Printf("ERROR : audit log: %+v", err)
+	}
+}
+
+// auditWriter capture status and body of response written to ResponseWriter
+type auditWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader capture status of response
+func (w *auditWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write capture body of response
+func (w *auditWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
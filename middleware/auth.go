@@ -42,12 +42,14 @@ func Auths(db *sql.DB, log *log.Logger, allow []string) api.Middleware {
 
 				if err == sql.ErrNoRows {
 					log.Printf("ERROR : %+v", err)
+					recordAudit(r, db, log, user, models.AuditForbidden)
 					api.ResponseError(w, api.ErrForbidden(errors.New("Forbidden"), ""))
 					return
 				}
 
 				if err != nil {
 					log.Printf("ERROR : %+v", err)
+					recordAudit(r, db, log, user, models.AuditForbidden)
 					api.ResponseError(w, err)
 					return
 				}
@@ -55,6 +57,7 @@ func Auths(db *sql.DB, log *log.Logger, allow []string) api.Middleware {
 
 			if !isAuth {
 				log.Print("ERROR : Forbidden")
+				recordAudit(r, db, log, user, models.AuditForbidden)
 				api.ResponseError(w, api.ErrForbidden(errors.New("Forbidden"), ""))
 				return
 			}
@@ -0,0 +1,449 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// Type of document which can require approval. Amount of sales order is its total price and discount is its discount percentage,
+// while amount of document without price is a quantity and it has no discount:
+// total returned quantity of delivery return and receive return, total absolute variance quantity of stock opname.
+const (
+	ApprovalSalesOrder     = "SO"
+	ApprovalDeliveryReturn = "DR"
+	ApprovalReceiveReturn  = "RR"
+	ApprovalStockOpname    = "OP"
+)
+
+// Status of approval. Approval is superseded when its document is changed and approval is requested again.
+const (
+	ApprovalPending    = "pending"
+	ApprovalApproved   = "approved"
+	ApprovalRejected   = "rejected"
+	ApprovalSuperseded = "superseded"
+)
+
+// Approval : struct of approval request of document, it walks through the steps of its rule
+type Approval struct {
+	ID           uint64
+	Company      Company
+	Branch       Branch
+	Rule         ApprovalRule
+	DocumentType string
+	DocumentID   uint64
+	DocumentCode string
+	Amount       float64
+	Discount     float64
+	Step         uint8
+	Status       string
+	Created      time.Time
+	CreatedBy    User
+	Histories    []ApprovalHistory
+}
+
+// ApprovalHistory : struct of action taken by user on a step of approval
+type ApprovalHistory struct {
+	Step    uint8
+	Role    Role
+	User    User
+	Action  string
+	Remark  string
+	Created time.Time
+}
+
+const qApprovals = `
+SELECT 	approvals.id,
+		approvals.document_type,
+		approvals.document_id,
+		approvals.document_code,
+		approvals.amount,
+		approvals.discount,
+		approvals.step,
+		approvals.status,
+		approvals.created,
+		users.id,
+		users.username,
+		branches.id,
+		branches.code,
+		branches.name,
+		branches.address,
+		branches.type,
+		approval_rules.id,
+		approval_rules.name
+FROM approvals
+JOIN users ON approvals.created_by = users.id
+JOIN branches ON approvals.branch_id = branches.id
+JOIN approval_rules ON approvals.approval_rule_id = approval_rules.id
+`
+
+// Inbox list pending approvals which current step can be approved by roles of user login
+func (u *Approval) Inbox(ctx context.Context, tx *sql.Tx) ([]Approval, error) {
+	list := []Approval{}
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	query := qApprovals + `
+	WHERE approvals.company_id = ?
+	AND approvals.status = ?
+	AND EXISTS(
+		SELECT approval_rule_steps.id
+		FROM approval_rule_steps
+		JOIN roles_users ON approval_rule_steps.role_id = roles_users.role_id
+		WHERE approval_rule_steps.approval_rule_id = approvals.approval_rule_id
+		AND approval_rule_steps.step = approvals.step
+		AND roles_users.user_id = ?
+	)`
+	params := []interface{}{userLogin.Company.ID, ApprovalPending, userLogin.ID}
+
+	where, branchParams, err := u.branchScope(ctx, tx)
+	if err != nil {
+		return list, err
+	}
+
+	rows, err := tx.QueryContext(ctx, query+where+" ORDER BY approvals.id", append(params, branchParams...)...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var a Approval
+		err = rows.Scan(a.getArgs()...)
+		if err != nil {
+			return list, err
+		}
+
+		a.Company = userLogin.Company
+		a.Branch.Company = userLogin.Company
+		list = append(list, a)
+	}
+
+	return list, rows.Err()
+}
+
+// Get approval by id, including steps of its rule and its histories
+func (u *Approval) Get(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	where, branchParams, err := u.branchScope(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	params := append([]interface{}{u.ID, userLogin.Company.ID}, branchParams...)
+	err = tx.QueryRowContext(ctx, qApprovals+" WHERE approvals.id = ? AND approvals.company_id = ?"+where, params...).Scan(u.getArgs()...)
+	if err != nil {
+		return err
+	}
+
+	u.Company = userLogin.Company
+	u.Branch.Company = userLogin.Company
+
+	err = u.Rule.getSteps(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return u.getHistories(ctx, tx)
+}
+
+// Approve current step of approval by user login. Approval is approved when its last step is approved.
+func (u *Approval) Approve(ctx context.Context, tx *sql.Tx, remark string) error {
+	role, err := u.checkApprover(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	err = u.storeHistory(ctx, tx, role, ApprovalApproved, remark)
+	if err != nil {
+		return err
+	}
+
+	step, status := u.Step, ApprovalPending
+	if int(u.Step) >= len(u.Rule.Steps) {
+		status = ApprovalApproved
+	} else {
+		step++
+	}
+
+	return u.changeStatus(ctx, tx, step, status)
+}
+
+// Reject approval on its current step by user login, the document has to be changed and requested for approval again
+func (u *Approval) Reject(ctx context.Context, tx *sql.Tx, remark string) error {
+	role, err := u.checkApprover(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	err = u.storeHistory(ctx, tx, role, ApprovalRejected, remark)
+	if err != nil {
+		return err
+	}
+
+	return u.changeStatus(ctx, tx, u.Step, ApprovalRejected)
+}
+
+// ListApprovals of document, including superseded approvals and their histories
+func ListApprovals(ctx context.Context, tx *sql.Tx, documentType string, documentID uint64) ([]Approval, error) {
+	list := []Approval{}
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	rows, err := tx.QueryContext(ctx, qApprovals+`
+		WHERE approvals.company_id = ? AND approvals.document_type = ? AND approvals.document_id = ?
+		ORDER BY approvals.id`,
+		userLogin.Company.ID, documentType, documentID)
+	if err != nil {
+		return list, err
+	}
+
+	for rows.Next() {
+		var a Approval
+		err = rows.Scan(a.getArgs()...)
+		if err != nil {
+			rows.Close()
+			return list, err
+		}
+
+		a.Company = userLogin.Company
+		a.Branch.Company = userLogin.Company
+		list = append(list, a)
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return list, err
+	}
+
+	for i := range list {
+		err = list[i].getHistories(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+	}
+
+	return list, nil
+}
+
+// requestApproval supersede previous approvals of document and request a new approval when a rule applies to the document.
+// Return approval with zero id when the document does not require approval.
+func requestApproval(ctx context.Context, tx *sql.Tx, documentType string, documentID uint64, documentCode string, amount float64, discount float64) (Approval, error) {
+	approval := Approval{DocumentType: documentType, DocumentID: documentID, DocumentCode: documentCode, Amount: amount, Discount: discount}
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	err := supersedeApprovals(ctx, tx, documentType, documentID)
+	if err != nil {
+		return approval, err
+	}
+
+	approval.Rule, err = matchApprovalRule(ctx, tx, documentType, amount, discount)
+	if err != nil || approval.Rule.ID == 0 {
+		return approval, err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO approvals (company_id, branch_id, approval_rule_id, document_type, document_id, document_code, amount, discount, step, status, created_by, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, 1, ?, ?, NOW(), NOW())`,
+		userLogin.Company.ID, userLogin.Branch.ID, approval.Rule.ID, documentType, documentID, documentCode, amount, discount, ApprovalPending, userLogin.ID)
+	if err != nil {
+		return approval, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return approval, err
+	}
+
+	approval.ID = uint64(id)
+	approval.Step = 1
+	approval.Status = ApprovalPending
+	approval.Company = userLogin.Company
+	approval.Branch = userLogin.Branch
+	approval.CreatedBy = userLogin
+
+	return approval, nil
+}
+
+// supersedeApprovals mark approvals of changed document as superseded, the document has to be requested for approval again
+func supersedeApprovals(ctx context.Context, tx *sql.Tx, documentType string, documentID uint64) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE approvals
+		SET status = ?,
+			updated = NOW()
+		WHERE company_id = ?
+		AND document_type = ?
+		AND document_id = ?
+		AND status <> ?`,
+		ApprovalSuperseded, ctx.Value(api.Ctx("auth")).(User).Company.ID, documentType, documentID, ApprovalSuperseded)
+	return err
+}
+
+// getApprovalStatus return status of the current approval of document, empty when document has not required approval
+func getApprovalStatus(ctx context.Context, tx *sql.Tx, documentType string, documentID uint64) (string, error) {
+	var status string
+	err := tx.QueryRowContext(ctx, `
+		SELECT status FROM approvals
+		WHERE company_id = ? AND document_type = ? AND document_id = ? AND status <> ?
+		ORDER BY id DESC LIMIT 1`,
+		ctx.Value(api.Ctx("auth")).(User).Company.ID, documentType, documentID, ApprovalSuperseded).Scan(&status)
+	if err == sql.ErrNoRows {
+		return status, nil
+	}
+
+	return status, err
+}
+
+// checkApproval reject posting of document which approval is pending or has been rejected
+func checkApproval(ctx context.Context, tx *sql.Tx, documentType string, documentID uint64, documentCode string) error {
+	status, err := getApprovalStatus(ctx, tx, documentType, documentID)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case ApprovalPending:
+		return api.ErrBadRequest(fmt.Errorf("Document %s is waiting for approval", documentCode), "")
+	case ApprovalRejected:
+		return api.ErrBadRequest(fmt.Errorf("Approval of document %s has been rejected", documentCode), "")
+	}
+
+	return nil
+}
+
+// branchScope return where clause limiting branches.id to branches of region or branch of user login
+func (u *Approval) branchScope(ctx context.Context, tx *sql.Tx) (string, []interface{}, error) {
+	var params []interface{}
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return "", params, err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=?")
+			params = append(params, b)
+		}
+
+		return " AND (" + strings.Join(orWhere, " OR ") + ")", params, nil
+
+	case userLogin.Branch.ID > 0:
+		return " AND branches.id=?", append(params, userLogin.Branch.ID), nil
+	}
+
+	return "", params, nil
+}
+
+// checkApprover return role of current step held by user login. A user approves one step of approval only.
+func (u *Approval) checkApprover(ctx context.Context, tx *sql.Tx) (Role, error) {
+	var role Role
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	if u.Status != ApprovalPending {
+		return role, api.ErrBadRequest(fmt.Errorf("Approval with status %s can not be processed", u.Status), "")
+	}
+
+	for _, h := range u.Histories {
+		if h.User.ID == userLogin.ID {
+			return role, api.ErrBadRequest(errors.New("User has approved another step of this approval"), "")
+		}
+	}
+
+	err := tx.QueryRowContext(ctx, `
+		SELECT roles.id, roles.name
+		FROM approval_rule_steps
+		JOIN roles ON approval_rule_steps.role_id = roles.id
+		JOIN roles_users ON roles.id = roles_users.role_id
+		WHERE approval_rule_steps.approval_rule_id = ? AND approval_rule_steps.step = ? AND roles_users.user_id = ?`,
+		u.Rule.ID, u.Step, userLogin.ID).Scan(&role.ID, &role.Name)
+	if err == sql.ErrNoRows {
+		return role, api.ErrForbidden(errors.New("User does not have the approver role of current step"), "")
+	}
+
+	return role, err
+}
+
+func (u *Approval) storeHistory(ctx context.Context, tx *sql.Tx, role Role, action string, remark string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO approval_histories (approval_id, step, role_id, user_id, action, remark, created)
+		VALUES (?, ?, ?, ?, ?, ?, NOW())`,
+		u.ID, u.Step, role.ID, ctx.Value(api.Ctx("auth")).(User).ID, action, remark)
+	return err
+}
+
+func (u *Approval) changeStatus(ctx context.Context, tx *sql.Tx, step uint8, status string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE approvals
+		SET step = ?,
+			status = ?,
+			updated = NOW()
+		WHERE id = ?
+		AND company_id = ?`,
+		step, status, u.ID, ctx.Value(api.Ctx("auth")).(User).Company.ID)
+	if err != nil {
+		return err
+	}
+
+	return u.Get(ctx, tx)
+}
+
+func (u *Approval) getHistories(ctx context.Context, tx *sql.Tx) error {
+	u.Histories = []ApprovalHistory{}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT approval_histories.step, roles.id, roles.name, users.id, users.username, approval_histories.action, approval_histories.remark, approval_histories.created
+		FROM approval_histories
+		JOIN roles ON approval_histories.role_id = roles.id
+		JOIN users ON approval_histories.user_id = users.id
+		WHERE approval_histories.approval_id = ?
+		ORDER BY approval_histories.id`, u.ID)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var h ApprovalHistory
+		err = rows.Scan(&h.Step, &h.Role.ID, &h.Role.Name, &h.User.ID, &h.User.Username, &h.Action, &h.Remark, &h.Created)
+		if err != nil {
+			return err
+		}
+
+		u.Histories = append(u.Histories, h)
+	}
+
+	return rows.Err()
+}
+
+func (u *Approval) getArgs() []interface{} {
+	var args []interface{}
+	args = append(args, &u.ID)
+	args = append(args, &u.DocumentType)
+	args = append(args, &u.DocumentID)
+	args = append(args, &u.DocumentCode)
+	args = append(args, &u.Amount)
+	args = append(args, &u.Discount)
+	args = append(args, &u.Step)
+	args = append(args, &u.Status)
+	args = append(args, &u.Created)
+	args = append(args, &u.CreatedBy.ID)
+	args = append(args, &u.CreatedBy.Username)
+	args = append(args, &u.Branch.ID)
+	args = append(args, &u.Branch.Code)
+	args = append(args, &u.Branch.Name)
+	args = append(args, &u.Branch.Address)
+	args = append(args, &u.Branch.Type)
+	args = append(args, &u.Rule.ID)
+	args = append(args, &u.Rule.Name)
+	return args
+}
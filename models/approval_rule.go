@@ -0,0 +1,322 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// ApprovalRule : struct of rule requiring document of a type to be approved before it is posted.
+// The rule applies to document of its branch, or every branch when branch is empty, which amount and discount percentage
+// reach the minimum of rule. Amount of document without price is its total quantity, see the document types of approval.
+type ApprovalRule struct {
+	ID           uint32
+	Company      Company
+	Branch       Branch
+	DocumentType string
+	Name         string
+	MinAmount    float64
+	MinDiscount  float64
+	Steps        []ApprovalStep
+}
+
+// ApprovalStep : struct of ordered step of approval rule and the role approving it
+type ApprovalStep struct {
+	Step uint8
+	Role Role
+}
+
+const qApprovalRules = `
+SELECT 	approval_rules.id,
+		approval_rules.document_type,
+		approval_rules.name,
+		approval_rules.min_amount,
+		approval_rules.min_discount,
+		IFNULL(branches.id, 0),
+		IFNULL(branches.code, ''),
+		IFNULL(branches.name, '')
+FROM approval_rules
+LEFT JOIN branches ON approval_rules.branch_id = branches.id
+`
+
+// List of approval rules
+func (u *ApprovalRule) List(ctx context.Context, tx *sql.Tx) ([]ApprovalRule, error) {
+	list := []ApprovalRule{}
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	rows, err := tx.QueryContext(ctx, qApprovalRules+" WHERE approval_rules.company_id = ? ORDER BY approval_rules.document_type, approval_rules.id", userLogin.Company.ID)
+	if err != nil {
+		return list, err
+	}
+
+	for rows.Next() {
+		var r ApprovalRule
+		err = rows.Scan(r.getArgs()...)
+		if err != nil {
+			rows.Close()
+			return list, err
+		}
+
+		r.Company = userLogin.Company
+		list = append(list, r)
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return list, err
+	}
+
+	for i := range list {
+		err = list[i].getSteps(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+	}
+
+	return list, nil
+}
+
+// Get approval rule by id, including its steps
+func (u *ApprovalRule) Get(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	err := tx.QueryRowContext(ctx, qApprovalRules+" WHERE approval_rules.id = ? AND approval_rules.company_id = ?", u.ID, userLogin.Company.ID).Scan(u.getArgs()...)
+	if err != nil {
+		return err
+	}
+
+	u.Company = userLogin.Company
+	return u.getSteps(ctx, tx)
+}
+
+// Create new approval rule with its steps
+func (u *ApprovalRule) Create(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	err := u.validate(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	const query = `
+		INSERT INTO approval_rules (company_id, branch_id, document_type, name, min_amount, min_discount, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, NOW(), NOW())
+	`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, userLogin.Company.ID, u.Branch.nullID(), u.DocumentType, u.Name, u.MinAmount, u.MinDiscount)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	u.ID = uint32(id)
+
+	err = u.storeSteps(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return u.Get(ctx, tx)
+}
+
+// Update approval rule and replace its steps, the new steps apply to pending approvals of rule as well
+func (u *ApprovalRule) Update(ctx context.Context, tx *sql.Tx) error {
+	err := u.validate(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		UPDATE approval_rules
+		SET branch_id = ?,
+			document_type = ?,
+			name = ?,
+			min_amount = ?,
+			min_discount = ?,
+			updated = NOW()
+		WHERE id = ?
+		AND company_id = ?
+	`)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, u.Branch.nullID(), u.DocumentType, u.Name, u.MinAmount, u.MinDiscount, u.ID, ctx.Value(api.Ctx("auth")).(User).Company.ID)
+	if err != nil {
+		return err
+	}
+
+	err = u.storeSteps(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return u.Get(ctx, tx)
+}
+
+// Delete approval rule, rule which has been used by approval can not be deleted
+func (u *ApprovalRule) Delete(ctx context.Context, tx *sql.Tx) error {
+	var used bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT id FROM approvals WHERE approval_rule_id = ?)`, u.ID).Scan(&used)
+	if err != nil {
+		return err
+	}
+
+	if used {
+		return api.ErrBadRequest(errors.New("Approval rule has been used by approvals"), "")
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `DELETE FROM approval_rules WHERE id = ? AND company_id = ?`)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, u.ID, ctx.Value(api.Ctx("auth")).(User).Company.ID)
+	return err
+}
+
+// matchApprovalRule return the rule applying to document in branch of user login, rule of the branch takes precedence
+// over rule of every branch and then the rule with the highest minimum. Return rule with zero id when no rule applies.
+func matchApprovalRule(ctx context.Context, tx *sql.Tx, documentType string, amount float64, discount float64) (ApprovalRule, error) {
+	var rule ApprovalRule
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	err := tx.QueryRowContext(ctx, qApprovalRules+`
+		WHERE approval_rules.company_id = ?
+		AND approval_rules.document_type = ?
+		AND (approval_rules.branch_id IS NULL OR approval_rules.branch_id = ?)
+		AND approval_rules.min_amount <= ?
+		AND approval_rules.min_discount <= ?
+		AND EXISTS(SELECT id FROM approval_rule_steps WHERE approval_rule_id = approval_rules.id)
+		ORDER BY approval_rules.branch_id IS NULL, approval_rules.min_amount DESC, approval_rules.min_discount DESC, approval_rules.id
+		LIMIT 1`,
+		userLogin.Company.ID, documentType, userLogin.Branch.ID, amount, discount).Scan(rule.getArgs()...)
+	if err == sql.ErrNoRows {
+		return rule, nil
+	}
+
+	if err != nil {
+		return rule, err
+	}
+
+	rule.Company = userLogin.Company
+	return rule, rule.getSteps(ctx, tx)
+}
+
+func (u *ApprovalRule) validate(ctx context.Context, tx *sql.Tx) error {
+	if len(u.Steps) == 0 {
+		return api.ErrBadRequest(errors.New("Approval rule must have at least one step"), "")
+	}
+
+	// only sales order has price and discount, minimum amount of other documents is a quantity
+	if u.DocumentType != ApprovalSalesOrder {
+		if u.MinDiscount > 0 {
+			return api.ErrBadRequest(fmt.Errorf("Minimum discount does not apply to document type %s", u.DocumentType), "")
+		}
+
+		if u.MinAmount != math.Trunc(u.MinAmount) {
+			return api.ErrBadRequest(fmt.Errorf("Minimum amount of document type %s is a quantity, it must be a whole number", u.DocumentType), "")
+		}
+	}
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if u.Branch.ID > 0 {
+		var exists bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT id FROM branches WHERE id = ? AND company_id = ?)`, u.Branch.ID, userLogin.Company.ID).Scan(&exists)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return api.ErrBadRequest(fmt.Errorf("Branch %d is not found", u.Branch.ID), "")
+		}
+	}
+
+	for _, s := range u.Steps {
+		var exists bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT id FROM roles WHERE id = ? AND company_id = ?)`, s.Role.ID, userLogin.Company.ID).Scan(&exists)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return api.ErrBadRequest(fmt.Errorf("Role %d is not found", s.Role.ID), "")
+		}
+	}
+
+	return nil
+}
+
+// storeSteps replace steps of approval rule, steps are numbered by their order
+func (u *ApprovalRule) storeSteps(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM approval_rule_steps WHERE approval_rule_id = ?`, u.ID)
+	if err != nil {
+		return err
+	}
+
+	for i, s := range u.Steps {
+		_, err = tx.ExecContext(ctx, `INSERT INTO approval_rule_steps (approval_rule_id, step, role_id) VALUES (?, ?, ?)`, u.ID, i+1, s.Role.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *ApprovalRule) getSteps(ctx context.Context, tx *sql.Tx) error {
+	u.Steps = []ApprovalStep{}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT approval_rule_steps.step, roles.id, roles.name
+		FROM approval_rule_steps
+		JOIN roles ON approval_rule_steps.role_id = roles.id
+		WHERE approval_rule_steps.approval_rule_id = ?
+		ORDER BY approval_rule_steps.step`, u.ID)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var s ApprovalStep
+		err = rows.Scan(&s.Step, &s.Role.ID, &s.Role.Name)
+		if err != nil {
+			return err
+		}
+
+		u.Steps = append(u.Steps, s)
+	}
+
+	return rows.Err()
+}
+
+func (u *ApprovalRule) getArgs() []interface{} {
+	var args []interface{}
+	args = append(args, &u.ID)
+	args = append(args, &u.DocumentType)
+	args = append(args, &u.Name)
+	args = append(args, &u.MinAmount)
+	args = append(args, &u.MinDiscount)
+	args = append(args, &u.Branch.ID)
+	args = append(args, &u.Branch.Code)
+	args = append(args, &u.Branch.Name)
+	return args
+}
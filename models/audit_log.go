@@ -0,0 +1,123 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// Action of audit log
+const (
+	AuditCreate      = "create"
+	AuditUpdate      = "update"
+	AuditDelete      = "delete"
+	AuditLoginFailed = "login_failed"
+	AuditForbidden   = "forbidden"
+)
+
+// AuditLog : struct of audit log, before and after are json data of entity around the action
+type AuditLog struct {
+	ID       uint64
+	Company  Company
+	User     User
+	Action   string
+	Route    string
+	Entity   string
+	EntityID uint64
+	Before   sql.NullString
+	After    sql.NullString
+	Created  time.Time
+}
+
+const qAuditLogs = `
+SELECT audit_logs.id, IFNULL(audit_logs.user_id, 0), audit_logs.username, audit_logs.action, audit_logs.route,
+	audit_logs.entity, IFNULL(audit_logs.entity_id, 0), audit_logs.before_data, audit_logs.after_data, audit_logs.created
+FROM audit_logs`
+
+// List of audit logs of company of user login created between start date and end date,
+// filtered by user, entity, entity id and action when they are set
+func (u *AuditLog) List(ctx context.Context, tx *sql.Tx, startDate, endDate time.Time) ([]AuditLog, error) {
+	list := []AuditLog{}
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	query := qAuditLogs + `
+	WHERE audit_logs.company_id = ?
+	AND DATE(audit_logs.created) BETWEEN ? AND ?`
+	params := []interface{}{userLogin.Company.ID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")}
+
+	if u.User.ID > 0 {
+		query += " AND audit_logs.user_id = ?"
+		params = append(params, u.User.ID)
+	}
+
+	if len(u.Entity) > 0 {
+		query += " AND audit_logs.entity = ?"
+		params = append(params, u.Entity)
+	}
+
+	if u.EntityID > 0 {
+		query += " AND audit_logs.entity_id = ?"
+		params = append(params, u.EntityID)
+	}
+
+	if len(u.Action) > 0 {
+		query += " AND audit_logs.action = ?"
+		params = append(params, u.Action)
+	}
+
+	rows, err := tx.QueryContext(ctx, query+" ORDER BY audit_logs.id DESC", params...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var a AuditLog
+		err = rows.Scan(
+			&a.ID, &a.User.ID, &a.User.Username, &a.Action, &a.Route,
+			&a.Entity, &a.EntityID, &a.Before, &a.After, &a.Created,
+		)
+		if err != nil {
+			return list, err
+		}
+
+		a.Company = userLogin.Company
+		list = append(list, a)
+	}
+
+	return list, rows.Err()
+}
+
+// Create new audit log. It is written outside of transaction of audited action,
+// so company, user and entity id which are not known are kept NULL.
+func (u *AuditLog) Create(ctx context.Context, db *sql.DB) error {
+	res, err := db.ExecContext(ctx, `
+		INSERT INTO audit_logs (company_id, user_id, username, action, route, entity, entity_id, before_data, after_data, created)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NOW())`,
+		nullUint(uint64(u.Company.ID)), nullUint(u.User.ID), u.User.Username, u.Action, u.Route,
+		u.Entity, nullUint(u.EntityID), u.Before, u.After,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	u.ID = uint64(id)
+	return nil
+}
+
+// nullUint return nil for zero id, so column referencing unknown record is kept NULL
+func nullUint(id uint64) interface{} {
+	if id == 0 {
+		return nil
+	}
+
+	return id
+}
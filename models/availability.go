@@ -0,0 +1,207 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// Availability : struct of available to promise quantity of product in a branch
+type Availability struct {
+	Branch   Branch
+	Product  Product
+	OnHand   int
+	Reserved int
+	Incoming int
+}
+
+type availabilityKey struct {
+	BranchID  uint32
+	ProductID uint64
+}
+
+// qSalesOrderQty join ordered, returned and delivered quantity of every sales order and product
+const qSalesOrderQty = `
+	FROM sales_orders
+	JOIN (
+		SELECT sales_order_id, product_id, SUM(qty) qty, SUM(backorder_qty) backorder_qty
+		FROM sales_order_details
+		GROUP BY sales_order_id, product_id
+	) ordered ON sales_orders.id = ordered.sales_order_id
+	LEFT JOIN (
+		SELECT sales_order_returns.sales_order_id, sales_order_return_details.product_id, SUM(sales_order_return_details.qty) qty
+		FROM sales_order_returns
+		JOIN sales_order_return_details ON sales_order_returns.id = sales_order_return_details.sales_order_return_id
+		GROUP BY sales_order_returns.sales_order_id, sales_order_return_details.product_id
+	) returned ON ordered.sales_order_id = returned.sales_order_id AND ordered.product_id = returned.product_id
+	LEFT JOIN (
+		SELECT deliveries.sales_order_id, delivery_details.product_id, SUM(delivery_details.qty) qty
+		FROM deliveries
+		JOIN delivery_details ON deliveries.id = delivery_details.delivery_id
+		WHERE deliveries.voided IS NULL
+		GROUP BY deliveries.sales_order_id, delivery_details.product_id
+	) delivered ON ordered.sales_order_id = delivered.sales_order_id AND ordered.product_id = delivered.product_id
+`
+
+const qReserved = `
+	SELECT sales_orders.branch_id, ordered.product_id,
+		SUM(GREATEST(ordered.qty - IFNULL(returned.qty, 0) - IFNULL(delivered.qty, 0), 0))
+` + qSalesOrderQty + `
+	WHERE sales_orders.company_id = ? AND sales_orders.cancelled IS NULL
+`
+
+// qPurchaseQty join ordered, returned and received quantity of every purchase and product
+const qPurchaseQty = `
+	FROM purchases
+	JOIN (
+		SELECT purchase_id, product_id, SUM(qty) qty
+		FROM purchase_details
+		GROUP BY purchase_id, product_id
+	) ordered ON purchases.id = ordered.purchase_id
+	LEFT JOIN (
+		SELECT purchase_returns.purchase_id, purchase_return_details.product_id, SUM(purchase_return_details.qty) qty
+		FROM purchase_returns
+		JOIN purchase_return_details ON purchase_returns.id = purchase_return_details.purchase_return_id
+		GROUP BY purchase_returns.purchase_id, purchase_return_details.product_id
+	) returned ON ordered.purchase_id = returned.purchase_id AND ordered.product_id = returned.product_id
+	LEFT JOIN (
+		SELECT good_receivings.purchase_id, good_receiving_details.product_id, SUM(good_receiving_details.qty) qty
+		FROM good_receivings
+		JOIN good_receiving_details ON good_receivings.id = good_receiving_details.good_receiving_id
+		WHERE good_receivings.voided IS NULL
+		GROUP BY good_receivings.purchase_id, good_receiving_details.product_id
+	) received ON ordered.purchase_id = received.purchase_id AND ordered.product_id = received.product_id
+`
+
+const qIncoming = `
+	SELECT purchases.branch_id, ordered.product_id,
+		SUM(GREATEST(ordered.qty - IFNULL(returned.qty, 0) - IFNULL(received.qty, 0), 0))
+` + qPurchaseQty + `
+	WHERE purchases.company_id = ? AND purchases.status IN ('approved', 'partially_received')
+`
+
+// ATP return available to promise quantity, on hand minus reserved plus incoming purchases
+func (u *Availability) ATP() int {
+	return u.OnHand - u.Reserved + u.Incoming
+}
+
+// List availability of product in every branch visible by user login
+func (u *Availability) List(ctx context.Context, tx *sql.Tx) ([]Availability, error) {
+	var list []Availability
+
+	err := u.Product.Get(ctx, tx)
+	if err != nil {
+		return list, err
+	}
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	query := `SELECT id, code, name, address, type FROM branches WHERE company_id = ?`
+	params := []interface{}{userLogin.Company.ID}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "id=?")
+			params = append(params, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND id=?"
+		params = append(params, userLogin.Branch.ID)
+	}
+
+	rows, err := tx.QueryContext(ctx, query+" ORDER BY id", params...)
+	if err != nil {
+		return list, err
+	}
+
+	for rows.Next() {
+		a := Availability{Product: u.Product}
+		err = rows.Scan(&a.Branch.ID, &a.Branch.Code, &a.Branch.Name, &a.Branch.Address, &a.Branch.Type)
+		if err != nil {
+			rows.Close()
+			return list, err
+		}
+
+		a.Branch.Company = userLogin.Company
+		list = append(list, a)
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return list, err
+	}
+
+	reserved, err := listReserved(ctx, tx, " AND ordered.product_id = ?", u.Product.ID)
+	if err != nil {
+		return list, err
+	}
+
+	incoming, err := listIncoming(ctx, tx, " AND ordered.product_id = ?", u.Product.ID)
+	if err != nil {
+		return list, err
+	}
+
+	for i := range list {
+		err = tx.QueryRowContext(ctx, "SELECT IFNULL(stock_branch(?, ?, ?), 0)", userLogin.Company.ID, list[i].Branch.ID, u.Product.ID).Scan(&list[i].OnHand)
+		if err != nil {
+			return list, err
+		}
+
+		key := availabilityKey{list[i].Branch.ID, u.Product.ID}
+		list[i].Reserved = reserved[key]
+		list[i].Incoming = incoming[key]
+	}
+
+	return list, nil
+}
+
+// listReserved return quantity reserved by open sales orders grouped by branch and product.
+// Reservation is released by delivery, sales order return and cancellation of sales order.
+func listReserved(ctx context.Context, tx *sql.Tx, where string, params ...interface{}) (map[availabilityKey]int, error) {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	params = append([]interface{}{userLogin.Company.ID}, params...)
+	return listAvailabilityQty(ctx, tx, qReserved+where+" GROUP BY sales_orders.branch_id, ordered.product_id", params...)
+}
+
+// listIncoming return quantity of approved purchases which has not been received grouped by branch and product,
+// purchase which has been partially received is still incoming for its remaining quantity
+func listIncoming(ctx context.Context, tx *sql.Tx, where string, params ...interface{}) (map[availabilityKey]int, error) {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	params = append([]interface{}{userLogin.Company.ID}, params...)
+	return listAvailabilityQty(ctx, tx, qIncoming+where+" GROUP BY purchases.branch_id, ordered.product_id", params...)
+}
+
+func listAvailabilityQty(ctx context.Context, tx *sql.Tx, query string, params ...interface{}) (map[availabilityKey]int, error) {
+	list := make(map[availabilityKey]int)
+
+	rows, err := tx.QueryContext(ctx, query, params...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var key availabilityKey
+		var qty int
+		err = rows.Scan(&key.BranchID, &key.ProductID, &qty)
+		if err != nil {
+			return list, err
+		}
+
+		list[key] = qty
+	}
+
+	return list, rows.Err()
+}
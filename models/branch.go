@@ -3,6 +3,8 @@ package models
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 
 	"github.com/jacky-htg/inventory/libraries/api"
 )
@@ -246,3 +248,48 @@ func (s *Shelve) Delete(ctx context.Context, tx *sql.Tx) error {
 	_, err = stmt.ExecContext(ctx, s.ID, ctx.Value(api.Ctx("auth")).(User).Branch.ID)
 	return err
 }
+
+// GetUsed return quantity on hand in the shelve, summed from movements posted into and out of the shelve
+func (s *Shelve) GetUsed(ctx context.Context, tx *sql.Tx) (uint, error) {
+	var used uint
+	err := tx.QueryRowContext(ctx, `
+		SELECT GREATEST(IFNULL(SUM(IF(in_out, qty, -qty)), 0), 0)
+		FROM inventories
+		WHERE company_id = ? AND shelve_id = ?`,
+		ctx.Value(api.Ctx("auth")).(User).Company.ID, s.ID).Scan(&used)
+
+	return used, err
+}
+
+// CheckCapacity validate the shelve is in user login branch and its unit codes on hand do not exceed the capacity.
+// It must be called after the inventory is posted into the shelve.
+func (s *Shelve) CheckCapacity(ctx context.Context, tx *sql.Tx) error {
+	err := s.View(ctx, tx)
+	if err == sql.ErrNoRows {
+		return api.ErrBadRequest(errors.New("Invalid shelve"), "")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	used, err := s.GetUsed(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if used > s.Capacity {
+		return api.ErrBadRequest(fmt.Errorf("Capacity of shelve %s is exceeded, %d of %d", s.Code, used, s.Capacity), "")
+	}
+
+	return nil
+}
+
+// nullID return nil for empty branch, so column referencing branch is kept NULL
+func (u *Branch) nullID() interface{} {
+	if u.ID == 0 {
+		return nil
+	}
+
+	return u.ID
+}
@@ -3,37 +3,432 @@ package models
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/jacky-htg/inventory/libraries/api"
 )
 
 // ClosingStock : struct of ClosingStock
 type ClosingStock struct {
-	Month int
-	Year  int
+	Month               int
+	Year                int
+	Status              string
+	Closed              time.Time
+	ClosingStockDetails []ClosingStockDetail
 }
 
-// Closing stock
-func (u *ClosingStock) Closing(ctx context.Context, db *sql.DB) error {
-	q := `
-		SELECT year, month
-		FROM saldo_stocks 
-		WHERE company_id=? 
-		ORDER BY CAST(CONCAT(year, month) AS UNSIGNED) DESC LIMIT 1 
-	`
-	err := db.QueryRowContext(ctx, q, ctx.Value(api.Ctx("auth")).(User).Company.ID).Scan(&u.Year, &u.Month)
+// ClosingStockDetail : struct of product balance in closing period
+type ClosingStockDetail struct {
+	Product Product
+	Opening int
+	In      int
+	Out     int
+	Closing int
+}
+
+// OpeningBalance : struct of unit code on hand imported before the first closing
+type OpeningBalance struct {
+	Product Product
+	Code    string
+	Shelve  Shelve
+	Qty     uint
+}
+
+// ClosedPeriodClosed : status of closed period which transactions are locked
+const ClosedPeriodClosed = "C"
+
+// ClosedPeriodReopened : status of closed period which is reopened and waiting to be closed again
+const ClosedPeriodReopened = "R"
+
+// Closing stock.
+// When there are reopened periods, all of them are closed again from the oldest one,
+// otherwise the next open period is closed. The period, when selected, must be the period to be closed.
+func (u *ClosingStock) Closing(ctx context.Context, tx *sql.Tx) error {
+	reopened, err := u.listReopened(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if len(reopened) > 0 {
+		if u.Year > 0 && (u.Year != reopened[0].Year || u.Month != reopened[0].Month) {
+			return api.ErrBadRequest(fmt.Errorf("Period to close is %02d-%d", reopened[0].Month, reopened[0].Year), "")
+		}
+
+		for i := range reopened {
+			err = reopened[i].close(ctx, tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		*u = reopened[len(reopened)-1]
+		return nil
+	}
+
+	err = u.getNextPeriod(ctx, tx)
 	if err != nil {
 		return err
 	}
 
-	q = `call closing_stocks(?, ?, ?)`
-	stmt, err := db.PrepareContext(ctx, q)
+	return u.close(ctx, tx)
+}
+
+// Preview the balance of the next closing without writing saldo stocks
+func (u *ClosingStock) Preview(ctx context.Context, tx *sql.Tx) error {
+	reopened, err := u.listReopened(ctx, tx)
 	if err != nil {
 		return err
 	}
 
-	defer stmt.Close()
+	if len(reopened) > 0 {
+		u.Year, u.Month = reopened[0].Year, reopened[0].Month
+	} else {
+		err = u.getNextPeriod(ctx, tx)
+		if err != nil {
+			return err
+		}
+	}
 
-	_, err = stmt.ExecContext(ctx, ctx.Value(api.Ctx("auth")).(User).Company.ID, u.Month, u.Year)
+	u.Status = ""
+	u.ClosingStockDetails, err = u.getDetails(ctx, tx)
 	return err
 }
+
+// List closed periods with balance of products, filtered by year and month when they are selected
+func (u *ClosingStock) List(ctx context.Context, tx *sql.Tx) ([]ClosingStock, error) {
+	var list []ClosingStock
+
+	query := `SELECT year, month, status, closed FROM closed_periods WHERE company_id = ?`
+	params := []interface{}{ctx.Value(api.Ctx("auth")).(User).Company.ID}
+	if u.Year > 0 {
+		query += " AND year = ?"
+		params = append(params, u.Year)
+	}
+
+	if u.Month > 0 {
+		query += " AND month = ?"
+		params = append(params, u.Month)
+	}
+
+	rows, err := tx.QueryContext(ctx, query+" ORDER BY year DESC, month DESC", params...)
+	if err != nil {
+		return list, err
+	}
+
+	for rows.Next() {
+		var c ClosingStock
+		err = rows.Scan(&c.Year, &c.Month, &c.Status, &c.Closed)
+		if err != nil {
+			rows.Close()
+			return list, err
+		}
+
+		list = append(list, c)
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return list, err
+	}
+
+	for i := range list {
+		list[i].ClosingStockDetails, err = list[i].getDetails(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+	}
+
+	return list, nil
+}
+
+// ImportOpeningBalances post unit codes on hand of user login branch as opening balance.
+// It is only allowed before the first closing of company.
+func (u *ClosingStock) ImportOpeningBalances(ctx context.Context, tx *sql.Tx, date time.Time, balances []OpeningBalance) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Branch.ID <= 0 {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	var closed int
+	err := tx.QueryRowContext(ctx, `SELECT COUNT(id) FROM closed_periods WHERE company_id = ?`, userLogin.Company.ID).Scan(&closed)
+	if err != nil {
+		return err
+	}
+
+	if closed > 0 {
+		return api.ErrBadRequest(errors.New("Opening balance is only allowed before the first closing"), "")
+	}
+
+	for _, b := range balances {
+		if len(b.Code) == 0 {
+			return api.ErrBadRequest(errors.New("Unit code is required"), "")
+		}
+
+		err = b.Product.CheckQty(ctx, tx, b.Qty)
+		if err != nil {
+			return err
+		}
+
+		position := new(Inventory)
+		position.ProductID = b.Product.ID
+		position.ProductCode = b.Code
+		_, err = position.GetLastPosition(ctx, tx)
+		if err == nil {
+			return api.ErrBadRequest(fmt.Errorf("Unit code %s already exists", b.Code), "")
+		}
+
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		inventory := new(Inventory)
+		inventory.CompanyID = userLogin.Company.ID
+		inventory.BranchID = userLogin.Branch.ID
+		inventory.ShelveID = b.Shelve.ID
+		inventory.ProductID = b.Product.ID
+		inventory.ProductCode = b.Code
+		inventory.Code = "OB" + date.Format("200601")
+		inventory.TransactionDate = date
+		inventory.Type = "OB"
+		inventory.InOut = true
+		inventory.Qty = b.Qty
+		err = inventory.Create(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		err = b.Shelve.CheckCapacity(ctx, tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reopen closed period and all periods after it, the saldo stocks resulted by their closing are removed.
+// It returns the reopened periods. Reopened periods accept postings until closing stock is run again,
+// which closes all of them from the oldest one and rebuilds their saldo stocks before any later period can be closed.
+func (u *ClosingStock) Reopen(ctx context.Context, tx *sql.Tx) ([]ClosingStock, error) {
+	var list []ClosingStock
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	period := u.Year*100 + u.Month
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT year, month
+		FROM closed_periods
+		WHERE company_id = ? AND status = ? AND year * 100 + month >= ?
+		ORDER BY year, month`,
+		userLogin.Company.ID, ClosedPeriodClosed, period)
+	if err != nil {
+		return list, err
+	}
+
+	for rows.Next() {
+		var p ClosingStock
+		err = rows.Scan(&p.Year, &p.Month)
+		if err != nil {
+			rows.Close()
+			return list, err
+		}
+
+		list = append(list, p)
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return list, err
+	}
+
+	if len(list) == 0 || list[0].Year != u.Year || list[0].Month != u.Month {
+		return list, api.ErrBadRequest(errors.New("Period is not closed"), "")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE closed_periods
+		SET status = ?, reopened = NOW(), reopened_by = ?
+		WHERE company_id = ? AND status = ? AND year * 100 + month >= ?`,
+		ClosedPeriodReopened, userLogin.ID, userLogin.Company.ID, ClosedPeriodClosed, period)
+	if err != nil {
+		return list, err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM saldo_stocks WHERE company_id = ? AND year * 100 + month > ?`, userLogin.Company.ID, period)
+
+	return list, err
+}
+
+// close run closing stock procedure of the period and register it as closed period
+func (u *ClosingStock) close(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	q := `call closing_stocks(?, ?, ?)`
+	stmt, err := tx.PrepareContext(ctx, q)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, userLogin.Company.ID, u.Month, u.Year)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO closed_periods (company_id, year, month, status, closed, closed_by)
+		VALUES (?, ?, ?, ?, NOW(), ?)
+		ON DUPLICATE KEY UPDATE status = VALUES(status), closed = NOW(), closed_by = VALUES(closed_by)`,
+		userLogin.Company.ID, u.Year, u.Month, ClosedPeriodClosed, userLogin.ID)
+	if err != nil {
+		return err
+	}
+
+	u.Status = ClosedPeriodClosed
+	u.Closed = time.Now()
+	return nil
+}
+
+// getNextPeriod fill the period to be closed, that is the period of the latest saldo stock.
+// For the first closing of company, the period must be selected.
+func (u *ClosingStock) getNextPeriod(ctx context.Context, tx *sql.Tx) error {
+	var next ClosingStock
+	err := tx.QueryRowContext(ctx, `
+		SELECT year, month
+		FROM saldo_stocks
+		WHERE company_id=?
+		ORDER BY year DESC, month DESC LIMIT 1`,
+		ctx.Value(api.Ctx("auth")).(User).Company.ID).Scan(&next.Year, &next.Month)
+
+	if err == sql.ErrNoRows {
+		if u.Year <= 0 || u.Month <= 0 {
+			return api.ErrBadRequest(errors.New("Period must be selected for the first closing"), "")
+		}
+
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if u.Year > 0 && (u.Year != next.Year || u.Month != next.Month) {
+		return api.ErrBadRequest(fmt.Errorf("Period to close is %02d-%d", next.Month, next.Year), "")
+	}
+
+	u.Year, u.Month = next.Year, next.Month
+	return nil
+}
+
+// getDetails return opening, movements and closing quantity of products in the period.
+// The closing quantity is taken from saldo stock of the next period when the period has been closed.
+func (u *ClosingStock) getDetails(ctx context.Context, tx *sql.Tx) ([]ClosingStockDetail, error) {
+	var list []ClosingStockDetail
+	companyID := ctx.Value(api.Ctx("auth")).(User).Company.ID
+
+	nextYear, nextMonth := u.Year, u.Month+1
+	if u.Month == 12 {
+		nextYear, nextMonth = u.Year+1, 1
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT products.id, products.code, products.name,
+			IFNULL(opening.qty, 0), IFNULL(movements.qty_in, 0), IFNULL(movements.qty_out, 0), closing.qty
+		FROM products
+		LEFT JOIN saldo_stocks opening ON products.id = opening.product_id AND opening.company_id = products.company_id AND opening.year = ? AND opening.month = ?
+		LEFT JOIN saldo_stocks closing ON products.id = closing.product_id AND closing.company_id = products.company_id AND closing.year = ? AND closing.month = ?
+		LEFT JOIN (
+			SELECT product_id, SUM(IF(in_out, qty, 0)) qty_in, SUM(IF(in_out, 0, qty)) qty_out
+			FROM inventories
+			WHERE company_id = ? AND YEAR(transaction_date) = ? AND MONTH(transaction_date) = ?
+			GROUP BY product_id
+		) movements ON products.id = movements.product_id
+		WHERE products.company_id = ? AND (opening.id IS NOT NULL OR closing.id IS NOT NULL OR movements.product_id IS NOT NULL)
+		ORDER BY products.id`,
+		u.Year, u.Month, nextYear, nextMonth, companyID, u.Year, u.Month, companyID)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var d ClosingStockDetail
+		var closing sql.NullInt64
+		err = rows.Scan(&d.Product.ID, &d.Product.Code, &d.Product.Name, &d.Opening, &d.In, &d.Out, &closing)
+		if err != nil {
+			return list, err
+		}
+
+		d.Closing = d.Opening + d.In - d.Out
+		if closing.Valid {
+			d.Closing = int(closing.Int64)
+		}
+
+		list = append(list, d)
+	}
+
+	return list, rows.Err()
+}
+
+func (u *ClosingStock) listReopened(ctx context.Context, tx *sql.Tx) ([]ClosingStock, error) {
+	var list []ClosingStock
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT year, month FROM closed_periods WHERE company_id = ? AND status = ? ORDER BY year, month`,
+		ctx.Value(api.Ctx("auth")).(User).Company.ID, ClosedPeriodReopened)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var p ClosingStock
+		err = rows.Scan(&p.Year, &p.Month)
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, p)
+	}
+
+	return list, rows.Err()
+}
+
+// checkClosedPeriod reject transaction date which is in closed period
+func checkClosedPeriod(ctx context.Context, tx *sql.Tx, date time.Time) error {
+	var closed bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) > 0
+		FROM closed_periods
+		WHERE company_id = ? AND status = ? AND year * 100 + month >= ?`,
+		ctx.Value(api.Ctx("auth")).(User).Company.ID, ClosedPeriodClosed, date.Year()*100+int(date.Month())).Scan(&closed)
+	if err != nil {
+		return err
+	}
+
+	if closed {
+		return api.ErrBadRequest(fmt.Errorf("Period %s is closed", date.Format("January 2006")), "")
+	}
+
+	return nil
+}
+
+// checkClosedTransaction reject changes of transaction which current or new date is in closed period
+func checkClosedTransaction(ctx context.Context, tx *sql.Tx, table string, id uint64, date time.Time) error {
+	var current time.Time
+	err := tx.QueryRowContext(ctx, "SELECT date FROM "+table+" WHERE id = ?", id).Scan(&current)
+	if err != nil {
+		return err
+	}
+
+	err = checkClosedPeriod(ctx, tx, current)
+	if err != nil {
+		return err
+	}
+
+	return checkClosedPeriod(ctx, tx, date)
+}
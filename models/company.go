@@ -10,13 +10,30 @@ import (
 
 //Company : struct of Company
 type Company struct {
-	ID      uint32
-	Code    string
-	Name    string
-	Address sql.NullString
+	ID                   uint32
+	Code                 string
+	Name                 string
+	Address              sql.NullString
+	ValuationMethod      string
+	ATPPolicy            string
+	POApprovalLimit      float64
+	OverReceiptTolerance float64
+	ImmutablePosting     bool
 }
 
-const qCompanies = `SELECT id, code, name, address FROM companies`
+// ValuationFIFO : cost every unit with its own receiving cost, first in first out
+const ValuationFIFO = "FIFO"
+
+// ValuationAverage : cost every unit with moving average cost of product
+const ValuationAverage = "AVG"
+
+// ATPReject : reject sales order which quantity exceeds available to promise
+const ATPReject = "REJECT"
+
+// ATPBackorder : accept sales order which quantity exceeds available to promise and flag the shortage as backorder
+const ATPBackorder = "BACKORDER"
+
+const qCompanies = `SELECT id, code, name, address, valuation_method, atp_policy, po_approval_limit, over_receipt_tolerance, immutable_posting FROM companies`
 
 //List of companies
 func (u *Company) List(ctx context.Context, db *sql.DB) ([]Company, error) {
@@ -58,8 +75,8 @@ func (u *Company) Get(ctx context.Context, db *sql.DB) error {
 //Create new company
 func (u *Company) Create(ctx context.Context, db *sql.DB) error {
 	const query = `
-		INSERT INTO companies (code, name, address, created)
-		VALUES (?, ?, ?, NOW())
+		INSERT INTO companies (code, name, address, valuation_method, atp_policy, po_approval_limit, over_receipt_tolerance, immutable_posting, created)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW())
 	`
 	stmt, err := db.PrepareContext(ctx, query)
 	if err != nil {
@@ -68,7 +85,15 @@ func (u *Company) Create(ctx context.Context, db *sql.DB) error {
 
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, u.Code, u.Name, u.Address)
+	if len(u.ValuationMethod) == 0 {
+		u.ValuationMethod = ValuationFIFO
+	}
+
+	if len(u.ATPPolicy) == 0 {
+		u.ATPPolicy = ATPBackorder
+	}
+
+	res, err := stmt.ExecContext(ctx, u.Code, u.Name, u.Address, u.ValuationMethod, u.ATPPolicy, u.POApprovalLimit, u.OverReceiptTolerance, u.ImmutablePosting)
 	if err != nil {
 		return err
 	}
@@ -90,6 +115,11 @@ func (u *Company) Update(ctx context.Context, db *sql.DB) error {
 		UPDATE companies 
 		SET name = ?,
 			address = ?,
+			valuation_method = ?,
+			atp_policy = ?,
+			po_approval_limit = ?,
+			over_receipt_tolerance = ?,
+			immutable_posting = ?,
 			updated = NOW()
 		WHERE id = ?
 	`)
@@ -99,10 +129,45 @@ func (u *Company) Update(ctx context.Context, db *sql.DB) error {
 
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(ctx, u.Name, u.Address, u.ID)
+	_, err = stmt.ExecContext(ctx, u.Name, u.Address, u.ValuationMethod, u.ATPPolicy, u.POApprovalLimit, u.OverReceiptTolerance, u.ImmutablePosting, u.ID)
 	return err
 }
 
+//GetValuationMethod of company, FIFO or moving average
+func (u *Company) GetValuationMethod(ctx context.Context, tx *sql.Tx) (string, error) {
+	var method string
+	err := tx.QueryRowContext(ctx, "SELECT valuation_method FROM companies WHERE id = ?", u.ID).Scan(&method)
+	return method, err
+}
+
+//GetATPPolicy of company, reject or backorder sales order exceeding available to promise
+func (u *Company) GetATPPolicy(ctx context.Context, tx *sql.Tx) (string, error) {
+	var policy string
+	err := tx.QueryRowContext(ctx, "SELECT atp_policy FROM companies WHERE id = ?", u.ID).Scan(&policy)
+	return policy, err
+}
+
+//GetPOApprovalLimit of company, purchase order with total above the limit requires a second approver. Zero means no limit.
+func (u *Company) GetPOApprovalLimit(ctx context.Context, tx *sql.Tx) (float64, error) {
+	var limit float64
+	err := tx.QueryRowContext(ctx, "SELECT po_approval_limit FROM companies WHERE id = ?", u.ID).Scan(&limit)
+	return limit, err
+}
+
+// GetOverReceiptTolerance of company, percentage of purchased quantity which can be received above it
+func (u *Company) GetOverReceiptTolerance(ctx context.Context, tx *sql.Tx) (float64, error) {
+	var tolerance float64
+	err := tx.QueryRowContext(ctx, "SELECT over_receipt_tolerance FROM companies WHERE id = ?", u.ID).Scan(&tolerance)
+	return tolerance, err
+}
+
+// GetImmutablePosting of company, posted documents of immutable posting company can only be voided instead of edited
+func (u *Company) GetImmutablePosting(ctx context.Context, tx *sql.Tx) (bool, error) {
+	var immutable bool
+	err := tx.QueryRowContext(ctx, "SELECT immutable_posting FROM companies WHERE id = ?", u.ID).Scan(&immutable)
+	return immutable, err
+}
+
 //Delete company
 func (u *Company) Delete(ctx context.Context, db *sql.DB) error {
 	stmt, err := db.PrepareContext(ctx, `DELETE FROM companies WHERE id = ?`)
@@ -172,6 +237,11 @@ func (u *Company) getArgs() []interface{} {
 	args = append(args, &u.Code)
 	args = append(args, &u.Name)
 	args = append(args, &u.Address)
+	args = append(args, &u.ValuationMethod)
+	args = append(args, &u.ATPPolicy)
+	args = append(args, &u.POApprovalLimit)
+	args = append(args, &u.OverReceiptTolerance)
+	args = append(args, &u.ImmutablePosting)
 
 	return args
 }
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -20,19 +19,40 @@ type Delivery struct {
 	Code            string
 	Date            time.Time
 	Remark          string
+	Picking         string
+	AllowExpired    bool
 	SalesOrder      SalesOrder
 	Company         Company
 	Branch          Branch
+	CorrectionOf    Correction
+	Voided          Void
 	DeliveryDetails []DeliveryDetail
 }
 
+// DeliveryPickingFIFO : auto pick unit codes by the earliest receipt date
+const DeliveryPickingFIFO = "FIFO"
+
+// DeliveryPickingFEFO : auto pick unit codes by the earliest expired date
+const DeliveryPickingFEFO = "FEFO"
+
 // DeliveryDetail struct
 type DeliveryDetail struct {
 	ID      uint64
 	Product Product
 	Qty     uint
+	Uom     Uom
+	UomQty  uint
 	Code    string
+	Lot     string
 	Shelve  Shelve
+	Cost    float64
+}
+
+// DeliveryMargin : struct of sales revenue against cost of goods sold of a delivery
+type DeliveryMargin struct {
+	Delivery Delivery
+	Revenue  float64
+	Cost     float64
 }
 
 // List Deliveries
@@ -44,6 +64,7 @@ func (u *Delivery) List(ctx context.Context, tx *sql.Tx) ([]Delivery, error) {
 	SELECT 	deliveries.id, 
 		deliveries.code, 
 		deliveries.date,
+		deliveries.voided,
 		sales_orders.id,
 		sales_orders.code,
 		companies.id, 
@@ -100,6 +121,7 @@ func (u *Delivery) List(ctx context.Context, tx *sql.Tx) ([]Delivery, error) {
 			&Delivery.ID,
 			&Delivery.Code,
 			&Delivery.Date,
+			&Delivery.Voided.Date,
 			&Delivery.SalesOrder.ID,
 			&Delivery.SalesOrder.Code,
 			&Delivery.Company.ID,
@@ -142,8 +164,14 @@ func (u *Delivery) Get(ctx context.Context, tx *sql.Tx) error {
 		branches.type,
 		JSON_ARRAYAGG(delivery_details.id),
 		JSON_ARRAYAGG(delivery_details.code),
+		JSON_ARRAYAGG(delivery_details.lot),
 		JSON_ARRAYAGG(delivery_details.shelve_id),
 		JSON_ARRAYAGG(delivery_details.qty),
+		JSON_ARRAYAGG(IFNULL(delivery_details.uom_id, 0)),
+		JSON_ARRAYAGG(IFNULL(uoms.code, '')),
+		JSON_ARRAYAGG(IFNULL(uoms.name, '')),
+		JSON_ARRAYAGG(IFNULL(delivery_details.uom_qty, delivery_details.qty)),
+		JSON_ARRAYAGG(delivery_details.cost),
 		JSON_ARRAYAGG(products.id),
 		JSON_ARRAYAGG(products.code),
 		JSON_ARRAYAGG(products.name),
@@ -154,6 +182,7 @@ func (u *Delivery) Get(ctx context.Context, tx *sql.Tx) error {
 	JOIN branches ON deliveries.branch_id = branches.id
 	JOIN delivery_details ON deliveries.id = delivery_details.delivery_id
 	JOIN products ON delivery_details.product_id = products.id 
+	LEFT JOIN uoms ON delivery_details.uom_id = uoms.id
 	WHERE deliveries.id=? AND companies.id=?
 	`
 	userLogin := ctx.Value(api.Ctx("auth")).(User)
@@ -179,7 +208,7 @@ func (u *Delivery) Get(ctx context.Context, tx *sql.Tx) error {
 		params = append(params, userLogin.Branch.ID)
 	}
 
-	var detailID, detailCode, detailShelveID, detailQty, productID, productCode, productName, productPrice string
+	var detailID, detailCode, detailLot, detailShelveID, detailQty, detailUomID, detailUomCode, detailUomName, detailUomQty, detailCost, productID, productCode, productName, productPrice string
 	err := tx.QueryRowContext(ctx, query+" GROUP BY deliveries.id", params...).Scan(
 		&u.ID,
 		&u.Code,
@@ -197,8 +226,14 @@ func (u *Delivery) Get(ctx context.Context, tx *sql.Tx) error {
 		&u.Branch.Type,
 		&detailID,
 		&detailCode,
+		&detailLot,
 		&detailShelveID,
 		&detailQty,
+		&detailUomID,
+		&detailUomCode,
+		&detailUomName,
+		&detailUomQty,
+		&detailCost,
 		&productID,
 		&productCode,
 		&productName,
@@ -222,6 +257,12 @@ func (u *Delivery) Get(ctx context.Context, tx *sql.Tx) error {
 			return err
 		}
 
+		var detailLots []string
+		err = json.Unmarshal([]byte(detailLot), &detailLots)
+		if err != nil {
+			return err
+		}
+
 		var detailShelveIDs []uint64
 		err = json.Unmarshal([]byte(detailShelveID), &detailShelveIDs)
 		if err != nil {
@@ -234,6 +275,36 @@ func (u *Delivery) Get(ctx context.Context, tx *sql.Tx) error {
 			return err
 		}
 
+		var detailUomIDs []uint64
+		err = json.Unmarshal([]byte(detailUomID), &detailUomIDs)
+		if err != nil {
+			return err
+		}
+
+		var detailUomCodes []string
+		err = json.Unmarshal([]byte(detailUomCode), &detailUomCodes)
+		if err != nil {
+			return err
+		}
+
+		var detailUomNames []string
+		err = json.Unmarshal([]byte(detailUomName), &detailUomNames)
+		if err != nil {
+			return err
+		}
+
+		var detailUomQtys []uint
+		err = json.Unmarshal([]byte(detailUomQty), &detailUomQtys)
+		if err != nil {
+			return err
+		}
+
+		var detailCosts []float64
+		err = json.Unmarshal([]byte(detailCost), &detailCosts)
+		if err != nil {
+			return err
+		}
+
 		var productIDs []uint64
 		err = json.Unmarshal([]byte(productID), &productIDs)
 		if err != nil {
@@ -262,10 +333,14 @@ func (u *Delivery) Get(ctx context.Context, tx *sql.Tx) error {
 			u.DeliveryDetails = append(u.DeliveryDetails, DeliveryDetail{
 				ID:   uint64(v),
 				Code: detailCodes[i],
+				Lot:  detailLots[i],
 				Shelve: Shelve{
 					ID: detailShelveIDs[i],
 				},
-				Qty: detailQtys[i],
+				Qty:    detailQtys[i],
+				Uom:    Uom{ID: detailUomIDs[i], Code: detailUomCodes[i], Name: detailUomNames[i]},
+				UomQty: detailUomQtys[i],
+				Cost:   detailCosts[i],
 				Product: Product{
 					ID:        productIDs[i],
 					Code:      productCodes[i],
@@ -277,7 +352,105 @@ func (u *Delivery) Get(ctx context.Context, tx *sql.Tx) error {
 		}
 	}
 
-	return nil
+	return getVoid(ctx, tx, "deliveries", u.ID, &u.Voided, &u.CorrectionOf)
+}
+
+// ListMargins return gross margin of deliveries between dates, voided deliveries are excluded.
+// Revenue is valued by the net unit price of product in sales order, cost by cost of goods sold recorded on delivery.
+func (u *Delivery) ListMargins(ctx context.Context, tx *sql.Tx, startDate, endDate time.Time) ([]DeliveryMargin, error) {
+	var list []DeliveryMargin
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	query := `
+	SELECT 	deliveries.id, 
+		deliveries.code, 
+		deliveries.date,
+		sales_orders.id,
+		sales_orders.code,
+		customers.id,
+		customers.name,
+		customers.email,
+		customers.address,
+		customers.hp,
+		branches.id,
+		branches.code,
+		branches.name,
+		branches.address,
+		branches.type,
+		SUM(delivery_details.qty * IFNULL(prices.price, 0)),
+		SUM(delivery_details.qty * delivery_details.cost)
+	FROM deliveries
+	JOIN sales_orders ON deliveries.sales_order_id = sales_orders.id
+	JOIN customers ON sales_orders.customer_id = customers.id
+	JOIN branches ON deliveries.branch_id = branches.id
+	JOIN delivery_details ON deliveries.id = delivery_details.delivery_id
+	LEFT JOIN (
+		SELECT sales_order_id, product_id, SUM(price - disc) / SUM(qty) price
+		FROM sales_order_details
+		GROUP BY sales_order_id, product_id
+	) prices ON deliveries.sales_order_id = prices.sales_order_id AND delivery_details.product_id = prices.product_id
+	WHERE deliveries.company_id = ? AND deliveries.voided IS NULL AND deliveries.date BETWEEN ? AND ?
+	`
+	params := []interface{}{userLogin.Company.ID, startDate, endDate}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=?")
+			params = append(params, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND branches.id=?"
+		params = append(params, userLogin.Branch.ID)
+	}
+
+	rows, err := tx.QueryContext(ctx, query+" GROUP BY deliveries.id ORDER BY deliveries.date, deliveries.id", params...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var m DeliveryMargin
+		err = rows.Scan(
+			&m.Delivery.ID,
+			&m.Delivery.Code,
+			&m.Delivery.Date,
+			&m.Delivery.SalesOrder.ID,
+			&m.Delivery.SalesOrder.Code,
+			&m.Delivery.SalesOrder.Customer.ID,
+			&m.Delivery.SalesOrder.Customer.Name,
+			&m.Delivery.SalesOrder.Customer.Email,
+			&m.Delivery.SalesOrder.Customer.Address,
+			&m.Delivery.SalesOrder.Customer.Hp,
+			&m.Delivery.Branch.ID,
+			&m.Delivery.Branch.Code,
+			&m.Delivery.Branch.Name,
+			&m.Delivery.Branch.Address,
+			&m.Delivery.Branch.Type,
+			&m.Revenue,
+			&m.Cost,
+		)
+		if err != nil {
+			return list, err
+		}
+
+		m.Delivery.Company = userLogin.Company
+		m.Delivery.Branch.Company = userLogin.Company
+		list = append(list, m)
+	}
+
+	return list, rows.Err()
 }
 
 // Create new Delivery
@@ -287,6 +460,10 @@ func (u *Delivery) Create(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if err := checkClosedPeriod(ctx, tx, u.Date); err != nil {
+		return err
+	}
+
 	err := u.SalesOrder.Get(ctx, tx)
 	if err != nil {
 		return err
@@ -296,9 +473,23 @@ func (u *Delivery) Create(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if u.SalesOrder.Cancelled.Valid {
+		return api.ErrBadRequest(errors.New("Sales order has been cancelled"), "")
+	}
+
+	err = checkApproval(ctx, tx, ApprovalSalesOrder, u.SalesOrder.ID, u.SalesOrder.Code)
+	if err != nil {
+		return err
+	}
+
+	err = u.CorrectionOf.check(ctx, tx, "delivery", "deliveries")
+	if err != nil {
+		return err
+	}
+
 	const query = `
-		INSERT INTO deliveries (code, date, remark, sales_order_id, company_id, branch_id, created_by, updated_by, created, updated)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+		INSERT INTO deliveries (code, date, remark, correction_of, sales_order_id, company_id, branch_id, created_by, updated_by, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
 	`
 	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
@@ -312,7 +503,7 @@ func (u *Delivery) Create(ctx context.Context, tx *sql.Tx) error {
 		return err
 	}
 
-	res, err := stmt.ExecContext(ctx, u.Code, u.Date, u.Remark, u.SalesOrder.ID, userLogin.Company.ID, userLogin.Branch.ID, userLogin.ID, userLogin.ID)
+	res, err := stmt.ExecContext(ctx, u.Code, u.Date, u.Remark, u.CorrectionOf.nullID(), u.SalesOrder.ID, userLogin.Company.ID, userLogin.Branch.ID, userLogin.ID, userLogin.ID)
 	if err != nil {
 		return err
 	}
@@ -328,6 +519,14 @@ func (u *Delivery) Create(ctx context.Context, tx *sql.Tx) error {
 	u.Branch.Company = u.Company
 
 	for i, d := range u.DeliveryDetails {
+		d.Qty, err = d.Product.ConvertQty(ctx, tx, &d.Uom, d.UomQty)
+		if err != nil {
+			return err
+		}
+
+		u.DeliveryDetails[i].Qty = d.Qty
+		u.DeliveryDetails[i].Uom = d.Uom
+
 		err := u.storeDetail(ctx, tx, d, i)
 		if err != nil {
 			return err
@@ -335,7 +534,7 @@ func (u *Delivery) Create(ctx context.Context, tx *sql.Tx) error {
 		u.DeliveryDetails[i].Product.Get(ctx, tx)
 	}
 
-	return nil
+	return u.checkFulfillment(ctx, tx)
 }
 
 // Update Delivery
@@ -345,6 +544,14 @@ func (u *Delivery) Update(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if err := u.Voided.checkEditable(ctx, tx, "Delivery", true); err != nil {
+		return err
+	}
+
+	if err := checkClosedTransaction(ctx, tx, "deliveries", u.ID, u.Date); err != nil {
+		return err
+	}
+
 	err := u.SalesOrder.Get(ctx, tx)
 	if err != nil {
 		return err
@@ -354,6 +561,15 @@ func (u *Delivery) Update(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if u.SalesOrder.Cancelled.Valid {
+		return api.ErrBadRequest(errors.New("Sales order has been cancelled"), "")
+	}
+
+	err = checkApproval(ctx, tx, ApprovalSalesOrder, u.SalesOrder.ID, u.SalesOrder.Code)
+	if err != nil {
+		return err
+	}
+
 	const query = `
 		UPDATE deliveries 
 		SET date = ?, 
@@ -383,6 +599,14 @@ func (u *Delivery) Update(ctx context.Context, tx *sql.Tx) error {
 	}
 
 	for i, d := range u.DeliveryDetails {
+		d.Qty, err = d.Product.ConvertQty(ctx, tx, &d.Uom, d.UomQty)
+		if err != nil {
+			return err
+		}
+
+		u.DeliveryDetails[i].Qty = d.Qty
+		u.DeliveryDetails[i].Uom = d.Uom
+
 		if d.ID <= 0 {
 			err := u.storeDetail(ctx, tx, d, i)
 			if err != nil {
@@ -394,15 +618,37 @@ func (u *Delivery) Update(ctx context.Context, tx *sql.Tx) error {
 				return err
 			}
 
-			d.Code = detail.Code
-
-			err = u.updateDetail(ctx, tx, d)
-			if err != nil {
-				return err
-			}
-
 			var arrUint64 array.ArrUint64
 			existingDetails = arrUint64.Remove(existingDetails, d.ID)
+
+			if d.Product.ID != detail.Product.ID || (len(d.Code) > 0 && d.Code != detail.Code) || d.Qty != detail.Qty {
+				// the unit is replaced or its quantity is changed, release the old unit code and allocate again
+				if d.Product.ID == detail.Product.ID && len(d.Code) == 0 {
+					d.Code = detail.Code
+				}
+
+				err = u.removeDetail(ctx, tx, d.ID)
+				if err != nil {
+					return err
+				}
+
+				d.ID = 0
+				err = u.storeDetail(ctx, tx, d, i)
+				if err != nil {
+					return err
+				}
+			} else {
+				d.Code = detail.Code
+				d.Shelve = detail.Shelve
+
+				err = u.updateDetail(ctx, tx, d)
+				if err != nil {
+					return err
+				}
+
+				u.DeliveryDetails[i].Code = d.Code
+				u.DeliveryDetails[i].Shelve = d.Shelve
+			}
 		}
 
 		u.DeliveryDetails[i].Product.Get(ctx, tx)
@@ -415,7 +661,30 @@ func (u *Delivery) Update(ctx context.Context, tx *sql.Tx) error {
 		}
 	}
 
-	return nil
+	return u.checkFulfillment(ctx, tx)
+}
+
+// Void posted delivery, its delivered units are reversed back into inventories on the void date
+// and its quantity is reserved again for the sales order.
+// Delivery which units have been returned must have its delivery returns voided first.
+func (u *Delivery) Void(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Company.ID != u.Company.ID || u.Branch.ID <= 0 || userLogin.Branch.ID != u.Branch.ID {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	var returned bool
+	err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) > 0 FROM delivery_returns WHERE delivery_id = ? AND voided IS NULL`, u.ID).Scan(&returned)
+	if err != nil {
+		return err
+	}
+
+	if returned {
+		return api.ErrBadRequest(errors.New("Delivery has delivery returns which have not been voided"), "")
+	}
+
+	return u.Voided.void(ctx, tx, "Delivery", "deliveries", "DO", u.ID)
 }
 
 // GetExistingDetails return array of existing delivery_details id
@@ -452,19 +721,64 @@ func (u *Delivery) getDetail(ctx context.Context, tx *sql.Tx, e uint64) (*Delive
 	return detail, err
 }
 
+// storeDetail store detail at index i of delivery. Detail without unit code is allocated to unit codes by picking,
+// each unit code beyond the first one is appended to details of delivery as its own detail.
 func (u *Delivery) storeDetail(ctx context.Context, tx *sql.Tx, d DeliveryDetail, i int) error {
-	var err error
+	err := d.Product.CheckQty(ctx, tx, d.Qty)
+	if err != nil {
+		return err
+	}
 
-	const queryDetail = `
-		INSERT INTO delivery_details (delivery_id, product_id, qty, code, shelve_id)
-		VALUES (?, ?, ?, ?, ?)
-	`
+	if len(d.Code) > 0 {
+		err = u.checkUnit(ctx, tx, &d)
+		if err != nil {
+			return err
+		}
+
+		return u.storeUnit(ctx, tx, d, i)
+	}
 
-	d.Code, err = u.getProductCode(ctx, tx, d.Product.ID)
+	units, err := u.pickUnit(ctx, tx, d)
 	if err != nil {
 		return err
 	}
 
+	for j, unit := range units {
+		if len(units) > 1 {
+			// quantity of split detail is in base unit, unit of measure of detail may not divide it
+			unit.Uom = Uom{}
+			unit.UomQty = unit.Qty
+		}
+
+		if j > 0 {
+			u.DeliveryDetails = append(u.DeliveryDetails, unit)
+			i = len(u.DeliveryDetails) - 1
+		}
+
+		u.DeliveryDetails[i].Qty = unit.Qty
+		u.DeliveryDetails[i].Uom = unit.Uom
+		u.DeliveryDetails[i].UomQty = unit.UomQty
+
+		err = u.storeUnit(ctx, tx, unit, i)
+		if err != nil {
+			return err
+		}
+
+		if j > 0 {
+			u.DeliveryDetails[i].Product.Get(ctx, tx)
+		}
+	}
+
+	return nil
+}
+
+// storeUnit store detail at index i of delivery with its unit code and post it out of inventories
+func (u *Delivery) storeUnit(ctx context.Context, tx *sql.Tx, d DeliveryDetail, i int) error {
+	const queryDetail = `
+		INSERT INTO delivery_details (delivery_id, product_id, qty, uom_id, uom_qty, code, shelve_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
 	stmt, err := tx.PrepareContext(ctx, queryDetail)
 	if err != nil {
 		return err
@@ -472,7 +786,7 @@ func (u *Delivery) storeDetail(ctx context.Context, tx *sql.Tx, d DeliveryDetail
 
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Qty, d.Code, d.Shelve.ID)
+	res, err := stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Qty, d.Uom.nullID(), d.UomQty, d.Code, d.Shelve.ID)
 	if err != nil {
 		return err
 	}
@@ -480,6 +794,7 @@ func (u *Delivery) storeDetail(ctx context.Context, tx *sql.Tx, d DeliveryDetail
 	detailID, err := res.LastInsertId()
 	u.DeliveryDetails[i].ID = uint64(detailID)
 	u.DeliveryDetails[i].Code = d.Code
+	u.DeliveryDetails[i].Shelve = d.Shelve
 
 	inventory := new(Inventory)
 	inventory.CompanyID = ctx.Value(api.Ctx("auth")).(User).Company.ID
@@ -492,8 +807,16 @@ func (u *Delivery) storeDetail(ctx context.Context, tx *sql.Tx, d DeliveryDetail
 	inventory.TransactionDate = u.Date
 	inventory.Type = "DO"
 	inventory.InOut = false
-	inventory.Qty = 1
-	return inventory.Create(ctx, tx)
+	inventory.Qty = d.Qty
+	err = inventory.Create(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE delivery_details SET cost = ?, lot = ? WHERE id = ?`, inventory.Cost, inventory.Lot, detailID)
+	u.DeliveryDetails[i].Cost = inventory.Cost
+	u.DeliveryDetails[i].Lot = inventory.Lot
+	return err
 }
 
 func (u *Delivery) updateDetail(ctx context.Context, tx *sql.Tx, d DeliveryDetail) error {
@@ -501,17 +824,25 @@ func (u *Delivery) updateDetail(ctx context.Context, tx *sql.Tx, d DeliveryDetai
 		UPDATE delivery_details 
 		SET product_id = ?, 
 			code = ?,
-			shelve_id = ?
+			qty = ?,
+			shelve_id = ?,
+			uom_id = ?,
+			uom_qty = ?
 		WHERE id = ?
 		AND delivery_id = ?
 	`
 
+	err := d.Product.CheckQty(ctx, tx, d.Qty)
+	if err != nil {
+		return err
+	}
+
 	inventory := new(Inventory)
 	inventory.ProductID = d.Product.ID
 	inventory.ProductCode = d.Code
 	inventory.TransactionID = u.ID
 	inventory.Type = "DO"
-	err := inventory.GetByComposit(ctx, tx)
+	err = inventory.GetByComposit(ctx, tx)
 	if err != nil {
 		return err
 	}
@@ -523,7 +854,7 @@ func (u *Delivery) updateDetail(ctx context.Context, tx *sql.Tx, d DeliveryDetai
 
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(ctx, d.Product.ID, d.Code, d.Shelve.ID, d.ID, u.ID)
+	_, err = stmt.ExecContext(ctx, d.Product.ID, d.Code, d.Qty, d.Shelve.ID, d.Uom.nullID(), d.UomQty, d.ID, u.ID)
 	if err != nil {
 		return err
 	}
@@ -531,8 +862,29 @@ func (u *Delivery) updateDetail(ctx context.Context, tx *sql.Tx, d DeliveryDetai
 	inventory.ProductID = d.Product.ID
 	inventory.ProductCode = d.Code
 	inventory.ShelveID = d.Shelve.ID
+	inventory.Code = u.Code
+	inventory.TransactionDate = u.Date
+	inventory.Qty = d.Qty
+
+	err = inventory.Update(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	// the raised quantity must not be more than quantity on hand of unit code
+	position := new(Inventory)
+	position.ProductID = d.Product.ID
+	position.ProductCode = d.Code
+	qty, err := position.GetLastPosition(ctx, tx)
+	if err != nil {
+		return err
+	}
 
-	return inventory.Update(ctx, tx)
+	if qty < 0 {
+		return api.ErrBadRequest(fmt.Errorf("Quantity of unit code %s is more than its quantity on hand", d.Code), "")
+	}
+
+	return nil
 }
 
 func (u *Delivery) removeDetail(ctx context.Context, tx *sql.Tx, e uint64) error {
@@ -564,33 +916,124 @@ func (u *Delivery) removeDetail(ctx context.Context, tx *sql.Tx, e uint64) error
 	return inventory.DeleteByComposit(ctx, tx)
 }
 
-func (u *Delivery) getProductCode(ctx context.Context, tx *sql.Tx, productID uint64) (string, error) {
-	var code string
-	var err error
-	var codeInt int
+// checkUnit validate the chosen unit code has enough quantity and not expired in user login branch and fill its shelve
+func (u *Delivery) checkUnit(ctx context.Context, tx *sql.Tx, d *DeliveryDetail) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	position := new(Inventory)
+	position.ProductID = d.Product.ID
+	position.ProductCode = d.Code
+	qty, err := position.GetLastPosition(ctx, tx)
+	if err == sql.ErrNoRows || (err == nil && (qty <= 0 || position.BranchID != userLogin.Branch.ID)) {
+		return api.ErrBadRequest(fmt.Errorf("Unit code %s is not available", d.Code), "")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if qty < int(d.Qty) {
+		return api.ErrBadRequest(fmt.Errorf("Unit code %s only has %d on hand", d.Code, qty), "")
+	}
+
+	if d.Shelve.ID > 0 && d.Shelve.ID != position.ShelveID {
+		return api.ErrBadRequest(fmt.Errorf("Unit code %s is not on shelve", d.Code), "")
+	}
+
+	if !u.AllowExpired {
+		var expired bool
+		err = tx.QueryRowContext(ctx,
+			`SELECT IFNULL(DATE(expired_date) < ?, FALSE) FROM good_receiving_details WHERE product_id = ? AND code = ?`,
+			u.Date, d.Product.ID, d.Code).Scan(&expired)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
 
-	prefix := time.Now().Format("200601")
+		if expired {
+			return api.ErrBadRequest(fmt.Errorf("Unit code %s is expired", d.Code), "")
+		}
+	}
+
+	d.Shelve.ID = position.ShelveID
+
+	return nil
+}
+
+// pickUnit allocate quantity of detail to available unit codes of product in user login branch by FIFO or FEFO.
+// Unit codes are taken in picking order until the quantity is fulfilled, the last unit code may be taken partially.
+// Return a detail of every allocated unit code with its quantity and shelve.
+// The shelve of detail, when provided, limits the picking to that shelve.
+// Expired unit codes are skipped unless the delivery allows expired units.
+func (u *Delivery) pickUnit(ctx context.Context, tx *sql.Tx, d DeliveryDetail) ([]DeliveryDetail, error) {
+	var units []DeliveryDetail
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
 
 	query := `
-		SELECT delivery_details.code 
-		FROM delivery_details 
-		JOIN deliveries ON delivery_details.delivery_id = deliveries.id
-		WHERE deliveries.company_id = ? AND delivery_details.code LIKE ? AND delivery_details.product_id = ? 
-		ORDER BY delivery_details.code DESC LIMIT 1
+		SELECT inventories.product_code, inventories.shelve_id, units.qty
+		FROM inventories
+		JOIN (
+			SELECT MAX(id) id, SUM(IF(in_out, qty, -qty)) qty
+			FROM inventories
+			WHERE company_id = ? AND product_id = ?
+			GROUP BY product_code
+		) units ON inventories.id = units.id
+		LEFT JOIN good_receiving_details ON inventories.product_id = good_receiving_details.product_id AND inventories.product_code = good_receiving_details.code
+		LEFT JOIN good_receivings ON good_receiving_details.good_receiving_id = good_receivings.id
+		WHERE units.qty > 0 AND inventories.branch_id = ?
 	`
-	err = tx.QueryRowContext(ctx, query, ctx.Value(api.Ctx("auth")).(User).Company.ID, prefix+"%", productID).Scan(&code)
+	params := []interface{}{userLogin.Company.ID, d.Product.ID, userLogin.Branch.ID}
 
-	if err != nil && err != sql.ErrNoRows {
-		return code, err
+	if d.Shelve.ID > 0 {
+		query += " AND inventories.shelve_id = ?"
+		params = append(params, d.Shelve.ID)
 	}
 
-	if len(code) > 0 {
-		runes := []rune(code)
-		codeInt, err = strconv.Atoi(string(runes[6:]))
+	if !u.AllowExpired {
+		query += " AND (good_receiving_details.expired_date IS NULL OR DATE(good_receiving_details.expired_date) >= ?)"
+		params = append(params, u.Date)
+	}
+
+	switch u.Picking {
+	case "", DeliveryPickingFIFO:
+		query += " ORDER BY IFNULL(good_receivings.date, inventories.transaction_date), inventories.product_code"
+	case DeliveryPickingFEFO:
+		query += " ORDER BY good_receiving_details.expired_date IS NULL, good_receiving_details.expired_date, IFNULL(good_receivings.date, inventories.transaction_date), inventories.product_code"
+	default:
+		return units, api.ErrBadRequest(errors.New("Picking must be FIFO or FEFO"), "")
+	}
+
+	rows, err := tx.QueryContext(ctx, query, params...)
+	if err != nil {
+		return units, err
+	}
+
+	defer rows.Close()
+
+	remaining := d.Qty
+	for remaining > 0 && rows.Next() {
+		var qty uint
+		unit := d
+		err = rows.Scan(&unit.Code, &unit.Shelve.ID, &qty)
 		if err != nil {
-			return code, err
+			return units, err
 		}
+
+		if qty > remaining {
+			qty = remaining
+		}
+
+		unit.Qty = qty
+		remaining -= qty
+		units = append(units, unit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return units, err
+	}
+
+	if remaining > 0 {
+		return units, api.ErrBadRequest(fmt.Errorf("Insufficient stock of product %d", d.Product.ID), "")
 	}
 
-	return prefix + fmt.Sprintf("%014d", codeInt+1), nil
+	return units, nil
 }
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -22,6 +23,10 @@ type DeliveryReturn struct {
 	Company               Company
 	Branch                Branch
 	DeliveryReturnDetails []DeliveryReturnDetail
+	Posted                bool
+	Approvals             []Approval
+	CorrectionOf          Correction
+	Voided                Void
 }
 
 // DeliveryReturnDetail struct
@@ -29,6 +34,7 @@ type DeliveryReturnDetail struct {
 	ID      uint64
 	Product Product
 	Code    string
+	Lot     string
 	Qty     uint
 }
 
@@ -42,6 +48,8 @@ func (u *DeliveryReturn) List(ctx context.Context, tx *sql.Tx) ([]DeliveryReturn
 		delivery_returns.code, 
 		delivery_returns.date,
 		delivery_returns.remark,
+		delivery_returns.posted,
+		delivery_returns.voided,
 		deliveries.id,
 		deliveries.code,
 		deliveries.date,
@@ -98,6 +106,8 @@ func (u *DeliveryReturn) List(ctx context.Context, tx *sql.Tx) ([]DeliveryReturn
 			&deliveryReturn.Code,
 			&deliveryReturn.Date,
 			&deliveryReturn.Remark,
+			&deliveryReturn.Posted,
+			&deliveryReturn.Voided.Date,
 			&deliveryReturn.Delivery.ID,
 			&deliveryReturn.Delivery.Code,
 			&deliveryReturn.Delivery.Date,
@@ -133,6 +143,8 @@ func (u *DeliveryReturn) Get(ctx context.Context, tx *sql.Tx) error {
 		delivery_returns.code, 
 		delivery_returns.date,
 		delivery_returns.remark,
+		delivery_returns.posted,
+		delivery_returns.voided,
 		deliveries.id,
 		deliveries.code,
 		deliveries.date,
@@ -148,6 +160,7 @@ func (u *DeliveryReturn) Get(ctx context.Context, tx *sql.Tx) error {
 		branches.type,
 		JSON_ARRAYAGG(delivery_return_details.id),
 		JSON_ARRAYAGG(delivery_return_details.code),
+		JSON_ARRAYAGG(delivery_return_details.lot),
 		JSON_ARRAYAGG(delivery_return_details.qty),
 		JSON_ARRAYAGG(products.id),
 		JSON_ARRAYAGG(products.code),
@@ -184,12 +197,14 @@ func (u *DeliveryReturn) Get(ctx context.Context, tx *sql.Tx) error {
 		params = append(params, userLogin.Branch.ID)
 	}
 
-	var detailID, detailCode, detailQty, productID, productCode, productName, productPrice string
+	var detailID, detailCode, detailLot, detailQty, productID, productCode, productName, productPrice string
 	err := tx.QueryRowContext(ctx, query+" GROUP BY delivery_returns.id", params...).Scan(
 		&u.ID,
 		&u.Code,
 		&u.Date,
 		&u.Remark,
+		&u.Posted,
+		&u.Voided.Date,
 		&u.Delivery.ID,
 		&u.Delivery.Code,
 		&u.Delivery.Date,
@@ -205,6 +220,7 @@ func (u *DeliveryReturn) Get(ctx context.Context, tx *sql.Tx) error {
 		&u.Branch.Type,
 		&detailID,
 		&detailCode,
+		&detailLot,
 		&detailQty,
 		&productID,
 		&productCode,
@@ -229,6 +245,12 @@ func (u *DeliveryReturn) Get(ctx context.Context, tx *sql.Tx) error {
 			return err
 		}
 
+		var detailLots []string
+		err = json.Unmarshal([]byte(detailLot), &detailLots)
+		if err != nil {
+			return err
+		}
+
 		var detailQtys []uint
 		err = json.Unmarshal([]byte(detailQty), &detailQtys)
 		if err != nil {
@@ -263,6 +285,7 @@ func (u *DeliveryReturn) Get(ctx context.Context, tx *sql.Tx) error {
 			u.DeliveryReturnDetails = append(u.DeliveryReturnDetails, DeliveryReturnDetail{
 				ID:   uint64(v),
 				Code: detailCodes[i],
+				Lot:  detailLots[i],
 				Qty:  detailQtys[i],
 				Product: Product{
 					ID:        productIDs[i],
@@ -278,7 +301,12 @@ func (u *DeliveryReturn) Get(ctx context.Context, tx *sql.Tx) error {
 	u.Delivery.Company = u.Company
 	u.Branch.Company = u.Company
 
-	return nil
+	u.Approvals, err = ListApprovals(ctx, tx, ApprovalDeliveryReturn, u.ID)
+	if err != nil {
+		return err
+	}
+
+	return getVoid(ctx, tx, "delivery_returns", u.ID, &u.Voided, &u.CorrectionOf)
 }
 
 // Create new delivery return
@@ -288,9 +316,18 @@ func (u *DeliveryReturn) Create(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if err := checkClosedPeriod(ctx, tx, u.Date); err != nil {
+		return err
+	}
+
+	err := u.CorrectionOf.check(ctx, tx, "delivery return", "delivery_returns")
+	if err != nil {
+		return err
+	}
+
 	const query = `
-		INSERT INTO delivery_returns (code, date, remark, delivery_id, company_id, branch_id, created_by, updated_by, created, updated)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+		INSERT INTO delivery_returns (code, date, remark, correction_of, delivery_id, company_id, branch_id, created_by, updated_by, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
 	`
 	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
@@ -304,7 +341,7 @@ func (u *DeliveryReturn) Create(ctx context.Context, tx *sql.Tx) error {
 		return err
 	}
 
-	res, err := stmt.ExecContext(ctx, u.Code, u.Date, u.Remark, u.Delivery.ID, userLogin.Company.ID, userLogin.Branch.ID, userLogin.ID, userLogin.ID)
+	res, err := stmt.ExecContext(ctx, u.Code, u.Date, u.Remark, u.CorrectionOf.nullID(), u.Delivery.ID, userLogin.Company.ID, userLogin.Branch.ID, userLogin.ID, userLogin.ID)
 	if err != nil {
 		return err
 	}
@@ -320,6 +357,19 @@ func (u *DeliveryReturn) Create(ctx context.Context, tx *sql.Tx) error {
 	u.Branch.Company = u.Company
 	u.Delivery.Get(ctx, tx)
 
+	approval, err := u.submitApproval(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	u.Posted = approval.ID == 0
+	if !u.Posted {
+		_, err = tx.ExecContext(ctx, `UPDATE delivery_returns SET posted = 0 WHERE id = ?`, u.ID)
+		if err != nil {
+			return err
+		}
+	}
+
 	for i, d := range u.DeliveryReturnDetails {
 		err = u.storeDetail(ctx, tx, d, u.Delivery.ID, i)
 		if err != nil {
@@ -339,6 +389,14 @@ func (u *DeliveryReturn) Update(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if err := u.Voided.checkEditable(ctx, tx, "Delivery return", u.Posted); err != nil {
+		return err
+	}
+
+	if err := checkClosedTransaction(ctx, tx, "delivery_returns", u.ID, u.Date); err != nil {
+		return err
+	}
+
 	const query = `
 		UPDATE delivery_returns 
 		SET date = ?, 
@@ -362,6 +420,20 @@ func (u *DeliveryReturn) Update(ctx context.Context, tx *sql.Tx) error {
 		return err
 	}
 
+	// changed document is checked against approval rules again, posted document which now requires approval
+	// is taken out of inventories and waits for approval before it is posted again
+	approval, err := u.submitApproval(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if u.Posted && approval.ID > 0 {
+		err = u.unpost(ctx, tx)
+		if err != nil {
+			return err
+		}
+	}
+
 	existingDetails, err := u.GetExistingDetails(ctx, tx)
 	if err != nil {
 		return err
@@ -395,6 +467,98 @@ func (u *DeliveryReturn) Update(ctx context.Context, tx *sql.Tx) error {
 	return nil
 }
 
+// Post delivery return which has been approved into inventories
+func (u *DeliveryReturn) Post(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Company.ID != u.Company.ID || u.Branch.ID <= 0 || userLogin.Branch.ID != u.Branch.ID {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	if u.Voided.Date.Valid {
+		return api.ErrBadRequest(errors.New("Delivery return has been voided"), "")
+	}
+
+	if u.Posted {
+		return api.ErrBadRequest(errors.New("Delivery return has been posted"), "")
+	}
+
+	err := checkClosedPeriod(ctx, tx, u.Date)
+	if err != nil {
+		return err
+	}
+
+	err = checkApproval(ctx, tx, ApprovalDeliveryReturn, u.ID, u.Code)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range u.DeliveryReturnDetails {
+		var shelveID uint64
+		err = tx.QueryRowContext(ctx, `SELECT shelve_id FROM delivery_details WHERE delivery_id = ? AND product_id = ? AND code = ? LIMIT 1`,
+			u.Delivery.ID, d.Product.ID, d.Code).Scan(&shelveID)
+		if err != nil {
+			return err
+		}
+
+		err = u.postDetail(ctx, tx, d, shelveID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE delivery_returns SET posted = 1, updated_by = ?, updated = NOW() WHERE id = ? AND company_id = ?`,
+		userLogin.ID, u.ID, userLogin.Company.ID)
+	if err != nil {
+		return err
+	}
+
+	u.Posted = true
+	return nil
+}
+
+// Void delivery return, its returned units are reversed in inventories on the void date when it has been posted,
+// while approvals of delivery return which has not been posted are superseded
+func (u *DeliveryReturn) Void(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Company.ID != u.Company.ID || u.Branch.ID <= 0 || userLogin.Branch.ID != u.Branch.ID {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	err := u.Voided.void(ctx, tx, "Delivery return", "delivery_returns", "DR", u.ID)
+	if err != nil {
+		return err
+	}
+
+	if u.Posted {
+		return nil
+	}
+
+	err = supersedeApprovals(ctx, tx, ApprovalDeliveryReturn, u.ID)
+	if err != nil {
+		return err
+	}
+
+	u.Approvals, err = ListApprovals(ctx, tx, ApprovalDeliveryReturn, u.ID)
+	return err
+}
+
+// submitApproval request approval of delivery return by its total quantity, delivery return requiring approval
+// is not posted into inventories until it is approved and posted
+func (u *DeliveryReturn) submitApproval(ctx context.Context, tx *sql.Tx) (Approval, error) {
+	var qty uint
+	for _, d := range u.DeliveryReturnDetails {
+		qty += d.Qty
+	}
+
+	approval, err := requestApproval(ctx, tx, ApprovalDeliveryReturn, u.ID, u.Code, float64(qty), 0)
+	if err != nil {
+		return approval, err
+	}
+
+	u.Approvals, err = ListApprovals(ctx, tx, ApprovalDeliveryReturn, u.ID)
+	return approval, err
+}
+
 // GetExistingDetails return array of existing Delivery_return_details id
 func (u *DeliveryReturn) GetExistingDetails(ctx context.Context, tx *sql.Tx) ([]uint64, error) {
 	var list []uint64
@@ -432,25 +596,40 @@ func (u *DeliveryReturn) getDetail(ctx context.Context, tx *sql.Tx, e uint64) (*
 func (u *DeliveryReturn) storeDetail(ctx context.Context, tx *sql.Tx, d DeliveryReturnDetail, deliveryID uint64, i int) error {
 	// check :
 	// 1. valid detail Delivery return is only product in Delivery detail list.
+	// 2. quantity does not exceed the delivered quantity which has not been returned.
 	userLogin := ctx.Value(api.Ctx("auth")).(User)
-	var code string
+	err := d.Product.CheckQty(ctx, tx, d.Qty)
+	if err != nil {
+		return err
+	}
+
 	var shelveID uint64
-	err := tx.QueryRowContext(ctx, `
-		SELECT delivery_details.code, delivery_details.shelve_id 
+	var returnable int
+	err = tx.QueryRowContext(ctx, `
+		SELECT delivery_details.shelve_id, delivery_details.lot, delivery_details.qty - IFNULL(SUM(delivery_return_details.qty), 0)
 		FROM delivery_details
-		JOIN deliveries ON delivery_details.delivery_id = deliveries.id AND deliveries.company_id = ? AND deliveries.branch_id = ?
-		LEFT JOIN delivery_returns ON delivery_returns.company_id = deliveries.company_id AND delivery_returns.branch_id = deliveries.branch_id AND delivery_returns.delivery_id = deliveries.id
+		JOIN deliveries ON delivery_details.delivery_id = deliveries.id AND deliveries.company_id = ? AND deliveries.branch_id = ? AND deliveries.voided IS NULL
+		LEFT JOIN delivery_returns ON delivery_returns.company_id = deliveries.company_id AND delivery_returns.branch_id = deliveries.branch_id AND delivery_returns.delivery_id = deliveries.id AND delivery_returns.voided IS NULL
 		LEFT JOIN delivery_return_details ON delivery_returns.id = delivery_return_details.delivery_return_id AND delivery_return_details.product_id = delivery_details.product_id AND delivery_return_details.code = delivery_details.code
-		WHERE delivery_details.product_id = ? AND delivery_details.code = ? AND delivery_return_details.code is null 
-	`, userLogin.Company.ID, userLogin.Branch.ID, d.Product.ID, d.Code).Scan(&code, &shelveID)
+		WHERE delivery_details.delivery_id = ? AND delivery_details.product_id = ? AND delivery_details.code = ?
+		GROUP BY delivery_details.id
+	`, userLogin.Company.ID, userLogin.Branch.ID, deliveryID, d.Product.ID, d.Code).Scan(&shelveID, &d.Lot, &returnable)
+
+	if err == sql.ErrNoRows {
+		return api.ErrBadRequest(fmt.Errorf("Unit code %s is not in the delivery", d.Code), "")
+	}
 
 	if err != nil {
 		return err
 	}
 
+	if returnable < int(d.Qty) {
+		return api.ErrBadRequest(fmt.Errorf("Unit code %s only has %d returnable", d.Code, returnable), "")
+	}
+
 	const queryDetail = `
-		INSERT INTO delivery_return_details (delivery_return_id, product_id, code, qty)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO delivery_return_details (delivery_return_id, product_id, code, lot, qty)
+		VALUES (?, ?, ?, ?, ?)
 	`
 	stmt, err := tx.PrepareContext(ctx, queryDetail)
 	if err != nil {
@@ -459,7 +638,7 @@ func (u *DeliveryReturn) storeDetail(ctx context.Context, tx *sql.Tx, d Delivery
 
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Code, d.Qty)
+	res, err := stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Code, d.Lot, d.Qty)
 	if err != nil {
 		return err
 	}
@@ -470,20 +649,86 @@ func (u *DeliveryReturn) storeDetail(ctx context.Context, tx *sql.Tx, d Delivery
 	}
 
 	u.DeliveryReturnDetails[i].ID = uint64(detailID)
+	u.DeliveryReturnDetails[i].Lot = d.Lot
+
+	if !u.Posted {
+		return nil
+	}
 
+	return u.postDetail(ctx, tx, d, shelveID)
+}
+
+// unpost take posted delivery return out of inventories, it is posted again after its approval is approved
+func (u *DeliveryReturn) unpost(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	existingDetails, err := u.GetExistingDetails(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range existingDetails {
+		d, err := u.getDetail(ctx, tx, e)
+		if err != nil {
+			return err
+		}
+
+		inventory := Inventory{
+			CompanyID:     userLogin.Company.ID,
+			BranchID:      userLogin.Branch.ID,
+			ProductID:     d.Product.ID,
+			ProductCode:   d.Code,
+			TransactionID: u.ID,
+			Type:          "DR",
+		}
+		err = inventory.DeleteByComposit(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		// returned unit code must not have been moved out after it is returned
+		position := Inventory{ProductID: d.Product.ID, ProductCode: d.Code}
+		qty, err := position.GetLastPosition(ctx, tx)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		if err == nil && qty < 0 {
+			return api.ErrBadRequest(fmt.Errorf("Unit code %s has been moved out, delivery return can not be taken out of inventories", d.Code), "")
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE delivery_returns SET posted = 0 WHERE id = ?`, u.ID)
+	if err != nil {
+		return err
+	}
+
+	u.Posted = false
+	return nil
+}
+
+// postDetail post returned unit code into inventories
+func (u *DeliveryReturn) postDetail(ctx context.Context, tx *sql.Tx, d DeliveryReturnDetail, shelveID uint64) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
 	inventory := new(Inventory)
 	inventory.CompanyID = userLogin.Company.ID
 	inventory.BranchID = userLogin.Branch.ID
 	inventory.ProductID = d.Product.ID
 	inventory.ProductCode = d.Code
+	inventory.Lot = d.Lot
 	inventory.TransactionID = u.ID
 	inventory.Code = u.Code
 	inventory.TransactionDate = u.Date
 	inventory.Type = "DR"
 	inventory.InOut = true
-	inventory.Qty = 1
+	inventory.Qty = d.Qty
 	inventory.ShelveID = shelveID
-	return inventory.Create(ctx, tx)
+	err := inventory.Create(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	shelve := Shelve{ID: shelveID}
+	return shelve.CheckCapacity(ctx, tx)
 }
 
 /*func (u *DeliveryReturn) updateDetail(ctx context.Context, tx *sql.Tx, d DeliveryReturnDetail) error {
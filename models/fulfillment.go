@@ -0,0 +1,337 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// Fulfillment status of sales order and its lines
+const (
+	FulfillmentOpen      = "open"
+	FulfillmentPartial   = "partially_delivered"
+	FulfillmentDelivered = "delivered"
+	FulfillmentClosed    = "closed"
+)
+
+// Fulfillment : struct of ordered, returned and delivered quantity of product in sales order
+type Fulfillment struct {
+	Ordered   int
+	Returned  int
+	Delivered int
+	Backorder int
+}
+
+// Backorder : struct of outstanding quantity of product in open sales order
+type Backorder struct {
+	SalesOrder SalesOrder
+	Fulfillment
+}
+
+// ProductBackorder : struct of outstanding sales orders of product
+type ProductBackorder struct {
+	Product     Product
+	Outstanding int
+	Backorders  []Backorder
+}
+
+// CustomerBackorder : struct of outstanding products of customer
+type CustomerBackorder struct {
+	Customer Customer
+	Products []ProductBackorder
+}
+
+type fulfillmentKey struct {
+	SalesOrderID uint64
+	ProductID    uint64
+}
+
+const qFulfillment = `
+	SELECT ordered.sales_order_id, ordered.product_id, ordered.qty, IFNULL(returned.qty, 0), IFNULL(delivered.qty, 0), ordered.backorder_qty
+` + qSalesOrderQty + `
+	WHERE sales_orders.company_id = ?
+`
+
+// Outstanding return quantity which has not been delivered, ordered minus returned minus delivered
+func (u *Fulfillment) Outstanding() int {
+	outstanding := u.Ordered - u.Returned - u.Delivered
+	if outstanding < 0 {
+		return 0
+	}
+
+	return outstanding
+}
+
+// fulfillmentStatus return status of ordered quantity, net of sales order return, against delivered quantity
+func fulfillmentStatus(ordered, delivered int) string {
+	switch {
+	case ordered <= 0:
+		return FulfillmentClosed
+	case delivered >= ordered:
+		return FulfillmentDelivered
+	case delivered > 0:
+		return FulfillmentPartial
+	default:
+		return FulfillmentOpen
+	}
+}
+
+// headerStatus return status of sales order from status of its lines
+func headerStatus(cancelled bool, statuses []string) string {
+	if cancelled {
+		return FulfillmentClosed
+	}
+
+	var open, delivered, closed int
+	for _, s := range statuses {
+		switch s {
+		case FulfillmentOpen:
+			open++
+		case FulfillmentDelivered:
+			delivered++
+		case FulfillmentClosed:
+			closed++
+		}
+	}
+
+	switch {
+	case closed == len(statuses):
+		return FulfillmentClosed
+	case delivered+closed == len(statuses):
+		return FulfillmentDelivered
+	case open+closed == len(statuses):
+		return FulfillmentOpen
+	default:
+		return FulfillmentPartial
+	}
+}
+
+// listFulfillment return fulfillment of sales orders grouped by sales order and product
+func listFulfillment(ctx context.Context, tx *sql.Tx, where string, params ...interface{}) (map[fulfillmentKey]Fulfillment, error) {
+	list := make(map[fulfillmentKey]Fulfillment)
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	params = append([]interface{}{userLogin.Company.ID}, params...)
+
+	rows, err := tx.QueryContext(ctx, qFulfillment+where, params...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var key fulfillmentKey
+		var f Fulfillment
+		err = rows.Scan(&key.SalesOrderID, &key.ProductID, &f.Ordered, &f.Returned, &f.Delivered, &f.Backorder)
+		if err != nil {
+			return list, err
+		}
+
+		list[key] = f
+	}
+
+	return list, rows.Err()
+}
+
+// setFulfillment allocate returned quantity from the last lines and delivered quantity from the first lines of every product,
+// then set status of lines and sales order
+func (u *SalesOrder) setFulfillment(ctx context.Context, tx *sql.Tx) error {
+	fulfillments, err := listFulfillment(ctx, tx, " AND sales_orders.id = ?", u.ID)
+	if err != nil {
+		return err
+	}
+
+	returned := make(map[uint64]int)
+	for i := len(u.SalesOrderDetails) - 1; i >= 0; i-- {
+		d := &u.SalesOrderDetails[i]
+		if _, ok := returned[d.Product.ID]; !ok {
+			returned[d.Product.ID] = fulfillments[fulfillmentKey{u.ID, d.Product.ID}].Returned
+		}
+
+		qty := returned[d.Product.ID]
+		if qty > int(d.Qty) {
+			qty = int(d.Qty)
+		}
+
+		d.ReturnedQty = uint(qty)
+		returned[d.Product.ID] -= qty
+	}
+
+	delivered := make(map[uint64]int)
+	var statuses []string
+	for i := range u.SalesOrderDetails {
+		d := &u.SalesOrderDetails[i]
+		if _, ok := delivered[d.Product.ID]; !ok {
+			delivered[d.Product.ID] = fulfillments[fulfillmentKey{u.ID, d.Product.ID}].Delivered
+		}
+
+		qty := delivered[d.Product.ID]
+		if qty > int(d.Qty-d.ReturnedQty) {
+			qty = int(d.Qty - d.ReturnedQty)
+		}
+
+		d.DeliveredQty = uint(qty)
+		delivered[d.Product.ID] -= qty
+
+		d.Status = fulfillmentStatus(int(d.Qty-d.ReturnedQty), qty)
+		if u.Cancelled.Valid {
+			d.Status = FulfillmentClosed
+		}
+
+		statuses = append(statuses, d.Status)
+	}
+
+	u.Status = headerStatus(u.Cancelled.Valid, statuses)
+
+	return nil
+}
+
+// checkFulfillment reject delivery of product which is not ordered or exceeds ordered minus returned minus already delivered quantity
+func (u *Delivery) checkFulfillment(ctx context.Context, tx *sql.Tx) error {
+	fulfillments, err := listFulfillment(ctx, tx, " AND sales_orders.id = ?", u.SalesOrder.ID)
+	if err != nil {
+		return err
+	}
+
+	qtys := make(map[uint64]int)
+	for _, d := range u.DeliveryDetails {
+		qtys[d.Product.ID] += int(d.Qty)
+	}
+
+	for _, d := range u.DeliveryDetails {
+		f, ok := fulfillments[fulfillmentKey{u.SalesOrder.ID, d.Product.ID}]
+		if !ok {
+			return api.ErrBadRequest(fmt.Errorf("Product %s is not ordered in sales order %s", d.Product.Code, u.SalesOrder.Code), "")
+		}
+
+		if f.Delivered > f.Ordered-f.Returned {
+			outstanding := f.Ordered - f.Returned - (f.Delivered - qtys[d.Product.ID])
+			if outstanding < 0 {
+				outstanding = 0
+			}
+
+			return api.ErrBadRequest(fmt.Errorf("Quantity of product %s exceeds outstanding quantity %d", d.Product.Code, outstanding), "")
+		}
+	}
+
+	return nil
+}
+
+// ListBackorders list outstanding quantity of open sales orders grouped by customer and product
+func (u *SalesOrder) ListBackorders(ctx context.Context, tx *sql.Tx) ([]CustomerBackorder, error) {
+	var list []CustomerBackorder
+
+	query := `
+	SELECT ordered.sales_order_id, ordered.product_id, ordered.qty, IFNULL(returned.qty, 0), IFNULL(delivered.qty, 0), ordered.backorder_qty,
+		sales_orders.code,
+		sales_orders.date,
+		customers.id,
+		customers.name,
+		customers.email,
+		customers.address,
+		customers.hp,
+		branches.id,
+		branches.code,
+		branches.name,
+		branches.address,
+		branches.type,
+		products.code,
+		products.name,
+		products.sale_price
+	` + qSalesOrderQty + `
+	JOIN customers ON sales_orders.customer_id = customers.id
+	JOIN branches ON sales_orders.branch_id = branches.id
+	JOIN products ON ordered.product_id = products.id
+	WHERE sales_orders.company_id = ?
+	AND sales_orders.cancelled IS NULL
+	AND ordered.qty - IFNULL(returned.qty, 0) - IFNULL(delivered.qty, 0) > 0
+	`
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	params := []interface{}{userLogin.Company.ID}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=?")
+			params = append(params, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND branches.id=?"
+		params = append(params, userLogin.Branch.ID)
+	}
+
+	query += " ORDER BY customers.name, customers.id, products.code, products.id, sales_orders.date, sales_orders.id"
+
+	rows, err := tx.QueryContext(ctx, query, params...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var b Backorder
+		var product Product
+		var date time.Time
+		err = rows.Scan(
+			&b.SalesOrder.ID,
+			&product.ID,
+			&b.Ordered,
+			&b.Returned,
+			&b.Delivered,
+			&b.Backorder,
+			&b.SalesOrder.Code,
+			&date,
+			&b.SalesOrder.Customer.ID,
+			&b.SalesOrder.Customer.Name,
+			&b.SalesOrder.Customer.Email,
+			&b.SalesOrder.Customer.Address,
+			&b.SalesOrder.Customer.Hp,
+			&b.SalesOrder.Branch.ID,
+			&b.SalesOrder.Branch.Code,
+			&b.SalesOrder.Branch.Name,
+			&b.SalesOrder.Branch.Address,
+			&b.SalesOrder.Branch.Type,
+			&product.Code,
+			&product.Name,
+			&product.SalePrice,
+		)
+		if err != nil {
+			return list, err
+		}
+
+		b.SalesOrder.Date = date
+		b.SalesOrder.Company = userLogin.Company
+		b.SalesOrder.Branch.Company = userLogin.Company
+		b.SalesOrder.Customer.Company = userLogin.Company
+		product.Company = userLogin.Company
+
+		if len(list) == 0 || list[len(list)-1].Customer.ID != b.SalesOrder.Customer.ID {
+			list = append(list, CustomerBackorder{Customer: b.SalesOrder.Customer})
+		}
+
+		customer := &list[len(list)-1]
+		if len(customer.Products) == 0 || customer.Products[len(customer.Products)-1].Product.ID != product.ID {
+			customer.Products = append(customer.Products, ProductBackorder{Product: product})
+		}
+
+		p := &customer.Products[len(customer.Products)-1]
+		p.Outstanding += b.Outstanding()
+		p.Backorders = append(p.Backorders, b)
+	}
+
+	return list, rows.Err()
+}
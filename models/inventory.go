@@ -17,24 +17,49 @@ type Inventory struct {
 	ShelveID        uint64
 	ProductID       uint64
 	ProductCode     string
+	Lot             string
 	TransactionID   uint64
 	Code            string
 	TransactionDate time.Time
 	Type            string
 	InOut           bool
 	Qty             uint
+	Cost            float64
 	Created         time.Time
 	Updated         time.Time
 }
 
-// Create new inventory
+// Create new inventory, the cost of unit is valued by valuation method of company
+// and the lot of unit is kept from its receiving
 func (u *Inventory) Create(ctx context.Context, tx *sql.Tx) error {
 	var err error
 	userLogin := ctx.Value(api.Ctx("auth")).(User)
 
+	// receiving cost is kept apart from the valued cost, so moving average can be recalculated from the ledger
+	var receiptCost interface{}
+	if u.InOut && u.Cost > 0 {
+		receiptCost = u.Cost
+	}
+
+	err = u.valuate(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if len(u.Lot) == 0 {
+		err = tx.QueryRowContext(ctx, `
+			SELECT lot FROM inventories 
+			WHERE company_id = ? AND product_id = ? AND product_code = ? 
+			ORDER BY id DESC LIMIT 1`,
+			userLogin.Company.ID, u.ProductID, u.ProductCode).Scan(&u.Lot)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+
 	const queryDetail = `
-		INSERT INTO inventories (company_id, branch_id, product_id, product_code, transaction_id, code, transaction_date, type, in_out, qty, shelve_id, created, updated)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 1, ?, NOW(), NOW())
+		INSERT INTO inventories (company_id, branch_id, product_id, product_code, lot, transaction_id, code, transaction_date, type, in_out, qty, cost, receipt_cost, shelve_id, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
 	`
 	stmt, err := tx.PrepareContext(ctx, queryDetail)
 	if err != nil {
@@ -48,21 +73,198 @@ func (u *Inventory) Create(ctx context.Context, tx *sql.Tx) error {
 		userLogin.Branch.ID,
 		u.ProductID,
 		u.ProductCode,
+		u.Lot,
 		u.TransactionID,
 		u.Code,
 		u.TransactionDate,
 		u.Type,
 		u.InOut,
+		u.Qty,
+		u.Cost,
+		receiptCost,
 		u.ShelveID,
 	)
 	return err
 }
 
-// Update inventory
+// valuate fill the cost of unit. A cost given by caller is the receiving cost of a new unit.
+// FIFO keep the receiving cost of unit on every movement, while moving average value every movement
+// with the average cost of product which is recalculated on every receiving.
+func (u *Inventory) valuate(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	company := Company{ID: userLogin.Company.ID}
+	method, err := company.GetValuationMethod(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case method == ValuationAverage:
+		var qty int
+		var average float64
+		err = tx.QueryRowContext(ctx, `
+			SELECT IFNULL(SUM(IF(in_out, qty, -qty)), 0), 
+				IFNULL((SELECT cost FROM inventories WHERE company_id = ? AND product_id = ? ORDER BY id DESC LIMIT 1), 0)
+			FROM inventories 
+			WHERE company_id = ? AND product_id = ?`,
+			userLogin.Company.ID, u.ProductID, userLogin.Company.ID, u.ProductID).Scan(&qty, &average)
+		if err != nil {
+			return err
+		}
+
+		if u.InOut && u.Cost > 0 {
+			if qty > 0 {
+				u.Cost = (float64(qty)*average + u.Cost*float64(u.Qty)) / float64(qty+int(u.Qty))
+			}
+			return nil
+		}
+
+		u.Cost = average
+
+	case u.Cost > 0:
+		return nil
+
+	default:
+		err = tx.QueryRowContext(ctx, `
+			SELECT cost FROM inventories 
+			WHERE company_id = ? AND product_id = ? AND product_code = ? 
+			ORDER BY id DESC LIMIT 1`,
+			userLogin.Company.ID, u.ProductID, u.ProductCode).Scan(&u.Cost)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+
+	if u.Cost > 0 {
+		return nil
+	}
+
+	return tx.QueryRowContext(ctx, `SELECT purchase_price FROM products WHERE id = ?`, u.ProductID).Scan(&u.Cost)
+}
+
+// revaluate replay movements of product in ledger order, from movement with id fromID onward,
+// when company values stock with moving average. The replay starts from quantity of the earlier movements
+// and the average valued on the last of them, as cost of every movement is the average after it.
+// Every receiving recalculates the average from its receipt cost, and every movement is valued with the average
+// at that time, so the costs are kept right after a movement is changed, removed or reversed.
+// Reversal of a receiving takes its quantity out with its receipt cost and recalculates the average of the rest,
+// so the last movement keeps the average read by valuate.
+// Cost of goods sold recorded on deliveries follows the cost of their movements.
+func revaluate(ctx context.Context, tx *sql.Tx, productID uint64, fromID uint64) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	company := Company{ID: userLogin.Company.ID}
+	method, err := company.GetValuationMethod(ctx, tx)
+	if err != nil || method != ValuationAverage {
+		return err
+	}
+
+	type movement struct {
+		id          uint64
+		inOut       bool
+		reversal    bool
+		qty         int
+		cost        float64
+		receiptCost sql.NullFloat64
+	}
+
+	var qty int
+	var average float64
+	err = tx.QueryRowContext(ctx, `
+		SELECT IFNULL(SUM(IF(in_out, qty, -qty)), 0),
+			IFNULL((SELECT cost FROM inventories WHERE company_id = ? AND product_id = ? AND id < ? ORDER BY id DESC LIMIT 1), 0)
+		FROM inventories
+		WHERE company_id = ? AND product_id = ? AND id < ?`,
+		userLogin.Company.ID, productID, fromID, userLogin.Company.ID, productID, fromID).Scan(&qty, &average)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, in_out, reversal, qty, cost, receipt_cost
+		FROM inventories
+		WHERE company_id = ? AND product_id = ? AND id >= ?
+		ORDER BY id`,
+		userLogin.Company.ID, productID, fromID)
+	if err != nil {
+		return err
+	}
+
+	var movements []movement
+	for rows.Next() {
+		var m movement
+		err = rows.Scan(&m.id, &m.inOut, &m.reversal, &m.qty, &m.cost, &m.receiptCost)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		movements = append(movements, m)
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, m := range movements {
+		switch {
+		case m.inOut && m.receiptCost.Float64 > 0:
+			if qty > 0 {
+				average = (float64(qty)*average + m.receiptCost.Float64*float64(m.qty)) / float64(qty+m.qty)
+			} else {
+				average = m.receiptCost.Float64
+			}
+
+		case m.reversal && m.receiptCost.Float64 > 0:
+			if qty > m.qty {
+				average = (float64(qty)*average - m.receiptCost.Float64*float64(m.qty)) / float64(qty-m.qty)
+			}
+
+		case average == 0:
+			// movement before any receiving keeps the cost it has been valued with
+			average = m.cost
+		}
+
+		if m.inOut {
+			qty += m.qty
+		} else {
+			qty -= m.qty
+		}
+
+		if m.cost == average {
+			continue
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE inventories SET cost = ? WHERE id = ?`, average, m.id)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE delivery_details
+		JOIN inventories ON inventories.type = 'DO'
+			AND inventories.transaction_id = delivery_details.delivery_id
+			AND inventories.product_id = delivery_details.product_id
+			AND inventories.product_code = delivery_details.code
+			AND inventories.reversal = 0
+		SET delivery_details.cost = inventories.cost
+		WHERE inventories.company_id = ? AND inventories.product_id = ? AND inventories.id >= ?`,
+		userLogin.Company.ID, productID, fromID)
+	return err
+}
+
+// Update inventory, moving average of product is recalculated as the quantity may be changed
 func (u *Inventory) Update(ctx context.Context, tx *sql.Tx) error {
 	var err error
 	userLogin := ctx.Value(api.Ctx("auth")).(User)
 
+	var productID uint64
+	err = tx.QueryRowContext(ctx, `SELECT product_id FROM inventories WHERE id = ? AND company_id = ?`, u.ID, userLogin.Company.ID).Scan(&productID)
+	if err != nil {
+		return err
+	}
+
 	const queryUpdate = `
 		UPDATE inventories
 		SET shelve_id = ?,
@@ -71,6 +273,7 @@ func (u *Inventory) Update(ctx context.Context, tx *sql.Tx) error {
 			transaction_id = ?, 
 			code = ?, 
 			transaction_date = ?, 
+			qty = ?,
 			updated= NOW()
 		WHERE id = ? AND company_id = ? AND branch_id = ?
 	`
@@ -88,20 +291,38 @@ func (u *Inventory) Update(ctx context.Context, tx *sql.Tx) error {
 		u.TransactionID,
 		u.Code,
 		u.TransactionDate,
+		u.Qty,
 		u.ID,
 		userLogin.Company.ID,
 		userLogin.Branch.ID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if productID != u.ProductID {
+		err = revaluate(ctx, tx, productID, u.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return revaluate(ctx, tx, u.ProductID, u.ID)
 }
 
-// Delete Inventory
+// Delete Inventory, moving average of product is recalculated from the remaining movements
 func (u *Inventory) Delete(ctx context.Context, tx *sql.Tx) error {
 	userLogin := ctx.Value(api.Ctx("auth")).(User)
 	if userLogin.Company.ID != u.CompanyID || u.BranchID <= 0 || userLogin.Branch.ID != u.BranchID {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	var productID uint64
+	err := tx.QueryRowContext(ctx, `SELECT product_id FROM inventories WHERE id = ? AND company_id = ?`, u.ID, userLogin.Company.ID).Scan(&productID)
+	if err != nil {
+		return err
+	}
+
 	const query = `DELETE FROM inventories WHERE id = ? AND company_id = ? AND branch_id = ?`
 	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
@@ -111,38 +332,74 @@ func (u *Inventory) Delete(ctx context.Context, tx *sql.Tx) error {
 	defer stmt.Close()
 
 	_, err = stmt.ExecContext(ctx, u.ID, userLogin.Company.ID, userLogin.Branch.ID)
-	return err
+	if err != nil {
+		return err
+	}
+
+	return revaluate(ctx, tx, productID, u.ID)
 }
 
-// DeleteByComposit Inventory
+// DeleteByComposit Inventory, moving average of product is recalculated from the remaining movements
 func (u *Inventory) DeleteByComposit(ctx context.Context, tx *sql.Tx) error {
 	userLogin := ctx.Value(api.Ctx("auth")).(User)
 	if userLogin.Company.ID != u.CompanyID || u.BranchID <= 0 || userLogin.Branch.ID != u.BranchID {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
-	const query = `DELETE FROM inventories WHERE product_id = ? AND product_code = ? AND transaction_id = ? AND type = ? AND company_id = ? AND branch_id = ?`
-	stmt, err := tx.PrepareContext(ctx, query)
+	const where = ` WHERE product_id = ? AND product_code = ? AND transaction_id = ? AND type = ? AND reversal = 0 AND company_id = ? AND branch_id = ?`
+	params := []interface{}{u.ProductID, u.ProductCode, u.TransactionID, u.Type, userLogin.Company.ID, userLogin.Branch.ID}
+
+	var fromID uint64
+	err := tx.QueryRowContext(ctx, `SELECT IFNULL(MIN(id), 0) FROM inventories`+where, params...).Scan(&fromID)
+	if err != nil || fromID == 0 {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `DELETE FROM inventories`+where)
 	if err != nil {
 		return err
 	}
 
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(ctx, u.ProductID, u.ProductCode, u.TransactionID, u.Type, userLogin.Company.ID, userLogin.Branch.ID)
-	return err
+	_, err = stmt.ExecContext(ctx, params...)
+	if err != nil {
+		return err
+	}
+
+	return revaluate(ctx, tx, u.ProductID, fromID)
 }
 
-// GetByComposit Inventory
+// GetByComposit Inventory, reversal of voided movement is not included
 func (u *Inventory) GetByComposit(ctx context.Context, tx *sql.Tx) error {
 	userLogin := ctx.Value(api.Ctx("auth")).(User)
 	const query = `
 		SELECT id, company_id, branch_id, shelve_id, code, transaction_date, in_out, qty  
 		FROM inventories 
-		WHERE product_id = ? AND product_code = ? AND transaction_id = ? AND type = ? AND company_id = ? AND branch_id = ?`
+		WHERE product_id = ? AND product_code = ? AND transaction_id = ? AND type = ? AND reversal = 0 AND company_id = ? AND branch_id = ?`
 	err := tx.QueryRowContext(ctx, query,
 		u.ProductID, u.ProductCode, u.TransactionID, u.Type, userLogin.Company.ID, userLogin.Branch.ID).Scan(
 		&u.ID, &u.CompanyID, &u.BranchID, &u.ShelveID, &u.Code, &u.TransactionDate, &u.InOut, &u.Qty)
 
 	return err
 }
+
+// GetLastPosition of unit code, fill the last branch and shelve of unit code and return its quantity on hand
+func (u *Inventory) GetLastPosition(ctx context.Context, tx *sql.Tx) (int, error) {
+	var qty int
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	const query = `
+		SELECT inventories.id, inventories.company_id, inventories.branch_id, inventories.shelve_id,
+			(SELECT IFNULL(SUM(IF(unit_inventories.in_out, unit_inventories.qty, -unit_inventories.qty)), 0)
+			FROM inventories unit_inventories
+			WHERE unit_inventories.company_id = inventories.company_id 
+			AND unit_inventories.product_id = inventories.product_id 
+			AND unit_inventories.product_code = inventories.product_code) qty
+		FROM inventories
+		WHERE inventories.company_id = ? AND inventories.product_id = ? AND inventories.product_code = ?
+		ORDER BY inventories.id DESC LIMIT 1`
+	err := tx.QueryRowContext(ctx, query, userLogin.Company.ID, u.ProductID, u.ProductCode).Scan(
+		&u.ID, &u.CompanyID, &u.BranchID, &u.ShelveID, &qty)
+
+	return qty, err
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// Label : struct of unit code to be printed on label
+type Label struct {
+	Code    string
+	Lot     string
+	Product Product
+}
+
+// Get product and lot of unit code on inventories visible by user login
+func (u *Label) Get(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	query := `SELECT product_id, lot FROM inventories WHERE company_id=? AND product_code=?`
+	params := []interface{}{userLogin.Company.ID, u.Code}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branch_id=?")
+			params = append(params, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND branch_id=?"
+		params = append(params, userLogin.Branch.ID)
+	}
+
+	err := tx.QueryRowContext(ctx, query+" ORDER BY id DESC LIMIT 1", params...).Scan(&u.Product.ID, &u.Lot)
+	if err != nil {
+		return err
+	}
+
+	return u.Product.Get(ctx, tx)
+}
+
+// ListByReceive return label of every unit code received on good receiving
+func (u *Label) ListByReceive(ctx context.Context, tx *sql.Tx, receive *Receive) ([]Label, error) {
+	var list []Label
+
+	err := receive.Get(ctx, tx)
+	if err != nil {
+		return list, err
+	}
+
+	for _, d := range receive.ReceiveDetails {
+		list = append(list, Label{Code: d.Code, Lot: d.Lot, Product: d.Product})
+	}
+
+	return list, nil
+}
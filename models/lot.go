@@ -0,0 +1,104 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// LotTrace : struct of delivered units coming from a supplier lot
+type LotTrace struct {
+	Lot         string
+	ProductCode string
+	Qty         uint
+	Returned    uint
+	Product     Product
+	Branch      Branch
+	Delivery    Delivery
+	SalesOrder  SalesOrder
+}
+
+// List every delivery of units from the lot ordered by customer, for recall purposes
+func (u *LotTrace) List(ctx context.Context, tx *sql.Tx) ([]LotTrace, error) {
+	var list []LotTrace
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	query := `
+		SELECT delivery_details.lot, delivery_details.code, delivery_details.qty,
+			IFNULL((SELECT SUM(delivery_return_details.qty) 
+				FROM delivery_returns
+				JOIN delivery_return_details ON delivery_returns.id = delivery_return_details.delivery_return_id
+				WHERE delivery_returns.delivery_id = deliveries.id AND delivery_returns.voided IS NULL
+				AND delivery_return_details.product_id = delivery_details.product_id 
+				AND delivery_return_details.code = delivery_details.code), 0),
+			products.id, products.code, products.name,
+			branches.id, branches.code, branches.name, branches.address, branches.type,
+			deliveries.id, deliveries.code, deliveries.date,
+			sales_orders.id, sales_orders.code, sales_orders.date,
+			customers.id, customers.name, customers.email, customers.address, customers.hp
+		FROM delivery_details
+		JOIN deliveries ON delivery_details.delivery_id = deliveries.id
+		JOIN sales_orders ON deliveries.sales_order_id = sales_orders.id
+		JOIN customers ON sales_orders.customer_id = customers.id
+		JOIN products ON delivery_details.product_id = products.id
+		JOIN branches ON deliveries.branch_id = branches.id
+		WHERE deliveries.company_id = ? AND deliveries.voided IS NULL AND delivery_details.lot = ?
+	`
+	params := []interface{}{userLogin.Company.ID, u.Lot}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=?")
+			params = append(params, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND branches.id=?"
+		params = append(params, userLogin.Branch.ID)
+	}
+
+	rows, err := tx.QueryContext(ctx, query+" ORDER BY customers.id, deliveries.date, deliveries.id", params...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var t LotTrace
+		err = rows.Scan(
+			&t.Lot, &t.ProductCode, &t.Qty, &t.Returned,
+			&t.Product.ID, &t.Product.Code, &t.Product.Name,
+			&t.Branch.ID, &t.Branch.Code, &t.Branch.Name, &t.Branch.Address, &t.Branch.Type,
+			&t.Delivery.ID, &t.Delivery.Code, &t.Delivery.Date,
+			&t.SalesOrder.ID, &t.SalesOrder.Code, &t.SalesOrder.Date,
+			&t.SalesOrder.Customer.ID, &t.SalesOrder.Customer.Name, &t.SalesOrder.Customer.Email, &t.SalesOrder.Customer.Address, &t.SalesOrder.Customer.Hp,
+		)
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return list, err
+	}
+
+	if len(list) == 0 {
+		return list, sql.ErrNoRows
+	}
+
+	return list, nil
+}
@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Approval : unit test for multi step approval of sales order, posting is blocked until the last step is approved
+// and changing the document supersedes its approval
+func (u *Ledger) Approval(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	first := u.approverRole(t, ctx, tx, "Approver Step 1", u.UserLogin.ID)
+	second := u.approverRole(t, ctx, tx, "Approver Step 2", u.UserLogin.ID)
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO users (username, password, email, is_active, company_id, branch_id) VALUES ('approver', '', 'approver@gmail.com', 1, ?, ?)`,
+		u.UserLogin.Company.ID, u.UserLogin.Branch.ID)
+	if err != nil {
+		t.Fatalf("creating approver: %s", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		t.Fatalf("creating approver: %s", err)
+	}
+
+	approver := u.UserLogin
+	approver.ID = uint64(id)
+	approver.Username = "approver"
+	_, err = tx.ExecContext(ctx, `INSERT INTO roles_users (role_id, user_id) VALUES (?, ?)`, second.ID, approver.ID)
+	if err != nil {
+		t.Fatalf("granting approver role: %s", err)
+	}
+
+	rule := models.ApprovalRule{
+		DocumentType: models.ApprovalSalesOrder,
+		Name:         "Sales Order Two Steps",
+		Steps:        []models.ApprovalStep{{Role: first}, {Role: second}},
+	}
+	err = rule.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating approval rule: %s", err)
+	}
+
+	product := u.product(t, ctx, tx, "APV-01", true)
+	purchase := u.purchase(t, ctx, tx, product, 1, 100)
+	u.receive(t, ctx, tx, purchase, 1, 1)
+
+	salesOrder := u.salesOrder(t, ctx, tx, product, 1)
+	approvals := u.approvals(t, ctx, tx, salesOrder.ID, 1)
+	if approvals[0].Status != models.ApprovalPending || approvals[0].Step != 1 {
+		t.Fatalf("expected pending approval on step 1, got %s on step %d", approvals[0].Status, approvals[0].Step)
+	}
+
+	delivery := models.Delivery{
+		Date:            time.Now(),
+		SalesOrder:      models.SalesOrder{ID: salesOrder.ID},
+		DeliveryDetails: []models.DeliveryDetail{{Product: models.Product{ID: product.ID}, UomQty: 1}},
+	}
+	err = delivery.Create(ctx, tx)
+	if err == nil {
+		t.Fatal("expected delivery of sales order waiting for approval to be rejected")
+	}
+
+	approval := u.approval(t, ctx, tx, approvals[0].ID)
+	err = approval.Approve(ctx, tx, "step 1")
+	if err != nil {
+		t.Fatalf("approving step 1: %s", err)
+	}
+
+	approval = u.approval(t, ctx, tx, approvals[0].ID)
+	if approval.Status != models.ApprovalPending || approval.Step != 2 {
+		t.Fatalf("expected pending approval on step 2, got %s on step %d", approval.Status, approval.Step)
+	}
+
+	err = approval.Approve(ctx, tx, "step 2")
+	if err == nil {
+		t.Fatal("expected user approving both steps to be rejected")
+	}
+
+	// changing sales order supersedes its approval, the new approval starts from step 1 again
+	salesOrder.AdditionalDisc = 1
+	err = salesOrder.Update(ctx, tx)
+	if err != nil {
+		t.Fatalf("updating sales order: %s", err)
+	}
+
+	approvals = u.approvals(t, ctx, tx, salesOrder.ID, 2)
+	if approvals[0].Status != models.ApprovalSuperseded {
+		t.Fatalf("expected approval of changed sales order superseded, got %s", approvals[0].Status)
+	}
+
+	if approvals[1].Status != models.ApprovalPending || approvals[1].Step != 1 || len(approvals[1].Histories) != 0 {
+		t.Fatalf("expected new pending approval on step 1 without histories, got %s on step %d with %d histories",
+			approvals[1].Status, approvals[1].Step, len(approvals[1].Histories))
+	}
+
+	approval = u.approval(t, ctx, tx, approvals[1].ID)
+	err = approval.Approve(ctx, tx, "step 1")
+	if err != nil {
+		t.Fatalf("approving step 1: %s", err)
+	}
+
+	approverCtx := context.WithValue(context.Background(), api.Ctx("auth"), approver)
+	approval = u.approval(t, approverCtx, tx, approvals[1].ID)
+	err = approval.Approve(approverCtx, tx, "step 2")
+	if err != nil {
+		t.Fatalf("approving step 2: %s", err)
+	}
+
+	approvals = u.approvals(t, ctx, tx, salesOrder.ID, 2)
+	if approvals[1].Status != models.ApprovalApproved || len(approvals[1].Histories) != 2 {
+		t.Fatalf("expected approval approved with 2 histories, got %s with %d histories", approvals[1].Status, len(approvals[1].Histories))
+	}
+
+	u.deliver(t, ctx, tx, salesOrder, 1)
+}
+
+// approverRole create role of company of user login and grant it to users
+func (u *Ledger) approverRole(t *testing.T, ctx context.Context, tx *sql.Tx, name string, userIDs ...uint64) models.Role {
+	role := models.Role{Name: name}
+	res, err := tx.ExecContext(ctx, `INSERT INTO roles (name, company_id) VALUES (?, ?)`, role.Name, u.UserLogin.Company.ID)
+	if err != nil {
+		t.Fatalf("creating role: %s", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		t.Fatalf("creating role: %s", err)
+	}
+
+	role.ID = uint32(id)
+	for _, userID := range userIDs {
+		_, err = tx.ExecContext(ctx, `INSERT INTO roles_users (role_id, user_id) VALUES (?, ?)`, role.ID, userID)
+		if err != nil {
+			t.Fatalf("granting role: %s", err)
+		}
+	}
+
+	return role
+}
+
+// approvals return approvals of sales order, it fails when their number is not as expected
+func (u *Ledger) approvals(t *testing.T, ctx context.Context, tx *sql.Tx, salesOrderID uint64, expected int) []models.Approval {
+	list, err := models.ListApprovals(ctx, tx, models.ApprovalSalesOrder, salesOrderID)
+	if err != nil {
+		t.Fatalf("listing approvals: %s", err)
+	}
+
+	if len(list) != expected {
+		t.Fatalf("expected %d approvals, got %d", expected, len(list))
+	}
+
+	return list
+}
+
+// approval return approval with steps of its rule and its histories
+func (u *Ledger) approval(t *testing.T, ctx context.Context, tx *sql.Tx, id uint64) models.Approval {
+	approval := models.Approval{ID: id}
+	err := approval.Get(ctx, tx)
+	if err != nil {
+		t.Fatalf("getting approval: %s", err)
+	}
+
+	return approval
+}
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+)
+
+// AuditLog : unit test for content of audit log written outside transaction of the audited request,
+// it is deleted at the end of the test
+func (u *Ledger) AuditLog(t *testing.T) {
+	ctx := context.WithValue(context.Background(), api.Ctx("auth"), u.UserLogin)
+	auditLog := models.AuditLog{
+		Company:  u.UserLogin.Company,
+		User:     u.UserLogin,
+		Action:   models.AuditUpdate,
+		Route:    "PUT /products/:id",
+		Entity:   "products",
+		EntityID: 999999,
+		Before:   sql.NullString{String: `{"id":999999,"name":"Before"}`, Valid: true},
+		After:    sql.NullString{String: `{"id":999999,"name":"After"}`, Valid: true},
+	}
+
+	err := auditLog.Create(ctx, u.Db)
+	if err != nil {
+		t.Fatalf("creating audit log: %s", err)
+	}
+
+	defer u.Db.Exec(`DELETE FROM audit_logs WHERE id = ?`, auditLog.ID)
+
+	tx, err := u.Db.Begin()
+	if err != nil {
+		t.Fatalf("begin transaction: %s", err)
+	}
+
+	defer tx.Rollback()
+
+	filter := models.AuditLog{Entity: "products", EntityID: 999999, Action: models.AuditUpdate, User: models.User{ID: u.UserLogin.ID}}
+	list, err := filter.List(ctx, tx, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("listing audit logs: %s", err)
+	}
+
+	if len(list) != 1 {
+		t.Fatalf("expected 1 audit log, got %d", len(list))
+	}
+
+	got := list[0]
+	if got.ID != auditLog.ID || got.User.Username != u.UserLogin.Username || got.Route != auditLog.Route {
+		t.Fatalf("expected audit log %d of %s on %s, got %d of %s on %s",
+			auditLog.ID, u.UserLogin.Username, auditLog.Route, got.ID, got.User.Username, got.Route)
+	}
+
+	if got.Before.String != auditLog.Before.String || got.After.String != auditLog.After.String {
+		t.Fatalf("expected before %s and after %s, got before %s and after %s",
+			auditLog.Before.String, auditLog.After.String, got.Before.String, got.After.String)
+	}
+
+	filter.Action = models.AuditDelete
+	list, err = filter.List(ctx, tx, time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("listing audit logs: %s", err)
+	}
+
+	if len(list) != 0 {
+		t.Fatalf("expected no audit log of other action, got %d", len(list))
+	}
+}
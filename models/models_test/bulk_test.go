@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Bulk : unit test for bulk quantity posted in a single movement, costed by FIFO and moving average
+// across receive, deliver and receive again
+func (u *Ledger) Bulk(t *testing.T) {
+	for _, tt := range []struct {
+		method string
+		costs  []float64
+	}{
+		{models.ValuationFIFO, []float64{100, 100, 200}},
+		{models.ValuationAverage, []float64{100, 100, 162.5}},
+	} {
+		t.Run(tt.method, func(t *testing.T) {
+			ctx, tx := u.begin(t)
+			defer tx.Rollback()
+
+			u.valuationMethod(t, ctx, tx, tt.method)
+			product := u.product(t, ctx, tx, "BLK-01", false)
+			first := u.purchase(t, ctx, tx, product, 10, 100)
+			u.receive(t, ctx, tx, first, 1, 10)
+
+			salesOrder := u.salesOrder(t, ctx, tx, product, 4)
+			delivery := u.deliver(t, ctx, tx, salesOrder, 4)
+			if exp, got := float64(100), delivery.DeliveryDetails[0].Cost; exp != got {
+				t.Fatalf("expected cost of goods sold %v, got %v", exp, got)
+			}
+
+			second := u.purchase(t, ctx, tx, product, 10, 200)
+			u.receive(t, ctx, tx, second, 1, 10)
+
+			if exp, got := 16, u.onHand(t, ctx, tx, product.ID); exp != got {
+				t.Fatalf("expected on hand %v, got %v", exp, got)
+			}
+
+			if diff := cmp.Diff(tt.costs, u.costs(t, ctx, tx, product.ID)); diff != "" {
+				t.Fatalf("costs did not match expected. Diff:\n%s", diff)
+			}
+		})
+	}
+}
+
+// BulkPicking : unit test for bulk quantity picked across unit codes by FIFO, the last unit code is taken partially,
+// and partial quantity of unit code moved to another shelve
+func (u *Ledger) BulkPicking(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	product := u.product(t, ctx, tx, "BLK-02", false)
+	purchase := u.purchase(t, ctx, tx, product, 8, 100)
+	receive := u.receive(t, ctx, tx, purchase, 1, 3, 5)
+
+	salesOrder := u.salesOrder(t, ctx, tx, product, 6)
+	delivery := u.deliver(t, ctx, tx, salesOrder, 6)
+	if exp, got := 2, len(delivery.DeliveryDetails); exp != got {
+		t.Fatalf("expected delivery split into %v unit codes, got %v", exp, got)
+	}
+
+	for i, qty := range []uint{3, 3} {
+		d := delivery.DeliveryDetails[i]
+		if d.Code != receive.ReceiveDetails[i].Code || d.Qty != qty {
+			t.Fatalf("expected %v of unit code %s, got %v of unit code %s", qty, receive.ReceiveDetails[i].Code, d.Qty, d.Code)
+		}
+	}
+
+	if exp, got := 2, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand %v, got %v", exp, got)
+	}
+
+	shelve := models.Shelve{Code: "SHV-BLK", Capacity: 10}
+	err := shelve.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating shelve: %s", err)
+	}
+
+	code := receive.ReceiveDetails[1].Code
+	mutation := models.Mutation{
+		Date: time.Now(),
+		MutationDetails: []models.MutationDetail{
+			{Product: models.Product{ID: product.ID}, Code: code, ShelveTo: models.Shelve{ID: shelve.ID}, Qty: 3},
+		},
+	}
+
+	if err = mutation.Create(ctx, tx); err == nil {
+		t.Fatal("expected mutation of more than quantity on hand to be rejected")
+	}
+
+	mutation.MutationDetails[0].Qty = 1
+	err = mutation.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating mutation: %s", err)
+	}
+
+	var moved int
+	err = tx.QueryRowContext(ctx, `SELECT IFNULL(SUM(IF(in_out, qty, -qty)), 0) FROM inventories WHERE product_code = ? AND shelve_id = ?`,
+		code, shelve.ID).Scan(&moved)
+	if err != nil {
+		t.Fatalf("getting quantity on shelve: %s", err)
+	}
+
+	if exp, got := 1, moved; exp != got {
+		t.Fatalf("expected quantity moved to shelve %v, got %v", exp, got)
+	}
+
+	if exp, got := 2, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after mutation %v, got %v", exp, got)
+	}
+}
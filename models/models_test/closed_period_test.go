@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// ClosedPeriod : unit test for posting dated in closed period, it is rejected until the period is reopened
+func (u *Ledger) ClosedPeriod(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	lastMonth := time.Now().AddDate(0, -1, 0)
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO closed_periods (company_id, year, month, status, closed, closed_by)
+		VALUES (?, ?, ?, ?, NOW(), ?)`,
+		u.UserLogin.Company.ID, lastMonth.Year(), int(lastMonth.Month()), models.ClosedPeriodClosed, u.UserLogin.ID)
+	if err != nil {
+		t.Fatalf("closing period: %s", err)
+	}
+
+	product := u.product(t, ctx, tx, "CLS-01", true)
+	purchase := u.purchase(t, ctx, tx, product, 1, 100)
+	receive := models.Receive{
+		Date:     lastMonth,
+		Purchase: models.Purchase{ID: purchase.ID},
+		ReceiveDetails: []models.ReceiveDetail{
+			{Product: models.Product{ID: product.ID}, UomQty: 1, Shelve: models.Shelve{ID: 1}},
+		},
+	}
+
+	if err = receive.Create(ctx, tx); err == nil {
+		t.Fatal("expected receive dated in closed period to be rejected")
+	}
+
+	if exp, got := 0, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after rejected receive %v, got %v", exp, got)
+	}
+
+	closingStock := models.ClosingStock{Year: lastMonth.Year(), Month: int(lastMonth.Month())}
+	reopened, err := closingStock.Reopen(ctx, tx)
+	if err != nil {
+		t.Fatalf("reopening period: %s", err)
+	}
+
+	if exp, got := 1, len(reopened); exp != got {
+		t.Fatalf("expected reopened periods %v, got %v", exp, got)
+	}
+
+	err = receive.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating receive dated in reopened period: %s", err)
+	}
+
+	if exp, got := 1, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after receive %v, got %v", exp, got)
+	}
+
+	// closing stock closes the reopened period again, with the receive in its saldo stock
+	closingStock = models.ClosingStock{}
+	err = closingStock.Closing(ctx, tx)
+	if err != nil {
+		t.Fatalf("closing reopened period: %s", err)
+	}
+
+	if closingStock.Year != lastMonth.Year() || closingStock.Month != int(lastMonth.Month()) || closingStock.Status != models.ClosedPeriodClosed {
+		t.Fatalf("expected period %02d-%d closed, got %02d-%d with status %s",
+			int(lastMonth.Month()), lastMonth.Year(), closingStock.Month, closingStock.Year, closingStock.Status)
+	}
+
+	receive.ID = 0
+	if err = receive.Create(ctx, tx); err == nil {
+		t.Fatal("expected receive dated in period closed again to be rejected")
+	}
+}
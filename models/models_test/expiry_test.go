@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Expiry : unit test for expiring unit codes on hand, expired unit code can only be delivered when it is allowed
+func (u *Ledger) Expiry(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	product := u.product(t, ctx, tx, "EXP-01", true)
+	purchase := u.purchase(t, ctx, tx, product, 3, 100)
+	receive := models.Receive{Date: time.Now(), Purchase: models.Purchase{ID: purchase.ID}}
+	for _, days := range []int{-1, 5, 60} {
+		receive.ReceiveDetails = append(receive.ReceiveDetails, models.ReceiveDetail{
+			Product:     models.Product{ID: product.ID},
+			UomQty:      1,
+			Shelve:      models.Shelve{ID: 1},
+			ExpiredDate: sql.NullTime{Time: time.Now().AddDate(0, 0, days), Valid: true},
+		})
+	}
+
+	err := receive.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating receive: %s", err)
+	}
+
+	var stock models.Stock
+	expiring, err := stock.ListExpiring(ctx, tx, 7)
+	if err != nil {
+		t.Fatalf("listing expiring stock: %s", err)
+	}
+
+	var codes []string
+	for _, e := range expiring {
+		for _, unit := range e.Units {
+			if unit.Product.ID == product.ID {
+				codes = append(codes, unit.Code)
+			}
+		}
+	}
+
+	expected := []string{receive.ReceiveDetails[0].Code, receive.ReceiveDetails[1].Code}
+	if diff := cmp.Diff(expected, codes); diff != "" {
+		t.Fatalf("expiring unit codes did not match expected. Diff:\n%s", diff)
+	}
+
+	salesOrder := u.salesOrder(t, ctx, tx, product, 1)
+	delivery := models.Delivery{
+		Date:       time.Now(),
+		SalesOrder: models.SalesOrder{ID: salesOrder.ID},
+		DeliveryDetails: []models.DeliveryDetail{
+			{Product: models.Product{ID: product.ID}, UomQty: 1, Code: receive.ReceiveDetails[0].Code, Shelve: models.Shelve{ID: 1}},
+		},
+	}
+
+	if err = delivery.Create(ctx, tx); err == nil {
+		t.Fatal("expected expired unit code to be rejected on delivery")
+	}
+
+	delivery.AllowExpired = true
+	err = delivery.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating delivery of allowed expired unit code: %s", err)
+	}
+
+	if exp, got := 2, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after delivery %v, got %v", exp, got)
+	}
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Fulfillment : unit test for fulfillment status and outstanding quantity of sales order through partial deliveries,
+// delivery exceeding outstanding quantity is rejected
+func (u *Ledger) Fulfillment(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	product := u.product(t, ctx, tx, "FUL-01", true)
+	purchase := u.purchase(t, ctx, tx, product, 4, 100)
+	u.receive(t, ctx, tx, purchase, 1, 1, 1, 1, 1)
+
+	salesOrder := u.salesOrder(t, ctx, tx, product, 3)
+	u.fulfillmentStatus(t, ctx, tx, salesOrder.ID, models.FulfillmentOpen, 0)
+
+	u.deliver(t, ctx, tx, salesOrder, 1)
+	u.fulfillmentStatus(t, ctx, tx, salesOrder.ID, models.FulfillmentPartial, 1)
+
+	var backorder models.SalesOrder
+	backorders, err := backorder.ListBackorders(ctx, tx)
+	if err != nil {
+		t.Fatalf("listing backorders: %s", err)
+	}
+
+	var outstanding int
+	for _, b := range backorders {
+		for _, p := range b.Products {
+			if p.Product.ID == product.ID {
+				outstanding += p.Outstanding
+			}
+		}
+	}
+
+	if exp, got := 2, outstanding; exp != got {
+		t.Fatalf("expected outstanding quantity %v, got %v", exp, got)
+	}
+
+	u.deliver(t, ctx, tx, salesOrder, 1, 1)
+	u.fulfillmentStatus(t, ctx, tx, salesOrder.ID, models.FulfillmentDelivered, 3)
+
+	if exp, got := 1, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after deliveries %v, got %v", exp, got)
+	}
+
+	delivery := models.Delivery{
+		Date:            time.Now(),
+		SalesOrder:      models.SalesOrder{ID: salesOrder.ID},
+		DeliveryDetails: []models.DeliveryDetail{{Product: models.Product{ID: product.ID}, UomQty: 1}},
+	}
+
+	if err = delivery.Create(ctx, tx); err == nil {
+		t.Fatal("expected delivery exceeding outstanding quantity to be rejected")
+	}
+}
+
+// fulfillmentStatus check status of sales order and delivered quantity of its line
+func (u *Ledger) fulfillmentStatus(t *testing.T, ctx context.Context, tx *sql.Tx, salesOrderID uint64, status string, delivered uint) {
+	salesOrder := models.SalesOrder{ID: salesOrderID}
+	err := salesOrder.Get(ctx, tx)
+	if err != nil {
+		t.Fatalf("getting sales order: %s", err)
+	}
+
+	if salesOrder.Status != status || salesOrder.SalesOrderDetails[0].DeliveredQty != delivered {
+		t.Fatalf("expected sales order %s with delivered %v, got %s with delivered %v",
+			status, delivered, salesOrder.Status, salesOrder.SalesOrderDetails[0].DeliveredQty)
+	}
+}
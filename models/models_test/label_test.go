@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Label : unit test for labels of unit codes received on good receiving, with product and lot of unit code
+func (u *Ledger) Label(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	product := u.product(t, ctx, tx, "LBL-01", true)
+	purchase := u.purchase(t, ctx, tx, product, 2, 100)
+	receive := models.Receive{
+		Date:     time.Now(),
+		Purchase: models.Purchase{ID: purchase.ID},
+		ReceiveDetails: []models.ReceiveDetail{
+			{Product: models.Product{ID: product.ID}, UomQty: 1, Shelve: models.Shelve{ID: 1}, Lot: "LBL-A"},
+			{Product: models.Product{ID: product.ID}, UomQty: 1, Shelve: models.Shelve{ID: 1}, Lot: "LBL-A"},
+		},
+	}
+
+	err := receive.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating receive: %s", err)
+	}
+
+	var label models.Label
+	labels, err := label.ListByReceive(ctx, tx, &models.Receive{ID: receive.ID})
+	if err != nil {
+		t.Fatalf("listing labels of receive: %s", err)
+	}
+
+	var codes []string
+	for _, l := range labels {
+		codes = append(codes, l.Code)
+	}
+
+	expected := []string{receive.ReceiveDetails[0].Code, receive.ReceiveDetails[1].Code}
+	if diff := cmp.Diff(expected, codes); diff != "" {
+		t.Fatalf("labelled unit codes did not match expected. Diff:\n%s", diff)
+	}
+
+	label = models.Label{Code: receive.ReceiveDetails[1].Code}
+	err = label.Get(ctx, tx)
+	if err != nil {
+		t.Fatalf("getting label: %s", err)
+	}
+
+	if label.Product.ID != product.ID || label.Lot != "LBL-A" {
+		t.Fatalf("expected label of product %v lot LBL-A, got product %v lot %s", product.ID, label.Product.ID, label.Lot)
+	}
+
+	if exp, got := 2, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand of labelled unit codes %v, got %v", exp, got)
+	}
+}
@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Ledger struct for test transactions posted into inventories.
+// Every test runs in its own transaction which is rolled back, so its postings do not affect the other tests.
+type Ledger struct {
+	Db        *sql.DB
+	UserLogin models.User
+	Salesman  models.Salesman
+	Customer  models.Customer
+}
+
+// newLedger return ledger test of user of branch of seed
+func newLedger(t *testing.T, db *sql.DB) Ledger {
+	userLogin := models.User{Username: "peterpan"}
+	err := userLogin.GetByUsername(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return Ledger{Db: db, UserLogin: userLogin}
+}
+
+// begin return context of user login and transaction of a test
+func (u *Ledger) begin(t *testing.T) (context.Context, *sql.Tx) {
+	ctx := context.WithValue(context.Background(), api.Ctx("auth"), u.UserLogin)
+	tx, err := u.Db.Begin()
+	if err != nil {
+		t.Fatalf("begin transaction: %s", err)
+	}
+
+	u.Salesman = models.Salesman{}
+	u.Customer = models.Customer{}
+
+	return ctx, tx
+}
+
+// party create salesman and customer of sales orders in transaction of the test
+func (u *Ledger) party(t *testing.T, ctx context.Context, tx *sql.Tx) {
+	u.Salesman = models.Salesman{Code: "SLS-01", Name: "Salesman Test", Email: "salesman@gmail.com"}
+	res, err := tx.ExecContext(ctx, `INSERT INTO salesmen (company_id, code, name, email, address, hp) VALUES (?, ?, ?, ?, '', '')`,
+		u.UserLogin.Company.ID, u.Salesman.Code, u.Salesman.Name, u.Salesman.Email)
+	if err != nil {
+		t.Fatalf("creating salesman: %s", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		t.Fatalf("creating salesman: %s", err)
+	}
+
+	u.Salesman.ID = uint64(id)
+	u.Customer = models.Customer{Name: "Customer Test", Email: "customer@gmail.com"}
+	err = u.Customer.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating customer: %s", err)
+	}
+}
+
+// valuationMethod set valuation method of company of user login
+func (u *Ledger) valuationMethod(t *testing.T, ctx context.Context, tx *sql.Tx, method string) {
+	_, err := tx.ExecContext(ctx, `UPDATE companies SET valuation_method = ? WHERE id = ?`, method, u.UserLogin.Company.ID)
+	if err != nil {
+		t.Fatalf("setting valuation method: %s", err)
+	}
+}
+
+// product create new product of brand and category of seed
+func (u *Ledger) product(t *testing.T, ctx context.Context, tx *sql.Tx, code string, serialized bool) models.Product {
+	product := models.Product{
+		Code:            code,
+		Name:            code,
+		SalePrice:       1000,
+		Serialized:      serialized,
+		Brand:           models.Brand{ID: 1},
+		ProductCategory: models.ProductCategory{ID: 1},
+	}
+
+	err := product.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating product %s: %s", code, err)
+	}
+
+	return product
+}
+
+// purchase create approved purchase of quantity of product with unit price
+func (u *Ledger) purchase(t *testing.T, ctx context.Context, tx *sql.Tx, product models.Product, qty uint, price float64) models.Purchase {
+	purchase := models.Purchase{
+		Date:     time.Now(),
+		Supplier: models.Supplier{ID: 1},
+		PurchaseDetails: []models.PurchaseDetail{
+			{Product: models.Product{ID: product.ID}, Price: price * float64(qty), UomQty: qty},
+		},
+	}
+
+	err := purchase.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating purchase: %s", err)
+	}
+
+	err = purchase.Submit(ctx, tx)
+	if err != nil {
+		t.Fatalf("submitting purchase: %s", err)
+	}
+
+	err = purchase.Approve(ctx, tx)
+	if err != nil {
+		t.Fatalf("approving purchase: %s", err)
+	}
+
+	return purchase
+}
+
+// receive create good receiving of product of purchase on shelve, a detail for every quantity
+func (u *Ledger) receive(t *testing.T, ctx context.Context, tx *sql.Tx, purchase models.Purchase, shelveID uint64, qtys ...uint) models.Receive {
+	receive := models.Receive{Date: time.Now(), Purchase: models.Purchase{ID: purchase.ID}}
+	for _, qty := range qtys {
+		receive.ReceiveDetails = append(receive.ReceiveDetails, models.ReceiveDetail{
+			Product: models.Product{ID: purchase.PurchaseDetails[0].Product.ID},
+			UomQty:  qty,
+			Shelve:  models.Shelve{ID: shelveID},
+		})
+	}
+
+	err := receive.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating receive: %s", err)
+	}
+
+	return receive
+}
+
+// salesOrder create sales order of quantity of product
+func (u *Ledger) salesOrder(t *testing.T, ctx context.Context, tx *sql.Tx, product models.Product, qty uint) models.SalesOrder {
+	if u.Salesman.ID == 0 {
+		u.party(t, ctx, tx)
+	}
+
+	salesOrder := models.SalesOrder{
+		Date:     time.Now(),
+		Salesman: models.Salesman{ID: u.Salesman.ID},
+		Customer: models.Customer{ID: u.Customer.ID},
+		SalesOrderDetails: []models.SalesOrderDetail{
+			{Product: models.Product{ID: product.ID}, Price: product.SalePrice * float64(qty), UomQty: qty},
+		},
+	}
+
+	err := salesOrder.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating sales order: %s", err)
+	}
+
+	return salesOrder
+}
+
+// deliver create delivery of sales order, unit codes of every quantity are picked by FIFO
+func (u *Ledger) deliver(t *testing.T, ctx context.Context, tx *sql.Tx, salesOrder models.SalesOrder, qtys ...uint) models.Delivery {
+	delivery := models.Delivery{Date: time.Now(), SalesOrder: models.SalesOrder{ID: salesOrder.ID}}
+	for _, qty := range qtys {
+		delivery.DeliveryDetails = append(delivery.DeliveryDetails, models.DeliveryDetail{
+			Product: models.Product{ID: salesOrder.SalesOrderDetails[0].Product.ID},
+			UomQty:  qty,
+		})
+	}
+
+	err := delivery.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating delivery: %s", err)
+	}
+
+	return delivery
+}
+
+// onHand return quantity on hand of product in branch of user login
+func (u *Ledger) onHand(t *testing.T, ctx context.Context, tx *sql.Tx, productID uint64) int {
+	stock := models.Stock{Product: models.Product{ID: productID}}
+	err := stock.Get(ctx, tx)
+	if err != nil {
+		t.Fatalf("getting stock: %s", err)
+	}
+
+	return stock.Qty
+}
+
+// costs return cost of movements of product in ledger order
+func (u *Ledger) costs(t *testing.T, ctx context.Context, tx *sql.Tx, productID uint64) []float64 {
+	var costs []float64
+	rows, err := tx.QueryContext(ctx, `SELECT cost FROM inventories WHERE product_id = ? ORDER BY id`, productID)
+	if err != nil {
+		t.Fatalf("listing costs: %s", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var cost float64
+		if err := rows.Scan(&cost); err != nil {
+			t.Fatalf("scanning cost: %s", err)
+		}
+
+		costs = append(costs, cost)
+	}
+
+	if err := rows.Err(); err != nil {
+		t.Fatalf("listing costs: %s", err)
+	}
+
+	return costs
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Lot : unit test for supplier lot of received unit code propagated to its delivery and traced to the customer
+func (u *Ledger) Lot(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	product := u.product(t, ctx, tx, "LOT-01", true)
+	purchase := u.purchase(t, ctx, tx, product, 2, 100)
+	receive := models.Receive{
+		Date:     time.Now(),
+		Purchase: models.Purchase{ID: purchase.ID},
+		ReceiveDetails: []models.ReceiveDetail{
+			{Product: models.Product{ID: product.ID}, UomQty: 1, Shelve: models.Shelve{ID: 1}, Lot: "LOT-A"},
+			{Product: models.Product{ID: product.ID}, UomQty: 1, Shelve: models.Shelve{ID: 1}, Lot: "LOT-B"},
+		},
+	}
+
+	err := receive.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating receive: %s", err)
+	}
+
+	salesOrder := u.salesOrder(t, ctx, tx, product, 1)
+	delivery := models.Delivery{
+		Date:       time.Now(),
+		SalesOrder: models.SalesOrder{ID: salesOrder.ID},
+		DeliveryDetails: []models.DeliveryDetail{
+			{Product: models.Product{ID: product.ID}, UomQty: 1, Code: receive.ReceiveDetails[1].Code, Shelve: models.Shelve{ID: 1}},
+		},
+	}
+
+	err = delivery.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating delivery: %s", err)
+	}
+
+	if d := delivery.DeliveryDetails[0]; d.Lot != "LOT-B" || d.Cost != 100 {
+		t.Fatalf("expected delivery of lot LOT-B with cost 100, got lot %s with cost %v", d.Lot, d.Cost)
+	}
+
+	if exp, got := 1, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after delivery %v, got %v", exp, got)
+	}
+
+	for _, tt := range []struct {
+		lot    string
+		traces int
+	}{
+		{"LOT-A", 0},
+		{"LOT-B", 1},
+	} {
+		lotTrace := models.LotTrace{Lot: tt.lot}
+		traces, err := lotTrace.List(ctx, tx)
+		if err != nil {
+			t.Fatalf("listing trace of lot %s: %s", tt.lot, err)
+		}
+
+		if len(traces) != tt.traces {
+			t.Fatalf("expected deliveries of lot %s %v, got %v", tt.lot, tt.traces, len(traces))
+		}
+	}
+}
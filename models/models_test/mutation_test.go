@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Mutation : unit test for moving unit code to another shelve, stock on hand and cost are kept
+func (u *Ledger) Mutation(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	product := u.product(t, ctx, tx, "MUT-01", true)
+	purchase := u.purchase(t, ctx, tx, product, 1, 100)
+	receive := u.receive(t, ctx, tx, purchase, 1, 1)
+
+	shelve := models.Shelve{Code: "SHV-MUT", Capacity: 10}
+	err := shelve.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating shelve: %s", err)
+	}
+
+	mutation := models.Mutation{
+		Date: time.Now(),
+		MutationDetails: []models.MutationDetail{
+			{Product: models.Product{ID: product.ID}, Code: receive.ReceiveDetails[0].Code, ShelveTo: models.Shelve{ID: shelve.ID}, Qty: 1},
+		},
+	}
+
+	err = mutation.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating mutation: %s", err)
+	}
+
+	if exp, got := 1, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after mutation %v, got %v", exp, got)
+	}
+
+	position := models.Inventory{ProductID: product.ID, ProductCode: receive.ReceiveDetails[0].Code}
+	_, err = position.GetLastPosition(ctx, tx)
+	if err != nil {
+		t.Fatalf("getting last position: %s", err)
+	}
+
+	if exp, got := shelve.ID, position.ShelveID; exp != got {
+		t.Fatalf("expected shelve after mutation %v, got %v", exp, got)
+	}
+
+	if diff := cmp.Diff([]float64{100, 100, 100}, u.costs(t, ctx, tx, product.ID)); diff != "" {
+		t.Fatalf("costs did not match expected. Diff:\n%s", diff)
+	}
+}
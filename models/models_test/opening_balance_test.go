@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// OpeningBalance : unit test for importing unit codes on hand before the first closing
+func (u *Ledger) OpeningBalance(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	_, err := tx.ExecContext(ctx, `DELETE FROM closed_periods WHERE company_id = ?`, u.UserLogin.Company.ID)
+	if err != nil {
+		t.Fatalf("removing closed periods: %s", err)
+	}
+
+	product := u.product(t, ctx, tx, "OBL-01", true)
+	balances := []models.OpeningBalance{
+		{Product: models.Product{ID: product.ID}, Code: "OBL-01-001", Shelve: models.Shelve{ID: 1}, Qty: 1},
+		{Product: models.Product{ID: product.ID}, Code: "OBL-01-002", Shelve: models.Shelve{ID: 1}, Qty: 1},
+	}
+
+	var closingStock models.ClosingStock
+	err = closingStock.ImportOpeningBalances(ctx, tx, time.Now(), balances)
+	if err != nil {
+		t.Fatalf("importing opening balances: %s", err)
+	}
+
+	if exp, got := 2, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after opening balance %v, got %v", exp, got)
+	}
+
+	if err = closingStock.ImportOpeningBalances(ctx, tx, time.Now(), balances[:1]); err == nil {
+		t.Fatal("expected unit code which already exists to be rejected")
+	}
+
+	lastMonth := time.Now().AddDate(0, -1, 0)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO closed_periods (company_id, year, month, status, closed, closed_by)
+		VALUES (?, ?, ?, ?, NOW(), ?)`,
+		u.UserLogin.Company.ID, lastMonth.Year(), int(lastMonth.Month()), models.ClosedPeriodClosed, u.UserLogin.ID)
+	if err != nil {
+		t.Fatalf("closing period: %s", err)
+	}
+
+	others := []models.OpeningBalance{
+		{Product: models.Product{ID: product.ID}, Code: "OBL-01-003", Shelve: models.Shelve{ID: 1}, Qty: 1},
+	}
+	if err = closingStock.ImportOpeningBalances(ctx, tx, time.Now(), others); err == nil {
+		t.Fatal("expected opening balance after the first closing to be rejected")
+	}
+
+	if exp, got := 2, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after rejected opening balance %v, got %v", exp, got)
+	}
+}
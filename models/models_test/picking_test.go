@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Picking : unit test for unit code picked by FEFO and FIFO, delivery is valued with the cost of picked unit code
+func (u *Ledger) Picking(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	product := u.product(t, ctx, tx, "PCK-01", true)
+	var codes []string
+	for _, p := range []struct {
+		price float64
+		days  int
+	}{
+		{100, 60},
+		{120, 10},
+	} {
+		purchase := u.purchase(t, ctx, tx, product, 1, p.price)
+		receive := u.receive(t, ctx, tx, purchase, 1, 1)
+
+		_, err := tx.ExecContext(ctx, `UPDATE good_receiving_details SET expired_date = ? WHERE id = ?`,
+			time.Now().AddDate(0, 0, p.days), receive.ReceiveDetails[0].ID)
+		if err != nil {
+			t.Fatalf("setting expired date: %s", err)
+		}
+
+		codes = append(codes, receive.ReceiveDetails[0].Code)
+	}
+
+	salesOrder := u.salesOrder(t, ctx, tx, product, 2)
+	for _, p := range []struct {
+		picking string
+		code    string
+		cost    float64
+	}{
+		{models.DeliveryPickingFEFO, codes[1], 120},
+		{models.DeliveryPickingFIFO, codes[0], 100},
+	} {
+		delivery := models.Delivery{
+			Date:            time.Now(),
+			Picking:         p.picking,
+			SalesOrder:      models.SalesOrder{ID: salesOrder.ID},
+			DeliveryDetails: []models.DeliveryDetail{{Product: models.Product{ID: product.ID}, UomQty: 1}},
+		}
+
+		err := delivery.Create(ctx, tx)
+		if err != nil {
+			t.Fatalf("creating delivery picked by %s: %s", p.picking, err)
+		}
+
+		if d := delivery.DeliveryDetails[0]; d.Code != p.code || d.Cost != p.cost {
+			t.Fatalf("expected %s picking unit code %s with cost %v, got %s with cost %v", p.picking, p.code, p.cost, d.Code, d.Cost)
+		}
+	}
+
+	if exp, got := 0, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after deliveries %v, got %v", exp, got)
+	}
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+)
+
+// PurchaseLifecycle : unit test for receiving purchase through its status, goods are only received against approved purchase
+func (u *Ledger) PurchaseLifecycle(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	product := u.product(t, ctx, tx, "POL-01", true)
+	purchase := models.Purchase{
+		Date:     time.Now(),
+		Supplier: models.Supplier{ID: 1},
+		PurchaseDetails: []models.PurchaseDetail{
+			{Product: models.Product{ID: product.ID}, Price: 200, UomQty: 2},
+		},
+	}
+
+	err := purchase.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating purchase: %s", err)
+	}
+
+	err = purchase.Submit(ctx, tx)
+	if err != nil {
+		t.Fatalf("submitting purchase: %s", err)
+	}
+
+	u.receiveRejected(t, ctx, tx, purchase, product)
+
+	// user of another branch of company can not approve the purchase
+	otherBranch := u.UserLogin
+	otherBranch.Branch.ID += 1000
+	if err = purchase.Approve(context.WithValue(ctx, api.Ctx("auth"), otherBranch), tx); err == nil {
+		t.Fatal("expected approval by user of another branch to be rejected")
+	}
+
+	err = purchase.Approve(ctx, tx)
+	if err != nil {
+		t.Fatalf("approving purchase: %s", err)
+	}
+
+	u.receive(t, ctx, tx, purchase, 1, 1)
+	u.purchaseStatus(t, ctx, tx, purchase.ID, models.PurchasePartiallyReceived)
+
+	purchase = models.Purchase{ID: purchase.ID}
+	err = purchase.Get(ctx, tx)
+	if err != nil {
+		t.Fatalf("getting purchase: %s", err)
+	}
+
+	if err = purchase.Update(ctx, tx); err == nil {
+		t.Fatal("expected update of received purchase to be rejected")
+	}
+
+	err = purchase.Close(ctx, tx)
+	if err != nil {
+		t.Fatalf("closing purchase: %s", err)
+	}
+
+	u.receiveRejected(t, ctx, tx, purchase, product)
+	u.purchaseStatus(t, ctx, tx, purchase.ID, models.PurchaseClosed)
+
+	if exp, got := 1, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand received against approved purchase %v, got %v", exp, got)
+	}
+
+	if diff := cmp.Diff([]float64{100}, u.costs(t, ctx, tx, product.ID)); diff != "" {
+		t.Fatalf("costs did not match expected. Diff:\n%s", diff)
+	}
+}
+
+// receiveRejected check receiving of product against purchase is rejected
+func (u *Ledger) receiveRejected(t *testing.T, ctx context.Context, tx *sql.Tx, purchase models.Purchase, product models.Product) {
+	receive := models.Receive{
+		Date:     time.Now(),
+		Purchase: models.Purchase{ID: purchase.ID},
+		ReceiveDetails: []models.ReceiveDetail{
+			{Product: models.Product{ID: product.ID}, UomQty: 1, Shelve: models.Shelve{ID: 1}},
+		},
+	}
+
+	if err := receive.Create(ctx, tx); err == nil {
+		t.Fatalf("expected receive against purchase with status %s to be rejected", purchase.Status)
+	}
+}
+
+// purchaseStatus check status of purchase
+func (u *Ledger) purchaseStatus(t *testing.T, ctx context.Context, tx *sql.Tx, purchaseID uint64, status string) {
+	purchase := models.Purchase{ID: purchaseID}
+	err := purchase.Get(ctx, tx)
+	if err != nil {
+		t.Fatalf("getting purchase: %s", err)
+	}
+
+	if purchase.Status != status {
+		t.Fatalf("expected purchase status %s, got %s", status, purchase.Status)
+	}
+}
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// PurchaseSuggestion : unit test for suggested quantity of product from its stock on hand, open purchase and minimum stock
+func (u *Ledger) PurchaseSuggestion(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	product := u.product(t, ctx, tx, "SUG-01", true)
+	_, err := tx.ExecContext(ctx, `UPDATE products SET minimum_stock = 5 WHERE id = ?`, product.ID)
+	if err != nil {
+		t.Fatalf("setting minimum stock: %s", err)
+	}
+
+	received := u.purchase(t, ctx, tx, product, 2, 100)
+	u.receive(t, ctx, tx, received, 1, 1, 1)
+	u.purchase(t, ctx, tx, product, 1, 120)
+
+	// draft purchase is neither on order nor the last purchase price
+	draft := models.Purchase{
+		Date:            time.Now(),
+		Supplier:        models.Supplier{ID: 1},
+		PurchaseDetails: []models.PurchaseDetail{{Product: models.Product{ID: product.ID}, Price: 900, UomQty: 10}},
+	}
+	err = draft.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating purchase: %s", err)
+	}
+
+	var suggestion models.PurchaseSuggestion
+	suggestions, err := suggestion.List(ctx, tx)
+	if err != nil {
+		t.Fatalf("listing purchase suggestions: %s", err)
+	}
+
+	var detail *models.PurchaseSuggestionDetail
+	for _, s := range suggestions {
+		for i, d := range s.PurchaseSuggestionDetails {
+			if d.Product.ID == product.ID {
+				detail = &s.PurchaseSuggestionDetails[i]
+			}
+		}
+	}
+
+	if detail == nil {
+		t.Fatal("expected purchase suggestion of product below minimum stock")
+	}
+
+	if detail.Stock != 2 || detail.OnOrder != 1 || detail.Qty != 2 || detail.UnitPrice != 120 {
+		t.Fatalf("expected stock 2, on order 1, qty 2 and unit price 120, got stock %v, on order %v, qty %v and unit price %v",
+			detail.Stock, detail.OnOrder, detail.Qty, detail.UnitPrice)
+	}
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Receipt : unit test for receiving quantity against purchase, over receipt is allowed within tolerance of company
+// and purchase return is limited to quantity which has not been received
+func (u *Ledger) Receipt(t *testing.T) {
+	t.Run("OverReceipt", u.overReceipt)
+	t.Run("PurchaseReturn", u.purchaseReturn)
+}
+
+func (u *Ledger) overReceipt(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	_, err := tx.ExecContext(ctx, `UPDATE companies SET over_receipt_tolerance = 10 WHERE id = ?`, u.UserLogin.Company.ID)
+	if err != nil {
+		t.Fatalf("setting over receipt tolerance: %s", err)
+	}
+
+	product := u.product(t, ctx, tx, "RCP-01", false)
+	purchase := u.purchase(t, ctx, tx, product, 10, 100)
+	u.receive(t, ctx, tx, purchase, 1, 11)
+	u.purchaseReceipt(t, ctx, tx, purchase.ID, 11, 0, 0)
+
+	if exp, got := 11, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand received within tolerance %v, got %v", exp, got)
+	}
+
+	if diff := cmp.Diff([]float64{100}, u.costs(t, ctx, tx, product.ID)); diff != "" {
+		t.Fatalf("costs did not match expected. Diff:\n%s", diff)
+	}
+
+	receive := models.Receive{
+		Date:     time.Now(),
+		Purchase: models.Purchase{ID: purchase.ID},
+		ReceiveDetails: []models.ReceiveDetail{
+			{Product: models.Product{ID: product.ID}, UomQty: 1, Shelve: models.Shelve{ID: 1}},
+		},
+	}
+
+	if err = receive.Create(ctx, tx); err == nil {
+		t.Fatal("expected receive beyond tolerance to be rejected")
+	}
+}
+
+func (u *Ledger) purchaseReturn(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	product := u.product(t, ctx, tx, "RCP-02", false)
+	purchase := u.purchase(t, ctx, tx, product, 10, 100)
+	u.receive(t, ctx, tx, purchase, 1, 6)
+
+	purchaseReturn := models.PurchaseReturn{
+		Date:     time.Now(),
+		Purchase: models.Purchase{ID: purchase.ID},
+		PurchaseReturnDetails: []models.PurchaseReturnDetail{
+			{Product: models.Product{ID: product.ID}, Price: 400, Qty: 4},
+		},
+	}
+
+	err := purchaseReturn.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating purchase return: %s", err)
+	}
+
+	u.purchaseReceipt(t, ctx, tx, purchase.ID, 6, 4, 0)
+
+	if exp, got := 6, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after purchase return %v, got %v", exp, got)
+	}
+
+	purchaseReturn = models.PurchaseReturn{
+		Date:     time.Now(),
+		Purchase: models.Purchase{ID: purchase.ID},
+		PurchaseReturnDetails: []models.PurchaseReturnDetail{
+			{Product: models.Product{ID: product.ID}, Price: 100, Qty: 1},
+		},
+	}
+
+	if err = purchaseReturn.Create(ctx, tx); err == nil {
+		t.Fatal("expected purchase return of received quantity to be rejected")
+	}
+}
+
+// purchaseReceipt check received, returned and remaining quantity of the purchase line
+func (u *Ledger) purchaseReceipt(t *testing.T, ctx context.Context, tx *sql.Tx, purchaseID uint64, received, returned, remaining uint) {
+	purchase := models.Purchase{ID: purchaseID}
+	err := purchase.Get(ctx, tx)
+	if err != nil {
+		t.Fatalf("getting purchase: %s", err)
+	}
+
+	d := purchase.PurchaseDetails[0]
+	if d.ReceivedQty != received || d.ReturnedQty != returned || d.RemainingQty != remaining {
+		t.Fatalf("expected received %v, returned %v and remaining %v, got received %v, returned %v and remaining %v",
+			received, returned, remaining, d.ReceivedQty, d.ReturnedQty, d.RemainingQty)
+	}
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Reservation : unit test for quantity reserved by open sales orders and available to promise,
+// shortage is flagged as backorder or rejected depending on ATP policy of company
+func (u *Ledger) Reservation(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	product := u.product(t, ctx, tx, "ATP-01", true)
+	purchase := u.purchase(t, ctx, tx, product, 3, 100)
+	u.receive(t, ctx, tx, purchase, 1, 1, 1)
+
+	u.availability(t, ctx, tx, product.ID, 2, 0, 1)
+
+	first := u.salesOrder(t, ctx, tx, product, 2)
+	if exp, got := uint(0), first.SalesOrderDetails[0].BackorderQty; exp != got {
+		t.Fatalf("expected backorder of sales order within available to promise %v, got %v", exp, got)
+	}
+
+	u.availability(t, ctx, tx, product.ID, 2, 2, 1)
+
+	second := u.salesOrder(t, ctx, tx, product, 2)
+	if exp, got := uint(1), second.SalesOrderDetails[0].BackorderQty; exp != got {
+		t.Fatalf("expected backorder of sales order exceeding available to promise %v, got %v", exp, got)
+	}
+
+	delivery := u.deliver(t, ctx, tx, first, 1, 1)
+	for _, d := range delivery.DeliveryDetails {
+		if d.Cost != 100 {
+			t.Fatalf("expected cost of goods sold 100, got %v", d.Cost)
+		}
+	}
+
+	u.availability(t, ctx, tx, product.ID, 0, 2, 1)
+
+	err := second.Cancel(ctx, tx)
+	if err != nil {
+		t.Fatalf("cancelling sales order: %s", err)
+	}
+
+	u.availability(t, ctx, tx, product.ID, 0, 0, 1)
+
+	_, err = tx.ExecContext(ctx, `UPDATE companies SET atp_policy = ? WHERE id = ?`, models.ATPReject, u.UserLogin.Company.ID)
+	if err != nil {
+		t.Fatalf("setting ATP policy: %s", err)
+	}
+
+	rejected := models.SalesOrder{
+		Date:     time.Now(),
+		Salesman: models.Salesman{ID: u.Salesman.ID},
+		Customer: models.Customer{ID: u.Customer.ID},
+		SalesOrderDetails: []models.SalesOrderDetail{
+			{Product: models.Product{ID: product.ID}, Price: product.SalePrice * 2, UomQty: 2},
+		},
+	}
+
+	if err = rejected.Create(ctx, tx); err == nil {
+		t.Fatal("expected sales order exceeding available to promise to be rejected")
+	}
+}
+
+// availability check quantity on hand, reserved and incoming of product in branch of user login
+func (u *Ledger) availability(t *testing.T, ctx context.Context, tx *sql.Tx, productID uint64, qty, reserved, incoming int) {
+	stock := models.Stock{Product: models.Product{ID: productID}}
+	err := stock.Get(ctx, tx)
+	if err != nil {
+		t.Fatalf("getting stock: %s", err)
+	}
+
+	if stock.Qty != qty || stock.Reserved != reserved || stock.Incoming != incoming {
+		t.Fatalf("expected on hand %v, reserved %v and incoming %v, got on hand %v, reserved %v and incoming %v",
+			qty, reserved, incoming, stock.Qty, stock.Reserved, stock.Incoming)
+	}
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Scan : unit test for status of scanned unit code following its movements, voided delivery puts it back in stock
+func (u *Ledger) Scan(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	product := u.product(t, ctx, tx, "SCN-01", true)
+	purchase := u.purchase(t, ctx, tx, product, 1, 100)
+	receive := u.receive(t, ctx, tx, purchase, 1, 1)
+	code := receive.ReceiveDetails[0].Code
+
+	u.scanStatus(t, ctx, tx, code, models.ScanStatusInStock, 1)
+
+	salesOrder := u.salesOrder(t, ctx, tx, product, 1)
+	delivery := u.deliver(t, ctx, tx, salesOrder, 1)
+	scan := u.scanStatus(t, ctx, tx, code, models.ScanStatusDelivered, 0)
+
+	var linked bool
+	for _, l := range scan.Links {
+		if l.Type == models.ScanDelivery && l.ID == delivery.ID {
+			linked = true
+		}
+	}
+
+	if !linked {
+		t.Fatalf("expected scanned unit code linked to delivery %v, got %v", delivery.ID, scan.Links)
+	}
+
+	delivery.Voided.Reason = "Wrong customer"
+	err := delivery.Void(ctx, tx)
+	if err != nil {
+		t.Fatalf("voiding delivery: %s", err)
+	}
+
+	u.scanStatus(t, ctx, tx, code, models.ScanStatusInStock, 1)
+
+	if exp, got := 1, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after voided delivery %v, got %v", exp, got)
+	}
+}
+
+// scanStatus return scanned unit code after checking its status and quantity on hand
+func (u *Ledger) scanStatus(t *testing.T, ctx context.Context, tx *sql.Tx, code, status string, qty int) models.Scan {
+	scan := models.Scan{Code: code}
+	scans, err := scan.List(ctx, tx)
+	if err != nil {
+		t.Fatalf("scanning unit code: %s", err)
+	}
+
+	for _, s := range scans {
+		if s.Type != models.ScanUnit {
+			continue
+		}
+
+		if s.Status != status || s.Qty != qty {
+			t.Fatalf("expected scanned unit code %s with qty %v, got %s with qty %v", status, qty, s.Status, s.Qty)
+		}
+
+		return s
+	}
+
+	t.Fatalf("expected scanned code %s resolved as unit code", code)
+	return scan
+}
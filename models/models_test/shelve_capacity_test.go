@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// ShelveCapacity : unit test for used capacity of shelve, posting unit code into a full shelve is rejected
+func (u *Ledger) ShelveCapacity(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	shelve := models.Shelve{Code: "SHV-CAP", Capacity: 2}
+	err := shelve.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating shelve: %s", err)
+	}
+
+	product := u.product(t, ctx, tx, "CAP-01", true)
+	purchase := u.purchase(t, ctx, tx, product, 3, 100)
+	u.receive(t, ctx, tx, purchase, shelve.ID, 1, 1)
+
+	var stock models.Stock
+	utilizations, err := stock.ListShelveUtilization(ctx, tx)
+	if err != nil {
+		t.Fatalf("listing shelve utilization: %s", err)
+	}
+
+	var used uint
+	for _, utilization := range utilizations {
+		for _, usage := range utilization.Shelves {
+			if usage.Shelve.ID == shelve.ID {
+				used = usage.Used
+			}
+		}
+	}
+
+	if exp, got := uint(2), used; exp != got {
+		t.Fatalf("expected used capacity %v, got %v", exp, got)
+	}
+
+	if exp, got := 2, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand %v, got %v", exp, got)
+	}
+
+	receive := models.Receive{
+		Date:     time.Now(),
+		Purchase: models.Purchase{ID: purchase.ID},
+		ReceiveDetails: []models.ReceiveDetail{
+			{Product: models.Product{ID: product.ID}, UomQty: 1, Shelve: models.Shelve{ID: shelve.ID}},
+		},
+	}
+
+	if err = receive.Create(ctx, tx); err == nil {
+		t.Fatal("expected receive into full shelve to be rejected")
+	}
+
+	// part of bulk unit code moved into another shelve only uses the moved quantity of it
+	partial := models.Shelve{Code: "SHV-CAP-2", Capacity: 5}
+	err = partial.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating shelve: %s", err)
+	}
+
+	bulk := u.product(t, ctx, tx, "CAP-02", false)
+	bulkPurchase := u.purchase(t, ctx, tx, bulk, 4, 100)
+	bulkReceive := u.receive(t, ctx, tx, bulkPurchase, 1, 4)
+	mutation := models.Mutation{
+		Date: time.Now(),
+		MutationDetails: []models.MutationDetail{
+			{Product: models.Product{ID: bulk.ID}, Code: bulkReceive.ReceiveDetails[0].Code, ShelveTo: models.Shelve{ID: partial.ID}, Qty: 1},
+		},
+	}
+
+	err = mutation.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating mutation: %s", err)
+	}
+
+	used, err = partial.GetUsed(ctx, tx)
+	if err != nil {
+		t.Fatalf("getting used capacity: %s", err)
+	}
+
+	if exp, got := uint(1), used; exp != got {
+		t.Fatalf("expected used capacity of shelve with partial unit code %v, got %v", exp, got)
+	}
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// StockOpname : unit test for posting variance of physical count, missing unit is taken out with its cost,
+// unit code which is moved after the count sheet is created is not posted as missing
+// and unit code which is in stock on another shelve can not be counted as found
+func (u *Ledger) StockOpname(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	shelve := models.Shelve{Code: "SHV-OPN", Capacity: 10}
+	err := shelve.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating shelve: %s", err)
+	}
+
+	product := u.product(t, ctx, tx, "OPN-01", true)
+	purchase := u.purchase(t, ctx, tx, product, 4, 100)
+	counted := u.receive(t, ctx, tx, purchase, shelve.ID, 1, 1, 1)
+	other := u.receive(t, ctx, tx, purchase, 1, 1)
+
+	stockOpname := models.StockOpname{Date: time.Now(), Shelves: []models.Shelve{{ID: shelve.ID}}}
+	err = stockOpname.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating stock opname: %s", err)
+	}
+
+	if exp, got := 3, len(stockOpname.StockOpnameDetails); exp != got {
+		t.Fatalf("expected count sheet lines %v, got %v", exp, got)
+	}
+
+	mutation := models.Mutation{
+		Date: time.Now(),
+		MutationDetails: []models.MutationDetail{
+			{Product: models.Product{ID: product.ID}, Code: counted.ReceiveDetails[2].Code, ShelveTo: models.Shelve{ID: 1}, Qty: 1},
+		},
+	}
+
+	err = mutation.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating mutation: %s", err)
+	}
+
+	found := []models.StockOpnameDetail{
+		{Product: models.Product{ID: product.ID}, Code: other.ReceiveDetails[0].Code, Shelve: models.Shelve{ID: shelve.ID}, CountedQty: 1},
+	}
+	if err = stockOpname.Count(ctx, tx, found); err == nil {
+		t.Fatal("expected unit code in stock on another shelve to be rejected as found unit")
+	}
+
+	counts := []models.StockOpnameDetail{
+		{Product: models.Product{ID: product.ID}, Code: counted.ReceiveDetails[0].Code, CountedQty: 1},
+		{Product: models.Product{ID: product.ID}, Code: counted.ReceiveDetails[1].Code, CountedQty: 0},
+		{Product: models.Product{ID: product.ID}, Code: counted.ReceiveDetails[2].Code, CountedQty: 0},
+	}
+	err = stockOpname.Count(ctx, tx, counts)
+	if err != nil {
+		t.Fatalf("counting stock opname: %s", err)
+	}
+
+	err = stockOpname.Approve(ctx, tx)
+	if err != nil {
+		t.Fatalf("approving stock opname: %s", err)
+	}
+
+	if exp, got := 3, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after variance posting %v, got %v", exp, got)
+	}
+
+	var postings int
+	var cost float64
+	err = tx.QueryRowContext(ctx,
+		`SELECT COUNT(*), IFNULL(SUM(cost), 0) FROM inventories WHERE type = 'OP' AND transaction_id = ? AND in_out = 0`,
+		stockOpname.ID).Scan(&postings, &cost)
+	if err != nil {
+		t.Fatalf("counting variance postings: %s", err)
+	}
+
+	if postings != 1 || cost != 100 {
+		t.Fatalf("expected missing unit taken out 1 with cost 100, got %v with cost %v", postings, cost)
+	}
+}
@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// StockOnHand : unit test for stock on hand and units of product after receiving
+func (u *Ledger) StockOnHand(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	product := u.product(t, ctx, tx, "STK-01", true)
+	purchase := u.purchase(t, ctx, tx, product, 3, 100)
+	u.receive(t, ctx, tx, purchase, 1, 1, 1, 1)
+
+	if exp, got := 3, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after receiving %v, got %v", exp, got)
+	}
+
+	stock := models.Stock{Branch: models.Branch{ID: u.UserLogin.Branch.ID}, Product: models.Product{ID: product.ID}}
+	err := stock.ListUnits(ctx, tx)
+	if err != nil {
+		t.Fatalf("listing units: %s", err)
+	}
+
+	if exp, got := 3, len(stock.Units); exp != got {
+		t.Fatalf("expected units on hand %v, got %v", exp, got)
+	}
+}
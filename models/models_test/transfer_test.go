@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Transfer : unit test for transferring unit code to another branch, it is in transit until the destination branch receives it
+func (u *Ledger) Transfer(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	product := u.product(t, ctx, tx, "TRF-01", true)
+	purchase := u.purchase(t, ctx, tx, product, 1, 100)
+	receive := u.receive(t, ctx, tx, purchase, 1, 1)
+	code := receive.ReceiveDetails[0].Code
+
+	branch := models.Branch{Code: "TRF", Name: "Toko Transfer", Type: "s"}
+	err := branch.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating branch: %s", err)
+	}
+
+	destination := u.UserLogin
+	destination.Branch = branch
+	destinationCtx := context.WithValue(ctx, api.Ctx("auth"), destination)
+
+	shelve := models.Shelve{Code: "SHV-TRF", Capacity: 10}
+	err = shelve.Create(destinationCtx, tx)
+	if err != nil {
+		t.Fatalf("creating shelve: %s", err)
+	}
+
+	transfer := models.Transfer{
+		Date:              time.Now(),
+		DestinationBranch: models.Branch{ID: branch.ID},
+		TransferDetails:   []models.TransferDetail{{Product: models.Product{ID: product.ID}, Code: code, Qty: 1}},
+	}
+
+	err = transfer.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating transfer: %s", err)
+	}
+
+	if exp, got := 0, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand of source branch in transit %v, got %v", exp, got)
+	}
+
+	inTransit, err := transfer.ListInTransit(ctx, tx)
+	if err != nil {
+		t.Fatalf("listing in transit: %s", err)
+	}
+
+	if exp, got := 1, len(inTransit); exp != got {
+		t.Fatalf("expected unit codes in transit %v, got %v", exp, got)
+	}
+
+	transferReceive := models.TransferReceive{
+		Date:     time.Now(),
+		Transfer: models.Transfer{ID: transfer.ID},
+		TransferReceiveDetails: []models.TransferReceiveDetail{
+			{Product: models.Product{ID: product.ID}, Code: code, Shelve: models.Shelve{ID: shelve.ID}},
+		},
+	}
+
+	err = transferReceive.Create(destinationCtx, tx)
+	if err != nil {
+		t.Fatalf("creating transfer receive: %s", err)
+	}
+
+	if exp, got := 1, u.onHand(t, destinationCtx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand of destination branch %v, got %v", exp, got)
+	}
+
+	if diff := cmp.Diff([]float64{100, 100, 100}, u.costs(t, ctx, tx, product.ID)); diff != "" {
+		t.Fatalf("costs did not match expected. Diff:\n%s", diff)
+	}
+}
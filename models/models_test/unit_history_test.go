@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jacky-htg/inventory/models"
+)
+
+// UnitHistory : unit test for movements of unit code traced to its purchase and sales order
+func (u *Ledger) UnitHistory(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	product := u.product(t, ctx, tx, "HIS-01", true)
+	purchase := u.purchase(t, ctx, tx, product, 1, 100)
+	receive := u.receive(t, ctx, tx, purchase, 1, 1)
+
+	shelve := models.Shelve{Code: "SHV-HIS", Capacity: 10}
+	err := shelve.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating shelve: %s", err)
+	}
+
+	mutation := models.Mutation{
+		Date: time.Now(),
+		MutationDetails: []models.MutationDetail{
+			{Product: models.Product{ID: product.ID}, Code: receive.ReceiveDetails[0].Code, ShelveTo: models.Shelve{ID: shelve.ID}, Qty: 1},
+		},
+	}
+
+	err = mutation.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating mutation: %s", err)
+	}
+
+	salesOrder := u.salesOrder(t, ctx, tx, product, 1)
+	u.deliver(t, ctx, tx, salesOrder, 1)
+
+	unitHistory := models.UnitHistory{ProductCode: receive.ReceiveDetails[0].Code}
+	histories, err := unitHistory.List(ctx, tx)
+	if err != nil {
+		t.Fatalf("listing unit history: %s", err)
+	}
+
+	var types []string
+	var qty int
+	for _, h := range histories {
+		types = append(types, h.Type)
+		if h.InOut {
+			qty += int(h.Qty)
+		} else {
+			qty -= int(h.Qty)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"GR", "MU", "MU", "DO"}, types); diff != "" {
+		t.Fatalf("movements did not match expected. Diff:\n%s", diff)
+	}
+
+	if qty != 0 {
+		t.Fatalf("expected delivered unit code has no quantity on hand, got %v", qty)
+	}
+
+	if histories[2].Shelve.ID != shelve.ID {
+		t.Fatalf("expected unit code moved to shelve %v, got %v", shelve.ID, histories[2].Shelve.ID)
+	}
+
+	if histories[0].Purchase.ID != purchase.ID || histories[3].SalesOrder.ID != salesOrder.ID {
+		t.Fatalf("expected movements traced to purchase %v and sales order %v, got %v and %v",
+			purchase.ID, salesOrder.ID, histories[0].Purchase.ID, histories[3].SalesOrder.ID)
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Uom : unit test for quantity entered in unit of measure of product, it is converted into base unit
+// before posting and unit which is not configured for the product is rejected
+func (u *Ledger) Uom(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	uoms := make(map[string]models.Uom)
+	for _, code := range []string{"PCS", "BOX", "KG"} {
+		uom := models.Uom{Code: code, Name: code}
+		err := uom.Create(ctx, tx)
+		if err != nil {
+			t.Fatalf("creating uom %s: %s", code, err)
+		}
+
+		uoms[code] = uom
+	}
+
+	product := models.Product{
+		Code:            "UOM-01",
+		Name:            "UOM-01",
+		SalePrice:       1000,
+		Brand:           models.Brand{ID: 1},
+		ProductCategory: models.ProductCategory{ID: 1},
+		Uom:             models.Uom{ID: uoms["PCS"].ID},
+	}
+
+	err := product.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating product: %s", err)
+	}
+
+	productUom := models.ProductUom{Product: product, Uom: models.Uom{ID: uoms["PCS"].ID}, Conversion: 10}
+	if err = productUom.Save(ctx, tx); err == nil {
+		t.Fatal("expected conversion of base unit to be rejected")
+	}
+
+	productUom.Uom = models.Uom{ID: uoms["BOX"].ID}
+	err = productUom.Save(ctx, tx)
+	if err != nil {
+		t.Fatalf("saving conversion: %s", err)
+	}
+
+	uom := models.Uom{ID: uoms["BOX"].ID}
+	qty, err := product.ConvertQty(ctx, tx, &uom, 3)
+	if err != nil {
+		t.Fatalf("converting quantity: %s", err)
+	}
+
+	if qty != 30 || uom.Code != "BOX" {
+		t.Fatalf("expected 3 BOX converted into 30, got %v of %s", qty, uom.Code)
+	}
+
+	if _, err = product.ConvertQty(ctx, tx, &models.Uom{ID: uoms["KG"].ID}, 1); err == nil {
+		t.Fatal("expected unit of measure which is not configured for product to be rejected")
+	}
+
+	purchase := models.Purchase{
+		Date:     time.Now(),
+		Supplier: models.Supplier{ID: 1},
+		PurchaseDetails: []models.PurchaseDetail{
+			{Product: models.Product{ID: product.ID}, Price: 2000, Uom: models.Uom{ID: uoms["BOX"].ID}, UomQty: 2},
+		},
+	}
+
+	err = purchase.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating purchase: %s", err)
+	}
+
+	err = purchase.Submit(ctx, tx)
+	if err != nil {
+		t.Fatalf("submitting purchase: %s", err)
+	}
+
+	err = purchase.Approve(ctx, tx)
+	if err != nil {
+		t.Fatalf("approving purchase: %s", err)
+	}
+
+	if exp, got := uint(20), purchase.PurchaseDetails[0].Qty; exp != got {
+		t.Fatalf("expected purchase quantity in base unit %v, got %v", exp, got)
+	}
+
+	receive := models.Receive{
+		Date:     time.Now(),
+		Purchase: models.Purchase{ID: purchase.ID},
+		ReceiveDetails: []models.ReceiveDetail{
+			{Product: models.Product{ID: product.ID}, Uom: models.Uom{ID: uoms["BOX"].ID}, UomQty: 1, Shelve: models.Shelve{ID: 1}},
+		},
+	}
+
+	err = receive.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating receive: %s", err)
+	}
+
+	if exp, got := 10, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after receiving 1 BOX %v, got %v", exp, got)
+	}
+
+	salesOrder := u.salesOrder(t, ctx, tx, product, 3)
+	delivery := u.deliver(t, ctx, tx, salesOrder, 3)
+	if d := delivery.DeliveryDetails[0]; d.Qty != 3 || d.Code != receive.ReceiveDetails[0].Code {
+		t.Fatalf("expected 3 delivered from unit code %s, got %v from %s", receive.ReceiveDetails[0].Code, d.Qty, d.Code)
+	}
+
+	if exp, got := 7, u.onHand(t, ctx, tx, product.ID); exp != got {
+		t.Fatalf("expected on hand after delivery %v, got %v", exp, got)
+	}
+}
@@ -43,6 +43,34 @@ func TestMain(t *testing.T) {
 	user := User{Db: db, UserLogin: userLogin}
 	t.Run("List", user.List)
 	t.Run("Crud", user.Crud)
+
+	ledger := newLedger(t, db)
+	t.Run("StockOnHand", ledger.StockOnHand)
+	t.Run("Mutation", ledger.Mutation)
+	t.Run("Transfer", ledger.Transfer)
+	t.Run("StockOpname", ledger.StockOpname)
+	t.Run("PurchaseSuggestion", ledger.PurchaseSuggestion)
+	t.Run("UnitHistory", ledger.UnitHistory)
+	t.Run("Picking", ledger.Picking)
+	t.Run("Expiry", ledger.Expiry)
+	t.Run("ShelveCapacity", ledger.ShelveCapacity)
+	t.Run("ClosedPeriod", ledger.ClosedPeriod)
+	t.Run("OpeningBalance", ledger.OpeningBalance)
+	t.Run("Valuation", ledger.Valuation)
+	t.Run("Bulk", ledger.Bulk)
+	t.Run("Lot", ledger.Lot)
+	t.Run("Uom", ledger.Uom)
+	t.Run("Variant", ledger.Variant)
+	t.Run("Label", ledger.Label)
+	t.Run("Scan", ledger.Scan)
+	t.Run("Reservation", ledger.Reservation)
+	t.Run("Fulfillment", ledger.Fulfillment)
+	t.Run("PurchaseLifecycle", ledger.PurchaseLifecycle)
+	t.Run("Approval", ledger.Approval)
+	t.Run("Receipt", ledger.Receipt)
+	t.Run("Void", ledger.Void)
+	t.Run("AuditLog", ledger.AuditLog)
+	t.Run("BulkPicking", ledger.BulkPicking)
 }
 
 //Crud : unit test  for create get and delete user function
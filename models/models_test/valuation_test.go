@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Valuation : unit test for cost of goods sold of delivery and value of stock on hand by FIFO and moving average
+func (u *Ledger) Valuation(t *testing.T) {
+	for _, tt := range []struct {
+		method string
+		cogs   float64
+		value  float64
+	}{
+		{models.ValuationFIFO, 100, 200},
+		{models.ValuationAverage, 150, 150},
+	} {
+		t.Run(tt.method, func(t *testing.T) {
+			ctx, tx := u.begin(t)
+			defer tx.Rollback()
+
+			u.valuationMethod(t, ctx, tx, tt.method)
+			product := u.product(t, ctx, tx, "VAL-01", true)
+			for _, price := range []float64{100, 200} {
+				purchase := u.purchase(t, ctx, tx, product, 1, price)
+				u.receive(t, ctx, tx, purchase, 1, 1)
+			}
+
+			salesOrder := u.salesOrder(t, ctx, tx, product, 1)
+			delivery := u.deliver(t, ctx, tx, salesOrder, 1)
+			if exp, got := tt.cogs, delivery.DeliveryDetails[0].Cost; exp != got {
+				t.Fatalf("expected cost of goods sold %v, got %v", exp, got)
+			}
+
+			var stock models.Stock
+			valuations, err := stock.ListValuation(ctx, tx, time.Now())
+			if err != nil {
+				t.Fatalf("listing stock valuation: %s", err)
+			}
+
+			var valuation *models.ProductValuation
+			for _, v := range valuations {
+				for i, p := range v.Products {
+					if p.Product.ID == product.ID {
+						valuation = &v.Products[i]
+					}
+				}
+			}
+
+			if valuation == nil {
+				t.Fatal("expected valuation of product on hand")
+			}
+
+			if valuation.Qty != 1 || valuation.Value != tt.value {
+				t.Fatalf("expected qty 1 valued %v, got qty %v valued %v", tt.value, valuation.Qty, valuation.Value)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Variant : unit test for variants generated from attribute values of product template,
+// stock of variants is rolled up to the template and attribute can not be added once variants exist
+func (u *Ledger) Variant(t *testing.T) {
+	ctx, tx := u.begin(t)
+	defer tx.Rollback()
+
+	template := models.ProductTemplate{
+		Code:            "TPL-01",
+		Name:            "Template Test",
+		SalePrice:       1000,
+		Serialized:      true,
+		Brand:           models.Brand{ID: 1},
+		ProductCategory: models.ProductCategory{ID: 1},
+		Attributes: []models.ProductAttribute{
+			{Name: "Size", Values: []models.ProductAttributeValue{{Value: "S"}, {Value: "M"}}},
+			{Name: "Color", Values: []models.ProductAttributeValue{{Value: "Red"}}},
+		},
+	}
+
+	err := template.Create(ctx, tx)
+	if err != nil {
+		t.Fatalf("creating product template: %s", err)
+	}
+
+	variants, err := template.GenerateVariants(ctx, tx)
+	if err != nil {
+		t.Fatalf("generating variants: %s", err)
+	}
+
+	codes := make(map[string]models.Product)
+	for _, v := range variants {
+		codes[v.Code] = v
+	}
+
+	small, okSmall := codes["TPL-01-S-Red"]
+	medium, okMedium := codes["TPL-01-M-Red"]
+	if len(variants) != 2 || !okSmall || !okMedium {
+		t.Fatalf("expected variants TPL-01-S-Red and TPL-01-M-Red, got %v", codes)
+	}
+
+	variants, err = template.GenerateVariants(ctx, tx)
+	if err != nil {
+		t.Fatalf("generating variants again: %s", err)
+	}
+
+	if exp, got := 0, len(variants); exp != got {
+		t.Fatalf("expected new variants of generated template %v, got %v", exp, got)
+	}
+
+	purchase := u.purchase(t, ctx, tx, small, 2, 100)
+	u.receive(t, ctx, tx, purchase, 1, 1, 1)
+	purchase = u.purchase(t, ctx, tx, medium, 1, 100)
+	u.receive(t, ctx, tx, purchase, 1, 1)
+
+	var stock models.Stock
+	list, err := stock.List(ctx, tx)
+	if err != nil {
+		t.Fatalf("listing stocks: %s", err)
+	}
+
+	var rollup *models.TemplateStock
+	templates := stock.RollupTemplates(list)
+	for i, s := range templates {
+		if s.Template.ID == template.ID {
+			rollup = &templates[i]
+		}
+	}
+
+	if rollup == nil {
+		t.Fatal("expected stock rolled up to product template")
+	}
+
+	if rollup.Qty != 3 || len(rollup.Variants) != 2 {
+		t.Fatalf("expected template on hand 3 of 2 variants, got %v of %v variants", rollup.Qty, len(rollup.Variants))
+	}
+
+	template.Attributes = append(template.Attributes, models.ProductAttribute{
+		Name:   "Material",
+		Values: []models.ProductAttributeValue{{Value: "Cotton"}},
+	})
+	if err = template.Update(ctx, tx); err == nil {
+		t.Fatal("expected new attribute of template with variants to be rejected")
+	}
+}
@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jacky-htg/inventory/models"
+)
+
+// Void : unit test for voiding receive, its unit code is taken out by reversal movement
+// and moving average is recalculated without the voided receiving cost
+func (u *Ledger) Void(t *testing.T) {
+	for _, tt := range []struct {
+		method string
+		costs  []float64
+	}{
+		{models.ValuationFIFO, []float64{100, 200, 200, 100}},
+		{models.ValuationAverage, []float64{100, 150, 100, 100}},
+	} {
+		t.Run(tt.method, func(t *testing.T) {
+			ctx, tx := u.begin(t)
+			defer tx.Rollback()
+
+			u.valuationMethod(t, ctx, tx, tt.method)
+			product := u.product(t, ctx, tx, "VOI-01", true)
+			first := u.purchase(t, ctx, tx, product, 1, 100)
+			u.receive(t, ctx, tx, first, 1, 1)
+			second := u.purchase(t, ctx, tx, product, 1, 200)
+			receive := u.receive(t, ctx, tx, second, 1, 1)
+
+			receive.Voided.Reason = "Wrong supplier"
+			err := receive.Void(ctx, tx)
+			if err != nil {
+				t.Fatalf("voiding receive: %s", err)
+			}
+
+			if exp, got := 1, u.onHand(t, ctx, tx, product.ID); exp != got {
+				t.Fatalf("expected on hand after voided receive %v, got %v", exp, got)
+			}
+
+			var reversals int
+			err = tx.QueryRowContext(ctx,
+				`SELECT COUNT(*) FROM inventories WHERE type = 'GR' AND transaction_id = ? AND reversal = 1 AND in_out = 0`,
+				receive.ID).Scan(&reversals)
+			if err != nil {
+				t.Fatalf("counting reversals: %s", err)
+			}
+
+			if exp, got := 1, reversals; exp != got {
+				t.Fatalf("expected reversal movements of voided receive %v, got %v", exp, got)
+			}
+
+			salesOrder := u.salesOrder(t, ctx, tx, product, 1)
+			delivery := u.deliver(t, ctx, tx, salesOrder, 1)
+			if exp, got := float64(100), delivery.DeliveryDetails[0].Cost; exp != got {
+				t.Fatalf("expected cost of goods sold %v, got %v", exp, got)
+			}
+
+			if diff := cmp.Diff(tt.costs, u.costs(t, ctx, tx, product.ID)); diff != "" {
+				t.Fatalf("costs did not match expected. Diff:\n%s", diff)
+			}
+		})
+	}
+}
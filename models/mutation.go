@@ -0,0 +1,672 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/libraries/array"
+)
+
+// Mutation : struct of internal warehouse Mutation
+type Mutation struct {
+	ID              uint64
+	Code            string
+	Date            time.Time
+	Remark          string
+	Company         Company
+	Branch          Branch
+	MutationDetails []MutationDetail
+}
+
+// MutationDetail struct
+type MutationDetail struct {
+	ID         uint64
+	Product    Product
+	Qty        uint
+	Code       string
+	ShelveFrom Shelve
+	ShelveTo   Shelve
+}
+
+// List Mutations
+func (u *Mutation) List(ctx context.Context, tx *sql.Tx) ([]Mutation, error) {
+	var list []Mutation
+	var err error
+
+	query := `
+	SELECT 	mutations.id,
+		mutations.code,
+		mutations.date,
+		mutations.remark,
+		companies.id,
+		companies.code,
+		companies.name,
+		companies.address,
+		branches.id,
+		branches.code,
+		branches.name,
+		branches.address,
+		branches.type
+	FROM mutations
+	JOIN companies ON mutations.company_id = companies.id
+	JOIN branches ON mutations.branch_id = branches.id
+	WHERE companies.id=?
+	`
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	params := []interface{}{userLogin.Company.ID}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=?")
+			params = append(params, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND branches.id=?"
+		params = append(params, userLogin.Branch.ID)
+	}
+
+	rows, err := tx.QueryContext(ctx, query, params...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var mutation Mutation
+		err = rows.Scan(
+			&mutation.ID,
+			&mutation.Code,
+			&mutation.Date,
+			&mutation.Remark,
+			&mutation.Company.ID,
+			&mutation.Company.Code,
+			&mutation.Company.Name,
+			&mutation.Company.Address,
+			&mutation.Branch.ID,
+			&mutation.Branch.Code,
+			&mutation.Branch.Name,
+			&mutation.Branch.Address,
+			&mutation.Branch.Type,
+		)
+
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, mutation)
+	}
+
+	return list, rows.Err()
+}
+
+// Get Mutation by id
+func (u *Mutation) Get(ctx context.Context, tx *sql.Tx) error {
+	query := `
+	SELECT 	mutations.id,
+		mutations.code,
+		mutations.date,
+		mutations.remark,
+		companies.id,
+		companies.code,
+		companies.name,
+		companies.address,
+		branches.id,
+		branches.code,
+		branches.name,
+		branches.address,
+		branches.type,
+		JSON_ARRAYAGG(mutation_details.id),
+		JSON_ARRAYAGG(mutation_details.code),
+		JSON_ARRAYAGG(mutation_details.qty),
+		JSON_ARRAYAGG(shelves_from.id),
+		JSON_ARRAYAGG(shelves_from.code),
+		JSON_ARRAYAGG(shelves_to.id),
+		JSON_ARRAYAGG(shelves_to.code),
+		JSON_ARRAYAGG(products.id),
+		JSON_ARRAYAGG(products.code),
+		JSON_ARRAYAGG(products.name),
+		JSON_ARRAYAGG(products.sale_price)
+	FROM mutations
+	JOIN companies ON mutations.company_id = companies.id
+	JOIN branches ON mutations.branch_id = branches.id
+	JOIN mutation_details ON mutations.id = mutation_details.mutation_id
+	JOIN shelves shelves_from ON mutation_details.shelve_from_id = shelves_from.id
+	JOIN shelves shelves_to ON mutation_details.shelve_to_id = shelves_to.id
+	JOIN products ON mutation_details.product_id = products.id
+	WHERE mutations.id=? AND companies.id=?
+	`
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	params := []interface{}{u.ID, userLogin.Company.ID}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=?")
+			params = append(params, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND branches.id=?"
+		params = append(params, userLogin.Branch.ID)
+	}
+
+	var detailID, detailCode, detailQty, shelveFromID, shelveFromCode, shelveToID, shelveToCode, productID, productCode, productName, productPrice string
+	err := tx.QueryRowContext(ctx, query+" GROUP BY mutations.id", params...).Scan(
+		&u.ID,
+		&u.Code,
+		&u.Date,
+		&u.Remark,
+		&u.Company.ID,
+		&u.Company.Code,
+		&u.Company.Name,
+		&u.Company.Address,
+		&u.Branch.ID,
+		&u.Branch.Code,
+		&u.Branch.Name,
+		&u.Branch.Address,
+		&u.Branch.Type,
+		&detailID,
+		&detailCode,
+		&detailQty,
+		&shelveFromID,
+		&shelveFromCode,
+		&shelveToID,
+		&shelveToCode,
+		&productID,
+		&productCode,
+		&productName,
+		&productPrice,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	u.Branch.Company = u.Company
+
+	if len(detailID) > 0 {
+		var detailIDs []uint64
+		err = json.Unmarshal([]byte(detailID), &detailIDs)
+		if err != nil {
+			return err
+		}
+
+		var detailCodes []string
+		err = json.Unmarshal([]byte(detailCode), &detailCodes)
+		if err != nil {
+			return err
+		}
+
+		var detailQtys []uint
+		err = json.Unmarshal([]byte(detailQty), &detailQtys)
+		if err != nil {
+			return err
+		}
+
+		var shelveFromIDs []uint64
+		err = json.Unmarshal([]byte(shelveFromID), &shelveFromIDs)
+		if err != nil {
+			return err
+		}
+
+		var shelveFromCodes []string
+		err = json.Unmarshal([]byte(shelveFromCode), &shelveFromCodes)
+		if err != nil {
+			return err
+		}
+
+		var shelveToIDs []uint64
+		err = json.Unmarshal([]byte(shelveToID), &shelveToIDs)
+		if err != nil {
+			return err
+		}
+
+		var shelveToCodes []string
+		err = json.Unmarshal([]byte(shelveToCode), &shelveToCodes)
+		if err != nil {
+			return err
+		}
+
+		var productIDs []uint64
+		err = json.Unmarshal([]byte(productID), &productIDs)
+		if err != nil {
+			return err
+		}
+
+		var productCodes []string
+		err = json.Unmarshal([]byte(productCode), &productCodes)
+		if err != nil {
+			return err
+		}
+
+		var productNames []string
+		err = json.Unmarshal([]byte(productName), &productNames)
+		if err != nil {
+			return err
+		}
+
+		var productPrices []float64
+		err = json.Unmarshal([]byte(productPrice), &productPrices)
+		if err != nil {
+			return err
+		}
+
+		for i, v := range detailIDs {
+			u.MutationDetails = append(u.MutationDetails, MutationDetail{
+				ID:   v,
+				Code: detailCodes[i],
+				Qty:  detailQtys[i],
+				ShelveFrom: Shelve{
+					ID:   shelveFromIDs[i],
+					Code: shelveFromCodes[i],
+				},
+				ShelveTo: Shelve{
+					ID:   shelveToIDs[i],
+					Code: shelveToCodes[i],
+				},
+				Product: Product{
+					ID:        productIDs[i],
+					Code:      productCodes[i],
+					Name:      productNames[i],
+					SalePrice: productPrices[i],
+					Company:   u.Company,
+				},
+			})
+		}
+	}
+
+	return nil
+}
+
+// Create new Mutation
+func (u *Mutation) Create(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Branch.ID <= 0 {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	if err := checkClosedPeriod(ctx, tx, u.Date); err != nil {
+		return err
+	}
+
+	const query = `
+		INSERT INTO mutations (code, date, remark, company_id, branch_id, created_by, updated_by, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+	`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	u.Code, err = api.GetCode(ctx, tx, "MU", "mutations", userLogin.Company.ID)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, u.Code, u.Date, u.Remark, userLogin.Company.ID, userLogin.Branch.ID, userLogin.ID, userLogin.ID)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	u.ID = uint64(id)
+	u.Company = userLogin.Company
+	u.Branch = userLogin.Branch
+	u.Branch.Company = u.Company
+
+	for i, d := range u.MutationDetails {
+		err = u.storeDetail(ctx, tx, d, i)
+		if err != nil {
+			return err
+		}
+
+		u.MutationDetails[i].Product.Get(ctx, tx)
+	}
+
+	return nil
+}
+
+// Update Mutation
+func (u *Mutation) Update(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Company.ID != u.Company.ID || u.Branch.ID <= 0 || userLogin.Branch.ID != u.Branch.ID {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	if err := checkClosedTransaction(ctx, tx, "mutations", u.ID, u.Date); err != nil {
+		return err
+	}
+
+	const query = `
+		UPDATE mutations
+		SET date = ?,
+			remark = ?,
+			updated_by = ?,
+			updated = NOW()
+		WHERE id = ?
+		AND company_id = ?
+		AND branch_id = ?
+	`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, u.Date, u.Remark, userLogin.ID, u.ID, userLogin.Company.ID, userLogin.Branch.ID)
+	if err != nil {
+		return err
+	}
+
+	existingDetails, err := u.GetExistingDetails(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	for i, d := range u.MutationDetails {
+		if d.ID <= 0 {
+			err = u.storeDetail(ctx, tx, d, i)
+			if err != nil {
+				return err
+			}
+		} else {
+			err = u.updateDetail(ctx, tx, d, i)
+			if err != nil {
+				return err
+			}
+
+			var arrUint64 array.ArrUint64
+			existingDetails = arrUint64.Remove(existingDetails, d.ID)
+		}
+
+		u.MutationDetails[i].Product.Get(ctx, tx)
+	}
+
+	for _, e := range existingDetails {
+		err = u.removeDetail(ctx, tx, e)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetExistingDetails return array of existing mutation_details id
+func (u *Mutation) GetExistingDetails(ctx context.Context, tx *sql.Tx) ([]uint64, error) {
+	var list []uint64
+	var err error
+
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM mutation_details WHERE mutation_id=?", u.ID)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var temp uint64
+		err = rows.Scan(&temp)
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, temp)
+	}
+
+	return list, rows.Err()
+}
+
+func (u *Mutation) getDetail(ctx context.Context, tx *sql.Tx, e uint64) (*MutationDetail, error) {
+	detail := new(MutationDetail)
+	err := tx.QueryRowContext(ctx,
+		`SELECT id, product_id, qty, code, shelve_from_id, shelve_to_id FROM mutation_details WHERE id = ? AND mutation_id = ?`,
+		e, u.ID).Scan(&detail.ID, &detail.Product.ID, &detail.Qty, &detail.Code, &detail.ShelveFrom.ID, &detail.ShelveTo.ID)
+
+	return detail, err
+}
+
+func (u *Mutation) checkShelveTo(ctx context.Context, tx *sql.Tx, d *MutationDetail) error {
+	err := d.ShelveTo.View(ctx, tx)
+	if err == sql.ErrNoRows {
+		return api.ErrBadRequest(errors.New("Invalid destination shelve"), "")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if d.ShelveTo.ID == d.ShelveFrom.ID {
+		return api.ErrBadRequest(errors.New("Destination shelve must be different from origin shelve"), "")
+	}
+
+	return nil
+}
+
+func (u *Mutation) storeDetail(ctx context.Context, tx *sql.Tx, d MutationDetail, i int) error {
+	// check :
+	// 1. unit code is on hand in user login branch, the origin shelve is the last position of unit code
+	// 2. destination shelve is in user login branch
+	// 3. quantity is not more than quantity on hand of unit code, the rest stays on the origin shelve
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	err := d.Product.CheckQty(ctx, tx, d.Qty)
+	if err != nil {
+		return err
+	}
+
+	position := new(Inventory)
+	position.ProductID = d.Product.ID
+	position.ProductCode = d.Code
+	qty, err := position.GetLastPosition(ctx, tx)
+	if err == sql.ErrNoRows || (err == nil && (qty <= 0 || position.BranchID != userLogin.Branch.ID)) {
+		return api.ErrBadRequest(errors.New("Unit code is not on hand"), "")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if qty < int(d.Qty) {
+		return api.ErrBadRequest(fmt.Errorf("Unit code %s only has %d on hand", d.Code, qty), "")
+	}
+
+	d.ShelveFrom.ID = position.ShelveID
+	err = u.checkShelveTo(ctx, tx, &d)
+	if err != nil {
+		return err
+	}
+
+	const queryDetail = `
+		INSERT INTO mutation_details (mutation_id, product_id, code, qty, shelve_from_id, shelve_to_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	stmt, err := tx.PrepareContext(ctx, queryDetail)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Code, d.Qty, d.ShelveFrom.ID, d.ShelveTo.ID)
+	if err != nil {
+		return err
+	}
+
+	detailID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	u.MutationDetails[i].ID = uint64(detailID)
+	u.MutationDetails[i].Qty = d.Qty
+	u.MutationDetails[i].ShelveFrom = d.ShelveFrom
+	u.MutationDetails[i].ShelveFrom.View(ctx, tx)
+	u.MutationDetails[i].ShelveTo = d.ShelveTo
+
+	err = u.storeInventories(ctx, tx, d)
+	if err != nil {
+		return err
+	}
+
+	return d.ShelveTo.CheckCapacity(ctx, tx)
+}
+
+func (u *Mutation) updateDetail(ctx context.Context, tx *sql.Tx, d MutationDetail, i int) error {
+	detail, err := u.getDetail(ctx, tx, d.ID)
+	if err != nil {
+		return err
+	}
+
+	d.Product.ID = detail.Product.ID
+	d.Code = detail.Code
+	if d.Qty != detail.Qty {
+		// quantity is changed, release the moved quantity and move the unit code again
+		err = u.removeDetail(ctx, tx, d.ID)
+		if err != nil {
+			return err
+		}
+
+		d.ID = 0
+		return u.storeDetail(ctx, tx, d, i)
+	}
+
+	d.ShelveFrom = detail.ShelveFrom
+	err = u.checkShelveTo(ctx, tx, &d)
+	if err != nil {
+		return err
+	}
+
+	const queryDetail = `
+		UPDATE mutation_details
+		SET shelve_to_id = ?
+		WHERE id = ?
+		AND mutation_id = ?
+	`
+	stmt, err := tx.PrepareContext(ctx, queryDetail)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, d.ShelveTo.ID, d.ID, u.ID)
+	if err != nil {
+		return err
+	}
+
+	u.MutationDetails[i] = d
+	u.MutationDetails[i].ShelveFrom.View(ctx, tx)
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	const queryInventory = `
+		UPDATE inventories
+		SET shelve_id = IF(in_out, ?, shelve_id),
+			code = ?,
+			transaction_date = ?,
+			updated = NOW()
+		WHERE product_id = ? AND product_code = ? AND transaction_id = ? AND type = 'MU' AND company_id = ? AND branch_id = ?
+	`
+	stmtInventory, err := tx.PrepareContext(ctx, queryInventory)
+	if err != nil {
+		return err
+	}
+
+	defer stmtInventory.Close()
+
+	_, err = stmtInventory.ExecContext(ctx, d.ShelveTo.ID, u.Code, u.Date, d.Product.ID, d.Code, u.ID, userLogin.Company.ID, userLogin.Branch.ID)
+	if err != nil {
+		return err
+	}
+
+	return d.ShelveTo.CheckCapacity(ctx, tx)
+}
+
+func (u *Mutation) removeDetail(ctx context.Context, tx *sql.Tx, e uint64) error {
+	const queryDetail = `DELETE FROM mutation_details WHERE id = ? AND mutation_id = ?`
+
+	detail, err := u.getDetail(ctx, tx, e)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, queryDetail)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, e, u.ID)
+	if err != nil {
+		return err
+	}
+
+	inventory := new(Inventory)
+	inventory.ProductID = detail.Product.ID
+	inventory.ProductCode = detail.Code
+	inventory.TransactionID = u.ID
+	inventory.Type = "MU"
+	inventory.CompanyID = ctx.Value(api.Ctx("auth")).(User).Company.ID
+	inventory.BranchID = ctx.Value(api.Ctx("auth")).(User).Branch.ID
+	return inventory.DeleteByComposit(ctx, tx)
+}
+
+// storeInventories post the unit out of origin shelve and into destination shelve
+func (u *Mutation) storeInventories(ctx context.Context, tx *sql.Tx, d MutationDetail) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	out := new(Inventory)
+	out.CompanyID = userLogin.Company.ID
+	out.BranchID = userLogin.Branch.ID
+	out.ProductID = d.Product.ID
+	out.ProductCode = d.Code
+	out.TransactionID = u.ID
+	out.Code = u.Code
+	out.TransactionDate = u.Date
+	out.Type = "MU"
+	out.InOut = false
+	out.Qty = d.Qty
+	out.ShelveID = d.ShelveFrom.ID
+	err := out.Create(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	in := *out
+	in.InOut = true
+	in.ShelveID = d.ShelveTo.ID
+	return in.Create(ctx, tx)
+}
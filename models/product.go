@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/jacky-htg/inventory/libraries/api"
 )
@@ -16,9 +17,12 @@ type Product struct {
 	PurchasePrice   float64
 	SalePrice       float64
 	MinimumStock    uint
+	Serialized      bool
 	Company         Company
 	Brand           Brand
 	ProductCategory ProductCategory
+	Uom             Uom
+	Template        ProductTemplate
 }
 
 const qProducts = `
@@ -27,6 +31,7 @@ SELECT 	products.id,
 		products.name,
 		products.sale_price,
 		products.minimum_stock, 
+		products.serialized,
 		companies.id as company_id, 
 		companies.code as company_code, 
 		companies.name as company_name,
@@ -35,11 +40,19 @@ SELECT 	products.id,
 		brands.code as brand_code,
 		brands.name as brand_name,
 		product_categories.id as product_category_id,
-		product_categories.name as product_category_name  
+		product_categories.name as product_category_name,
+		IFNULL(uoms.id, 0) as uom_id,
+		IFNULL(uoms.code, '') as uom_code,
+		IFNULL(uoms.name, '') as uom_name,
+		IFNULL(product_templates.id, 0) as product_template_id,
+		IFNULL(product_templates.code, '') as product_template_code,
+		IFNULL(product_templates.name, '') as product_template_name
 FROM products
 JOIN companies ON products.company_id = companies.id
 JOIN brands ON products.brand_id = brands.id
 JOIN product_categories ON products.product_category_id = product_categories.id
+LEFT JOIN uoms ON products.uom_id = uoms.id
+LEFT JOIN product_templates ON products.product_template_id = product_templates.id
 `
 
 // List of products
@@ -83,9 +96,14 @@ func (u *Product) Get(ctx context.Context, tx *sql.Tx) error {
 func (u *Product) Create(ctx context.Context, tx *sql.Tx) error {
 	userLogin := ctx.Value(api.Ctx("auth")).(User)
 	const query = `
-		INSERT INTO products (company_id, brand_id, product_category_id, code, name, sale_price, minimum_stock, created)
-		VALUES (?, ?, ?, ?, ?, ?, ?, NOW())
+		INSERT INTO products (company_id, brand_id, product_category_id, product_template_id, uom_id, code, name, sale_price, minimum_stock, serialized, created)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW())
 	`
+	err := u.checkUom(ctx, tx)
+	if err != nil {
+		return err
+	}
+
 	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
 		return err
@@ -93,7 +111,7 @@ func (u *Product) Create(ctx context.Context, tx *sql.Tx) error {
 
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, userLogin.Company.ID, u.Brand.ID, u.ProductCategory.ID, u.Code, u.Name, u.SalePrice, u.MinimumStock)
+	res, err := stmt.ExecContext(ctx, userLogin.Company.ID, u.Brand.ID, u.ProductCategory.ID, u.Template.nullID(), u.Uom.nullID(), u.Code, u.Name, u.SalePrice, u.MinimumStock, u.Serialized)
 	if err != nil {
 		return err
 	}
@@ -109,8 +127,34 @@ func (u *Product) Create(ctx context.Context, tx *sql.Tx) error {
 	return nil
 }
 
-// Update product
+// Update product, serialized flag and base unit can not be changed once the product has been posted into inventories
 func (u *Product) Update(ctx context.Context, tx *sql.Tx) error {
+	var serialized, posted bool
+	var uomID uint64
+	err := tx.QueryRowContext(ctx,
+		`SELECT serialized, IFNULL(uom_id, 0), EXISTS(SELECT id FROM inventories WHERE product_id = products.id) FROM products WHERE id = ?`,
+		u.ID).Scan(&serialized, &uomID, &posted)
+	if err != nil {
+		return err
+	}
+
+	if serialized != u.Serialized && posted {
+		return api.ErrBadRequest(errors.New("Serialized flag can not be changed, product already has stock movements"), "")
+	}
+
+	// base unit which is not sent is kept unchanged
+	if u.Uom.ID == 0 {
+		u.Uom.ID = uomID
+	}
+
+	if uomID != u.Uom.ID && posted {
+		return api.ErrBadRequest(errors.New("Base unit can not be changed, product already has stock movements"), "")
+	}
+
+	err = u.checkUom(ctx, tx)
+	if err != nil {
+		return err
+	}
 
 	stmt, err := tx.PrepareContext(ctx, `
 		UPDATE products 
@@ -118,7 +162,9 @@ func (u *Product) Update(ctx context.Context, tx *sql.Tx) error {
 			sale_price = ?,
 			brand_id = ?,
 			product_category_id = ?,
+			uom_id = ?,
 			minimum_stock = ?,
+			serialized = ?,
 			updated = NOW()
 		WHERE id = ?
 		AND company_id = ?
@@ -129,7 +175,7 @@ func (u *Product) Update(ctx context.Context, tx *sql.Tx) error {
 
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(ctx, u.Name, u.SalePrice, u.Brand.ID, u.ProductCategory.ID, u.MinimumStock, u.ID, ctx.Value(api.Ctx("auth")).(User).Company.ID)
+	_, err = stmt.ExecContext(ctx, u.Name, u.SalePrice, u.Brand.ID, u.ProductCategory.ID, u.Uom.nullID(), u.MinimumStock, u.Serialized, u.ID, ctx.Value(api.Ctx("auth")).(User).Company.ID)
 	return err
 }
 
@@ -146,6 +192,66 @@ func (u *Product) Delete(ctx context.Context, tx *sql.Tx) error {
 	return err
 }
 
+// CheckQty validate quantity of a movement, serialized product is moved per unit code
+func (u *Product) CheckQty(ctx context.Context, tx *sql.Tx, qty uint) error {
+	var serialized bool
+	err := tx.QueryRowContext(ctx, `SELECT serialized FROM products WHERE id = ? AND company_id = ?`,
+		u.ID, ctx.Value(api.Ctx("auth")).(User).Company.ID).Scan(&serialized)
+	if err == sql.ErrNoRows {
+		return api.ErrBadRequest(fmt.Errorf("Invalid product %d", u.ID), "")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if qty == 0 || (serialized && qty != 1) {
+		return api.ErrBadRequest(fmt.Errorf("Invalid quantity %d of product %d, serialized product is moved per unit code", qty, u.ID), "")
+	}
+
+	return nil
+}
+
+// ConvertQty normalize quantity entered in a unit of measure into base unit of product and fill the unit.
+// Empty unit or the base unit itself is taken as base unit.
+func (u *Product) ConvertQty(ctx context.Context, tx *sql.Tx, uom *Uom, qty uint) (uint, error) {
+	if uom.ID == 0 {
+		return qty, nil
+	}
+
+	var conversion uint
+	err := tx.QueryRowContext(ctx, `
+		SELECT uoms.code, uoms.name, IF(products.uom_id = uoms.id, 1, IFNULL(product_uoms.conversion, 0))
+		FROM products
+		JOIN uoms ON uoms.id = ? AND uoms.company_id = products.company_id
+		LEFT JOIN product_uoms ON product_uoms.product_id = products.id AND product_uoms.uom_id = uoms.id
+		WHERE products.id = ? AND products.company_id = ?`,
+		uom.ID, u.ID, ctx.Value(api.Ctx("auth")).(User).Company.ID).Scan(&uom.Code, &uom.Name, &conversion)
+	if err != nil && err != sql.ErrNoRows {
+		return qty, err
+	}
+
+	if conversion == 0 {
+		return qty, api.ErrBadRequest(fmt.Errorf("Unit of measure %d is not configured for product %d", uom.ID, u.ID), "")
+	}
+
+	return qty * conversion, nil
+}
+
+// checkUom validate base unit of product is owned by company
+func (u *Product) checkUom(ctx context.Context, tx *sql.Tx) error {
+	if u.Uom.ID == 0 {
+		return nil
+	}
+
+	err := u.Uom.View(ctx, tx)
+	if err == sql.ErrNoRows {
+		return api.ErrBadRequest(fmt.Errorf("Invalid unit of measure %d", u.Uom.ID), "")
+	}
+
+	return err
+}
+
 func (u *Product) getArgs() []interface{} {
 	var args []interface{}
 	args = append(args, &u.ID)
@@ -153,6 +259,7 @@ func (u *Product) getArgs() []interface{} {
 	args = append(args, &u.Name)
 	args = append(args, &u.SalePrice)
 	args = append(args, &u.MinimumStock)
+	args = append(args, &u.Serialized)
 	args = append(args, &u.Company.ID)
 	args = append(args, &u.Company.Code)
 	args = append(args, &u.Company.Name)
@@ -162,6 +269,12 @@ func (u *Product) getArgs() []interface{} {
 	args = append(args, &u.Brand.Name)
 	args = append(args, &u.ProductCategory.ID)
 	args = append(args, &u.ProductCategory.Name)
+	args = append(args, &u.Uom.ID)
+	args = append(args, &u.Uom.Code)
+	args = append(args, &u.Uom.Name)
+	args = append(args, &u.Template.ID)
+	args = append(args, &u.Template.Code)
+	args = append(args, &u.Template.Name)
 
 	return args
 }
@@ -0,0 +1,449 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// ProductTemplate : struct of product family, its variants are generated from combination of attribute values
+type ProductTemplate struct {
+	ID              uint64
+	Code            string
+	Name            string
+	SalePrice       float64
+	MinimumStock    uint
+	Serialized      bool
+	Company         Company
+	Brand           Brand
+	ProductCategory ProductCategory
+	Attributes      []ProductAttribute
+	Variants        []ProductVariant
+}
+
+// ProductAttribute : struct of attribute definition of product template, e.g. Size or Color
+type ProductAttribute struct {
+	ID     uint64
+	Name   string
+	Values []ProductAttributeValue
+}
+
+// ProductAttributeValue : struct of option of attribute, e.g. XL or Red
+type ProductAttributeValue struct {
+	ID        uint64
+	Attribute string
+	Value     string
+}
+
+// ProductVariant : struct of variant product and its attribute values
+type ProductVariant struct {
+	Product Product
+	Values  []ProductAttributeValue
+}
+
+const qProductTemplates = `
+SELECT 	product_templates.id,
+		product_templates.code,
+		product_templates.name,
+		product_templates.sale_price,
+		product_templates.minimum_stock,
+		product_templates.serialized,
+		companies.id,
+		companies.code,
+		companies.name,
+		companies.address,
+		brands.id,
+		brands.code,
+		brands.name,
+		product_categories.id,
+		product_categories.name
+FROM product_templates
+JOIN companies ON product_templates.company_id = companies.id
+JOIN brands ON product_templates.brand_id = brands.id
+JOIN product_categories ON product_templates.product_category_id = product_categories.id
+`
+
+// List of product templates
+func (u *ProductTemplate) List(ctx context.Context, tx *sql.Tx) ([]ProductTemplate, error) {
+	list := []ProductTemplate{}
+
+	rows, err := tx.QueryContext(ctx, qProductTemplates+" WHERE companies.id=?", ctx.Value(api.Ctx("auth")).(User).Company.ID)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var r ProductTemplate
+		err = rows.Scan(r.getArgs()...)
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, r)
+	}
+
+	return list, rows.Err()
+}
+
+// Get product template by id, including its attributes and variants
+func (u *ProductTemplate) Get(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	err := tx.QueryRowContext(ctx, qProductTemplates+" WHERE product_templates.id=? AND companies.id=?", u.ID, userLogin.Company.ID).Scan(u.getArgs()...)
+	if err != nil {
+		return err
+	}
+
+	err = u.getAttributes(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return u.getVariants(ctx, tx)
+}
+
+// Create new product template with its attributes
+func (u *ProductTemplate) Create(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	const query = `
+		INSERT INTO product_templates (company_id, brand_id, product_category_id, code, name, sale_price, minimum_stock, serialized, created)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW())
+	`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, userLogin.Company.ID, u.Brand.ID, u.ProductCategory.ID, u.Code, u.Name, u.SalePrice, u.MinimumStock, u.Serialized)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	u.ID = uint64(id)
+
+	err = u.storeAttributes(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return u.Get(ctx, tx)
+}
+
+// Update product template. Attributes and values are only added, new attribute is rejected once variants have been generated.
+// Price and minimum stock of existing variants are kept, they are maintained per variant product.
+func (u *ProductTemplate) Update(ctx context.Context, tx *sql.Tx) error {
+	stmt, err := tx.PrepareContext(ctx, `
+		UPDATE product_templates
+		SET name = ?,
+			sale_price = ?,
+			brand_id = ?,
+			product_category_id = ?,
+			minimum_stock = ?,
+			serialized = ?,
+			updated = NOW()
+		WHERE id = ?
+		AND company_id = ?
+	`)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, u.Name, u.SalePrice, u.Brand.ID, u.ProductCategory.ID, u.MinimumStock, u.Serialized, u.ID, ctx.Value(api.Ctx("auth")).(User).Company.ID)
+	if err != nil {
+		return err
+	}
+
+	err = u.storeAttributes(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return u.Get(ctx, tx)
+}
+
+// Delete product template, template which already has variants can not be deleted
+func (u *ProductTemplate) Delete(ctx context.Context, tx *sql.Tx) error {
+	if len(u.Variants) > 0 {
+		return api.ErrBadRequest(errors.New("Product template already has variants"), "")
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `DELETE FROM product_templates WHERE id = ? AND company_id = ?`)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, u.ID, ctx.Value(api.Ctx("auth")).(User).Company.ID)
+	return err
+}
+
+// GenerateVariants create a variant product for every combination of attribute values which does not have variant yet.
+// Variant inherits brand, category, price, minimum stock and serialized flag of template and return the new variants.
+func (u *ProductTemplate) GenerateVariants(ctx context.Context, tx *sql.Tx) ([]Product, error) {
+	var list []Product
+
+	if len(u.Attributes) == 0 {
+		return list, api.ErrBadRequest(errors.New("Product template has no attribute"), "")
+	}
+
+	existing := make(map[string]bool)
+	for _, v := range u.Variants {
+		existing[variantKey(v.Values)] = true
+	}
+
+	combinations := [][]ProductAttributeValue{{}}
+	for _, a := range u.Attributes {
+		if len(a.Values) == 0 {
+			return list, api.ErrBadRequest(fmt.Errorf("Attribute %s has no value", a.Name), "")
+		}
+
+		var next [][]ProductAttributeValue
+		for _, c := range combinations {
+			for _, v := range a.Values {
+				combination := append(append([]ProductAttributeValue{}, c...), v)
+				next = append(next, combination)
+			}
+		}
+
+		combinations = next
+	}
+
+	for _, c := range combinations {
+		if existing[variantKey(c)] {
+			continue
+		}
+
+		var codes, names []string
+		for _, v := range c {
+			codes = append(codes, v.Value)
+			names = append(names, v.Value)
+		}
+
+		product := Product{
+			Code:            u.Code + "-" + strings.Join(codes, "-"),
+			Name:            u.Name + " (" + strings.Join(names, ", ") + ")",
+			SalePrice:       u.SalePrice,
+			MinimumStock:    u.MinimumStock,
+			Serialized:      u.Serialized,
+			Brand:           u.Brand,
+			ProductCategory: u.ProductCategory,
+			Template:        ProductTemplate{ID: u.ID},
+		}
+
+		err := product.Create(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		for _, v := range c {
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO product_variant_values (product_id, product_attribute_value_id) VALUES (?, ?)`,
+				product.ID, v.ID)
+			if err != nil {
+				return list, err
+			}
+		}
+
+		err = product.Get(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		u.Variants = append(u.Variants, ProductVariant{Product: product, Values: c})
+		list = append(list, product)
+	}
+
+	return list, nil
+}
+
+// storeAttributes save new attributes and values of product template
+func (u *ProductTemplate) storeAttributes(ctx context.Context, tx *sql.Tx) error {
+	var hasVariant bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT id FROM products WHERE product_template_id = ?)`, u.ID).Scan(&hasVariant)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range u.Attributes {
+		var attributeID uint64
+		err = tx.QueryRowContext(ctx, `SELECT id FROM product_attributes WHERE product_template_id = ? AND name = ?`, u.ID, a.Name).Scan(&attributeID)
+		if err == sql.ErrNoRows {
+			if hasVariant {
+				return api.ErrBadRequest(fmt.Errorf("Attribute %s can not be added, product template already has variants", a.Name), "")
+			}
+
+			var res sql.Result
+			res, err = tx.ExecContext(ctx, `INSERT INTO product_attributes (product_template_id, name) VALUES (?, ?)`, u.ID, a.Name)
+			if err != nil {
+				return err
+			}
+
+			var id int64
+			id, err = res.LastInsertId()
+			attributeID = uint64(id)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		for _, v := range a.Values {
+			_, err = tx.ExecContext(ctx, `INSERT IGNORE INTO product_attribute_values (product_attribute_id, value) VALUES (?, ?)`, attributeID, v.Value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (u *ProductTemplate) getAttributes(ctx context.Context, tx *sql.Tx) error {
+	u.Attributes = []ProductAttribute{}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT product_attributes.id, product_attributes.name, product_attribute_values.id, product_attribute_values.value
+		FROM product_attributes
+		JOIN product_attribute_values ON product_attributes.id = product_attribute_values.product_attribute_id
+		WHERE product_attributes.product_template_id = ?
+		ORDER BY product_attributes.id, product_attribute_values.id`, u.ID)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var a ProductAttribute
+		var v ProductAttributeValue
+		err = rows.Scan(&a.ID, &a.Name, &v.ID, &v.Value)
+		if err != nil {
+			return err
+		}
+
+		v.Attribute = a.Name
+		last := len(u.Attributes) - 1
+		if last < 0 || u.Attributes[last].ID != a.ID {
+			u.Attributes = append(u.Attributes, a)
+			last++
+		}
+
+		u.Attributes[last].Values = append(u.Attributes[last].Values, v)
+	}
+
+	return rows.Err()
+}
+
+func (u *ProductTemplate) getVariants(ctx context.Context, tx *sql.Tx) error {
+	u.Variants = []ProductVariant{}
+
+	rows, err := tx.QueryContext(ctx, qProducts+" WHERE products.product_template_id=? AND companies.id=? ORDER BY products.id",
+		u.ID, ctx.Value(api.Ctx("auth")).(User).Company.ID)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	idx := make(map[uint64]int)
+	for rows.Next() {
+		var v ProductVariant
+		err = rows.Scan(v.Product.getArgs()...)
+		if err != nil {
+			return err
+		}
+
+		idx[v.Product.ID] = len(u.Variants)
+		u.Variants = append(u.Variants, v)
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	valueRows, err := tx.QueryContext(ctx, `
+		SELECT product_variant_values.product_id, product_attribute_values.id, product_attributes.name, product_attribute_values.value
+		FROM product_variant_values
+		JOIN product_attribute_values ON product_variant_values.product_attribute_value_id = product_attribute_values.id
+		JOIN product_attributes ON product_attribute_values.product_attribute_id = product_attributes.id
+		WHERE product_attributes.product_template_id = ?
+		ORDER BY product_variant_values.product_id, product_attributes.id`, u.ID)
+	if err != nil {
+		return err
+	}
+
+	defer valueRows.Close()
+
+	for valueRows.Next() {
+		var productID uint64
+		var v ProductAttributeValue
+		err = valueRows.Scan(&productID, &v.ID, &v.Attribute, &v.Value)
+		if err != nil {
+			return err
+		}
+
+		if i, ok := idx[productID]; ok {
+			u.Variants[i].Values = append(u.Variants[i].Values, v)
+		}
+	}
+
+	return valueRows.Err()
+}
+
+func (u *ProductTemplate) getArgs() []interface{} {
+	var args []interface{}
+	args = append(args, &u.ID)
+	args = append(args, &u.Code)
+	args = append(args, &u.Name)
+	args = append(args, &u.SalePrice)
+	args = append(args, &u.MinimumStock)
+	args = append(args, &u.Serialized)
+	args = append(args, &u.Company.ID)
+	args = append(args, &u.Company.Code)
+	args = append(args, &u.Company.Name)
+	args = append(args, &u.Company.Address)
+	args = append(args, &u.Brand.ID)
+	args = append(args, &u.Brand.Code)
+	args = append(args, &u.Brand.Name)
+	args = append(args, &u.ProductCategory.ID)
+	args = append(args, &u.ProductCategory.Name)
+
+	return args
+}
+
+// nullID return nil for product without template, so column referencing template is kept NULL
+func (u *ProductTemplate) nullID() interface{} {
+	if u.ID == 0 {
+		return nil
+	}
+
+	return u.ID
+}
+
+// variantKey identify combination of attribute values regardless of its order
+func variantKey(values []ProductAttributeValue) string {
+	var ids []string
+	for _, v := range values {
+		ids = append(ids, strconv.FormatUint(v.ID, 10))
+	}
+
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// ProductUom : struct of unit of measure conversion into base unit of product
+type ProductUom struct {
+	Product    Product
+	Uom        Uom
+	Conversion uint
+}
+
+// List conversions of product
+func (u *ProductUom) List(ctx context.Context, tx *sql.Tx) ([]ProductUom, error) {
+	var list []ProductUom
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT uoms.id, uoms.code, uoms.name, product_uoms.conversion
+		FROM product_uoms
+		JOIN uoms ON product_uoms.uom_id = uoms.id
+		WHERE product_uoms.product_id = ?
+		ORDER BY product_uoms.conversion`, u.Product.ID)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var c ProductUom
+		c.Product = u.Product
+		err = rows.Scan(&c.Uom.ID, &c.Uom.Code, &c.Uom.Name, &c.Conversion)
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, c)
+	}
+
+	return list, rows.Err()
+}
+
+// Save conversion of product, existing conversion of the same unit is replaced
+func (u *ProductUom) Save(ctx context.Context, tx *sql.Tx) error {
+	if u.Product.Uom.ID == 0 {
+		return api.ErrBadRequest(errors.New("Product has no base unit"), "")
+	}
+
+	if u.Product.Uom.ID == u.Uom.ID {
+		return api.ErrBadRequest(errors.New("Base unit can not be converted"), "")
+	}
+
+	if u.Conversion <= 1 {
+		return api.ErrBadRequest(fmt.Errorf("Invalid conversion %d", u.Conversion), "")
+	}
+
+	err := u.Uom.View(ctx, tx)
+	if err == sql.ErrNoRows {
+		return api.ErrBadRequest(fmt.Errorf("Invalid unit of measure %d", u.Uom.ID), "")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO product_uoms (product_id, uom_id, conversion) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE conversion = VALUES(conversion)`,
+		u.Product.ID, u.Uom.ID, u.Conversion)
+
+	return err
+}
+
+// Delete conversion of product
+func (u *ProductUom) Delete(ctx context.Context, tx *sql.Tx) error {
+	res, err := tx.ExecContext(ctx, `DELETE FROM product_uoms WHERE product_id = ? AND uom_id = ?`, u.Product.ID, u.Uom.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return api.ErrNotFound(errors.New("Conversion not found"), "")
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -24,16 +25,58 @@ type Purchase struct {
 	Supplier        Supplier
 	Company         Company
 	Branch          Branch
+	Status          string
+	Approvals       []PurchaseApproval
 	PurchaseDetails []PurchaseDetail
 }
 
+// PurchaseApproval : struct of user approving purchase
+type PurchaseApproval struct {
+	User    User
+	Created time.Time
+}
+
+// PurchaseReceipt : struct of ordered, returned and received quantity of product in purchase
+type PurchaseReceipt struct {
+	Ordered  int
+	Returned int
+	Received int
+}
+
+type purchaseKey struct {
+	PurchaseID uint64
+	ProductID  uint64
+}
+
+// Status of purchase. Partially received and received are derived from good receivings of approved purchase.
+const (
+	PurchaseDraft             = "draft"
+	PurchaseSubmitted         = "submitted"
+	PurchaseApproved          = "approved"
+	PurchasePartiallyReceived = "partially_received"
+	PurchaseReceived          = "received"
+	PurchaseClosed            = "closed"
+	PurchaseCancelled         = "cancelled"
+)
+
+const qPurchaseReceipt = `
+	SELECT ordered.purchase_id, ordered.product_id, ordered.qty, IFNULL(returned.qty, 0), IFNULL(received.qty, 0)
+` + qPurchaseQty + `
+	WHERE purchases.company_id = ?
+`
+
 // PurchaseDetail struct
 type PurchaseDetail struct {
-	ID      uint64
-	Product Product
-	Price   float64
-	Disc    float64
-	Qty     uint
+	ID           uint64
+	Product      Product
+	Price        float64
+	Disc         float64
+	Qty          uint
+	Uom          Uom
+	UomQty       uint
+	ReturnedQty  uint
+	ReceivedQty  uint
+	RemainingQty uint
 }
 
 // List purchases
@@ -60,7 +103,8 @@ func (u *Purchase) List(ctx context.Context, tx *sql.Tx) ([]Purchase, error) {
 		branches.type,
 		SUM(purchase_details.price),
 		SUM(purchase_details.disc),
-		purchases.disc
+		purchases.disc,
+		purchases.status
 	FROM purchases
 	JOIN companies ON purchases.company_id = companies.id
 	JOIN suppliers ON purchases.supplier_id = suppliers.id
@@ -122,6 +166,7 @@ func (u *Purchase) List(ctx context.Context, tx *sql.Tx) ([]Purchase, error) {
 			&purchase.Price,
 			&purchase.Disc,
 			&purchase.AdditionalDisc,
+			&purchase.Status,
 		)
 
 		if err != nil {
@@ -135,7 +180,25 @@ func (u *Purchase) List(ctx context.Context, tx *sql.Tx) ([]Purchase, error) {
 		list = append(list, purchase)
 	}
 
-	return list, rows.Err()
+	if err = rows.Err(); err != nil {
+		return list, err
+	}
+
+	receipts, err := listPurchaseReceipt(ctx, tx, "")
+	if err != nil {
+		return list, err
+	}
+
+	purchaseReceipts := make(map[uint64][]PurchaseReceipt)
+	for key, r := range receipts {
+		purchaseReceipts[key.PurchaseID] = append(purchaseReceipts[key.PurchaseID], r)
+	}
+
+	for i := range list {
+		list[i].Status = receivedStatus(list[i].Status, purchaseReceipts[list[i].ID])
+	}
+
+	return list, nil
 }
 
 // Get purchase by id
@@ -163,17 +226,23 @@ func (u *Purchase) Get(ctx context.Context, tx *sql.Tx) error {
 		JSON_ARRAYAGG(purchase_details.price),
 		JSON_ARRAYAGG(purchase_details.disc),
 		JSON_ARRAYAGG(purchase_details.qty),
+		JSON_ARRAYAGG(IFNULL(purchase_details.uom_id, 0)),
+		JSON_ARRAYAGG(IFNULL(uoms.code, '')),
+		JSON_ARRAYAGG(IFNULL(uoms.name, '')),
+		JSON_ARRAYAGG(IFNULL(purchase_details.uom_qty, purchase_details.qty)),
 		JSON_ARRAYAGG(products.id),
 		JSON_ARRAYAGG(products.code),
 		JSON_ARRAYAGG(products.name),
 		JSON_ARRAYAGG(products.sale_price),
-		purchases.disc
+		purchases.disc,
+		purchases.status
 	FROM purchases
 	JOIN companies ON purchases.company_id = companies.id
 	JOIN suppliers ON purchases.supplier_id = suppliers.id
 	JOIN branches ON purchases.branch_id = branches.id
 	JOIN purchase_details ON purchases.id = purchase_details.purchase_id
 	JOIN products ON purchase_details.product_id = products.id 
+	LEFT JOIN uoms ON purchase_details.uom_id = uoms.id
 	WHERE purchases.id=? AND companies.id=?
 	`
 	userLogin := ctx.Value(api.Ctx("auth")).(User)
@@ -199,7 +268,7 @@ func (u *Purchase) Get(ctx context.Context, tx *sql.Tx) error {
 		params = append(params, userLogin.Branch.ID)
 	}
 
-	var detailID, detailPrice, detailDisc, detailQty, productID, productCode, productName, productPrice string
+	var detailID, detailPrice, detailDisc, detailQty, detailUomID, detailUomCode, detailUomName, detailUomQty, productID, productCode, productName, productPrice string
 	err := tx.QueryRowContext(ctx, query+" GROUP BY purchases.id", params...).Scan(
 		&u.ID,
 		&u.Code,
@@ -223,11 +292,16 @@ func (u *Purchase) Get(ctx context.Context, tx *sql.Tx) error {
 		&detailPrice,
 		&detailDisc,
 		&detailQty,
+		&detailUomID,
+		&detailUomCode,
+		&detailUomName,
+		&detailUomQty,
 		&productID,
 		&productCode,
 		&productName,
 		&productPrice,
 		&u.AdditionalDisc,
+		&u.Status,
 	)
 
 	if err != nil {
@@ -261,6 +335,30 @@ func (u *Purchase) Get(ctx context.Context, tx *sql.Tx) error {
 			return err
 		}
 
+		var detailUomIDs []uint64
+		err = json.Unmarshal([]byte(detailUomID), &detailUomIDs)
+		if err != nil {
+			return err
+		}
+
+		var detailUomCodes []string
+		err = json.Unmarshal([]byte(detailUomCode), &detailUomCodes)
+		if err != nil {
+			return err
+		}
+
+		var detailUomNames []string
+		err = json.Unmarshal([]byte(detailUomName), &detailUomNames)
+		if err != nil {
+			return err
+		}
+
+		var detailUomQtys []uint
+		err = json.Unmarshal([]byte(detailUomQty), &detailUomQtys)
+		if err != nil {
+			return err
+		}
+
 		var productIDs []uint64
 		err = json.Unmarshal([]byte(productID), &productIDs)
 		if err != nil {
@@ -287,10 +385,12 @@ func (u *Purchase) Get(ctx context.Context, tx *sql.Tx) error {
 
 		for i, v := range detailIDs {
 			u.PurchaseDetails = append(u.PurchaseDetails, PurchaseDetail{
-				ID:    uint64(v),
-				Price: detailPrices[i],
-				Disc:  detailDiscs[i],
-				Qty:   detailQtys[i],
+				ID:     uint64(v),
+				Price:  detailPrices[i],
+				Disc:   detailDiscs[i],
+				Qty:    detailQtys[i],
+				Uom:    Uom{ID: detailUomIDs[i], Code: detailUomCodes[i], Name: detailUomNames[i]},
+				UomQty: detailUomQtys[i],
 				Product: Product{
 					ID:        productIDs[i],
 					Code:      productCodes[i],
@@ -305,7 +405,20 @@ func (u *Purchase) Get(ctx context.Context, tx *sql.Tx) error {
 	u.Supplier.Company = u.Company
 	u.Branch.Company = u.Company
 
-	return nil
+	receipts, err := listPurchaseReceipt(ctx, tx, " AND purchases.id = ?", u.ID)
+	if err != nil {
+		return err
+	}
+
+	var purchaseReceipts []PurchaseReceipt
+	for _, r := range receipts {
+		purchaseReceipts = append(purchaseReceipts, r)
+	}
+
+	u.Status = receivedStatus(u.Status, purchaseReceipts)
+	u.setReceipt(receipts)
+
+	return u.getApprovals(ctx, tx)
 }
 
 // Create new purchase
@@ -315,9 +428,13 @@ func (u *Purchase) Create(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if err := checkClosedPeriod(ctx, tx, u.Date); err != nil {
+		return err
+	}
+
 	const query = `
-		INSERT INTO purchases (code, date, disc, supplier_id, company_id, branch_id, created_by, updated_by, created, updated)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+		INSERT INTO purchases (code, date, disc, status, supplier_id, company_id, branch_id, created_by, updated_by, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
 	`
 	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
@@ -331,7 +448,8 @@ func (u *Purchase) Create(ctx context.Context, tx *sql.Tx) error {
 		return err
 	}
 
-	res, err := stmt.ExecContext(ctx, u.Code, u.Date, u.AdditionalDisc, u.Supplier.ID, userLogin.Company.ID, userLogin.Branch.ID, userLogin.ID, userLogin.ID)
+	u.Status = PurchaseDraft
+	res, err := stmt.ExecContext(ctx, u.Code, u.Date, u.AdditionalDisc, u.Status, u.Supplier.ID, userLogin.Company.ID, userLogin.Branch.ID, userLogin.ID, userLogin.ID)
 	if err != nil {
 		return err
 	}
@@ -348,6 +466,14 @@ func (u *Purchase) Create(ctx context.Context, tx *sql.Tx) error {
 	u.Supplier.Get(ctx, tx)
 
 	for i, d := range u.PurchaseDetails {
+		d.Qty, err = d.Product.ConvertQty(ctx, tx, &d.Uom, d.UomQty)
+		if err != nil {
+			return err
+		}
+
+		u.PurchaseDetails[i].Qty = d.Qty
+		u.PurchaseDetails[i].Uom = d.Uom
+
 		detailID, err := u.storeDetail(ctx, tx, d)
 		if err != nil {
 			return err
@@ -358,6 +484,7 @@ func (u *Purchase) Create(ctx context.Context, tx *sql.Tx) error {
 		u.PurchaseDetails[i].Product.Get(ctx, tx)
 	}
 	u.Total = u.Price - u.Disc - u.AdditionalDisc
+	u.setReceipt(nil)
 
 	return nil
 }
@@ -369,6 +496,14 @@ func (u *Purchase) Update(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if err := checkClosedTransaction(ctx, tx, "purchases", u.ID, u.Date); err != nil {
+		return err
+	}
+
+	if u.Status != PurchaseDraft {
+		return api.ErrBadRequest(fmt.Errorf("Purchase with status %s can not be updated", u.Status), "")
+	}
+
 	const query = `
 		UPDATE purchases 
 		SET date = ?, 
@@ -398,6 +533,14 @@ func (u *Purchase) Update(ctx context.Context, tx *sql.Tx) error {
 	}
 
 	for i, d := range u.PurchaseDetails {
+		d.Qty, err = d.Product.ConvertQty(ctx, tx, &d.Uom, d.UomQty)
+		if err != nil {
+			return err
+		}
+
+		u.PurchaseDetails[i].Qty = d.Qty
+		u.PurchaseDetails[i].Uom = d.Uom
+
 		if d.ID <= 0 {
 			detailID, err := u.storeDetail(ctx, tx, d)
 			if err != nil {
@@ -428,9 +571,252 @@ func (u *Purchase) Update(ctx context.Context, tx *sql.Tx) error {
 		}
 	}
 
+	u.setReceipt(nil)
 	return nil
 }
 
+// Submit draft purchase for approval
+func (u *Purchase) Submit(ctx context.Context, tx *sql.Tx) error {
+	if err := u.checkOwner(ctx); err != nil {
+		return err
+	}
+
+	if u.Status != PurchaseDraft {
+		return api.ErrBadRequest(fmt.Errorf("Purchase with status %s can not be submitted", u.Status), "")
+	}
+
+	return u.changeStatus(ctx, tx, PurchaseSubmitted)
+}
+
+// Approve submitted purchase by user login. Purchase with total above approval limit of company requires approval from two different users.
+func (u *Purchase) Approve(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if err := u.checkApprover(ctx, tx); err != nil {
+		return err
+	}
+
+	if u.Status != PurchaseSubmitted {
+		return api.ErrBadRequest(fmt.Errorf("Purchase with status %s can not be approved", u.Status), "")
+	}
+
+	for _, a := range u.Approvals {
+		if a.User.ID == userLogin.ID {
+			return api.ErrBadRequest(errors.New("Purchase has been approved by this user, a second approver is required"), "")
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, `INSERT INTO purchase_approvals (purchase_id, user_id, created) VALUES (?, ?, NOW())`, u.ID, userLogin.ID)
+	if err != nil {
+		return err
+	}
+
+	limit, err := userLogin.Company.GetPOApprovalLimit(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	required := 1
+	if limit > 0 && u.Total > limit {
+		required = 2
+	}
+
+	if len(u.Approvals)+1 < required {
+		u.PurchaseDetails = nil
+		u.Approvals = nil
+		return u.Get(ctx, tx)
+	}
+
+	return u.changeStatus(ctx, tx, PurchaseApproved)
+}
+
+// Reject submitted purchase, return it to draft and discard its approvals
+func (u *Purchase) Reject(ctx context.Context, tx *sql.Tx) error {
+	if err := u.checkApprover(ctx, tx); err != nil {
+		return err
+	}
+
+	if u.Status != PurchaseSubmitted {
+		return api.ErrBadRequest(fmt.Errorf("Purchase with status %s can not be rejected", u.Status), "")
+	}
+
+	_, err := tx.ExecContext(ctx, `DELETE FROM purchase_approvals WHERE purchase_id = ?`, u.ID)
+	if err != nil {
+		return err
+	}
+
+	return u.changeStatus(ctx, tx, PurchaseDraft)
+}
+
+// Close approved purchase, the remaining quantity will not be received anymore
+func (u *Purchase) Close(ctx context.Context, tx *sql.Tx) error {
+	if err := u.checkOwner(ctx); err != nil {
+		return err
+	}
+
+	if u.Status != PurchaseApproved && u.Status != PurchasePartiallyReceived && u.Status != PurchaseReceived {
+		return api.ErrBadRequest(fmt.Errorf("Purchase with status %s can not be closed", u.Status), "")
+	}
+
+	return u.changeStatus(ctx, tx, PurchaseClosed)
+}
+
+// Cancel purchase which has not been received
+func (u *Purchase) Cancel(ctx context.Context, tx *sql.Tx) error {
+	if err := u.checkOwner(ctx); err != nil {
+		return err
+	}
+
+	if u.Status != PurchaseDraft && u.Status != PurchaseSubmitted && u.Status != PurchaseApproved {
+		return api.ErrBadRequest(fmt.Errorf("Purchase with status %s can not be cancelled", u.Status), "")
+	}
+
+	return u.changeStatus(ctx, tx, PurchaseCancelled)
+}
+
+// IsReceivable return true if goods of purchase can be received, that is purchase has been approved and is not closed or cancelled
+func (u *Purchase) IsReceivable() bool {
+	return u.Status == PurchaseApproved || u.Status == PurchasePartiallyReceived || u.Status == PurchaseReceived
+}
+
+func (u *Purchase) checkOwner(ctx context.Context) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Company.ID != u.Company.ID || u.Branch.ID <= 0 || userLogin.Branch.ID != u.Branch.ID {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	return nil
+}
+
+// checkApprover validate purchase is owned by user login, which may approve purchase of its company,
+// of branches of its region or of its branch only
+func (u *Purchase) checkApprover(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Company.ID != u.Company.ID || u.Branch.ID <= 0 {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, b := range branches {
+			if b == u.Branch.ID {
+				return nil
+			}
+		}
+
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+
+	case userLogin.Branch.ID > 0 && userLogin.Branch.ID != u.Branch.ID:
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	return nil
+}
+
+func (u *Purchase) changeStatus(ctx context.Context, tx *sql.Tx, status string) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	_, err := tx.ExecContext(ctx, `
+		UPDATE purchases 
+		SET status = ?,
+			updated_by = ?,
+			updated = NOW()
+		WHERE id = ?
+		AND company_id = ?`,
+		status, userLogin.ID, u.ID, userLogin.Company.ID)
+	if err != nil {
+		return err
+	}
+
+	u.PurchaseDetails = nil
+	u.Approvals = nil
+	return u.Get(ctx, tx)
+}
+
+func (u *Purchase) getApprovals(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT users.id, users.username, users.email, purchase_approvals.created
+		FROM purchase_approvals
+		JOIN users ON purchase_approvals.user_id = users.id
+		WHERE purchase_approvals.purchase_id = ?
+		ORDER BY purchase_approvals.id`, u.ID)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var a PurchaseApproval
+		err = rows.Scan(&a.User.ID, &a.User.Username, &a.User.Email, &a.Created)
+		if err != nil {
+			return err
+		}
+
+		a.User.Company = u.Company
+		u.Approvals = append(u.Approvals, a)
+	}
+
+	return rows.Err()
+}
+
+// listPurchaseReceipt return ordered, returned and received quantity of purchases grouped by purchase and product
+func listPurchaseReceipt(ctx context.Context, tx *sql.Tx, where string, params ...interface{}) (map[purchaseKey]PurchaseReceipt, error) {
+	list := make(map[purchaseKey]PurchaseReceipt)
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	params = append([]interface{}{userLogin.Company.ID}, params...)
+
+	rows, err := tx.QueryContext(ctx, qPurchaseReceipt+where, params...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var key purchaseKey
+		var r PurchaseReceipt
+		err = rows.Scan(&key.PurchaseID, &key.ProductID, &r.Ordered, &r.Returned, &r.Received)
+		if err != nil {
+			return list, err
+		}
+
+		list[key] = r
+	}
+
+	return list, rows.Err()
+}
+
+// receivedStatus derive partially received and received status of approved purchase from receipts of its products
+func receivedStatus(status string, receipts []PurchaseReceipt) string {
+	if status != PurchaseApproved {
+		return status
+	}
+
+	var received, pending int
+	for _, r := range receipts {
+		if r.Received > 0 {
+			received++
+		}
+
+		if r.Received < r.Ordered-r.Returned {
+			pending++
+		}
+	}
+
+	switch {
+	case received == 0:
+		return PurchaseApproved
+	case pending == 0:
+		return PurchaseReceived
+	default:
+		return PurchasePartiallyReceived
+	}
+}
+
 // GetExistingDetails return array of existing purchase_details id
 func (u *Purchase) GetExistingDetails(ctx context.Context, tx *sql.Tx) ([]uint64, error) {
 	var list []uint64
@@ -459,8 +845,8 @@ func (u *Purchase) GetExistingDetails(ctx context.Context, tx *sql.Tx) ([]uint64
 func (u *Purchase) storeDetail(ctx context.Context, tx *sql.Tx, d PurchaseDetail) (uint64, error) {
 	var id uint64
 	const queryDetail = `
-		INSERT INTO purchase_details (purchase_id, product_id, price, disc, qty)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO purchase_details (purchase_id, product_id, price, disc, qty, uom_id, uom_qty)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 	stmt, err := tx.PrepareContext(ctx, queryDetail)
 	if err != nil {
@@ -469,7 +855,7 @@ func (u *Purchase) storeDetail(ctx context.Context, tx *sql.Tx, d PurchaseDetail
 
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Price, d.Disc, d.Qty)
+	res, err := stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Price, d.Disc, d.Qty, d.Uom.nullID(), d.UomQty)
 	if err != nil {
 		return id, err
 	}
@@ -488,7 +874,9 @@ func (u *Purchase) updateDetail(ctx context.Context, tx *sql.Tx, d PurchaseDetai
 		SET product_id = ?, 
 			price = ?,
 			disc = ?,
-			qty = ?
+			qty = ?,
+			uom_id = ?,
+			uom_qty = ?
 		WHERE id = ?
 		AND purchase_id = ?
 	`
@@ -499,7 +887,7 @@ func (u *Purchase) updateDetail(ctx context.Context, tx *sql.Tx, d PurchaseDetai
 
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(ctx, d.Product.ID, d.Price, d.Disc, d.Qty, d.ID, u.ID)
+	_, err = stmt.ExecContext(ctx, d.Product.ID, d.Price, d.Disc, d.Qty, d.Uom.nullID(), d.UomQty, d.ID, u.ID)
 	return err
 }
 
@@ -317,6 +317,10 @@ func (u *PurchaseReturn) Create(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if err := checkClosedPeriod(ctx, tx, u.Date); err != nil {
+		return err
+	}
+
 	const query = `
 		INSERT INTO purchase_returns (code, date, disc, purchase_id, company_id, branch_id, created_by, updated_by, created, updated)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
@@ -350,11 +354,7 @@ func (u *PurchaseReturn) Create(ctx context.Context, tx *sql.Tx) error {
 	u.Purchase.Get(ctx, tx)
 
 	for i, d := range u.PurchaseReturnDetails {
-		// TODO :
-		// VALIDATE that detail is open
-		// 1. Detail belum pernah direturn
-		// 2. Detail belum pernah direceiving
-		detailID, err := u.storeDetail(ctx, tx, d, u.Purchase.ID)
+		detailID, err := u.storeDetail(ctx, tx, d)
 		if err != nil {
 			return err
 		}
@@ -366,7 +366,7 @@ func (u *PurchaseReturn) Create(ctx context.Context, tx *sql.Tx) error {
 	}
 	u.Total = u.Price - u.Disc - u.AdditionalDisc
 
-	return nil
+	return u.checkReceipt(ctx, tx)
 }
 
 // Update purchase return
@@ -376,6 +376,10 @@ func (u *PurchaseReturn) Update(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if err := checkClosedTransaction(ctx, tx, "purchase_returns", u.ID, u.Date); err != nil {
+		return err
+	}
+
 	const query = `
 		UPDATE purchase_returns 
 		SET date = ?, 
@@ -406,7 +410,7 @@ func (u *PurchaseReturn) Update(ctx context.Context, tx *sql.Tx) error {
 
 	for i, d := range u.PurchaseReturnDetails {
 		if d.ID <= 0 {
-			detailID, err := u.storeDetail(ctx, tx, d, u.Purchase.ID)
+			detailID, err := u.storeDetail(ctx, tx, d)
 			if err != nil {
 				return err
 			}
@@ -434,7 +438,7 @@ func (u *PurchaseReturn) Update(ctx context.Context, tx *sql.Tx) error {
 		}
 	}
 
-	return nil
+	return u.checkReceipt(ctx, tx)
 }
 
 // GetExistingDetails return array of existing purchase_return_details id
@@ -485,34 +489,10 @@ func (u *PurchaseReturn) getCode(ctx context.Context, tx *sql.Tx) (string, error
 	return prefix + fmt.Sprintf("%05d", codeInt+1), nil
 }
 
-func (u *PurchaseReturn) storeDetail(ctx context.Context, tx *sql.Tx, d PurchaseReturnDetail, purchaseID uint64) (uint64, error) {
+func (u *PurchaseReturn) storeDetail(ctx context.Context, tx *sql.Tx, d PurchaseReturnDetail) (uint64, error) {
 	var id uint64
 
-	// check :
-	// 1. valid detail purchase return is only product in purchase detail list.
-	// 2. Max qty of product detail purchase return = qty product detail purchase - qty existing return
-	// 3. Jika modul receiving sudah selesai, tambahkan validasi max qty = qty purchase - qty return - qty receiving
-	var qty uint
-	userLogin := ctx.Value(api.Ctx("auth")).(User)
-	err := tx.QueryRowContext(ctx, `
-		SELECT (MAX(purchase_details.qty) - IFNULL(SUM(purchase_return_details.qty), 0)) as qty
-		FROM purchase_details 
-		JOIN purchases ON purchase_details.purchase_id = purchases.id
-		LEFT JOIN purchase_returns ON purchases.id = purchase_returns.purchase_id
-		LEFT JOIN purchase_return_details ON purchase_returns.id = purchase_return_details.purchase_return_id AND purchase_details.product_id = purchase_return_details.product_id
-		WHERE purchase_details.purchase_id=? AND purchase_details.product_id=?
-		AND purchases.company_id=? AND purchases.branch_id=?
-		GROUP BY purchase_details.product_id
-	`, purchaseID, d.Product.ID, userLogin.Company.ID, userLogin.Branch.ID).Scan(&qty)
-
-	if err != nil {
-		return id, err
-	}
-
-	if d.Qty > qty {
-		return id, api.ErrBadRequest(errors.New("Invalid quantity"), "")
-	}
-
+	// quantity is checked against ordered minus returned minus received quantity after all details are stored
 	const queryDetail = `
 		INSERT INTO purchase_return_details (purchase_return_id, product_id, price, disc, qty)
 		VALUES (?, ?, ?, ?, ?)
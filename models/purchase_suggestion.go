@@ -0,0 +1,249 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/libraries/array"
+)
+
+// PurchaseSuggestion : struct of suggested purchase, grouped by last used supplier
+type PurchaseSuggestion struct {
+	Supplier                  Supplier
+	PurchaseSuggestionDetails []PurchaseSuggestionDetail
+}
+
+// PurchaseSuggestionDetail : struct of suggested product to purchase
+type PurchaseSuggestionDetail struct {
+	Product   Product
+	Stock     int
+	OnOrder   int
+	Qty       uint
+	UnitPrice float64
+}
+
+// List purchase suggestions of products which stock plus open purchase is less than minimum stock
+func (u *PurchaseSuggestion) List(ctx context.Context, tx *sql.Tx) ([]PurchaseSuggestion, error) {
+	list := []PurchaseSuggestion{}
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	var branchWhere string
+	var branchParams []interface{}
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "purchases.branch_id=?")
+			branchParams = append(branchParams, b)
+		}
+
+		branchWhere = " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		branchWhere = " AND purchases.branch_id=?"
+		branchParams = append(branchParams, userLogin.Branch.ID)
+	}
+
+	stock := new(Stock)
+	stocks, err := stock.List(ctx, tx)
+	if err != nil {
+		return list, err
+	}
+
+	stockQty := make(map[uint64]int)
+	for _, s := range stocks {
+		stockQty[s.Product.ID] = s.Qty
+	}
+
+	onOrder, err := u.getOnOrder(ctx, tx, branchWhere, branchParams)
+	if err != nil {
+		return list, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT products.id, products.code, products.name, products.purchase_price, products.sale_price, products.minimum_stock
+		FROM products
+		WHERE products.company_id = ? AND products.minimum_stock > 0
+		ORDER BY products.id`, userLogin.Company.ID)
+	if err != nil {
+		return list, err
+	}
+
+	var details []PurchaseSuggestionDetail
+	for rows.Next() {
+		var d PurchaseSuggestionDetail
+		err = rows.Scan(&d.Product.ID, &d.Product.Code, &d.Product.Name, &d.Product.PurchasePrice, &d.Product.SalePrice, &d.Product.MinimumStock)
+		if err != nil {
+			rows.Close()
+			return list, err
+		}
+
+		d.Product.Company = userLogin.Company
+		d.Stock = stockQty[d.Product.ID]
+		d.OnOrder = onOrder[d.Product.ID]
+		available := d.Stock + d.OnOrder
+		if available >= int(d.Product.MinimumStock) {
+			continue
+		}
+
+		d.Qty = uint(int(d.Product.MinimumStock) - available)
+		d.UnitPrice = d.Product.PurchasePrice
+		details = append(details, d)
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return list, err
+	}
+
+	idx := make(map[uint64]int)
+	for _, d := range details {
+		var supplier Supplier
+		var unitPrice float64
+		err = tx.QueryRowContext(ctx, `
+			SELECT purchases.supplier_id, (purchase_details.price - purchase_details.disc) / purchase_details.qty
+			FROM purchases
+			JOIN purchase_details ON purchases.id = purchase_details.purchase_id
+			WHERE purchases.company_id = ? AND purchase_details.product_id = ?
+			AND purchases.status IN ('approved', 'partially_received', 'received', 'closed')`+branchWhere+`
+			ORDER BY purchases.date DESC, purchases.id DESC LIMIT 1`,
+			append([]interface{}{userLogin.Company.ID, d.Product.ID}, branchParams...)...).Scan(&supplier.ID, &unitPrice)
+
+		if err != nil && err != sql.ErrNoRows {
+			return list, err
+		}
+
+		if err == nil {
+			d.UnitPrice = unitPrice
+		}
+
+		i, ok := idx[supplier.ID]
+		if !ok {
+			if supplier.ID > 0 {
+				err = supplier.Get(ctx, tx)
+				if err != nil {
+					return list, err
+				}
+			}
+
+			i = len(list)
+			idx[supplier.ID] = i
+			list = append(list, PurchaseSuggestion{Supplier: supplier})
+		}
+
+		list[i].PurchaseSuggestionDetails = append(list[i].PurchaseSuggestionDetails, d)
+	}
+
+	return list, nil
+}
+
+// CreatePurchase from suggestion of supplier.
+// When productIDs is empty, all suggested products of the supplier are purchased,
+// otherwise only the selected products are purchased regardless of their last used supplier.
+func (u *PurchaseSuggestion) CreatePurchase(ctx context.Context, tx *sql.Tx, date time.Time, productIDs []uint64) (*Purchase, error) {
+	purchase := new(Purchase)
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Branch.ID <= 0 {
+		return purchase, api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	err := u.Supplier.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		return purchase, api.ErrBadRequest(errors.New("Invalid supplier"), "")
+	}
+
+	if err != nil {
+		return purchase, err
+	}
+
+	suggestions, err := u.List(ctx, tx)
+	if err != nil {
+		return purchase, err
+	}
+
+	var arrUint64 array.ArrUint64
+	for _, s := range suggestions {
+		for _, d := range s.PurchaseSuggestionDetails {
+			if len(productIDs) > 0 {
+				if isExist, _ := arrUint64.InArray(d.Product.ID, productIDs); !isExist {
+					continue
+				}
+			} else if s.Supplier.ID != u.Supplier.ID {
+				continue
+			}
+
+			purchase.PurchaseDetails = append(purchase.PurchaseDetails, PurchaseDetail{
+				Product: Product{ID: d.Product.ID},
+				Price:   d.UnitPrice * float64(d.Qty),
+				Qty:     d.Qty,
+				UomQty:  d.Qty,
+			})
+		}
+	}
+
+	if len(purchase.PurchaseDetails) == 0 {
+		return purchase, api.ErrBadRequest(errors.New("No purchase suggestion for supplier"), "")
+	}
+
+	purchase.Date = date
+	purchase.Supplier.ID = u.Supplier.ID
+	err = purchase.Create(ctx, tx)
+
+	return purchase, err
+}
+
+// getOnOrder return open purchase quantity per product, that is ordered quantity of approved purchase which has not been returned or received.
+// Draft and submitted purchase is not on order until it is approved.
+func (u *PurchaseSuggestion) getOnOrder(ctx context.Context, tx *sql.Tx, branchWhere string, branchParams []interface{}) (map[uint64]int, error) {
+	onOrder := make(map[uint64]int)
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT open_purchases.product_id, SUM(GREATEST(open_purchases.qty, 0))
+		FROM (
+			SELECT purchase_details.product_id,
+				CAST(SUM(purchase_details.qty) AS SIGNED)
+				- IFNULL((SELECT SUM(purchase_return_details.qty)
+					FROM purchase_return_details
+					JOIN purchase_returns ON purchase_return_details.purchase_return_id = purchase_returns.id
+					WHERE purchase_returns.purchase_id = purchases.id AND purchase_return_details.product_id = purchase_details.product_id), 0)
+				- IFNULL((SELECT SUM(good_receiving_details.qty)
+					FROM good_receiving_details
+					JOIN good_receivings ON good_receiving_details.good_receiving_id = good_receivings.id
+					WHERE good_receivings.purchase_id = purchases.id AND good_receivings.voided IS NULL AND good_receiving_details.product_id = purchase_details.product_id), 0) qty
+			FROM purchases
+			JOIN purchase_details ON purchases.id = purchase_details.purchase_id
+			WHERE purchases.company_id = ? AND purchases.status IN ('approved', 'partially_received')`+branchWhere+`
+			GROUP BY purchases.id, purchase_details.product_id
+		) open_purchases
+		GROUP BY open_purchases.product_id`,
+		append([]interface{}{userLogin.Company.ID}, branchParams...)...)
+	if err != nil {
+		return onOrder, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID uint64
+		var qty int
+		err = rows.Scan(&productID, &qty)
+		if err != nil {
+			return onOrder, err
+		}
+
+		onOrder[productID] = qty
+	}
+
+	return onOrder, rows.Err()
+}
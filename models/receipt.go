@@ -0,0 +1,187 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// Outstanding return quantity which has not been received, ordered minus returned minus received
+func (u *PurchaseReceipt) Outstanding() int {
+	outstanding := u.Ordered - u.Returned - u.Received
+	if outstanding < 0 {
+		return 0
+	}
+
+	return outstanding
+}
+
+// Allowance return total quantity which can be received, ordered minus returned plus tolerance percentage of it
+func (u *PurchaseReceipt) Allowance(tolerance float64) int {
+	ordered := u.Ordered - u.Returned
+	if ordered <= 0 {
+		return 0
+	}
+
+	return int(math.Floor(float64(ordered) * (100 + tolerance) / 100))
+}
+
+// setReceipt allocate returned quantity from the last lines and received quantity from the first lines of every product,
+// then set remaining quantity to receive of lines. Nothing remains to receive of closed or cancelled purchase.
+func (u *Purchase) setReceipt(receipts map[purchaseKey]PurchaseReceipt) {
+	returned := make(map[uint64]int)
+	for i := len(u.PurchaseDetails) - 1; i >= 0; i-- {
+		d := &u.PurchaseDetails[i]
+		if _, ok := returned[d.Product.ID]; !ok {
+			returned[d.Product.ID] = receipts[purchaseKey{u.ID, d.Product.ID}].Returned
+		}
+
+		qty := returned[d.Product.ID]
+		if qty > int(d.Qty) {
+			qty = int(d.Qty)
+		}
+
+		d.ReturnedQty = uint(qty)
+		returned[d.Product.ID] -= qty
+	}
+
+	received := make(map[uint64]int)
+	for i := range u.PurchaseDetails {
+		d := &u.PurchaseDetails[i]
+		if _, ok := received[d.Product.ID]; !ok {
+			received[d.Product.ID] = receipts[purchaseKey{u.ID, d.Product.ID}].Received
+		}
+
+		qty := received[d.Product.ID]
+		if qty > int(d.Qty-d.ReturnedQty) {
+			qty = int(d.Qty - d.ReturnedQty)
+		}
+
+		d.ReceivedQty = uint(qty)
+		received[d.Product.ID] -= qty
+
+		d.RemainingQty = d.Qty - d.ReturnedQty - d.ReceivedQty
+		if u.Status == PurchaseClosed || u.Status == PurchaseCancelled {
+			d.RemainingQty = 0
+		}
+	}
+
+	// quantity received above the ordered quantity within tolerance is added to the last line of product
+	for i := len(u.PurchaseDetails) - 1; i >= 0; i-- {
+		d := &u.PurchaseDetails[i]
+		if received[d.Product.ID] > 0 {
+			d.ReceivedQty += uint(received[d.Product.ID])
+			received[d.Product.ID] = 0
+		}
+	}
+}
+
+// checkReceipt reject receiving of product which is not purchased or exceeds ordered minus returned minus already received quantity,
+// allowing over receipt up to tolerance of company. Quantity already received excludes the stored details of receive itself.
+func (u *Receive) checkReceipt(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	tolerance, err := userLogin.Company.GetOverReceiptTolerance(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	receipts, err := listPurchaseReceipt(ctx, tx, " AND purchases.id = ?", u.Purchase.ID)
+	if err != nil {
+		return err
+	}
+
+	qtys, err := sumDetailQty(ctx, tx, "good_receiving_details", "good_receiving_id", u.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range u.ReceiveDetails {
+		r, ok := receipts[purchaseKey{u.Purchase.ID, d.Product.ID}]
+		if !ok {
+			return api.ErrBadRequest(fmt.Errorf("Product %s is not purchased in purchase %s", d.Product.Code, u.Purchase.Code), "")
+		}
+
+		if r.Received > r.Allowance(tolerance) {
+			remaining := r.Allowance(tolerance) - (r.Received - qtys[d.Product.ID])
+			if remaining < 0 {
+				remaining = 0
+			}
+
+			return api.ErrBadRequest(fmt.Errorf("Quantity of product %s exceeds remaining quantity to receive %d", d.Product.Code, remaining), "")
+		}
+	}
+
+	return nil
+}
+
+// checkReceipt reject return of product which is not purchased or exceeds ordered minus already returned minus received quantity.
+// Quantity already returned excludes the stored details of purchase return itself.
+func (u *PurchaseReturn) checkReceipt(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	receipts, err := listPurchaseReceipt(ctx, tx, " AND purchases.id = ? AND purchases.branch_id = ?", u.Purchase.ID, userLogin.Branch.ID)
+	if err != nil {
+		return err
+	}
+
+	qtys, err := sumDetailQty(ctx, tx, "purchase_return_details", "purchase_return_id", u.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range u.PurchaseReturnDetails {
+		product := d.Product
+		if len(product.Code) == 0 {
+			err = product.Get(ctx, tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		r, ok := receipts[purchaseKey{u.Purchase.ID, d.Product.ID}]
+		if !ok {
+			return api.ErrBadRequest(fmt.Errorf("Product %s is not purchased in purchase %s", product.Code, u.Purchase.Code), "")
+		}
+
+		if r.Returned+r.Received > r.Ordered {
+			returnable := r.Ordered - r.Received - (r.Returned - qtys[d.Product.ID])
+			if returnable < 0 {
+				returnable = 0
+			}
+
+			return api.ErrBadRequest(fmt.Errorf("Quantity of product %s exceeds returnable quantity %d which has not been received", product.Code, returnable), "")
+		}
+	}
+
+	return nil
+}
+
+// sumDetailQty return stored quantity per product of document in detail table, empty for new document
+func sumDetailQty(ctx context.Context, tx *sql.Tx, table, foreignKey string, id uint64) (map[uint64]int, error) {
+	qtys := make(map[uint64]int)
+	if id == 0 {
+		return qtys, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT product_id, SUM(qty) FROM `+table+` WHERE `+foreignKey+` = ? GROUP BY product_id`, id)
+	if err != nil {
+		return qtys, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID uint64
+		var qty int
+		err = rows.Scan(&productID, &qty)
+		if err != nil {
+			return qtys, err
+		}
+
+		qtys[productID] = qty
+	}
+
+	return qtys, rows.Err()
+}
@@ -23,16 +23,23 @@ type Receive struct {
 	Purchase       Purchase
 	Company        Company
 	Branch         Branch
+	CorrectionOf   Correction
+	Voided         Void
 	ReceiveDetails []ReceiveDetail
 }
 
 // ReceiveDetail struct
 type ReceiveDetail struct {
-	ID      uint64
-	Product Product
-	Qty     uint
-	Code    string
-	Shelve  Shelve
+	ID              uint64
+	Product         Product
+	Qty             uint
+	Uom             Uom
+	UomQty          uint
+	Code            string
+	Shelve          Shelve
+	Lot             string
+	ManufactureDate sql.NullTime
+	ExpiredDate     sql.NullTime
 }
 
 // List Receives
@@ -44,6 +51,7 @@ func (u *Receive) List(ctx context.Context, tx *sql.Tx) ([]Receive, error) {
 	SELECT 	good_receivings.id, 
 		good_receivings.code, 
 		good_receivings.date,
+		good_receivings.voided,
 		purchases.id,
 		purchases.code,
 		companies.id, 
@@ -100,6 +108,7 @@ func (u *Receive) List(ctx context.Context, tx *sql.Tx) ([]Receive, error) {
 			&Receive.ID,
 			&Receive.Code,
 			&Receive.Date,
+			&Receive.Voided.Date,
 			&Receive.Purchase.ID,
 			&Receive.Purchase.Code,
 			&Receive.Company.ID,
@@ -144,6 +153,13 @@ func (u *Receive) Get(ctx context.Context, tx *sql.Tx) error {
 		JSON_ARRAYAGG(good_receiving_details.code),
 		JSON_ARRAYAGG(good_receiving_details.shelve_id),
 		JSON_ARRAYAGG(good_receiving_details.qty),
+		JSON_ARRAYAGG(IFNULL(good_receiving_details.uom_id, 0)),
+		JSON_ARRAYAGG(IFNULL(uoms.code, '')),
+		JSON_ARRAYAGG(IFNULL(uoms.name, '')),
+		JSON_ARRAYAGG(IFNULL(good_receiving_details.uom_qty, good_receiving_details.qty)),
+		JSON_ARRAYAGG(good_receiving_details.lot),
+		JSON_ARRAYAGG(IFNULL(DATE_FORMAT(good_receiving_details.manufacture_date, '%Y-%m-%d'), '')),
+		JSON_ARRAYAGG(IFNULL(DATE_FORMAT(good_receiving_details.expired_date, '%Y-%m-%d'), '')),
 		JSON_ARRAYAGG(products.id),
 		JSON_ARRAYAGG(products.code),
 		JSON_ARRAYAGG(products.name),
@@ -154,6 +170,7 @@ func (u *Receive) Get(ctx context.Context, tx *sql.Tx) error {
 	JOIN branches ON good_receivings.branch_id = branches.id
 	JOIN good_receiving_details ON good_receivings.id = good_receiving_details.good_receiving_id
 	JOIN products ON good_receiving_details.product_id = products.id 
+	LEFT JOIN uoms ON good_receiving_details.uom_id = uoms.id
 	WHERE good_receivings.id=? AND companies.id=?
 	`
 	userLogin := ctx.Value(api.Ctx("auth")).(User)
@@ -179,7 +196,7 @@ func (u *Receive) Get(ctx context.Context, tx *sql.Tx) error {
 		params = append(params, userLogin.Branch.ID)
 	}
 
-	var detailID, detailCode, detailShelveID, detailQty, productID, productCode, productName, productPrice string
+	var detailID, detailCode, detailShelveID, detailQty, detailUomID, detailUomCode, detailUomName, detailUomQty, detailLot, detailManufactureDate, detailExpiredDate, productID, productCode, productName, productPrice string
 	err := tx.QueryRowContext(ctx, query+" GROUP BY good_receivings.id", params...).Scan(
 		&u.ID,
 		&u.Code,
@@ -199,6 +216,13 @@ func (u *Receive) Get(ctx context.Context, tx *sql.Tx) error {
 		&detailCode,
 		&detailShelveID,
 		&detailQty,
+		&detailUomID,
+		&detailUomCode,
+		&detailUomName,
+		&detailUomQty,
+		&detailLot,
+		&detailManufactureDate,
+		&detailExpiredDate,
 		&productID,
 		&productCode,
 		&productName,
@@ -234,6 +258,48 @@ func (u *Receive) Get(ctx context.Context, tx *sql.Tx) error {
 			return err
 		}
 
+		var detailUomIDs []uint64
+		err = json.Unmarshal([]byte(detailUomID), &detailUomIDs)
+		if err != nil {
+			return err
+		}
+
+		var detailUomCodes []string
+		err = json.Unmarshal([]byte(detailUomCode), &detailUomCodes)
+		if err != nil {
+			return err
+		}
+
+		var detailUomNames []string
+		err = json.Unmarshal([]byte(detailUomName), &detailUomNames)
+		if err != nil {
+			return err
+		}
+
+		var detailUomQtys []uint
+		err = json.Unmarshal([]byte(detailUomQty), &detailUomQtys)
+		if err != nil {
+			return err
+		}
+
+		var detailLots []string
+		err = json.Unmarshal([]byte(detailLot), &detailLots)
+		if err != nil {
+			return err
+		}
+
+		var detailManufactureDates []string
+		err = json.Unmarshal([]byte(detailManufactureDate), &detailManufactureDates)
+		if err != nil {
+			return err
+		}
+
+		var detailExpiredDates []string
+		err = json.Unmarshal([]byte(detailExpiredDate), &detailExpiredDates)
+		if err != nil {
+			return err
+		}
+
 		var productIDs []uint64
 		err = json.Unmarshal([]byte(productID), &productIDs)
 		if err != nil {
@@ -259,13 +325,36 @@ func (u *Receive) Get(ctx context.Context, tx *sql.Tx) error {
 		}
 
 		for i, v := range detailIDs {
+			var manufactureDate sql.NullTime
+			if len(detailManufactureDates[i]) > 0 {
+				manufactureDate.Time, err = time.Parse("2006-01-02", detailManufactureDates[i])
+				if err != nil {
+					return err
+				}
+				manufactureDate.Valid = true
+			}
+
+			var expiredDate sql.NullTime
+			if len(detailExpiredDates[i]) > 0 {
+				expiredDate.Time, err = time.Parse("2006-01-02", detailExpiredDates[i])
+				if err != nil {
+					return err
+				}
+				expiredDate.Valid = true
+			}
+
 			u.ReceiveDetails = append(u.ReceiveDetails, ReceiveDetail{
 				ID:   uint64(v),
 				Code: detailCodes[i],
 				Shelve: Shelve{
 					ID: detailShelveIDs[i],
 				},
-				Qty: detailQtys[i],
+				Qty:             detailQtys[i],
+				Uom:             Uom{ID: detailUomIDs[i], Code: detailUomCodes[i], Name: detailUomNames[i]},
+				UomQty:          detailUomQtys[i],
+				Lot:             detailLots[i],
+				ManufactureDate: manufactureDate,
+				ExpiredDate:     expiredDate,
 				Product: Product{
 					ID:        productIDs[i],
 					Code:      productCodes[i],
@@ -277,7 +366,7 @@ func (u *Receive) Get(ctx context.Context, tx *sql.Tx) error {
 		}
 	}
 
-	return nil
+	return getVoid(ctx, tx, "good_receivings", u.ID, &u.Voided, &u.CorrectionOf)
 }
 
 // Create new Receive
@@ -287,6 +376,10 @@ func (u *Receive) Create(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if err := checkClosedPeriod(ctx, tx, u.Date); err != nil {
+		return err
+	}
+
 	err := u.Purchase.Get(ctx, tx)
 	if err != nil {
 		return err
@@ -296,9 +389,18 @@ func (u *Receive) Create(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if !u.Purchase.IsReceivable() {
+		return api.ErrBadRequest(fmt.Errorf("Purchase with status %s can not be received", u.Purchase.Status), "")
+	}
+
+	err = u.CorrectionOf.check(ctx, tx, "receive", "good_receivings")
+	if err != nil {
+		return err
+	}
+
 	const query = `
-		INSERT INTO good_receivings (code, date, remark, purchase_id, company_id, branch_id, created_by, updated_by, created, updated)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+		INSERT INTO good_receivings (code, date, remark, correction_of, purchase_id, company_id, branch_id, created_by, updated_by, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
 	`
 	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
@@ -312,7 +414,7 @@ func (u *Receive) Create(ctx context.Context, tx *sql.Tx) error {
 		return err
 	}
 
-	res, err := stmt.ExecContext(ctx, u.Code, u.Date, u.Remark, u.Purchase.ID, userLogin.Company.ID, userLogin.Branch.ID, userLogin.ID, userLogin.ID)
+	res, err := stmt.ExecContext(ctx, u.Code, u.Date, u.Remark, u.CorrectionOf.nullID(), u.Purchase.ID, userLogin.Company.ID, userLogin.Branch.ID, userLogin.ID, userLogin.ID)
 	if err != nil {
 		return err
 	}
@@ -328,6 +430,14 @@ func (u *Receive) Create(ctx context.Context, tx *sql.Tx) error {
 	u.Branch.Company = u.Company
 
 	for i, d := range u.ReceiveDetails {
+		d.Qty, err = d.Product.ConvertQty(ctx, tx, &d.Uom, d.UomQty)
+		if err != nil {
+			return err
+		}
+
+		u.ReceiveDetails[i].Qty = d.Qty
+		u.ReceiveDetails[i].Uom = d.Uom
+
 		err := u.storeDetail(ctx, tx, d, i)
 		if err != nil {
 			return err
@@ -335,7 +445,7 @@ func (u *Receive) Create(ctx context.Context, tx *sql.Tx) error {
 		u.ReceiveDetails[i].Product.Get(ctx, tx)
 	}
 
-	return nil
+	return u.checkReceipt(ctx, tx)
 }
 
 // Update Receive
@@ -345,6 +455,14 @@ func (u *Receive) Update(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if err := u.Voided.checkEditable(ctx, tx, "Receive", true); err != nil {
+		return err
+	}
+
+	if err := checkClosedTransaction(ctx, tx, "good_receivings", u.ID, u.Date); err != nil {
+		return err
+	}
+
 	err := u.Purchase.Get(ctx, tx)
 	if err != nil {
 		return err
@@ -354,6 +472,10 @@ func (u *Receive) Update(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if !u.Purchase.IsReceivable() {
+		return api.ErrBadRequest(fmt.Errorf("Purchase with status %s can not be received", u.Purchase.Status), "")
+	}
+
 	const query = `
 		UPDATE good_receivings 
 		SET date = ?, 
@@ -383,6 +505,14 @@ func (u *Receive) Update(ctx context.Context, tx *sql.Tx) error {
 	}
 
 	for i, d := range u.ReceiveDetails {
+		d.Qty, err = d.Product.ConvertQty(ctx, tx, &d.Uom, d.UomQty)
+		if err != nil {
+			return err
+		}
+
+		u.ReceiveDetails[i].Qty = d.Qty
+		u.ReceiveDetails[i].Uom = d.Uom
+
 		if d.ID <= 0 {
 			err := u.storeDetail(ctx, tx, d, i)
 			if err != nil {
@@ -415,7 +545,29 @@ func (u *Receive) Update(ctx context.Context, tx *sql.Tx) error {
 		}
 	}
 
-	return nil
+	return u.checkReceipt(ctx, tx)
+}
+
+// Void posted receive, its received units are reversed out of inventories on the void date.
+// Receive which units have been returned must have its receive returns voided first.
+func (u *Receive) Void(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Company.ID != u.Company.ID || u.Branch.ID <= 0 || userLogin.Branch.ID != u.Branch.ID {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	var returned bool
+	err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) > 0 FROM receiving_returns WHERE good_receiving_id = ? AND voided IS NULL`, u.ID).Scan(&returned)
+	if err != nil {
+		return err
+	}
+
+	if returned {
+		return api.ErrBadRequest(errors.New("Receive has receive returns which have not been voided"), "")
+	}
+
+	return u.Voided.void(ctx, tx, "Receive", "good_receivings", "GR", u.ID)
 }
 
 // GetExistingDetails return array of existing receive_details id
@@ -456,10 +608,15 @@ func (u *Receive) storeDetail(ctx context.Context, tx *sql.Tx, d ReceiveDetail,
 	var err error
 
 	const queryDetail = `
-		INSERT INTO good_receiving_details (good_receiving_id, product_id, qty, code, shelve_id)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO good_receiving_details (good_receiving_id, product_id, qty, uom_id, uom_qty, code, shelve_id, lot, manufacture_date, expired_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
+	err = d.Product.CheckQty(ctx, tx, d.Qty)
+	if err != nil {
+		return err
+	}
+
 	d.Code, err = u.getProductCode(ctx, tx, d.Product.ID)
 	if err != nil {
 		return err
@@ -472,7 +629,7 @@ func (u *Receive) storeDetail(ctx context.Context, tx *sql.Tx, d ReceiveDetail,
 
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Qty, d.Code, d.Shelve.ID)
+	res, err := stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Qty, d.Uom.nullID(), d.UomQty, d.Code, d.Shelve.ID, d.Lot, d.ManufactureDate, d.ExpiredDate)
 	if err != nil {
 		return err
 	}
@@ -487,13 +644,26 @@ func (u *Receive) storeDetail(ctx context.Context, tx *sql.Tx, d ReceiveDetail,
 	inventory.ShelveID = d.Shelve.ID
 	inventory.ProductID = d.Product.ID
 	inventory.ProductCode = d.Code
+	inventory.Lot = d.Lot
 	inventory.TransactionID = u.ID
 	inventory.Code = u.Code
 	inventory.TransactionDate = u.Date
 	inventory.Type = "GR"
 	inventory.InOut = true
-	inventory.Qty = 1
-	return inventory.Create(ctx, tx)
+	inventory.Qty = d.Qty
+	inventory.Cost, err = u.getUnitCost(ctx, tx, d.Product.ID)
+	if err != nil {
+		return err
+	}
+
+	err = inventory.Create(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	err = d.Shelve.CheckCapacity(ctx, tx)
+	u.ReceiveDetails[i].Shelve = d.Shelve
+	return err
 }
 
 func (u *Receive) updateDetail(ctx context.Context, tx *sql.Tx, d ReceiveDetail) error {
@@ -501,17 +671,28 @@ func (u *Receive) updateDetail(ctx context.Context, tx *sql.Tx, d ReceiveDetail)
 		UPDATE good_receiving_details 
 		SET product_id = ?, 
 			code = ?,
-			shelve_id = ?
+			qty = ?,
+			uom_id = ?,
+			uom_qty = ?,
+			shelve_id = ?,
+			lot = ?,
+			manufacture_date = ?,
+			expired_date = ?
 		WHERE id = ?
 		AND good_receiving_id = ?
 	`
 
+	err := d.Product.CheckQty(ctx, tx, d.Qty)
+	if err != nil {
+		return err
+	}
+
 	inventory := new(Inventory)
 	inventory.ProductID = d.Product.ID
 	inventory.ProductCode = d.Code
 	inventory.TransactionID = u.ID
 	inventory.Type = "GR"
-	err := inventory.GetByComposit(ctx, tx)
+	err = inventory.GetByComposit(ctx, tx)
 	if err != nil {
 		return err
 	}
@@ -523,7 +704,12 @@ func (u *Receive) updateDetail(ctx context.Context, tx *sql.Tx, d ReceiveDetail)
 
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(ctx, d.Product.ID, d.Code, d.Shelve.ID, d.ID, u.ID)
+	_, err = stmt.ExecContext(ctx, d.Product.ID, d.Code, d.Qty, d.Uom.nullID(), d.UomQty, d.Shelve.ID, d.Lot, d.ManufactureDate, d.ExpiredDate, d.ID, u.ID)
+	if err != nil {
+		return err
+	}
+
+	err = u.relabelLot(ctx, tx, d)
 	if err != nil {
 		return err
 	}
@@ -531,8 +717,27 @@ func (u *Receive) updateDetail(ctx context.Context, tx *sql.Tx, d ReceiveDetail)
 	inventory.ProductID = d.Product.ID
 	inventory.ProductCode = d.Code
 	inventory.ShelveID = d.Shelve.ID
+	inventory.Qty = d.Qty
+
+	err = inventory.Update(ctx, tx)
+	if err != nil {
+		return err
+	}
 
-	return inventory.Update(ctx, tx)
+	// the reduced quantity must not be less than quantity which has been moved out of unit code
+	position := new(Inventory)
+	position.ProductID = d.Product.ID
+	position.ProductCode = d.Code
+	qty, err := position.GetLastPosition(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if qty < 0 {
+		return api.ErrBadRequest(fmt.Errorf("Quantity of unit code %s is less than its moved out quantity", d.Code), "")
+	}
+
+	return d.Shelve.CheckCapacity(ctx, tx)
 }
 
 func (u *Receive) removeDetail(ctx context.Context, tx *sql.Tx, e uint64) error {
@@ -564,6 +769,44 @@ func (u *Receive) removeDetail(ctx context.Context, tx *sql.Tx, e uint64) error
 	return inventory.DeleteByComposit(ctx, tx)
 }
 
+// relabelLot correct the lot of unit code on its whole movements and documents which have been followed the receiving
+func (u *Receive) relabelLot(ctx context.Context, tx *sql.Tx, d ReceiveDetail) error {
+	companyID := ctx.Value(api.Ctx("auth")).(User).Company.ID
+	_, err := tx.ExecContext(ctx,
+		`UPDATE inventories SET lot = ? WHERE company_id = ? AND product_id = ? AND product_code = ?`,
+		d.Lot, companyID, d.Product.ID, d.Code)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{"delivery_details", "delivery_return_details", "receiving_return_details"} {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE `+table+` SET lot = ? WHERE product_id = ? AND code = ?`,
+			d.Lot, d.Product.ID, d.Code)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getUnitCost of product from its purchase details and keep it as the last purchase price of product
+func (u *Receive) getUnitCost(ctx context.Context, tx *sql.Tx, productID uint64) (float64, error) {
+	var cost float64
+	err := tx.QueryRowContext(ctx, `
+		SELECT IFNULL(SUM(price - disc) / SUM(qty), 0) 
+		FROM purchase_details 
+		WHERE purchase_id = ? AND product_id = ?`,
+		u.Purchase.ID, productID).Scan(&cost)
+	if err != nil || cost <= 0 {
+		return cost, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE products SET purchase_price = ? WHERE id = ?`, cost, productID)
+	return cost, err
+}
+
 func (u *Receive) getProductCode(ctx context.Context, tx *sql.Tx, productID uint64) (string, error) {
 	var code string
 	var err error
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -22,6 +23,10 @@ type ReceiveReturn struct {
 	Company              Company
 	Branch               Branch
 	ReceiveReturnDetails []ReceiveReturnDetail
+	Posted               bool
+	Approvals            []Approval
+	CorrectionOf         Correction
+	Voided               Void
 }
 
 // ReceiveReturnDetail struct
@@ -29,6 +34,7 @@ type ReceiveReturnDetail struct {
 	ID      uint64
 	Product Product
 	Code    string
+	Lot     string
 	Qty     uint
 }
 
@@ -42,6 +48,8 @@ func (u *ReceiveReturn) List(ctx context.Context, tx *sql.Tx) ([]ReceiveReturn,
 		receiving_returns.code, 
 		receiving_returns.date,
 		receiving_returns.remark,
+		receiving_returns.posted,
+		receiving_returns.voided,
 		good_receivings.id,
 		good_receivings.code,
 		good_receivings.date,
@@ -98,6 +106,8 @@ func (u *ReceiveReturn) List(ctx context.Context, tx *sql.Tx) ([]ReceiveReturn,
 			&receiveReturn.Code,
 			&receiveReturn.Date,
 			&receiveReturn.Remark,
+			&receiveReturn.Posted,
+			&receiveReturn.Voided.Date,
 			&receiveReturn.Receive.ID,
 			&receiveReturn.Receive.Code,
 			&receiveReturn.Receive.Date,
@@ -133,6 +143,8 @@ func (u *ReceiveReturn) Get(ctx context.Context, tx *sql.Tx) error {
 		receiving_returns.code, 
 		receiving_returns.date,
 		receiving_returns.remark,
+		receiving_returns.posted,
+		receiving_returns.voided,
 		good_receivings.id,
 		good_receivings.code,
 		good_receivings.date,
@@ -148,6 +160,7 @@ func (u *ReceiveReturn) Get(ctx context.Context, tx *sql.Tx) error {
 		branches.type,
 		JSON_ARRAYAGG(receiving_return_details.id),
 		JSON_ARRAYAGG(receiving_return_details.code),
+		JSON_ARRAYAGG(receiving_return_details.lot),
 		JSON_ARRAYAGG(receiving_return_details.qty),
 		JSON_ARRAYAGG(products.id),
 		JSON_ARRAYAGG(products.code),
@@ -184,12 +197,14 @@ func (u *ReceiveReturn) Get(ctx context.Context, tx *sql.Tx) error {
 		params = append(params, userLogin.Branch.ID)
 	}
 
-	var detailID, detailCode, detailQty, productID, productCode, productName, productPrice string
+	var detailID, detailCode, detailLot, detailQty, productID, productCode, productName, productPrice string
 	err := tx.QueryRowContext(ctx, query+" GROUP BY receiving_returns.id", params...).Scan(
 		&u.ID,
 		&u.Code,
 		&u.Date,
 		&u.Remark,
+		&u.Posted,
+		&u.Voided.Date,
 		&u.Receive.ID,
 		&u.Receive.Code,
 		&u.Receive.Date,
@@ -205,6 +220,7 @@ func (u *ReceiveReturn) Get(ctx context.Context, tx *sql.Tx) error {
 		&u.Branch.Type,
 		&detailID,
 		&detailCode,
+		&detailLot,
 		&detailQty,
 		&productID,
 		&productCode,
@@ -229,6 +245,12 @@ func (u *ReceiveReturn) Get(ctx context.Context, tx *sql.Tx) error {
 			return err
 		}
 
+		var detailLots []string
+		err = json.Unmarshal([]byte(detailLot), &detailLots)
+		if err != nil {
+			return err
+		}
+
 		var detailQtys []uint
 		err = json.Unmarshal([]byte(detailQty), &detailQtys)
 		if err != nil {
@@ -263,6 +285,7 @@ func (u *ReceiveReturn) Get(ctx context.Context, tx *sql.Tx) error {
 			u.ReceiveReturnDetails = append(u.ReceiveReturnDetails, ReceiveReturnDetail{
 				ID:   uint64(v),
 				Code: detailCodes[i],
+				Lot:  detailLots[i],
 				Qty:  detailQtys[i],
 				Product: Product{
 					ID:        productIDs[i],
@@ -278,7 +301,12 @@ func (u *ReceiveReturn) Get(ctx context.Context, tx *sql.Tx) error {
 	u.Receive.Company = u.Company
 	u.Branch.Company = u.Company
 
-	return nil
+	u.Approvals, err = ListApprovals(ctx, tx, ApprovalReceiveReturn, u.ID)
+	if err != nil {
+		return err
+	}
+
+	return getVoid(ctx, tx, "receiving_returns", u.ID, &u.Voided, &u.CorrectionOf)
 }
 
 // Create new receive return
@@ -288,9 +316,18 @@ func (u *ReceiveReturn) Create(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if err := checkClosedPeriod(ctx, tx, u.Date); err != nil {
+		return err
+	}
+
+	err := u.CorrectionOf.check(ctx, tx, "receive return", "receiving_returns")
+	if err != nil {
+		return err
+	}
+
 	const query = `
-		INSERT INTO receiving_returns (code, date, remark, good_receiving_id, company_id, branch_id, created_by, updated_by, created, updated)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+		INSERT INTO receiving_returns (code, date, remark, correction_of, good_receiving_id, company_id, branch_id, created_by, updated_by, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
 	`
 	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
@@ -304,7 +341,7 @@ func (u *ReceiveReturn) Create(ctx context.Context, tx *sql.Tx) error {
 		return err
 	}
 
-	res, err := stmt.ExecContext(ctx, u.Code, u.Date, u.Remark, u.Receive.ID, userLogin.Company.ID, userLogin.Branch.ID, userLogin.ID, userLogin.ID)
+	res, err := stmt.ExecContext(ctx, u.Code, u.Date, u.Remark, u.CorrectionOf.nullID(), u.Receive.ID, userLogin.Company.ID, userLogin.Branch.ID, userLogin.ID, userLogin.ID)
 	if err != nil {
 		return err
 	}
@@ -320,6 +357,19 @@ func (u *ReceiveReturn) Create(ctx context.Context, tx *sql.Tx) error {
 	u.Branch.Company = u.Company
 	u.Receive.Get(ctx, tx)
 
+	approval, err := u.submitApproval(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	u.Posted = approval.ID == 0
+	if !u.Posted {
+		_, err = tx.ExecContext(ctx, `UPDATE receiving_returns SET posted = 0 WHERE id = ?`, u.ID)
+		if err != nil {
+			return err
+		}
+	}
+
 	for i, d := range u.ReceiveReturnDetails {
 		err = u.storeDetail(ctx, tx, d, u.Receive.ID, i)
 		if err != nil {
@@ -339,6 +389,14 @@ func (u *ReceiveReturn) Update(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if err := u.Voided.checkEditable(ctx, tx, "Receive return", u.Posted); err != nil {
+		return err
+	}
+
+	if err := checkClosedTransaction(ctx, tx, "receiving_returns", u.ID, u.Date); err != nil {
+		return err
+	}
+
 	const query = `
 		UPDATE receiving_returns 
 		SET date = ?, 
@@ -362,6 +420,20 @@ func (u *ReceiveReturn) Update(ctx context.Context, tx *sql.Tx) error {
 		return err
 	}
 
+	// changed document is checked against approval rules again, posted document which now requires approval
+	// is taken out of inventories and waits for approval before it is posted again
+	approval, err := u.submitApproval(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if u.Posted && approval.ID > 0 {
+		err = u.unpost(ctx, tx)
+		if err != nil {
+			return err
+		}
+	}
+
 	existingDetails, err := u.GetExistingDetails(ctx, tx)
 	if err != nil {
 		return err
@@ -395,6 +467,103 @@ func (u *ReceiveReturn) Update(ctx context.Context, tx *sql.Tx) error {
 	return nil
 }
 
+// Post receive return which has been approved into inventories
+func (u *ReceiveReturn) Post(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Company.ID != u.Company.ID || u.Branch.ID <= 0 || userLogin.Branch.ID != u.Branch.ID {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	if u.Voided.Date.Valid {
+		return api.ErrBadRequest(errors.New("Receive return has been voided"), "")
+	}
+
+	if u.Posted {
+		return api.ErrBadRequest(errors.New("Receive return has been posted"), "")
+	}
+
+	err := checkClosedPeriod(ctx, tx, u.Date)
+	if err != nil {
+		return err
+	}
+
+	err = checkApproval(ctx, tx, ApprovalReceiveReturn, u.ID, u.Code)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range u.ReceiveReturnDetails {
+		var shelveID uint64
+		err = tx.QueryRowContext(ctx, `SELECT shelve_id FROM good_receiving_details WHERE good_receiving_id = ? AND product_id = ? AND code = ? LIMIT 1`,
+			u.Receive.ID, d.Product.ID, d.Code).Scan(&shelveID)
+		if err != nil {
+			return err
+		}
+
+		err = u.checkOnHand(ctx, tx, d)
+		if err != nil {
+			return err
+		}
+
+		err = u.postDetail(ctx, tx, d, shelveID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE receiving_returns SET posted = 1, updated_by = ?, updated = NOW() WHERE id = ? AND company_id = ?`,
+		userLogin.ID, u.ID, userLogin.Company.ID)
+	if err != nil {
+		return err
+	}
+
+	u.Posted = true
+	return nil
+}
+
+// Void receive return, its returned units are reversed in inventories on the void date when it has been posted,
+// while approvals of receive return which has not been posted are superseded
+func (u *ReceiveReturn) Void(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Company.ID != u.Company.ID || u.Branch.ID <= 0 || userLogin.Branch.ID != u.Branch.ID {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	err := u.Voided.void(ctx, tx, "Receive return", "receiving_returns", "RR", u.ID)
+	if err != nil {
+		return err
+	}
+
+	if u.Posted {
+		return nil
+	}
+
+	err = supersedeApprovals(ctx, tx, ApprovalReceiveReturn, u.ID)
+	if err != nil {
+		return err
+	}
+
+	u.Approvals, err = ListApprovals(ctx, tx, ApprovalReceiveReturn, u.ID)
+	return err
+}
+
+// submitApproval request approval of receive return by its total quantity, receive return requiring approval
+// is not posted into inventories until it is approved and posted
+func (u *ReceiveReturn) submitApproval(ctx context.Context, tx *sql.Tx) (Approval, error) {
+	var qty uint
+	for _, d := range u.ReceiveReturnDetails {
+		qty += d.Qty
+	}
+
+	approval, err := requestApproval(ctx, tx, ApprovalReceiveReturn, u.ID, u.Code, float64(qty), 0)
+	if err != nil {
+		return approval, err
+	}
+
+	u.Approvals, err = ListApprovals(ctx, tx, ApprovalReceiveReturn, u.ID)
+	return approval, err
+}
+
 // GetExistingDetails return array of existing Receive_return_details id
 func (u *ReceiveReturn) GetExistingDetails(ctx context.Context, tx *sql.Tx) ([]uint64, error) {
 	var list []uint64
@@ -429,28 +598,65 @@ func (u *ReceiveReturn) getDetail(ctx context.Context, tx *sql.Tx, e uint64) (*R
 	return detail, err
 }
 
+// checkOnHand reject returned unit code which quantity on hand in branch of user login is less than returned quantity
+func (u *ReceiveReturn) checkOnHand(ctx context.Context, tx *sql.Tx, d ReceiveReturnDetail) error {
+	position := new(Inventory)
+	position.ProductID = d.Product.ID
+	position.ProductCode = d.Code
+	qty, err := position.GetLastPosition(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if qty < int(d.Qty) || position.BranchID != ctx.Value(api.Ctx("auth")).(User).Branch.ID {
+		return api.ErrBadRequest(fmt.Errorf("Unit code %s only has %d on hand", d.Code, qty), "")
+	}
+
+	return nil
+}
+
 func (u *ReceiveReturn) storeDetail(ctx context.Context, tx *sql.Tx, d ReceiveReturnDetail, receiveID uint64, i int) error {
 	// check :
 	// 1. valid detail Receive return is only product in Receive detail list.
+	// 2. quantity does not exceed the received quantity which has not been returned and the quantity on hand.
 	userLogin := ctx.Value(api.Ctx("auth")).(User)
-	var code string
+	err := d.Product.CheckQty(ctx, tx, d.Qty)
+	if err != nil {
+		return err
+	}
+
 	var shelveID uint64
-	err := tx.QueryRowContext(ctx, `
-		SELECT good_receiving_details.code, good_receiving_details.shelve_id 
+	var returnable int
+	err = tx.QueryRowContext(ctx, `
+		SELECT good_receiving_details.shelve_id, good_receiving_details.lot, good_receiving_details.qty - IFNULL(SUM(receiving_return_details.qty), 0)
 		FROM good_receiving_details
-		JOIN good_receivings ON good_receiving_details.good_receiving_id = good_receivings.id AND good_receivings.company_id = ? AND good_receivings.branch_id = ?
-		LEFT JOIN receiving_returns ON receiving_returns.company_id = good_receivings.company_id AND receiving_returns.branch_id = good_receivings.branch_id AND receiving_returns.good_receiving_id = good_receivings.id
+		JOIN good_receivings ON good_receiving_details.good_receiving_id = good_receivings.id AND good_receivings.company_id = ? AND good_receivings.branch_id = ? AND good_receivings.voided IS NULL
+		LEFT JOIN receiving_returns ON receiving_returns.company_id = good_receivings.company_id AND receiving_returns.branch_id = good_receivings.branch_id AND receiving_returns.good_receiving_id = good_receivings.id AND receiving_returns.voided IS NULL
 		LEFT JOIN receiving_return_details ON receiving_returns.id = receiving_return_details.receiving_return_id AND receiving_return_details.product_id = good_receiving_details.product_id AND receiving_return_details.code = good_receiving_details.code
-		WHERE good_receiving_details.product_id = ? AND good_receiving_details.code = ? AND receiving_return_details.code is null 
-	`, userLogin.Company.ID, userLogin.Branch.ID, d.Product.ID, d.Code).Scan(&code, &shelveID)
+		WHERE good_receiving_details.good_receiving_id = ? AND good_receiving_details.product_id = ? AND good_receiving_details.code = ?
+		GROUP BY good_receiving_details.id
+	`, userLogin.Company.ID, userLogin.Branch.ID, receiveID, d.Product.ID, d.Code).Scan(&shelveID, &d.Lot, &returnable)
 
+	if err == sql.ErrNoRows {
+		return api.ErrBadRequest(fmt.Errorf("Unit code %s is not in the good receiving", d.Code), "")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if returnable < int(d.Qty) {
+		return api.ErrBadRequest(fmt.Errorf("Unit code %s only has %d returnable", d.Code, returnable), "")
+	}
+
+	err = u.checkOnHand(ctx, tx, d)
 	if err != nil {
 		return err
 	}
 
 	const queryDetail = `
-		INSERT INTO receiving_return_details (receiving_return_id, product_id, code, qty)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO receiving_return_details (receiving_return_id, product_id, code, lot, qty)
+		VALUES (?, ?, ?, ?, ?)
 	`
 	stmt, err := tx.PrepareContext(ctx, queryDetail)
 	if err != nil {
@@ -459,7 +665,7 @@ func (u *ReceiveReturn) storeDetail(ctx context.Context, tx *sql.Tx, d ReceiveRe
 
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Code, d.Qty)
+	res, err := stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Code, d.Lot, d.Qty)
 	if err != nil {
 		return err
 	}
@@ -470,18 +676,67 @@ func (u *ReceiveReturn) storeDetail(ctx context.Context, tx *sql.Tx, d ReceiveRe
 	}
 
 	u.ReceiveReturnDetails[i].ID = uint64(detailID)
+	u.ReceiveReturnDetails[i].Lot = d.Lot
+
+	if !u.Posted {
+		return nil
+	}
 
+	return u.postDetail(ctx, tx, d, shelveID)
+}
+
+// unpost take posted receive return out of inventories, it is posted again after its approval is approved
+func (u *ReceiveReturn) unpost(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	existingDetails, err := u.GetExistingDetails(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range existingDetails {
+		d, err := u.getDetail(ctx, tx, e)
+		if err != nil {
+			return err
+		}
+
+		inventory := Inventory{
+			CompanyID:     userLogin.Company.ID,
+			BranchID:      userLogin.Branch.ID,
+			ProductID:     d.Product.ID,
+			ProductCode:   d.Code,
+			TransactionID: u.ID,
+			Type:          "RR",
+		}
+		err = inventory.DeleteByComposit(ctx, tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE receiving_returns SET posted = 0 WHERE id = ?`, u.ID)
+	if err != nil {
+		return err
+	}
+
+	u.Posted = false
+	return nil
+}
+
+// postDetail post returned unit code into inventories
+func (u *ReceiveReturn) postDetail(ctx context.Context, tx *sql.Tx, d ReceiveReturnDetail, shelveID uint64) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
 	inventory := new(Inventory)
 	inventory.CompanyID = userLogin.Company.ID
 	inventory.BranchID = userLogin.Branch.ID
 	inventory.ProductID = d.Product.ID
 	inventory.ProductCode = d.Code
+	inventory.Lot = d.Lot
 	inventory.TransactionID = u.ID
 	inventory.Code = u.Code
 	inventory.TransactionDate = u.Date
 	inventory.Type = "RR"
 	inventory.InOut = false
-	inventory.Qty = 1
+	inventory.Qty = d.Qty
 	inventory.ShelveID = shelveID
 	return inventory.Create(ctx, tx)
 }
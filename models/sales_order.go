@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -25,16 +26,25 @@ type SalesOrder struct {
 	Customer          Customer
 	Company           Company
 	Branch            Branch
+	Cancelled         sql.NullTime
+	Status            string
 	SalesOrderDetails []SalesOrderDetail
+	Approvals         []Approval
 }
 
 // SalesOrderDetail struct
 type SalesOrderDetail struct {
-	ID      uint64
-	Product Product
-	Price   float64
-	Disc    float64
-	Qty     uint
+	ID           uint64
+	Product      Product
+	Price        float64
+	Disc         float64
+	Qty          uint
+	Uom          Uom
+	UomQty       uint
+	BackorderQty uint
+	DeliveredQty uint
+	ReturnedQty  uint
+	Status       string
 }
 
 // List sales orders
@@ -66,7 +76,8 @@ func (u *SalesOrder) List(ctx context.Context, tx *sql.Tx) ([]SalesOrder, error)
 		branches.type,
 		SUM(sales_order_details.price),
 		SUM(sales_order_details.disc),
-		sales_orders.disc
+		sales_orders.disc,
+		sales_orders.cancelled
 	FROM sales_orders
 	JOIN customers ON sales_orders.customer_id = customers.id
 	JOIN companies ON sales_orders.company_id = companies.id
@@ -134,6 +145,7 @@ func (u *SalesOrder) List(ctx context.Context, tx *sql.Tx) ([]SalesOrder, error)
 			&salesOrder.Price,
 			&salesOrder.Disc,
 			&salesOrder.AdditionalDisc,
+			&salesOrder.Cancelled,
 		)
 
 		if err != nil {
@@ -147,7 +159,25 @@ func (u *SalesOrder) List(ctx context.Context, tx *sql.Tx) ([]SalesOrder, error)
 		list = append(list, salesOrder)
 	}
 
-	return list, rows.Err()
+	if err = rows.Err(); err != nil {
+		return list, err
+	}
+
+	fulfillments, err := listFulfillment(ctx, tx, "")
+	if err != nil {
+		return list, err
+	}
+
+	statuses := make(map[uint64][]string)
+	for key, f := range fulfillments {
+		statuses[key.SalesOrderID] = append(statuses[key.SalesOrderID], fulfillmentStatus(f.Ordered-f.Returned, f.Delivered))
+	}
+
+	for i := range list {
+		list[i].Status = headerStatus(list[i].Cancelled.Valid, statuses[list[i].ID])
+	}
+
+	return list, nil
 }
 
 // Get sales order by id
@@ -180,11 +210,17 @@ func (u *SalesOrder) Get(ctx context.Context, tx *sql.Tx) error {
 		JSON_ARRAYAGG(sales_order_details.price),
 		JSON_ARRAYAGG(sales_order_details.disc),
 		JSON_ARRAYAGG(sales_order_details.qty),
+		JSON_ARRAYAGG(IFNULL(sales_order_details.uom_id, 0)),
+		JSON_ARRAYAGG(IFNULL(uoms.code, '')),
+		JSON_ARRAYAGG(IFNULL(uoms.name, '')),
+		JSON_ARRAYAGG(IFNULL(sales_order_details.uom_qty, sales_order_details.qty)),
+		JSON_ARRAYAGG(sales_order_details.backorder_qty),
 		JSON_ARRAYAGG(products.id),
 		JSON_ARRAYAGG(products.code),
 		JSON_ARRAYAGG(products.name),
 		JSON_ARRAYAGG(products.sale_price),
-		sales_orders.disc
+		sales_orders.disc,
+		sales_orders.cancelled
 	FROM sales_orders
 	JOIN companies ON sales_orders.company_id = companies.id
 	JOIN salesmen ON sales_orders.salesman_id = salesmen.id
@@ -192,6 +228,7 @@ func (u *SalesOrder) Get(ctx context.Context, tx *sql.Tx) error {
 	JOIN branches ON sales_orders.branch_id = branches.id
 	JOIN sales_order_details ON sales_orders.id = sales_order_details.sales_order_id
 	JOIN products ON sales_order_details.product_id = products.id 
+	LEFT JOIN uoms ON sales_order_details.uom_id = uoms.id
 	WHERE sales_orders.id=? AND companies.id=?
 	`
 	userLogin := ctx.Value(api.Ctx("auth")).(User)
@@ -217,7 +254,7 @@ func (u *SalesOrder) Get(ctx context.Context, tx *sql.Tx) error {
 		params = append(params, userLogin.Branch.ID)
 	}
 
-	var detailID, detailPrice, detailDisc, detailQty, productID, productCode, productName, productPrice string
+	var detailID, detailPrice, detailDisc, detailQty, detailUomID, detailUomCode, detailUomName, detailUomQty, detailBackorderQty, productID, productCode, productName, productPrice string
 	err := tx.QueryRowContext(ctx, query+" GROUP BY sales_orders.id", params...).Scan(
 		&u.ID,
 		&u.Code,
@@ -246,11 +283,17 @@ func (u *SalesOrder) Get(ctx context.Context, tx *sql.Tx) error {
 		&detailPrice,
 		&detailDisc,
 		&detailQty,
+		&detailUomID,
+		&detailUomCode,
+		&detailUomName,
+		&detailUomQty,
+		&detailBackorderQty,
 		&productID,
 		&productCode,
 		&productName,
 		&productPrice,
 		&u.AdditionalDisc,
+		&u.Cancelled,
 	)
 
 	if err != nil {
@@ -284,6 +327,36 @@ func (u *SalesOrder) Get(ctx context.Context, tx *sql.Tx) error {
 			return err
 		}
 
+		var detailUomIDs []uint64
+		err = json.Unmarshal([]byte(detailUomID), &detailUomIDs)
+		if err != nil {
+			return err
+		}
+
+		var detailUomCodes []string
+		err = json.Unmarshal([]byte(detailUomCode), &detailUomCodes)
+		if err != nil {
+			return err
+		}
+
+		var detailUomNames []string
+		err = json.Unmarshal([]byte(detailUomName), &detailUomNames)
+		if err != nil {
+			return err
+		}
+
+		var detailUomQtys []uint
+		err = json.Unmarshal([]byte(detailUomQty), &detailUomQtys)
+		if err != nil {
+			return err
+		}
+
+		var detailBackorderQtys []uint
+		err = json.Unmarshal([]byte(detailBackorderQty), &detailBackorderQtys)
+		if err != nil {
+			return err
+		}
+
 		var productIDs []uint64
 		err = json.Unmarshal([]byte(productID), &productIDs)
 		if err != nil {
@@ -310,10 +383,13 @@ func (u *SalesOrder) Get(ctx context.Context, tx *sql.Tx) error {
 
 		for i, v := range detailIDs {
 			u.SalesOrderDetails = append(u.SalesOrderDetails, SalesOrderDetail{
-				ID:    uint64(v),
-				Price: detailPrices[i],
-				Disc:  detailDiscs[i],
-				Qty:   detailQtys[i],
+				ID:           uint64(v),
+				Price:        detailPrices[i],
+				Disc:         detailDiscs[i],
+				Qty:          detailQtys[i],
+				Uom:          Uom{ID: detailUomIDs[i], Code: detailUomCodes[i], Name: detailUomNames[i]},
+				UomQty:       detailUomQtys[i],
+				BackorderQty: detailBackorderQtys[i],
 				Product: Product{
 					ID:        productIDs[i],
 					Code:      productCodes[i],
@@ -328,7 +404,12 @@ func (u *SalesOrder) Get(ctx context.Context, tx *sql.Tx) error {
 	u.Salesman.Company = u.Company
 	u.Branch.Company = u.Company
 
-	return nil
+	u.Approvals, err = ListApprovals(ctx, tx, ApprovalSalesOrder, u.ID)
+	if err != nil {
+		return err
+	}
+
+	return u.setFulfillment(ctx, tx)
 }
 
 // Create new sales order
@@ -338,6 +419,10 @@ func (u *SalesOrder) Create(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if err := checkClosedPeriod(ctx, tx, u.Date); err != nil {
+		return err
+	}
+
 	const query = `
 		INSERT INTO sales_orders (code, date, disc, salesman_id, customer_id, company_id, branch_id, created_by, updated_by, created, updated)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
@@ -371,6 +456,14 @@ func (u *SalesOrder) Create(ctx context.Context, tx *sql.Tx) error {
 	u.Salesman.Get(ctx, tx)
 
 	for i, d := range u.SalesOrderDetails {
+		d.Qty, err = d.Product.ConvertQty(ctx, tx, &d.Uom, d.UomQty)
+		if err != nil {
+			return err
+		}
+
+		u.SalesOrderDetails[i].Qty = d.Qty
+		u.SalesOrderDetails[i].Uom = d.Uom
+
 		detailID, err := u.storeDetail(ctx, tx, d)
 		if err != nil {
 			return err
@@ -382,7 +475,17 @@ func (u *SalesOrder) Create(ctx context.Context, tx *sql.Tx) error {
 	}
 	u.Total = u.Price - u.Disc - u.AdditionalDisc
 
-	return nil
+	err = u.reserve(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	err = u.submitApproval(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return u.setFulfillment(ctx, tx)
 }
 
 // Update sales order
@@ -392,6 +495,14 @@ func (u *SalesOrder) Update(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if u.Cancelled.Valid {
+		return api.ErrBadRequest(errors.New("Sales order has been cancelled"), "")
+	}
+
+	if err := checkClosedTransaction(ctx, tx, "sales_orders", u.ID, u.Date); err != nil {
+		return err
+	}
+
 	const query = `
 		UPDATE sales_orders 
 		SET date = ?, 
@@ -422,6 +533,14 @@ func (u *SalesOrder) Update(ctx context.Context, tx *sql.Tx) error {
 	}
 
 	for i, d := range u.SalesOrderDetails {
+		d.Qty, err = d.Product.ConvertQty(ctx, tx, &d.Uom, d.UomQty)
+		if err != nil {
+			return err
+		}
+
+		u.SalesOrderDetails[i].Qty = d.Qty
+		u.SalesOrderDetails[i].Uom = d.Uom
+
 		if d.ID <= 0 {
 			detailID, err := u.storeDetail(ctx, tx, d)
 			if err != nil {
@@ -452,9 +571,148 @@ func (u *SalesOrder) Update(ctx context.Context, tx *sql.Tx) error {
 		}
 	}
 
+	err = u.reserve(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	err = u.submitApproval(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return u.setFulfillment(ctx, tx)
+}
+
+// Cancel sales order and release quantity reserved by its undelivered lines
+func (u *SalesOrder) Cancel(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Company.ID != u.Company.ID || u.Branch.ID <= 0 || userLogin.Branch.ID != u.Branch.ID {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	if u.Cancelled.Valid {
+		return api.ErrBadRequest(errors.New("Sales order has been cancelled"), "")
+	}
+
+	reserved, err := listReserved(ctx, tx, " AND sales_orders.id = ?", u.ID)
+	if err != nil {
+		return err
+	}
+
+	var open int
+	for _, qty := range reserved {
+		open += qty
+	}
+
+	if open == 0 {
+		return api.ErrBadRequest(errors.New("Sales order has been fully delivered"), "")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE sales_orders 
+		SET cancelled = NOW(),
+			cancelled_by = ?,
+			updated_by = ?,
+			updated = NOW()
+		WHERE id = ?
+		AND company_id = ?
+		AND branch_id = ?`,
+		userLogin.ID, userLogin.ID, u.ID, userLogin.Company.ID, userLogin.Branch.ID)
+	if err != nil {
+		return err
+	}
+
+	u.SalesOrderDetails = nil
+	return u.Get(ctx, tx)
+}
+
+// reserve check undelivered quantity of sales order against available to promise in its branch, excluding its own reservation.
+// The shortage is rejected or flagged as backorder on the last lines of product, depending on ATP policy of company.
+func (u *SalesOrder) reserve(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	policy, err := userLogin.Company.GetATPPolicy(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	open, err := listReserved(ctx, tx, " AND sales_orders.id = ?", u.ID)
+	if err != nil {
+		return err
+	}
+
+	reserved, err := listReserved(ctx, tx, " AND sales_orders.branch_id = ? AND sales_orders.id <> ?", userLogin.Branch.ID, u.ID)
+	if err != nil {
+		return err
+	}
+
+	incoming, err := listIncoming(ctx, tx, " AND purchases.branch_id = ?", userLogin.Branch.ID)
+	if err != nil {
+		return err
+	}
+
+	shortages := make(map[uint64]int)
+	for i := len(u.SalesOrderDetails) - 1; i >= 0; i-- {
+		d := u.SalesOrderDetails[i]
+		key := availabilityKey{userLogin.Branch.ID, d.Product.ID}
+
+		shortage, ok := shortages[d.Product.ID]
+		if !ok {
+			var onHand int
+			err = tx.QueryRowContext(ctx, "SELECT IFNULL(stock_branch(?, ?, ?), 0)", userLogin.Company.ID, userLogin.Branch.ID, d.Product.ID).Scan(&onHand)
+			if err != nil {
+				return err
+			}
+
+			atp := onHand - reserved[key] + incoming[key]
+			if atp < 0 {
+				atp = 0
+			}
+
+			shortage = open[key] - atp
+			if shortage > 0 && policy == ATPReject {
+				return api.ErrBadRequest(fmt.Errorf("Quantity of product %s exceeds available to promise %d", d.Product.Code, atp), "")
+			}
+		}
+
+		var backorder int
+		if shortage > 0 {
+			backorder = shortage
+			if backorder > int(d.Qty) {
+				backorder = int(d.Qty)
+			}
+		}
+
+		shortages[d.Product.ID] = shortage - backorder
+		u.SalesOrderDetails[i].BackorderQty = uint(backorder)
+
+		_, err = tx.ExecContext(ctx, `UPDATE sales_order_details SET backorder_qty = ? WHERE id = ? AND sales_order_id = ?`, backorder, d.ID, u.ID)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// submitApproval request approval of sales order by its total and discount percentage against its price.
+// Sales order which approval is pending or rejected can not be delivered.
+func (u *SalesOrder) submitApproval(ctx context.Context, tx *sql.Tx) error {
+	var discount float64
+	if u.Price > 0 {
+		discount = (u.Disc + u.AdditionalDisc) / u.Price * 100
+	}
+
+	_, err := requestApproval(ctx, tx, ApprovalSalesOrder, u.ID, u.Code, u.Total, discount)
+	if err != nil {
+		return err
+	}
+
+	u.Approvals, err = ListApprovals(ctx, tx, ApprovalSalesOrder, u.ID)
+	return err
+}
+
 // GetExistingDetails return array of existing sales_order_details id
 func (u *SalesOrder) GetExistingDetails(ctx context.Context, tx *sql.Tx) ([]uint64, error) {
 	var list []uint64
@@ -483,8 +741,8 @@ func (u *SalesOrder) GetExistingDetails(ctx context.Context, tx *sql.Tx) ([]uint
 func (u *SalesOrder) storeDetail(ctx context.Context, tx *sql.Tx, d SalesOrderDetail) (uint64, error) {
 	var id uint64
 	const queryDetail = `
-		INSERT INTO sales_order_details (sales_order_id, product_id, price, disc, qty)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO sales_order_details (sales_order_id, product_id, price, disc, qty, uom_id, uom_qty)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 	stmt, err := tx.PrepareContext(ctx, queryDetail)
 	if err != nil {
@@ -493,7 +751,7 @@ func (u *SalesOrder) storeDetail(ctx context.Context, tx *sql.Tx, d SalesOrderDe
 
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Price, d.Disc, d.Qty)
+	res, err := stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Price, d.Disc, d.Qty, d.Uom.nullID(), d.UomQty)
 	if err != nil {
 		return id, err
 	}
@@ -512,7 +770,9 @@ func (u *SalesOrder) updateDetail(ctx context.Context, tx *sql.Tx, d SalesOrderD
 		SET product_id = ?, 
 			price = ?,
 			disc = ?,
-			qty = ?
+			qty = ?,
+			uom_id = ?,
+			uom_qty = ?
 		WHERE id = ?
 		AND sales_order_id = ?
 	`
@@ -523,7 +783,7 @@ func (u *SalesOrder) updateDetail(ctx context.Context, tx *sql.Tx, d SalesOrderD
 
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(ctx, d.Product.ID, d.Price, d.Disc, d.Qty, d.ID, u.ID)
+	_, err = stmt.ExecContext(ctx, d.Product.ID, d.Price, d.Disc, d.Qty, d.Uom.nullID(), d.UomQty, d.ID, u.ID)
 	return err
 }
 
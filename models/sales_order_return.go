@@ -317,6 +317,10 @@ func (u *SalesOrderReturn) Create(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if err := checkClosedPeriod(ctx, tx, u.Date); err != nil {
+		return err
+	}
+
 	const query = `
 		INSERT INTO sales_order_returns (code, date, disc, sales_order_id, company_id, branch_id, created_by, updated_by, created, updated)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
@@ -376,6 +380,10 @@ func (u *SalesOrderReturn) Update(ctx context.Context, tx *sql.Tx) error {
 		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
 	}
 
+	if err := checkClosedTransaction(ctx, tx, "sales_order_returns", u.ID, u.Date); err != nil {
+		return err
+	}
+
 	const query = `
 		UPDATE sales_order_returns 
 		SET date = ?, 
@@ -0,0 +1,368 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// Type of entity resolved from scanned code
+const (
+	ScanUnit             = "unit"
+	ScanProduct          = "product"
+	ScanProductTemplate  = "product_template"
+	ScanPurchase         = "purchase"
+	ScanPurchaseReturn   = "purchase_return"
+	ScanReceive          = "good_receiving"
+	ScanReceiveReturn    = "receiving_return"
+	ScanSalesOrder       = "sales_order"
+	ScanSalesOrderReturn = "sales_order_return"
+	ScanDelivery         = "delivery"
+	ScanDeliveryReturn   = "delivery_return"
+	ScanMutation         = "mutation"
+	ScanTransfer         = "transfer"
+	ScanTransferReceive  = "transfer_receive"
+	ScanStockOpname      = "stock_opname"
+)
+
+// Status of scanned unit code or product
+const (
+	ScanStatusInStock    = "in_stock"
+	ScanStatusDelivered  = "delivered"
+	ScanStatusReturned   = "returned"
+	ScanStatusInTransit  = "in_transit"
+	ScanStatusOutOfStock = "out_of_stock"
+)
+
+const (
+	scanRelationParent   = true
+	scanRelationChildren = false
+)
+
+// Scan : struct of entity resolved from scanned code
+type Scan struct {
+	Type    string
+	ID      uint64
+	Code    string
+	Status  string
+	Qty     int
+	Date    time.Time
+	Product Product
+	Branch  Branch
+	Shelve  Shelve
+	Links   []ScanLink
+}
+
+// ScanLink : struct of document related to scanned code
+type ScanLink struct {
+	Type string
+	ID   uint64
+	Code string
+}
+
+type scanRelation struct {
+	Type   string
+	Table  string
+	Column string
+	Parent bool
+}
+
+type scanDocument struct {
+	Type      string
+	Table     string
+	Relations []scanRelation
+}
+
+// scanInventoryTypes map type of inventories movement to type of its document
+var scanInventoryTypes = map[string]string{
+	"GR": ScanReceive,
+	"RR": ScanReceiveReturn,
+	"DO": ScanDelivery,
+	"DR": ScanDeliveryReturn,
+	"MU": ScanMutation,
+	"TO": ScanTransfer,
+	"TI": ScanTransferReceive,
+	"OP": ScanStockOpname,
+}
+
+var scanDocuments = []scanDocument{
+	{ScanPurchase, "purchases", []scanRelation{
+		{ScanReceive, "good_receivings", "purchase_id", scanRelationChildren},
+		{ScanPurchaseReturn, "purchase_returns", "purchase_id", scanRelationChildren},
+	}},
+	{ScanPurchaseReturn, "purchase_returns", []scanRelation{
+		{ScanPurchase, "purchases", "purchase_id", scanRelationParent},
+	}},
+	{ScanReceive, "good_receivings", []scanRelation{
+		{ScanPurchase, "purchases", "purchase_id", scanRelationParent},
+		{ScanReceiveReturn, "receiving_returns", "good_receiving_id", scanRelationChildren},
+	}},
+	{ScanReceiveReturn, "receiving_returns", []scanRelation{
+		{ScanReceive, "good_receivings", "good_receiving_id", scanRelationParent},
+	}},
+	{ScanSalesOrder, "sales_orders", []scanRelation{
+		{ScanDelivery, "deliveries", "sales_order_id", scanRelationChildren},
+		{ScanSalesOrderReturn, "sales_order_returns", "sales_order_id", scanRelationChildren},
+	}},
+	{ScanSalesOrderReturn, "sales_order_returns", []scanRelation{
+		{ScanSalesOrder, "sales_orders", "sales_order_id", scanRelationParent},
+	}},
+	{ScanDelivery, "deliveries", []scanRelation{
+		{ScanSalesOrder, "sales_orders", "sales_order_id", scanRelationParent},
+		{ScanDeliveryReturn, "delivery_returns", "delivery_id", scanRelationChildren},
+	}},
+	{ScanDeliveryReturn, "delivery_returns", []scanRelation{
+		{ScanDelivery, "deliveries", "delivery_id", scanRelationParent},
+	}},
+}
+
+// List every entity matching the scanned code: unit codes, product codes and transaction codes.
+// Return sql.ErrNoRows when the code is unknown.
+func (u *Scan) List(ctx context.Context, tx *sql.Tx) ([]Scan, error) {
+	var list []Scan
+
+	units, err := u.listUnits(ctx, tx)
+	if err != nil {
+		return list, err
+	}
+
+	list = append(list, units...)
+
+	products, err := u.listProducts(ctx, tx)
+	if err != nil {
+		return list, err
+	}
+
+	list = append(list, products...)
+
+	for _, doc := range scanDocuments {
+		documents, err := u.listDocuments(ctx, tx, doc)
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, documents...)
+	}
+
+	if len(list) == 0 {
+		return list, sql.ErrNoRows
+	}
+
+	return list, nil
+}
+
+// listUnits resolve unit code from its movement history, a row for every product using the code
+func (u *Scan) listUnits(ctx context.Context, tx *sql.Tx) ([]Scan, error) {
+	var list []Scan
+
+	unitHistory := UnitHistory{ProductCode: u.Code}
+	histories, err := unitHistory.List(ctx, tx)
+	if err == sql.ErrNoRows {
+		return list, nil
+	}
+
+	if err != nil {
+		return list, err
+	}
+
+	idx := make(map[uint64]int)
+	// types of movements in effect, reversal of voided document takes its movement out
+	// so the status follows the movement before it
+	types := make(map[uint64][]string)
+	linked := make(map[string]bool)
+	for _, h := range histories {
+		if h.Type == UnitHistoryClosing {
+			continue
+		}
+
+		i, ok := idx[h.Product.ID]
+		if !ok {
+			i = len(list)
+			idx[h.Product.ID] = i
+			list = append(list, Scan{Type: ScanUnit, Code: u.Code, Product: h.Product})
+		}
+
+		if h.InOut {
+			list[i].Qty += int(h.Qty)
+		} else {
+			list[i].Qty -= int(h.Qty)
+		}
+
+		list[i].Date = h.TransactionDate
+		list[i].Branch = h.Branch
+		list[i].Shelve = h.Shelve
+		if h.Reversal {
+			types[h.Product.ID] = removeLastType(types[h.Product.ID], h.Type)
+		} else {
+			types[h.Product.ID] = append(types[h.Product.ID], h.Type)
+		}
+
+		links := []ScanLink{
+			{Type: ScanPurchase, ID: h.Purchase.ID, Code: h.Purchase.Code},
+			{Type: scanInventoryTypes[h.Type], ID: h.TransactionID, Code: h.TransactionCode},
+			{Type: ScanSalesOrder, ID: h.SalesOrder.ID, Code: h.SalesOrder.Code},
+		}
+
+		for _, l := range links {
+			key := fmt.Sprintf("%d-%s-%d", h.Product.ID, l.Type, l.ID)
+			if l.ID == 0 || len(l.Type) == 0 || linked[key] {
+				continue
+			}
+
+			linked[key] = true
+			list[i].Links = append(list[i].Links, l)
+		}
+	}
+
+	for i := range list {
+		list[i].Branch.Company = ctx.Value(api.Ctx("auth")).(User).Company
+		var lastType string
+		if t := types[list[i].Product.ID]; len(t) > 0 {
+			lastType = t[len(t)-1]
+		}
+
+		switch {
+		case list[i].Qty > 0:
+			list[i].Status = ScanStatusInStock
+		case lastType == "DO":
+			list[i].Status = ScanStatusDelivered
+		case lastType == "RR":
+			list[i].Status = ScanStatusReturned
+		case lastType == "TO":
+			list[i].Status = ScanStatusInTransit
+		default:
+			list[i].Status = ScanStatusOutOfStock
+		}
+	}
+
+	return list, nil
+}
+
+// removeLastType remove the last movement of type from types of movements in effect
+func removeLastType(types []string, t string) []string {
+	for i := len(types) - 1; i >= 0; i-- {
+		if types[i] == t {
+			return append(types[:i], types[i+1:]...)
+		}
+	}
+
+	return types
+}
+
+// listProducts resolve product code with its stock on hand visible by user login
+func (u *Scan) listProducts(ctx context.Context, tx *sql.Tx) ([]Scan, error) {
+	var list []Scan
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	var stock Stock
+	err := tx.QueryRowContext(ctx, `SELECT id FROM products WHERE company_id = ? AND code = ?`, userLogin.Company.ID, u.Code).Scan(&stock.Product.ID)
+	if err == sql.ErrNoRows {
+		return list, nil
+	}
+
+	if err != nil {
+		return list, err
+	}
+
+	err = stock.Get(ctx, tx)
+	if err != nil {
+		return list, err
+	}
+
+	s := Scan{Type: ScanProduct, ID: stock.Product.ID, Code: stock.Product.Code, Qty: stock.Qty, Product: stock.Product}
+	s.Status = ScanStatusOutOfStock
+	if stock.Qty > 0 {
+		s.Status = ScanStatusInStock
+	}
+
+	if stock.Product.Template.ID > 0 {
+		s.Links = append(s.Links, ScanLink{Type: ScanProductTemplate, ID: stock.Product.Template.ID, Code: stock.Product.Template.Code})
+	}
+
+	return append(list, s), nil
+}
+
+// listDocuments resolve transaction code of document visible by user login, including its related documents
+func (u *Scan) listDocuments(ctx context.Context, tx *sql.Tx, doc scanDocument) ([]Scan, error) {
+	var list []Scan
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	query := `
+		SELECT ` + doc.Table + `.id, ` + doc.Table + `.code, ` + doc.Table + `.date,
+			branches.id, branches.code, branches.name, branches.address, branches.type
+		FROM ` + doc.Table + `
+		JOIN branches ON ` + doc.Table + `.branch_id = branches.id
+		WHERE ` + doc.Table + `.company_id = ? AND ` + doc.Table + `.code = ?
+	`
+	params := []interface{}{userLogin.Company.ID, u.Code}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=?")
+			params = append(params, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND branches.id=?"
+		params = append(params, userLogin.Branch.ID)
+	}
+
+	s := Scan{Type: doc.Type}
+	err := tx.QueryRowContext(ctx, query, params...).Scan(
+		&s.ID, &s.Code, &s.Date,
+		&s.Branch.ID, &s.Branch.Code, &s.Branch.Name, &s.Branch.Address, &s.Branch.Type,
+	)
+	if err == sql.ErrNoRows {
+		return list, nil
+	}
+
+	if err != nil {
+		return list, err
+	}
+
+	for _, r := range doc.Relations {
+		relationQuery := `SELECT id, code FROM ` + r.Table + ` WHERE ` + r.Column + ` = ? ORDER BY id`
+		if r.Parent {
+			relationQuery = `SELECT ` + r.Table + `.id, ` + r.Table + `.code FROM ` + doc.Table + `
+				JOIN ` + r.Table + ` ON ` + doc.Table + `.` + r.Column + ` = ` + r.Table + `.id
+				WHERE ` + doc.Table + `.id = ?`
+		}
+
+		rows, err := tx.QueryContext(ctx, relationQuery, s.ID)
+		if err != nil {
+			return list, err
+		}
+
+		for rows.Next() {
+			l := ScanLink{Type: r.Type}
+			err = rows.Scan(&l.ID, &l.Code)
+			if err != nil {
+				rows.Close()
+				return list, err
+			}
+
+			s.Links = append(s.Links, l)
+		}
+
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return list, err
+		}
+	}
+
+	s.Branch.Company = userLogin.Company
+	return append(list, s), nil
+}
@@ -0,0 +1,727 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// Stock : struct of Stock
+type Stock struct {
+	Branch   Branch
+	Product  Product
+	Qty      int
+	Reserved int
+	Incoming int
+	Units    []StockUnit
+}
+
+// StockUnit : struct of unit code on hand
+type StockUnit struct {
+	Code        string
+	Qty         int
+	Shelve      Shelve
+	ExpiredDate sql.NullTime
+}
+
+// TemplateStock : struct of stock on hand rolled up to product template
+type TemplateStock struct {
+	Branch   Branch
+	Template ProductTemplate
+	Qty      int
+	Reserved int
+	Incoming int
+	Variants []Stock
+}
+
+// ExpiringStock : struct of expiring unit codes on hand in a shelve of branch
+type ExpiringStock struct {
+	Branch Branch
+	Shelve Shelve
+	Units  []ExpiringUnit
+}
+
+// ShelveUtilization : struct of shelves usage in a branch
+type ShelveUtilization struct {
+	Branch  Branch
+	Shelves []ShelveUsage
+}
+
+// ShelveUsage : struct of quantity on hand compared to capacity of shelve
+type ShelveUsage struct {
+	Shelve Shelve
+	Used   uint
+}
+
+// StockValuation : struct of stock value per product in a branch
+type StockValuation struct {
+	Branch   Branch
+	Method   string
+	Products []ProductValuation
+}
+
+// ProductValuation : struct of quantity on hand and its value
+type ProductValuation struct {
+	Product Product
+	Qty     int
+	Value   float64
+}
+
+// TemplateValuation : struct of quantity on hand and its value rolled up to product template
+type TemplateValuation struct {
+	Template ProductTemplate
+	Qty      int
+	Value    float64
+	Variants []ProductValuation
+}
+
+// ExpiringUnit : struct of expiring unit code on hand
+type ExpiringUnit struct {
+	Product     Product
+	Code        string
+	ExpiredDate time.Time
+}
+
+// List stocks of all products visible by user login
+func (u *Stock) List(ctx context.Context, tx *sql.Tx) ([]Stock, error) {
+	var list []Stock
+	var err error
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		idx := make(map[uint64]int)
+		for _, b := range branches {
+			branchStocks, err := u.callStocks(ctx, tx, "CALL branch_stocks(?, ?)", userLogin.Company.ID, b)
+			if err != nil {
+				return list, err
+			}
+
+			for _, s := range branchStocks {
+				if i, ok := idx[s.Product.ID]; ok {
+					list[i].Qty += s.Qty
+					continue
+				}
+
+				idx[s.Product.ID] = len(list)
+				list = append(list, s)
+			}
+		}
+
+	case userLogin.Branch.ID > 0:
+		list, err = u.callStocks(ctx, tx, "CALL branch_stocks(?, ?)", userLogin.Company.ID, userLogin.Branch.ID)
+
+	default:
+		list, err = u.callStocks(ctx, tx, "CALL stocks(?)", userLogin.Company.ID)
+	}
+
+	if err != nil {
+		return list, err
+	}
+
+	for i := range list {
+		err = list[i].Product.Get(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+	}
+
+	branches, err := u.scopeBranches(ctx, tx)
+	if err != nil {
+		return list, err
+	}
+
+	err = u.setAvailability(ctx, tx, list, branches)
+	return list, err
+}
+
+// ATP return available to promise quantity, on hand minus reserved plus incoming purchases
+func (u *Stock) ATP() int {
+	return u.Qty - u.Reserved + u.Incoming
+}
+
+// ATP return available to promise quantity of product template
+func (u *TemplateStock) ATP() int {
+	return u.Qty - u.Reserved + u.Incoming
+}
+
+// Get stock of a product visible by user login, including its reserved and incoming quantity
+func (u *Stock) Get(ctx context.Context, tx *sql.Tx) error {
+	err := u.Product.Get(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		u.Qty = 0
+		for _, b := range branches {
+			var qty int
+			err = tx.QueryRowContext(ctx, "SELECT IFNULL(stock_branch(?, ?, ?), 0)", userLogin.Company.ID, b, u.Product.ID).Scan(&qty)
+			if err != nil {
+				return err
+			}
+
+			u.Qty += qty
+		}
+
+	case userLogin.Branch.ID > 0:
+		err = tx.QueryRowContext(ctx, "SELECT IFNULL(stock_branch(?, ?, ?), 0)", userLogin.Company.ID, userLogin.Branch.ID, u.Product.ID).Scan(&u.Qty)
+
+	default:
+		err = tx.QueryRowContext(ctx, "SELECT IFNULL(stock(?, ?), 0)", userLogin.Company.ID, u.Product.ID).Scan(&u.Qty)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	branches, err := u.scopeBranches(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	list := []Stock{*u}
+	err = u.setAvailability(ctx, tx, list, branches)
+	if err != nil {
+		return err
+	}
+
+	*u = list[0]
+	return nil
+}
+
+// ListByBranch stocks of all products in a branch
+func (u *Stock) ListByBranch(ctx context.Context, tx *sql.Tx) ([]Stock, error) {
+	var list []Stock
+
+	err := u.checkBranch(ctx, tx)
+	if err != nil {
+		return list, err
+	}
+
+	list, err = u.callStocks(ctx, tx, "CALL branch_stocks(?, ?)", u.Branch.Company.ID, u.Branch.ID)
+	if err != nil {
+		return list, err
+	}
+
+	for i := range list {
+		list[i].Branch = u.Branch
+		err = list[i].Product.Get(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+	}
+
+	err = u.setAvailability(ctx, tx, list, []uint32{u.Branch.ID})
+	return list, err
+}
+
+// ListUnits on hand of a product in a branch
+func (u *Stock) ListUnits(ctx context.Context, tx *sql.Tx) error {
+	err := u.checkBranch(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	err = u.Product.Get(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	shelves := make(map[uint64]Shelve)
+	rows, err := tx.QueryContext(ctx, qShelve+"WHERE branch_id=?", u.Branch.ID)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var s Shelve
+		err = rows.Scan(&s.ID, &s.Code, &s.Capacity)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		shelves[s.ID] = s
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	expiredDates := make(map[string]time.Time)
+	rows, err = tx.QueryContext(ctx, "SELECT code, expired_date FROM good_receiving_details WHERE product_id=? AND expired_date IS NOT NULL", u.Product.ID)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var code string
+		var expiredDate time.Time
+		err = rows.Scan(&code, &expiredDate)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		expiredDates[code] = expiredDate
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = tx.QueryContext(ctx, "CALL branch_stock_details(?, ?, ?)", u.Branch.Company.ID, u.Branch.ID, u.Product.ID)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	u.Units = []StockUnit{}
+	u.Qty = 0
+	for rows.Next() {
+		var productID, shelveID uint64
+		var unit StockUnit
+		err = rows.Scan(&productID, &unit.Code, &unit.Qty, &shelveID)
+		if err != nil {
+			return err
+		}
+
+		unit.Shelve = shelves[shelveID]
+		unit.Shelve.ID = shelveID
+		unit.ExpiredDate.Time, unit.ExpiredDate.Valid = expiredDates[unit.Code]
+		u.Units = append(u.Units, unit)
+		u.Qty += unit.Qty
+	}
+
+	return rows.Err()
+}
+
+// ListExpiring unit codes on hand which expire within days, including the expired ones, grouped by branch and shelve
+func (u *Stock) ListExpiring(ctx context.Context, tx *sql.Tx, days int) ([]ExpiringStock, error) {
+	var list []ExpiringStock
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	query := `
+		SELECT branches.id, branches.code, branches.name, branches.address, branches.type,
+			IFNULL(shelves.id, 0), IFNULL(shelves.code, ''), IFNULL(shelves.capacity, 0),
+			products.id, products.code, products.name, products.sale_price,
+			inventories.product_code, good_receiving_details.expired_date
+		FROM inventories
+		JOIN (
+			SELECT MAX(id) id, SUM(IF(in_out, qty, -qty)) qty
+			FROM inventories
+			WHERE company_id = ?
+			GROUP BY product_id, product_code
+		) units ON inventories.id = units.id
+		JOIN good_receiving_details ON inventories.product_id = good_receiving_details.product_id AND inventories.product_code = good_receiving_details.code
+		JOIN branches ON inventories.branch_id = branches.id
+		JOIN products ON inventories.product_id = products.id
+		LEFT JOIN shelves ON inventories.shelve_id = shelves.id
+		WHERE units.qty > 0 AND DATE(good_receiving_details.expired_date) <= DATE_ADD(CURDATE(), INTERVAL ? DAY)
+	`
+	params := []interface{}{userLogin.Company.ID, days}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=?")
+			params = append(params, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND branches.id=?"
+		params = append(params, userLogin.Branch.ID)
+	}
+
+	rows, err := tx.QueryContext(ctx, query+" ORDER BY branches.id, shelves.code, good_receiving_details.expired_date, inventories.product_code", params...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var s ExpiringStock
+		var unit ExpiringUnit
+		err = rows.Scan(
+			&s.Branch.ID, &s.Branch.Code, &s.Branch.Name, &s.Branch.Address, &s.Branch.Type,
+			&s.Shelve.ID, &s.Shelve.Code, &s.Shelve.Capacity,
+			&unit.Product.ID, &unit.Product.Code, &unit.Product.Name, &unit.Product.SalePrice,
+			&unit.Code, &unit.ExpiredDate,
+		)
+		if err != nil {
+			return list, err
+		}
+
+		last := len(list) - 1
+		if last < 0 || list[last].Branch.ID != s.Branch.ID || list[last].Shelve.ID != s.Shelve.ID {
+			s.Branch.Company = userLogin.Company
+			list = append(list, s)
+			last++
+		}
+
+		unit.Product.Company = userLogin.Company
+		list[last].Units = append(list[last].Units, unit)
+	}
+
+	return list, rows.Err()
+}
+
+// ListShelveUtilization return used quantity against capacity of every shelve, grouped by branch
+func (u *Stock) ListShelveUtilization(ctx context.Context, tx *sql.Tx) ([]ShelveUtilization, error) {
+	var list []ShelveUtilization
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	query := `
+		SELECT branches.id, branches.code, branches.name, branches.address, branches.type,
+			shelves.id, shelves.code, shelves.capacity, IFNULL(used.qty, 0)
+		FROM shelves
+		JOIN branches ON shelves.branch_id = branches.id
+		LEFT JOIN (
+			SELECT shelve_id, SUM(IF(in_out, qty, -qty)) qty
+			FROM inventories
+			WHERE company_id = ?
+			GROUP BY shelve_id
+		) used ON shelves.id = used.shelve_id
+		WHERE branches.company_id = ?
+	`
+	params := []interface{}{userLogin.Company.ID, userLogin.Company.ID}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=?")
+			params = append(params, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND branches.id=?"
+		params = append(params, userLogin.Branch.ID)
+	}
+
+	rows, err := tx.QueryContext(ctx, query+" ORDER BY branches.id, shelves.code", params...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var s ShelveUtilization
+		var usage ShelveUsage
+		err = rows.Scan(
+			&s.Branch.ID, &s.Branch.Code, &s.Branch.Name, &s.Branch.Address, &s.Branch.Type,
+			&usage.Shelve.ID, &usage.Shelve.Code, &usage.Shelve.Capacity, &usage.Used,
+		)
+		if err != nil {
+			return list, err
+		}
+
+		last := len(list) - 1
+		if last < 0 || list[last].Branch.ID != s.Branch.ID {
+			s.Branch.Company = userLogin.Company
+			list = append(list, s)
+			last++
+		}
+
+		list[last].Shelves = append(list[last].Shelves, usage)
+	}
+
+	return list, rows.Err()
+}
+
+// ListValuation return value of stock on hand at date, grouped by branch.
+// FIFO value every unit with its own cost, moving average value product quantity with the last average cost at date.
+func (u *Stock) ListValuation(ctx context.Context, tx *sql.Tx, date time.Time) ([]StockValuation, error) {
+	var list []StockValuation
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	method, err := userLogin.Company.GetValuationMethod(ctx, tx)
+	if err != nil {
+		return list, err
+	}
+
+	query := `
+		SELECT branches.id, branches.code, branches.name, branches.address, branches.type,
+			products.id, products.code, products.name, products.sale_price,
+			IFNULL(product_templates.id, 0), IFNULL(product_templates.code, ''), IFNULL(product_templates.name, ''),
+			SUM(units.qty), SUM(units.qty * IF(? = ?, IFNULL(averages.cost, 0), inventories.cost))
+		FROM inventories
+		JOIN (
+			SELECT MAX(id) id, SUM(IF(in_out, qty, -qty)) qty
+			FROM inventories
+			WHERE company_id = ? AND transaction_date <= ?
+			GROUP BY product_id, product_code
+		) units ON inventories.id = units.id
+		LEFT JOIN (
+			SELECT product_id, cost 
+			FROM inventories 
+			WHERE id IN (
+				SELECT MAX(id) 
+				FROM inventories 
+				WHERE company_id = ? AND transaction_date <= ? 
+				GROUP BY product_id
+			)
+		) averages ON inventories.product_id = averages.product_id
+		JOIN branches ON inventories.branch_id = branches.id
+		JOIN products ON inventories.product_id = products.id
+		LEFT JOIN product_templates ON products.product_template_id = product_templates.id
+		WHERE units.qty > 0
+	`
+	params := []interface{}{method, ValuationAverage, userLogin.Company.ID, date, userLogin.Company.ID, date}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=?")
+			params = append(params, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND branches.id=?"
+		params = append(params, userLogin.Branch.ID)
+	}
+
+	rows, err := tx.QueryContext(ctx, query+" GROUP BY branches.id, products.id ORDER BY branches.id, products.code", params...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var s StockValuation
+		var valuation ProductValuation
+		err = rows.Scan(
+			&s.Branch.ID, &s.Branch.Code, &s.Branch.Name, &s.Branch.Address, &s.Branch.Type,
+			&valuation.Product.ID, &valuation.Product.Code, &valuation.Product.Name, &valuation.Product.SalePrice,
+			&valuation.Product.Template.ID, &valuation.Product.Template.Code, &valuation.Product.Template.Name,
+			&valuation.Qty, &valuation.Value,
+		)
+		if err != nil {
+			return list, err
+		}
+
+		last := len(list) - 1
+		if last < 0 || list[last].Branch.ID != s.Branch.ID {
+			s.Branch.Company = userLogin.Company
+			s.Method = method
+			list = append(list, s)
+			last++
+		}
+
+		valuation.Product.Company = userLogin.Company
+		list[last].Products = append(list[last].Products, valuation)
+	}
+
+	return list, rows.Err()
+}
+
+// RollupTemplates group stocks of variant products by branch and product template.
+// Product without template stay as its own row with a single variant.
+func (u *Stock) RollupTemplates(list []Stock) []TemplateStock {
+	var templates []TemplateStock
+	idx := make(map[string]int)
+	for _, s := range list {
+		key := fmt.Sprintf("%d-p%d", s.Branch.ID, s.Product.ID)
+		if s.Product.Template.ID > 0 {
+			key = fmt.Sprintf("%d-t%d", s.Branch.ID, s.Product.Template.ID)
+		}
+
+		i, ok := idx[key]
+		if !ok {
+			i = len(templates)
+			idx[key] = i
+			templates = append(templates, TemplateStock{Branch: s.Branch, Template: s.Product.Template})
+		}
+
+		templates[i].Qty += s.Qty
+		templates[i].Reserved += s.Reserved
+		templates[i].Incoming += s.Incoming
+		templates[i].Variants = append(templates[i].Variants, s)
+	}
+
+	return templates
+}
+
+// RollupTemplates group product valuations by product template.
+// Product without template stay as its own row with a single variant.
+func (u *StockValuation) RollupTemplates() []TemplateValuation {
+	var templates []TemplateValuation
+	idx := make(map[string]int)
+	for _, p := range u.Products {
+		key := fmt.Sprintf("p%d", p.Product.ID)
+		if p.Product.Template.ID > 0 {
+			key = fmt.Sprintf("t%d", p.Product.Template.ID)
+		}
+
+		i, ok := idx[key]
+		if !ok {
+			i = len(templates)
+			idx[key] = i
+			templates = append(templates, TemplateValuation{Template: p.Product.Template})
+		}
+
+		templates[i].Qty += p.Qty
+		templates[i].Value += p.Value
+		templates[i].Variants = append(templates[i].Variants, p)
+	}
+
+	return templates
+}
+
+func (u *Stock) callStocks(ctx context.Context, tx *sql.Tx, query string, params ...interface{}) ([]Stock, error) {
+	var list []Stock
+
+	rows, err := tx.QueryContext(ctx, query, params...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var s Stock
+		err = rows.Scan(&s.Product.ID, &s.Qty)
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, s)
+	}
+
+	return list, rows.Err()
+}
+
+// scopeBranches return id of branches visible by user login, empty for the whole company
+func (u *Stock) scopeBranches(ctx context.Context, tx *sql.Tx) ([]uint32, error) {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	switch {
+	case userLogin.Region.ID > 0:
+		return userLogin.Region.GetIDBranches(ctx, tx)
+
+	case userLogin.Branch.ID > 0:
+		return []uint32{userLogin.Branch.ID}, nil
+	}
+
+	return []uint32{}, nil
+}
+
+// setAvailability fill reserved and incoming quantity of stocks summed over branches, empty branches for the whole company
+func (u *Stock) setAvailability(ctx context.Context, tx *sql.Tx, list []Stock, branches []uint32) error {
+	reserved, err := listReserved(ctx, tx, "")
+	if err != nil {
+		return err
+	}
+
+	incoming, err := listIncoming(ctx, tx, "")
+	if err != nil {
+		return err
+	}
+
+	inScope := make(map[uint32]bool)
+	for _, b := range branches {
+		inScope[b] = true
+	}
+
+	reservedProducts := make(map[uint64]int)
+	for k, qty := range reserved {
+		if len(branches) == 0 || inScope[k.BranchID] {
+			reservedProducts[k.ProductID] += qty
+		}
+	}
+
+	incomingProducts := make(map[uint64]int)
+	for k, qty := range incoming {
+		if len(branches) == 0 || inScope[k.BranchID] {
+			incomingProducts[k.ProductID] += qty
+		}
+	}
+
+	for i := range list {
+		list[i].Reserved = reservedProducts[list[i].Product.ID]
+		list[i].Incoming = incomingProducts[list[i].Product.ID]
+	}
+
+	return nil
+}
+
+func (u *Stock) checkBranch(ctx context.Context, tx *sql.Tx) error {
+	err := u.Branch.Get(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, b := range branches {
+			if b == u.Branch.ID {
+				return nil
+			}
+		}
+
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+
+	case userLogin.Branch.ID > 0:
+		if userLogin.Branch.ID != u.Branch.ID {
+			return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+		}
+	}
+
+	return nil
+}
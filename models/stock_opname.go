@@ -0,0 +1,772 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// StockOpnameOpen : status of stock opname which still accept counting
+const StockOpnameOpen = "O"
+
+// StockOpnameApproved : status of stock opname which variances has been posted
+const StockOpnameApproved = "A"
+
+// StockOpname : struct of physical count StockOpname
+type StockOpname struct {
+	ID                 uint64
+	Code               string
+	Date               time.Time
+	Remark             string
+	Status             string
+	ApprovedBy         sql.NullInt64
+	Approved           sql.NullTime
+	Company            Company
+	Branch             Branch
+	Shelves            []Shelve
+	StockOpnameDetails []StockOpnameDetail
+	Approvals          []Approval
+}
+
+// StockOpnameDetail : line of count sheet
+type StockOpnameDetail struct {
+	ID          uint64
+	Product     Product
+	Code        string
+	Shelve      Shelve
+	ExpectedQty uint
+	CountedQty  uint
+}
+
+// StockOpnameSummary : variance of stock opname per product
+type StockOpnameSummary struct {
+	Product     Product
+	ExpectedQty uint
+	CountedQty  uint
+}
+
+// Variance of count sheet line, negative means missing unit and positive means found unit
+func (d *StockOpnameDetail) Variance() int {
+	return int(d.CountedQty) - int(d.ExpectedQty)
+}
+
+// Variance of product
+func (d *StockOpnameSummary) Variance() int {
+	return int(d.CountedQty) - int(d.ExpectedQty)
+}
+
+const qStockOpnames = `
+SELECT 	stock_opnames.id,
+	stock_opnames.code,
+	stock_opnames.date,
+	stock_opnames.remark,
+	stock_opnames.status,
+	stock_opnames.approved_by,
+	stock_opnames.approved,
+	companies.id,
+	companies.code,
+	companies.name,
+	companies.address,
+	branches.id,
+	branches.code,
+	branches.name,
+	branches.address,
+	branches.type
+FROM stock_opnames
+JOIN companies ON stock_opnames.company_id = companies.id
+JOIN branches ON stock_opnames.branch_id = branches.id
+`
+
+// List StockOpnames
+func (u *StockOpname) List(ctx context.Context, tx *sql.Tx) ([]StockOpname, error) {
+	var list []StockOpname
+	var err error
+
+	query := qStockOpnames + " WHERE companies.id=?"
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	params := []interface{}{userLogin.Company.ID}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=?")
+			params = append(params, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND branches.id=?"
+		params = append(params, userLogin.Branch.ID)
+	}
+
+	rows, err := tx.QueryContext(ctx, query, params...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var stockOpname StockOpname
+		err = rows.Scan(stockOpname.getArgs()...)
+		if err != nil {
+			return list, err
+		}
+
+		stockOpname.Branch.Company = stockOpname.Company
+		list = append(list, stockOpname)
+	}
+
+	return list, rows.Err()
+}
+
+// Get StockOpname by id, including its shelves and count sheet
+func (u *StockOpname) Get(ctx context.Context, tx *sql.Tx) error {
+	query := qStockOpnames + " WHERE stock_opnames.id=? AND companies.id=?"
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	params := []interface{}{u.ID, userLogin.Company.ID}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=?")
+			params = append(params, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND branches.id=?"
+		params = append(params, userLogin.Branch.ID)
+	}
+
+	err := tx.QueryRowContext(ctx, query, params...).Scan(u.getArgs()...)
+	if err != nil {
+		return err
+	}
+
+	u.Branch.Company = u.Company
+
+	u.Shelves, err = u.getShelves(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	u.StockOpnameDetails, err = u.getDetails(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	u.Approvals, err = ListApprovals(ctx, tx, ApprovalStockOpname, u.ID)
+	return err
+}
+
+// Create new StockOpname and freeze the count sheet from unit codes on hand
+func (u *StockOpname) Create(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Branch.ID <= 0 {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	if err := checkClosedPeriod(ctx, tx, u.Date); err != nil {
+		return err
+	}
+
+	for i := range u.Shelves {
+		err := u.Shelves[i].View(ctx, tx)
+		if err == sql.ErrNoRows {
+			return api.ErrBadRequest(errors.New("Invalid shelve"), "")
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	const query = `
+		INSERT INTO stock_opnames (code, date, remark, status, company_id, branch_id, created_by, updated_by, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+	`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	u.Code, err = api.GetCode(ctx, tx, "OP", "stock_opnames", userLogin.Company.ID)
+	if err != nil {
+		return err
+	}
+
+	u.Status = StockOpnameOpen
+	res, err := stmt.ExecContext(ctx, u.Code, u.Date, u.Remark, u.Status, userLogin.Company.ID, userLogin.Branch.ID, userLogin.ID, userLogin.ID)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	u.ID = uint64(id)
+	u.Company = userLogin.Company
+	u.Branch = userLogin.Branch
+	u.Branch.Company = u.Company
+
+	for _, s := range u.Shelves {
+		_, err = tx.ExecContext(ctx, `INSERT INTO stock_opname_shelves (stock_opname_id, shelve_id) VALUES (?, ?)`, u.ID, s.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = u.freeze(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	u.StockOpnameDetails, err = u.getDetails(ctx, tx)
+	return err
+}
+
+// Update StockOpname header
+func (u *StockOpname) Update(ctx context.Context, tx *sql.Tx) error {
+	err := u.checkOpen(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = checkClosedTransaction(ctx, tx, "stock_opnames", u.ID, u.Date)
+	if err != nil {
+		return err
+	}
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	const query = `
+		UPDATE stock_opnames
+		SET date = ?,
+			remark = ?,
+			updated_by = ?,
+			updated = NOW()
+		WHERE id = ?
+		AND company_id = ?
+		AND branch_id = ?
+	`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, u.Date, u.Remark, userLogin.ID, u.ID, userLogin.Company.ID, userLogin.Branch.ID)
+	return err
+}
+
+// Count stores counted quantity of unit codes.
+// Unit code which is not in the count sheet is stored as found unit and must have a shelve.
+func (u *StockOpname) Count(ctx context.Context, tx *sql.Tx, counts []StockOpnameDetail) error {
+	err := u.checkOpen(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range counts {
+		var detailID uint64
+		err = tx.QueryRowContext(ctx,
+			`SELECT id FROM stock_opname_details WHERE stock_opname_id = ? AND product_id = ? AND code = ?`,
+			u.ID, c.Product.ID, c.Code).Scan(&detailID)
+
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		if err == nil {
+			_, err = tx.ExecContext(ctx, `UPDATE stock_opname_details SET counted_qty = ? WHERE id = ?`, c.CountedQty, detailID)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err = u.checkFoundUnit(ctx, tx, &c)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_opname_details (stock_opname_id, product_id, code, shelve_id, expected_qty, counted_qty)
+			VALUES (?, ?, ?, ?, 0, ?)`,
+			u.ID, c.Product.ID, c.Code, c.Shelve.ID, c.CountedQty)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE stock_opnames SET updated_by = ?, updated = NOW() WHERE id = ?`, ctx.Value(api.Ctx("auth")).(User).ID, u.ID)
+	if err != nil {
+		return err
+	}
+
+	err = supersedeApprovals(ctx, tx, ApprovalStockOpname, u.ID)
+	if err != nil {
+		return err
+	}
+
+	u.StockOpnameDetails, err = u.getDetails(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	u.Approvals, err = ListApprovals(ctx, tx, ApprovalStockOpname, u.ID)
+	return err
+}
+
+// Approve StockOpname and post the variances as adjustment into inventories.
+// When an approval rule applies to the total variance, approval is requested and the variances are posted
+// by approving the stock opname again after the approval has been approved.
+func (u *StockOpname) Approve(ctx context.Context, tx *sql.Tx) error {
+	err := u.checkOpen(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = checkClosedPeriod(ctx, tx, u.Date)
+	if err != nil {
+		return err
+	}
+
+	status, err := getApprovalStatus(ctx, tx, ApprovalStockOpname, u.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(status) == 0 {
+		var variance int
+		for _, d := range u.StockOpnameDetails {
+			if d.Variance() < 0 {
+				variance -= d.Variance()
+			} else {
+				variance += d.Variance()
+			}
+		}
+
+		approval, err := requestApproval(ctx, tx, ApprovalStockOpname, u.ID, u.Code, float64(variance), 0)
+		if err != nil {
+			return err
+		}
+
+		if approval.ID > 0 {
+			u.Approvals, err = ListApprovals(ctx, tx, ApprovalStockOpname, u.ID)
+			return err
+		}
+	}
+
+	err = checkApproval(ctx, tx, ApprovalStockOpname, u.ID, u.Code)
+	if err != nil {
+		return err
+	}
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	for i, d := range u.StockOpnameDetails {
+		// unit code may be moved after the opname is created, so the variance is taken from its quantity on the shelve now
+		err = u.refreshExpectedQty(ctx, tx, &d)
+		if err != nil {
+			return err
+		}
+
+		u.StockOpnameDetails[i].ExpectedQty = d.ExpectedQty
+		variance := d.Variance()
+		if variance == 0 {
+			continue
+		}
+
+		inventory := new(Inventory)
+		inventory.CompanyID = userLogin.Company.ID
+		inventory.BranchID = userLogin.Branch.ID
+		inventory.ProductID = d.Product.ID
+		inventory.ProductCode = d.Code
+		inventory.TransactionID = u.ID
+		inventory.Code = u.Code
+		inventory.TransactionDate = u.Date
+		inventory.Type = "OP"
+		inventory.InOut = variance > 0
+		inventory.Qty = uint(variance)
+		inventory.ShelveID = d.Shelve.ID
+
+		if variance < 0 {
+			inventory.Qty = uint(-variance)
+		}
+
+		err = inventory.Create(ctx, tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	const query = `
+		UPDATE stock_opnames
+		SET status = ?,
+			approved_by = ?,
+			approved = NOW(),
+			updated_by = ?,
+			updated = NOW()
+		WHERE id = ?
+		AND company_id = ?
+		AND branch_id = ?
+	`
+	_, err = tx.ExecContext(ctx, query, StockOpnameApproved, userLogin.ID, userLogin.ID, u.ID, userLogin.Company.ID, userLogin.Branch.ID)
+	if err != nil {
+		return err
+	}
+
+	u.Status = StockOpnameApproved
+	u.ApprovedBy = sql.NullInt64{Int64: int64(userLogin.ID), Valid: true}
+	u.Approved = sql.NullTime{Time: time.Now(), Valid: true}
+
+	return nil
+}
+
+// Summary of count sheet variance per product
+func (u *StockOpname) Summary() []StockOpnameSummary {
+	list := []StockOpnameSummary{}
+	idx := make(map[uint64]int)
+
+	for _, d := range u.StockOpnameDetails {
+		i, ok := idx[d.Product.ID]
+		if !ok {
+			i = len(list)
+			idx[d.Product.ID] = i
+			list = append(list, StockOpnameSummary{Product: d.Product})
+		}
+
+		list[i].ExpectedQty += d.ExpectedQty
+		list[i].CountedQty += d.CountedQty
+	}
+
+	return list
+}
+
+// freeze count sheet from unit codes on hand of branch, optionally limited to stock opname shelves
+func (u *StockOpname) freeze(ctx context.Context, tx *sql.Tx) error {
+	shelves := make(map[uint64]bool)
+	for _, s := range u.Shelves {
+		shelves[s.ID] = true
+	}
+
+	rows, err := tx.QueryContext(ctx, "CALL branch_stock_details(?, ?, 0)", u.Company.ID, u.Branch.ID)
+	if err != nil {
+		return err
+	}
+
+	var details []StockOpnameDetail
+	for rows.Next() {
+		var d StockOpnameDetail
+		err = rows.Scan(&d.Product.ID, &d.Code, &d.ExpectedQty, &d.Shelve.ID)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		if len(shelves) > 0 && !shelves[d.Shelve.ID] {
+			continue
+		}
+
+		details = append(details, d)
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	const queryDetail = `
+		INSERT INTO stock_opname_details (stock_opname_id, product_id, code, shelve_id, expected_qty, counted_qty)
+		VALUES (?, ?, ?, ?, ?, 0)
+	`
+	stmt, err := tx.PrepareContext(ctx, queryDetail)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	for _, d := range details {
+		_, err = stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Code, d.Shelve.ID, d.ExpectedQty)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *StockOpname) refreshExpectedQty(ctx context.Context, tx *sql.Tx, d *StockOpnameDetail) error {
+	var expectedQty uint
+	inventory := Inventory{ProductID: d.Product.ID, ProductCode: d.Code}
+	qty, err := inventory.GetLastPosition(ctx, tx)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if err == nil && qty > 0 && inventory.BranchID == u.Branch.ID && inventory.ShelveID == d.Shelve.ID {
+		expectedQty = uint(qty)
+	}
+
+	if expectedQty == d.ExpectedQty {
+		return nil
+	}
+
+	d.ExpectedQty = expectedQty
+	_, err = tx.ExecContext(ctx, `UPDATE stock_opname_details SET expected_qty = ? WHERE id = ?`, d.ExpectedQty, d.ID)
+	return err
+}
+
+func (u *StockOpname) checkOpen(ctx context.Context) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Company.ID != u.Company.ID || u.Branch.ID <= 0 || userLogin.Branch.ID != u.Branch.ID {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	if u.Status != StockOpnameOpen {
+		return api.ErrBadRequest(errors.New("Stock opname has been approved"), "")
+	}
+
+	return nil
+}
+
+func (u *StockOpname) checkFoundUnit(ctx context.Context, tx *sql.Tx, d *StockOpnameDetail) error {
+	err := d.Product.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		return api.ErrBadRequest(errors.New("Invalid product"), "")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// unit code which is still in stock, at this or another branch of company, can not be found
+	inventory := Inventory{ProductID: d.Product.ID, ProductCode: d.Code}
+	qty, err := inventory.GetLastPosition(ctx, tx)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if err == nil && qty > 0 {
+		return api.ErrBadRequest(errors.New("Unit code "+d.Code+" is already in stock"), "")
+	}
+
+	if d.Shelve.ID <= 0 {
+		return api.ErrBadRequest(errors.New("Shelve is required for unit code "+d.Code), "")
+	}
+
+	err = d.Shelve.View(ctx, tx)
+	if err == sql.ErrNoRows {
+		return api.ErrBadRequest(errors.New("Invalid shelve"), "")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(u.Shelves) == 0 {
+		return nil
+	}
+
+	for _, s := range u.Shelves {
+		if s.ID == d.Shelve.ID {
+			return nil
+		}
+	}
+
+	return api.ErrBadRequest(errors.New("Shelve is not part of stock opname"), "")
+}
+
+func (u *StockOpname) getShelves(ctx context.Context, tx *sql.Tx) ([]Shelve, error) {
+	list := []Shelve{}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT shelves.id, shelves.code, shelves.capacity
+		FROM stock_opname_shelves
+		JOIN shelves ON stock_opname_shelves.shelve_id = shelves.id
+		WHERE stock_opname_shelves.stock_opname_id = ?`, u.ID)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var s Shelve
+		err = rows.Scan(&s.ID, &s.Code, &s.Capacity)
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, s)
+	}
+
+	return list, rows.Err()
+}
+
+func (u *StockOpname) getDetails(ctx context.Context, tx *sql.Tx) ([]StockOpnameDetail, error) {
+	list := []StockOpnameDetail{}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT stock_opname_details.id,
+			stock_opname_details.code,
+			stock_opname_details.expected_qty,
+			stock_opname_details.counted_qty,
+			shelves.id,
+			shelves.code,
+			products.id,
+			products.code,
+			products.name,
+			products.sale_price
+		FROM stock_opname_details
+		JOIN shelves ON stock_opname_details.shelve_id = shelves.id
+		JOIN products ON stock_opname_details.product_id = products.id
+		WHERE stock_opname_details.stock_opname_id = ?
+		ORDER BY products.id, stock_opname_details.code`, u.ID)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var d StockOpnameDetail
+		err = rows.Scan(
+			&d.ID,
+			&d.Code,
+			&d.ExpectedQty,
+			&d.CountedQty,
+			&d.Shelve.ID,
+			&d.Shelve.Code,
+			&d.Product.ID,
+			&d.Product.Code,
+			&d.Product.Name,
+			&d.Product.SalePrice,
+		)
+		if err != nil {
+			return list, err
+		}
+
+		d.Product.Company = u.Company
+		list = append(list, d)
+	}
+
+	return list, rows.Err()
+}
+
+func (u *StockOpname) getArgs() []interface{} {
+	var args []interface{}
+	args = append(args, &u.ID)
+	args = append(args, &u.Code)
+	args = append(args, &u.Date)
+	args = append(args, &u.Remark)
+	args = append(args, &u.Status)
+	args = append(args, &u.ApprovedBy)
+	args = append(args, &u.Approved)
+	args = append(args, &u.Company.ID)
+	args = append(args, &u.Company.Code)
+	args = append(args, &u.Company.Name)
+	args = append(args, &u.Company.Address)
+	args = append(args, &u.Branch.ID)
+	args = append(args, &u.Branch.Code)
+	args = append(args, &u.Branch.Name)
+	args = append(args, &u.Branch.Address)
+	args = append(args, &u.Branch.Type)
+
+	return args
+}
+
+// StockOpnameScan : line of scanned count upload
+type StockOpnameScan struct {
+	ProductCode string
+	Code        string
+	Qty         uint
+	ShelveCode  string
+}
+
+// Upload scanned lines, repeated scan of the same unit code is summed before stored as counted quantity
+func (u *StockOpname) Upload(ctx context.Context, tx *sql.Tx, scans []StockOpnameScan) error {
+	err := u.checkOpen(ctx)
+	if err != nil {
+		return err
+	}
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	var counts []StockOpnameDetail
+	idx := make(map[string]int)
+	products := make(map[string]uint64)
+	shelves := make(map[string]uint64)
+
+	for _, s := range scans {
+		productID, ok := products[s.ProductCode]
+		if !ok {
+			err = tx.QueryRowContext(ctx, `SELECT id FROM products WHERE company_id = ? AND code = ?`, userLogin.Company.ID, s.ProductCode).Scan(&productID)
+			if err == sql.ErrNoRows {
+				return api.ErrBadRequest(errors.New("Invalid product code "+s.ProductCode), "")
+			}
+
+			if err != nil {
+				return err
+			}
+
+			products[s.ProductCode] = productID
+		}
+
+		var shelveID uint64
+		if len(s.ShelveCode) > 0 {
+			shelveID, ok = shelves[s.ShelveCode]
+			if !ok {
+				err = tx.QueryRowContext(ctx, `SELECT id FROM shelves WHERE branch_id = ? AND code = ?`, userLogin.Branch.ID, s.ShelveCode).Scan(&shelveID)
+				if err == sql.ErrNoRows {
+					return api.ErrBadRequest(errors.New("Invalid shelve code "+s.ShelveCode), "")
+				}
+
+				if err != nil {
+					return err
+				}
+
+				shelves[s.ShelveCode] = shelveID
+			}
+		}
+
+		key := s.ProductCode + "|" + s.Code
+		i, ok := idx[key]
+		if !ok {
+			i = len(counts)
+			idx[key] = i
+			counts = append(counts, StockOpnameDetail{Product: Product{ID: productID}, Code: s.Code})
+		}
+
+		counts[i].CountedQty += s.Qty
+		if shelveID > 0 {
+			counts[i].Shelve.ID = shelveID
+		}
+	}
+
+	return u.Count(ctx, tx, counts)
+}
@@ -0,0 +1,644 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/libraries/array"
+)
+
+// Transfer : struct of inter branch Transfer out
+type Transfer struct {
+	ID                uint64
+	Code              string
+	Date              time.Time
+	Remark            string
+	Company           Company
+	Branch            Branch
+	DestinationBranch Branch
+	TransferDetails   []TransferDetail
+}
+
+// TransferDetail struct
+type TransferDetail struct {
+	ID      uint64
+	Product Product
+	Qty     uint
+	Code    string
+	Shelve  Shelve
+}
+
+const qTransfers = `
+SELECT 	transfers.id,
+	transfers.code,
+	transfers.date,
+	transfers.remark,
+	companies.id,
+	companies.code,
+	companies.name,
+	companies.address,
+	branches.id,
+	branches.code,
+	branches.name,
+	branches.address,
+	branches.type,
+	destination_branches.id,
+	destination_branches.code,
+	destination_branches.name,
+	destination_branches.address,
+	destination_branches.type
+FROM transfers
+JOIN companies ON transfers.company_id = companies.id
+JOIN branches ON transfers.branch_id = branches.id
+JOIN branches destination_branches ON transfers.destination_branch_id = destination_branches.id
+`
+
+// List Transfers, transfer is visible by the source and the destination branch
+func (u *Transfer) List(ctx context.Context, tx *sql.Tx) ([]Transfer, error) {
+	var list []Transfer
+	var err error
+
+	query := qTransfers + " WHERE companies.id=?"
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	params := []interface{}{userLogin.Company.ID}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=? OR destination_branches.id=?")
+			params = append(params, b, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND (branches.id=? OR destination_branches.id=?)"
+		params = append(params, userLogin.Branch.ID, userLogin.Branch.ID)
+	}
+
+	rows, err := tx.QueryContext(ctx, query, params...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var transfer Transfer
+		err = rows.Scan(transfer.getArgs()...)
+		if err != nil {
+			return list, err
+		}
+
+		transfer.Branch.Company = transfer.Company
+		transfer.DestinationBranch.Company = transfer.Company
+		list = append(list, transfer)
+	}
+
+	return list, rows.Err()
+}
+
+// Get Transfer by id
+func (u *Transfer) Get(ctx context.Context, tx *sql.Tx) error {
+	query := qTransfers + " WHERE transfers.id=? AND companies.id=?"
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	params := []interface{}{u.ID, userLogin.Company.ID}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=? OR destination_branches.id=?")
+			params = append(params, b, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND (branches.id=? OR destination_branches.id=?)"
+		params = append(params, userLogin.Branch.ID, userLogin.Branch.ID)
+	}
+
+	err := tx.QueryRowContext(ctx, query, params...).Scan(u.getArgs()...)
+	if err != nil {
+		return err
+	}
+
+	u.Branch.Company = u.Company
+	u.DestinationBranch.Company = u.Company
+
+	var detailID, detailCode, detailQty, shelveID, shelveCode, productID, productCode, productName, productPrice string
+	err = tx.QueryRowContext(ctx, `
+		SELECT JSON_ARRAYAGG(transfer_details.id),
+			JSON_ARRAYAGG(transfer_details.code),
+			JSON_ARRAYAGG(transfer_details.qty),
+			JSON_ARRAYAGG(shelves.id),
+			JSON_ARRAYAGG(shelves.code),
+			JSON_ARRAYAGG(products.id),
+			JSON_ARRAYAGG(products.code),
+			JSON_ARRAYAGG(products.name),
+			JSON_ARRAYAGG(products.sale_price)
+		FROM transfer_details
+		JOIN shelves ON transfer_details.shelve_id = shelves.id
+		JOIN products ON transfer_details.product_id = products.id
+		WHERE transfer_details.transfer_id = ?
+		GROUP BY transfer_details.transfer_id
+	`, u.ID).Scan(&detailID, &detailCode, &detailQty, &shelveID, &shelveCode, &productID, &productCode, &productName, &productPrice)
+
+	if err == sql.ErrNoRows {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var detailIDs []uint64
+	err = json.Unmarshal([]byte(detailID), &detailIDs)
+	if err != nil {
+		return err
+	}
+
+	var detailCodes []string
+	err = json.Unmarshal([]byte(detailCode), &detailCodes)
+	if err != nil {
+		return err
+	}
+
+	var detailQtys []uint
+	err = json.Unmarshal([]byte(detailQty), &detailQtys)
+	if err != nil {
+		return err
+	}
+
+	var shelveIDs []uint64
+	err = json.Unmarshal([]byte(shelveID), &shelveIDs)
+	if err != nil {
+		return err
+	}
+
+	var shelveCodes []string
+	err = json.Unmarshal([]byte(shelveCode), &shelveCodes)
+	if err != nil {
+		return err
+	}
+
+	var productIDs []uint64
+	err = json.Unmarshal([]byte(productID), &productIDs)
+	if err != nil {
+		return err
+	}
+
+	var productCodes []string
+	err = json.Unmarshal([]byte(productCode), &productCodes)
+	if err != nil {
+		return err
+	}
+
+	var productNames []string
+	err = json.Unmarshal([]byte(productName), &productNames)
+	if err != nil {
+		return err
+	}
+
+	var productPrices []float64
+	err = json.Unmarshal([]byte(productPrice), &productPrices)
+	if err != nil {
+		return err
+	}
+
+	for i, v := range detailIDs {
+		u.TransferDetails = append(u.TransferDetails, TransferDetail{
+			ID:   v,
+			Code: detailCodes[i],
+			Qty:  detailQtys[i],
+			Shelve: Shelve{
+				ID:   shelveIDs[i],
+				Code: shelveCodes[i],
+			},
+			Product: Product{
+				ID:        productIDs[i],
+				Code:      productCodes[i],
+				Name:      productNames[i],
+				SalePrice: productPrices[i],
+				Company:   u.Company,
+			},
+		})
+	}
+
+	return nil
+}
+
+// Create new Transfer
+func (u *Transfer) Create(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Branch.ID <= 0 {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	if err := checkClosedPeriod(ctx, tx, u.Date); err != nil {
+		return err
+	}
+
+	err := u.checkDestination(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	const query = `
+		INSERT INTO transfers (code, date, remark, destination_branch_id, company_id, branch_id, created_by, updated_by, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+	`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	u.Code, err = api.GetCode(ctx, tx, "TO", "transfers", userLogin.Company.ID)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, u.Code, u.Date, u.Remark, u.DestinationBranch.ID, userLogin.Company.ID, userLogin.Branch.ID, userLogin.ID, userLogin.ID)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	u.ID = uint64(id)
+	u.Company = userLogin.Company
+	u.Branch = userLogin.Branch
+	u.Branch.Company = u.Company
+
+	for i, d := range u.TransferDetails {
+		err = u.storeDetail(ctx, tx, d, i)
+		if err != nil {
+			return err
+		}
+
+		u.TransferDetails[i].Product.Get(ctx, tx)
+	}
+
+	return nil
+}
+
+// Update Transfer, only allowed before the destination branch receive any unit
+func (u *Transfer) Update(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Company.ID != u.Company.ID || u.Branch.ID <= 0 || userLogin.Branch.ID != u.Branch.ID {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	if err := checkClosedTransaction(ctx, tx, "transfers", u.ID, u.Date); err != nil {
+		return err
+	}
+
+	var received int
+	err := tx.QueryRowContext(ctx, `SELECT COUNT(id) FROM transfer_receives WHERE transfer_id = ?`, u.ID).Scan(&received)
+	if err != nil {
+		return err
+	}
+
+	if received > 0 {
+		return api.ErrBadRequest(errors.New("Transfer has been received"), "")
+	}
+
+	err = u.checkDestination(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	const query = `
+		UPDATE transfers
+		SET date = ?,
+			remark = ?,
+			destination_branch_id = ?,
+			updated_by = ?,
+			updated = NOW()
+		WHERE id = ?
+		AND company_id = ?
+		AND branch_id = ?
+	`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, u.Date, u.Remark, u.DestinationBranch.ID, userLogin.ID, u.ID, userLogin.Company.ID, userLogin.Branch.ID)
+	if err != nil {
+		return err
+	}
+
+	existingDetails, err := u.GetExistingDetails(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	for i, d := range u.TransferDetails {
+		if d.ID <= 0 {
+			err = u.storeDetail(ctx, tx, d, i)
+			if err != nil {
+				return err
+			}
+		} else {
+			err = u.updateDetail(ctx, tx, d, i)
+			if err != nil {
+				return err
+			}
+
+			var arrUint64 array.ArrUint64
+			existingDetails = arrUint64.Remove(existingDetails, d.ID)
+		}
+
+		u.TransferDetails[i].Product.Get(ctx, tx)
+	}
+
+	for _, e := range existingDetails {
+		err = u.removeDetail(ctx, tx, e)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListInTransit units of transfer that have not been received by the destination branch.
+// Transfer must be loaded by Get before calling ListInTransit
+func (u *Transfer) ListInTransit(ctx context.Context, tx *sql.Tx) ([]TransferDetail, error) {
+	var list []TransferDetail
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT transfer_details.id, transfer_details.code, transfer_details.qty, shelves.id, shelves.code
+		FROM transfer_details
+		JOIN shelves ON transfer_details.shelve_id = shelves.id
+		WHERE transfer_details.transfer_id = ?
+		AND NOT EXISTS (
+			SELECT transfer_receive_details.id
+			FROM transfer_receive_details
+			JOIN transfer_receives ON transfer_receive_details.transfer_receive_id = transfer_receives.id
+			WHERE transfer_receives.transfer_id = transfer_details.transfer_id
+			AND transfer_receive_details.product_id = transfer_details.product_id
+			AND transfer_receive_details.code = transfer_details.code
+		)
+	`, u.ID)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var d TransferDetail
+		err = rows.Scan(&d.ID, &d.Code, &d.Qty, &d.Shelve.ID, &d.Shelve.Code)
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, d)
+	}
+
+	for i := range list {
+		for _, d := range u.TransferDetails {
+			if d.ID == list[i].ID {
+				list[i].Product = d.Product
+			}
+		}
+	}
+
+	return list, rows.Err()
+}
+
+// GetExistingDetails return array of existing transfer_details id
+func (u *Transfer) GetExistingDetails(ctx context.Context, tx *sql.Tx) ([]uint64, error) {
+	var list []uint64
+	var err error
+
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM transfer_details WHERE transfer_id=?", u.ID)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var temp uint64
+		err = rows.Scan(&temp)
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, temp)
+	}
+
+	return list, rows.Err()
+}
+
+func (u *Transfer) getArgs() []interface{} {
+	var args []interface{}
+	args = append(args, &u.ID)
+	args = append(args, &u.Code)
+	args = append(args, &u.Date)
+	args = append(args, &u.Remark)
+	args = append(args, &u.Company.ID)
+	args = append(args, &u.Company.Code)
+	args = append(args, &u.Company.Name)
+	args = append(args, &u.Company.Address)
+	args = append(args, &u.Branch.ID)
+	args = append(args, &u.Branch.Code)
+	args = append(args, &u.Branch.Name)
+	args = append(args, &u.Branch.Address)
+	args = append(args, &u.Branch.Type)
+	args = append(args, &u.DestinationBranch.ID)
+	args = append(args, &u.DestinationBranch.Code)
+	args = append(args, &u.DestinationBranch.Name)
+	args = append(args, &u.DestinationBranch.Address)
+	args = append(args, &u.DestinationBranch.Type)
+
+	return args
+}
+
+func (u *Transfer) checkDestination(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if u.DestinationBranch.ID == userLogin.Branch.ID {
+		return api.ErrBadRequest(errors.New("Destination branch must be different from source branch"), "")
+	}
+
+	err := u.DestinationBranch.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		return api.ErrBadRequest(errors.New("Invalid destination branch"), "")
+	}
+
+	return err
+}
+
+func (u *Transfer) getDetail(ctx context.Context, tx *sql.Tx, e uint64) (*TransferDetail, error) {
+	detail := new(TransferDetail)
+	err := tx.QueryRowContext(ctx,
+		`SELECT id, product_id, qty, code, shelve_id FROM transfer_details WHERE id = ? AND transfer_id = ?`,
+		e, u.ID).Scan(&detail.ID, &detail.Product.ID, &detail.Qty, &detail.Code, &detail.Shelve.ID)
+
+	return detail, err
+}
+
+func (u *Transfer) storeDetail(ctx context.Context, tx *sql.Tx, d TransferDetail, i int) error {
+	// check :
+	// 1. unit code is on hand in user login branch, the shelve is the last position of unit code
+	// 2. quantity is not more than quantity on hand of unit code, the rest stays in user login branch
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	err := d.Product.CheckQty(ctx, tx, d.Qty)
+	if err != nil {
+		return err
+	}
+
+	position := new(Inventory)
+	position.ProductID = d.Product.ID
+	position.ProductCode = d.Code
+	qty, err := position.GetLastPosition(ctx, tx)
+	if err == sql.ErrNoRows || (err == nil && (qty <= 0 || position.BranchID != userLogin.Branch.ID)) {
+		return api.ErrBadRequest(errors.New("Unit code is not on hand"), "")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if qty < int(d.Qty) {
+		return api.ErrBadRequest(fmt.Errorf("Unit code %s only has %d on hand", d.Code, qty), "")
+	}
+
+	d.Shelve.ID = position.ShelveID
+
+	const queryDetail = `
+		INSERT INTO transfer_details (transfer_id, product_id, code, qty, shelve_id)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	stmt, err := tx.PrepareContext(ctx, queryDetail)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Code, d.Qty, d.Shelve.ID)
+	if err != nil {
+		return err
+	}
+
+	detailID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	u.TransferDetails[i].ID = uint64(detailID)
+	u.TransferDetails[i].Qty = d.Qty
+	u.TransferDetails[i].Shelve = d.Shelve
+	u.TransferDetails[i].Shelve.View(ctx, tx)
+
+	inventory := new(Inventory)
+	inventory.CompanyID = userLogin.Company.ID
+	inventory.BranchID = userLogin.Branch.ID
+	inventory.ProductID = d.Product.ID
+	inventory.ProductCode = d.Code
+	inventory.TransactionID = u.ID
+	inventory.Code = u.Code
+	inventory.TransactionDate = u.Date
+	inventory.Type = "TO"
+	inventory.InOut = false
+	inventory.Qty = d.Qty
+	inventory.ShelveID = d.Shelve.ID
+	return inventory.Create(ctx, tx)
+}
+
+func (u *Transfer) updateDetail(ctx context.Context, tx *sql.Tx, d TransferDetail, i int) error {
+	detail, err := u.getDetail(ctx, tx, d.ID)
+	if err != nil {
+		return err
+	}
+
+	if d.Qty != detail.Qty {
+		// quantity is changed, release the transferred quantity and transfer the unit code again
+		err = u.removeDetail(ctx, tx, d.ID)
+		if err != nil {
+			return err
+		}
+
+		d.ID = 0
+		d.Product.ID = detail.Product.ID
+		d.Code = detail.Code
+		return u.storeDetail(ctx, tx, d, i)
+	}
+
+	u.TransferDetails[i] = *detail
+	u.TransferDetails[i].Shelve.View(ctx, tx)
+
+	inventory := new(Inventory)
+	inventory.ProductID = detail.Product.ID
+	inventory.ProductCode = detail.Code
+	inventory.TransactionID = u.ID
+	inventory.Type = "TO"
+	err = inventory.GetByComposit(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	inventory.Code = u.Code
+	inventory.TransactionDate = u.Date
+	return inventory.Update(ctx, tx)
+}
+
+func (u *Transfer) removeDetail(ctx context.Context, tx *sql.Tx, e uint64) error {
+	const queryDetail = `DELETE FROM transfer_details WHERE id = ? AND transfer_id = ?`
+
+	detail, err := u.getDetail(ctx, tx, e)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, queryDetail)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, e, u.ID)
+	if err != nil {
+		return err
+	}
+
+	inventory := new(Inventory)
+	inventory.ProductID = detail.Product.ID
+	inventory.ProductCode = detail.Code
+	inventory.TransactionID = u.ID
+	inventory.Type = "TO"
+	inventory.CompanyID = ctx.Value(api.Ctx("auth")).(User).Company.ID
+	inventory.BranchID = ctx.Value(api.Ctx("auth")).(User).Branch.ID
+	return inventory.DeleteByComposit(ctx, tx)
+}
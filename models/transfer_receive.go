@@ -0,0 +1,648 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+	"github.com/jacky-htg/inventory/libraries/array"
+)
+
+// TransferReceive : struct of inter branch Transfer in
+type TransferReceive struct {
+	ID                     uint64
+	Code                   string
+	Date                   time.Time
+	Remark                 string
+	Transfer               Transfer
+	Company                Company
+	Branch                 Branch
+	TransferReceiveDetails []TransferReceiveDetail
+}
+
+// TransferReceiveDetail struct
+type TransferReceiveDetail struct {
+	ID      uint64
+	Product Product
+	Qty     uint
+	Code    string
+	Shelve  Shelve
+}
+
+// List TransferReceives
+func (u *TransferReceive) List(ctx context.Context, tx *sql.Tx) ([]TransferReceive, error) {
+	var list []TransferReceive
+	var err error
+
+	query := `
+	SELECT 	transfer_receives.id,
+		transfer_receives.code,
+		transfer_receives.date,
+		transfer_receives.remark,
+		transfers.id,
+		transfers.code,
+		companies.id,
+		companies.code,
+		companies.name,
+		companies.address,
+		branches.id,
+		branches.code,
+		branches.name,
+		branches.address,
+		branches.type
+	FROM transfer_receives
+	JOIN companies ON transfer_receives.company_id = companies.id
+	JOIN transfers ON transfer_receives.transfer_id = transfers.id AND transfer_receives.company_id = transfers.company_id
+	JOIN branches ON transfer_receives.branch_id = branches.id
+	WHERE companies.id=?
+	`
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	params := []interface{}{userLogin.Company.ID}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return list, err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=?")
+			params = append(params, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND branches.id=?"
+		params = append(params, userLogin.Branch.ID)
+	}
+
+	rows, err := tx.QueryContext(ctx, query, params...)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var transferReceive TransferReceive
+		err = rows.Scan(
+			&transferReceive.ID,
+			&transferReceive.Code,
+			&transferReceive.Date,
+			&transferReceive.Remark,
+			&transferReceive.Transfer.ID,
+			&transferReceive.Transfer.Code,
+			&transferReceive.Company.ID,
+			&transferReceive.Company.Code,
+			&transferReceive.Company.Name,
+			&transferReceive.Company.Address,
+			&transferReceive.Branch.ID,
+			&transferReceive.Branch.Code,
+			&transferReceive.Branch.Name,
+			&transferReceive.Branch.Address,
+			&transferReceive.Branch.Type,
+		)
+
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, transferReceive)
+	}
+
+	return list, rows.Err()
+}
+
+// Get TransferReceive by id
+func (u *TransferReceive) Get(ctx context.Context, tx *sql.Tx) error {
+	query := `
+	SELECT 	transfer_receives.id,
+		transfer_receives.code,
+		transfer_receives.date,
+		transfer_receives.remark,
+		transfers.id,
+		transfers.code,
+		companies.id,
+		companies.code,
+		companies.name,
+		companies.address,
+		branches.id,
+		branches.code,
+		branches.name,
+		branches.address,
+		branches.type,
+		JSON_ARRAYAGG(transfer_receive_details.id),
+		JSON_ARRAYAGG(transfer_receive_details.code),
+		JSON_ARRAYAGG(transfer_receive_details.qty),
+		JSON_ARRAYAGG(shelves.id),
+		JSON_ARRAYAGG(shelves.code),
+		JSON_ARRAYAGG(products.id),
+		JSON_ARRAYAGG(products.code),
+		JSON_ARRAYAGG(products.name),
+		JSON_ARRAYAGG(products.sale_price)
+	FROM transfer_receives
+	JOIN companies ON transfer_receives.company_id = companies.id
+	JOIN transfers ON transfer_receives.transfer_id = transfers.id AND transfer_receives.company_id = transfers.company_id
+	JOIN branches ON transfer_receives.branch_id = branches.id
+	JOIN transfer_receive_details ON transfer_receives.id = transfer_receive_details.transfer_receive_id
+	JOIN shelves ON transfer_receive_details.shelve_id = shelves.id
+	JOIN products ON transfer_receive_details.product_id = products.id
+	WHERE transfer_receives.id=? AND companies.id=?
+	`
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	params := []interface{}{u.ID, userLogin.Company.ID}
+
+	switch {
+	case userLogin.Region.ID > 0:
+		branches, err := userLogin.Region.GetIDBranches(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		var orWhere []string
+		for _, b := range branches {
+			orWhere = append(orWhere, "branches.id=?")
+			params = append(params, b)
+		}
+
+		query += " AND (" + strings.Join(orWhere, " OR ") + ")"
+
+	case userLogin.Branch.ID > 0:
+		query += " AND branches.id=?"
+		params = append(params, userLogin.Branch.ID)
+	}
+
+	var detailID, detailCode, detailQty, shelveID, shelveCode, productID, productCode, productName, productPrice string
+	err := tx.QueryRowContext(ctx, query+" GROUP BY transfer_receives.id", params...).Scan(
+		&u.ID,
+		&u.Code,
+		&u.Date,
+		&u.Remark,
+		&u.Transfer.ID,
+		&u.Transfer.Code,
+		&u.Company.ID,
+		&u.Company.Code,
+		&u.Company.Name,
+		&u.Company.Address,
+		&u.Branch.ID,
+		&u.Branch.Code,
+		&u.Branch.Name,
+		&u.Branch.Address,
+		&u.Branch.Type,
+		&detailID,
+		&detailCode,
+		&detailQty,
+		&shelveID,
+		&shelveCode,
+		&productID,
+		&productCode,
+		&productName,
+		&productPrice,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	u.Branch.Company = u.Company
+
+	if len(detailID) > 0 {
+		var detailIDs []uint64
+		err = json.Unmarshal([]byte(detailID), &detailIDs)
+		if err != nil {
+			return err
+		}
+
+		var detailCodes []string
+		err = json.Unmarshal([]byte(detailCode), &detailCodes)
+		if err != nil {
+			return err
+		}
+
+		var detailQtys []uint
+		err = json.Unmarshal([]byte(detailQty), &detailQtys)
+		if err != nil {
+			return err
+		}
+
+		var shelveIDs []uint64
+		err = json.Unmarshal([]byte(shelveID), &shelveIDs)
+		if err != nil {
+			return err
+		}
+
+		var shelveCodes []string
+		err = json.Unmarshal([]byte(shelveCode), &shelveCodes)
+		if err != nil {
+			return err
+		}
+
+		var productIDs []uint64
+		err = json.Unmarshal([]byte(productID), &productIDs)
+		if err != nil {
+			return err
+		}
+
+		var productCodes []string
+		err = json.Unmarshal([]byte(productCode), &productCodes)
+		if err != nil {
+			return err
+		}
+
+		var productNames []string
+		err = json.Unmarshal([]byte(productName), &productNames)
+		if err != nil {
+			return err
+		}
+
+		var productPrices []float64
+		err = json.Unmarshal([]byte(productPrice), &productPrices)
+		if err != nil {
+			return err
+		}
+
+		for i, v := range detailIDs {
+			u.TransferReceiveDetails = append(u.TransferReceiveDetails, TransferReceiveDetail{
+				ID:   v,
+				Code: detailCodes[i],
+				Qty:  detailQtys[i],
+				Shelve: Shelve{
+					ID:   shelveIDs[i],
+					Code: shelveCodes[i],
+				},
+				Product: Product{
+					ID:        productIDs[i],
+					Code:      productCodes[i],
+					Name:      productNames[i],
+					SalePrice: productPrices[i],
+					Company:   u.Company,
+				},
+			})
+		}
+	}
+
+	return nil
+}
+
+// Create new TransferReceive
+func (u *TransferReceive) Create(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Branch.ID <= 0 {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	if err := checkClosedPeriod(ctx, tx, u.Date); err != nil {
+		return err
+	}
+
+	err := u.checkTransfer(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	const query = `
+		INSERT INTO transfer_receives (code, date, remark, transfer_id, company_id, branch_id, created_by, updated_by, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+	`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	u.Code, err = api.GetCode(ctx, tx, "TI", "transfer_receives", userLogin.Company.ID)
+	if err != nil {
+		return err
+	}
+
+	res, err := stmt.ExecContext(ctx, u.Code, u.Date, u.Remark, u.Transfer.ID, userLogin.Company.ID, userLogin.Branch.ID, userLogin.ID, userLogin.ID)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	u.ID = uint64(id)
+	u.Company = userLogin.Company
+	u.Branch = userLogin.Branch
+	u.Branch.Company = u.Company
+
+	for i, d := range u.TransferReceiveDetails {
+		err = u.storeDetail(ctx, tx, d, i)
+		if err != nil {
+			return err
+		}
+
+		u.TransferReceiveDetails[i].Product.Get(ctx, tx)
+	}
+
+	return nil
+}
+
+// Update TransferReceive
+func (u *TransferReceive) Update(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	if userLogin.Company.ID != u.Company.ID || u.Branch.ID <= 0 || userLogin.Branch.ID != u.Branch.ID {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	if err := checkClosedTransaction(ctx, tx, "transfer_receives", u.ID, u.Date); err != nil {
+		return err
+	}
+
+	err := u.checkTransfer(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	const query = `
+		UPDATE transfer_receives
+		SET date = ?,
+			remark = ?,
+			updated_by = ?,
+			updated = NOW()
+		WHERE id = ?
+		AND company_id = ?
+		AND branch_id = ?
+	`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, u.Date, u.Remark, userLogin.ID, u.ID, userLogin.Company.ID, userLogin.Branch.ID)
+	if err != nil {
+		return err
+	}
+
+	existingDetails, err := u.GetExistingDetails(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	for i, d := range u.TransferReceiveDetails {
+		if d.ID <= 0 {
+			err = u.storeDetail(ctx, tx, d, i)
+			if err != nil {
+				return err
+			}
+		} else {
+			err = u.updateDetail(ctx, tx, d, i)
+			if err != nil {
+				return err
+			}
+
+			var arrUint64 array.ArrUint64
+			existingDetails = arrUint64.Remove(existingDetails, d.ID)
+		}
+
+		u.TransferReceiveDetails[i].Product.Get(ctx, tx)
+	}
+
+	for _, e := range existingDetails {
+		err = u.removeDetail(ctx, tx, e)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetExistingDetails return array of existing transfer_receive_details id
+func (u *TransferReceive) GetExistingDetails(ctx context.Context, tx *sql.Tx) ([]uint64, error) {
+	var list []uint64
+	var err error
+
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM transfer_receive_details WHERE transfer_receive_id=?", u.ID)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var temp uint64
+		err = rows.Scan(&temp)
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, temp)
+	}
+
+	return list, rows.Err()
+}
+
+func (u *TransferReceive) checkTransfer(ctx context.Context, tx *sql.Tx) error {
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	err := u.Transfer.Get(ctx, tx)
+	if err == sql.ErrNoRows {
+		return api.ErrBadRequest(errors.New("Invalid transfer"), "")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if u.Transfer.DestinationBranch.ID != userLogin.Branch.ID {
+		return api.ErrForbidden(errors.New("Forbidden data owner"), "")
+	}
+
+	return nil
+}
+
+func (u *TransferReceive) getDetail(ctx context.Context, tx *sql.Tx, e uint64) (*TransferReceiveDetail, error) {
+	detail := new(TransferReceiveDetail)
+	err := tx.QueryRowContext(ctx,
+		`SELECT id, product_id, qty, code, shelve_id FROM transfer_receive_details WHERE id = ? AND transfer_receive_id = ?`,
+		e, u.ID).Scan(&detail.ID, &detail.Product.ID, &detail.Qty, &detail.Code, &detail.Shelve.ID)
+
+	return detail, err
+}
+
+func (u *TransferReceive) checkShelve(ctx context.Context, tx *sql.Tx, d *TransferReceiveDetail) error {
+	err := d.Shelve.View(ctx, tx)
+	if err == sql.ErrNoRows {
+		return api.ErrBadRequest(errors.New("Invalid shelve"), "")
+	}
+
+	return err
+}
+
+func (u *TransferReceive) storeDetail(ctx context.Context, tx *sql.Tx, d TransferReceiveDetail, i int) error {
+	// check :
+	// 1. valid detail is only unit code in transfer detail list which has not been received
+	// 2. shelve is in user login branch
+	// the quantity received is the quantity transferred of unit code
+	var transferDetailID uint64
+	err := tx.QueryRowContext(ctx, `
+		SELECT transfer_details.id, transfer_details.qty
+		FROM transfer_details
+		WHERE transfer_details.transfer_id = ? AND transfer_details.product_id = ? AND transfer_details.code = ?
+		AND NOT EXISTS (
+			SELECT transfer_receive_details.id
+			FROM transfer_receive_details
+			JOIN transfer_receives ON transfer_receive_details.transfer_receive_id = transfer_receives.id
+			WHERE transfer_receives.transfer_id = transfer_details.transfer_id
+			AND transfer_receive_details.product_id = transfer_details.product_id
+			AND transfer_receive_details.code = transfer_details.code
+		)
+	`, u.Transfer.ID, d.Product.ID, d.Code).Scan(&transferDetailID, &d.Qty)
+
+	if err == sql.ErrNoRows {
+		return api.ErrBadRequest(errors.New("Unit code is not in transit of transfer"), "")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = u.checkShelve(ctx, tx, &d)
+	if err != nil {
+		return err
+	}
+
+	const queryDetail = `
+		INSERT INTO transfer_receive_details (transfer_receive_id, product_id, code, qty, shelve_id)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	stmt, err := tx.PrepareContext(ctx, queryDetail)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, u.ID, d.Product.ID, d.Code, d.Qty, d.Shelve.ID)
+	if err != nil {
+		return err
+	}
+
+	detailID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	u.TransferReceiveDetails[i].ID = uint64(detailID)
+	u.TransferReceiveDetails[i].Qty = d.Qty
+	u.TransferReceiveDetails[i].Shelve = d.Shelve
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	inventory := new(Inventory)
+	inventory.CompanyID = userLogin.Company.ID
+	inventory.BranchID = userLogin.Branch.ID
+	inventory.ProductID = d.Product.ID
+	inventory.ProductCode = d.Code
+	inventory.TransactionID = u.ID
+	inventory.Code = u.Code
+	inventory.TransactionDate = u.Date
+	inventory.Type = "TI"
+	inventory.InOut = true
+	inventory.Qty = d.Qty
+	inventory.ShelveID = d.Shelve.ID
+	err = inventory.Create(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return d.Shelve.CheckCapacity(ctx, tx)
+}
+
+func (u *TransferReceive) updateDetail(ctx context.Context, tx *sql.Tx, d TransferReceiveDetail, i int) error {
+	detail, err := u.getDetail(ctx, tx, d.ID)
+	if err != nil {
+		return err
+	}
+
+	d.Product.ID = detail.Product.ID
+	d.Code = detail.Code
+	d.Qty = detail.Qty
+	err = u.checkShelve(ctx, tx, &d)
+	if err != nil {
+		return err
+	}
+
+	const queryDetail = `
+		UPDATE transfer_receive_details
+		SET shelve_id = ?
+		WHERE id = ?
+		AND transfer_receive_id = ?
+	`
+	stmt, err := tx.PrepareContext(ctx, queryDetail)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, d.Shelve.ID, d.ID, u.ID)
+	if err != nil {
+		return err
+	}
+
+	u.TransferReceiveDetails[i] = d
+
+	inventory := new(Inventory)
+	inventory.ProductID = d.Product.ID
+	inventory.ProductCode = d.Code
+	inventory.TransactionID = u.ID
+	inventory.Type = "TI"
+	err = inventory.GetByComposit(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	inventory.Code = u.Code
+	inventory.TransactionDate = u.Date
+	inventory.ShelveID = d.Shelve.ID
+	err = inventory.Update(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	return d.Shelve.CheckCapacity(ctx, tx)
+}
+
+func (u *TransferReceive) removeDetail(ctx context.Context, tx *sql.Tx, e uint64) error {
+	const queryDetail = `DELETE FROM transfer_receive_details WHERE id = ? AND transfer_receive_id = ?`
+
+	detail, err := u.getDetail(ctx, tx, e)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, queryDetail)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, e, u.ID)
+	if err != nil {
+		return err
+	}
+
+	inventory := new(Inventory)
+	inventory.ProductID = detail.Product.ID
+	inventory.ProductCode = detail.Code
+	inventory.TransactionID = u.ID
+	inventory.Type = "TI"
+	inventory.CompanyID = ctx.Value(api.Ctx("auth")).(User).Company.ID
+	inventory.BranchID = ctx.Value(api.Ctx("auth")).(User).Branch.ID
+	return inventory.DeleteByComposit(ctx, tx)
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// UnitHistory : struct of unit code movement, including closing snapshot of stock
+type UnitHistory struct {
+	ID              uint64
+	Type            string
+	InOut           bool
+	Reversal        bool
+	Qty             uint
+	TransactionID   uint64
+	TransactionCode string
+	TransactionDate time.Time
+	ProductCode     string
+	Product         Product
+	Branch          Branch
+	Shelve          Shelve
+	Purchase        Purchase
+	SalesOrder      SalesOrder
+	CreatedBy       User
+	Created         time.Time
+}
+
+// UnitHistoryClosing : type of closing stock snapshot in unit history
+const UnitHistoryClosing = "CS"
+
+const qUnitHistories = `
+SELECT inventories.id, inventories.type, inventories.in_out, inventories.reversal, inventories.qty,
+	inventories.transaction_id, inventories.code, inventories.transaction_date, inventories.product_code,
+	products.id, products.code, products.name,
+	branches.id, branches.code, branches.name, branches.address, branches.type,
+	IFNULL(shelves.id, 0), IFNULL(shelves.code, ''), IFNULL(shelves.capacity, 0),
+	IFNULL(purchases.id, 0), IFNULL(purchases.code, ''), purchases.date,
+	IFNULL(suppliers.id, 0), IFNULL(suppliers.code, ''), IFNULL(suppliers.name, ''), IFNULL(suppliers.address, ''),
+	IFNULL(sales_orders.id, 0), IFNULL(sales_orders.code, ''), sales_orders.date,
+	IFNULL(customers.id, 0), IFNULL(customers.name, ''), IFNULL(customers.email, ''), IFNULL(customers.address, ''), IFNULL(customers.hp, ''),
+	IFNULL(users.id, 0), IFNULL(users.username, ''),
+	inventories.created
+FROM inventories
+JOIN products ON inventories.product_id = products.id
+JOIN branches ON inventories.branch_id = branches.id
+LEFT JOIN shelves ON inventories.shelve_id = shelves.id
+LEFT JOIN good_receivings ON inventories.type = 'GR' AND inventories.transaction_id = good_receivings.id
+LEFT JOIN receiving_returns ON inventories.type = 'RR' AND inventories.transaction_id = receiving_returns.id
+LEFT JOIN good_receivings returned_receivings ON receiving_returns.good_receiving_id = returned_receivings.id
+LEFT JOIN purchases ON IFNULL(good_receivings.purchase_id, returned_receivings.purchase_id) = purchases.id
+LEFT JOIN suppliers ON purchases.supplier_id = suppliers.id
+LEFT JOIN deliveries ON inventories.type = 'DO' AND inventories.transaction_id = deliveries.id
+LEFT JOIN delivery_returns ON inventories.type = 'DR' AND inventories.transaction_id = delivery_returns.id
+LEFT JOIN deliveries returned_deliveries ON delivery_returns.delivery_id = returned_deliveries.id
+LEFT JOIN sales_orders ON IFNULL(deliveries.sales_order_id, returned_deliveries.sales_order_id) = sales_orders.id
+LEFT JOIN customers ON sales_orders.customer_id = customers.id
+LEFT JOIN mutations ON inventories.type = 'MU' AND inventories.transaction_id = mutations.id
+LEFT JOIN transfers ON inventories.type = 'TO' AND inventories.transaction_id = transfers.id
+LEFT JOIN transfer_receives ON inventories.type = 'TI' AND inventories.transaction_id = transfer_receives.id
+LEFT JOIN stock_opnames ON inventories.type = 'OP' AND inventories.transaction_id = stock_opnames.id
+LEFT JOIN users ON COALESCE(good_receivings.created_by, receiving_returns.created_by, deliveries.created_by, delivery_returns.created_by,
+	mutations.created_by, transfers.created_by, transfer_receives.created_by, stock_opnames.created_by) = users.id
+`
+
+// List all movements of unit code ordered chronologically, closing snapshot is placed at the first day of its month
+func (u *UnitHistory) List(ctx context.Context, tx *sql.Tx) ([]UnitHistory, error) {
+	var list []UnitHistory
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	closings, err := u.listClosings(ctx, tx)
+	if err != nil {
+		return list, err
+	}
+
+	rows, err := tx.QueryContext(ctx, qUnitHistories+" WHERE inventories.company_id = ? AND inventories.product_code = ? ORDER BY inventories.id",
+		userLogin.Company.ID, u.ProductCode)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var h UnitHistory
+		var purchaseDate, salesOrderDate sql.NullTime
+		err = rows.Scan(
+			&h.ID, &h.Type, &h.InOut, &h.Reversal, &h.Qty,
+			&h.TransactionID, &h.TransactionCode, &h.TransactionDate, &h.ProductCode,
+			&h.Product.ID, &h.Product.Code, &h.Product.Name,
+			&h.Branch.ID, &h.Branch.Code, &h.Branch.Name, &h.Branch.Address, &h.Branch.Type,
+			&h.Shelve.ID, &h.Shelve.Code, &h.Shelve.Capacity,
+			&h.Purchase.ID, &h.Purchase.Code, &purchaseDate,
+			&h.Purchase.Supplier.ID, &h.Purchase.Supplier.Code, &h.Purchase.Supplier.Name, &h.Purchase.Supplier.Address,
+			&h.SalesOrder.ID, &h.SalesOrder.Code, &salesOrderDate,
+			&h.SalesOrder.Customer.ID, &h.SalesOrder.Customer.Name, &h.SalesOrder.Customer.Email, &h.SalesOrder.Customer.Address, &h.SalesOrder.Customer.Hp,
+			&h.CreatedBy.ID, &h.CreatedBy.Username,
+			&h.Created,
+		)
+		if err != nil {
+			return list, err
+		}
+
+		h.Purchase.Date = purchaseDate.Time
+		h.SalesOrder.Date = salesOrderDate.Time
+
+		for len(closings) > 0 && !closings[0].TransactionDate.After(h.TransactionDate) {
+			list = append(list, closings[0])
+			closings = closings[1:]
+		}
+
+		list = append(list, h)
+	}
+
+	if err = rows.Err(); err != nil {
+		return list, err
+	}
+
+	list = append(list, closings...)
+	if len(list) == 0 {
+		return list, sql.ErrNoRows
+	}
+
+	return list, nil
+}
+
+// listClosings return closing stock snapshots containing unit code
+func (u *UnitHistory) listClosings(ctx context.Context, tx *sql.Tx) ([]UnitHistory, error) {
+	var list []UnitHistory
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT saldo_stock_details.id, saldo_stocks.year, saldo_stocks.month, saldo_stock_details.code, saldo_stock_details.qty,
+			products.id, products.code, products.name,
+			branches.id, branches.code, branches.name, branches.address, branches.type
+		FROM saldo_stocks
+		JOIN saldo_stock_details ON saldo_stocks.id = saldo_stock_details.saldo_stock_id
+		JOIN products ON saldo_stocks.product_id = products.id
+		JOIN branches ON saldo_stock_details.branch_id = branches.id
+		WHERE saldo_stocks.company_id = ? AND saldo_stock_details.code = ?
+		ORDER BY saldo_stocks.year, saldo_stocks.month`,
+		userLogin.Company.ID, u.ProductCode)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var h UnitHistory
+		var year, month int
+		err = rows.Scan(&h.ID, &year, &month, &h.ProductCode, &h.Qty,
+			&h.Product.ID, &h.Product.Code, &h.Product.Name,
+			&h.Branch.ID, &h.Branch.Code, &h.Branch.Name, &h.Branch.Address, &h.Branch.Type)
+		if err != nil {
+			return list, err
+		}
+
+		h.Type = UnitHistoryClosing
+		h.InOut = true
+		h.TransactionDate = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		list = append(list, h)
+	}
+
+	return list, rows.Err()
+}
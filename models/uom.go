@@ -0,0 +1,121 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// Uom : struct of unit of measure
+type Uom struct {
+	ID      uint64
+	Company Company
+	Code    string
+	Name    string
+}
+
+const qUoms = `SELECT id, code, name FROM uoms`
+
+// List of uoms
+func (u *Uom) List(ctx context.Context, tx *sql.Tx) ([]Uom, error) {
+	var list []Uom
+
+	rows, err := tx.QueryContext(ctx, qUoms+" WHERE company_id=?", ctx.Value(api.Ctx("auth")).(User).Company.ID)
+	if err != nil {
+		return list, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var c Uom
+		c.Company = ctx.Value(api.Ctx("auth")).(User).Company
+		err = rows.Scan(&c.ID, &c.Code, &c.Name)
+		if err != nil {
+			return list, err
+		}
+
+		list = append(list, c)
+	}
+
+	return list, rows.Err()
+}
+
+// Create new Uom
+func (u *Uom) Create(ctx context.Context, tx *sql.Tx) error {
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO uoms (company_id, code, name) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	res, err := stmt.ExecContext(ctx, userLogin.Company.ID, u.Code, u.Name)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	u.ID = uint64(id)
+	u.Company = userLogin.Company
+
+	return err
+}
+
+// View Uom by id
+func (u *Uom) View(ctx context.Context, tx *sql.Tx) error {
+	u.Company = ctx.Value(api.Ctx("auth")).(User).Company
+
+	return tx.QueryRowContext(
+		ctx,
+		qUoms+" WHERE id=? AND company_id=?",
+		u.ID,
+		ctx.Value(api.Ctx("auth")).(User).Company.ID,
+	).Scan(&u.ID, &u.Code, &u.Name)
+}
+
+// Update Uom by id
+func (u *Uom) Update(ctx context.Context, tx *sql.Tx) error {
+	stmt, err := tx.PrepareContext(ctx, `
+		UPDATE uoms  
+		SET code = ?,
+			name = ?
+		WHERE id = ? AND company_id = ?`)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	u.Company = userLogin.Company
+	_, err = stmt.ExecContext(ctx, u.Code, u.Name, u.ID, userLogin.Company.ID)
+
+	return err
+}
+
+// Delete Uom by id
+func (u *Uom) Delete(ctx context.Context, tx *sql.Tx) error {
+	stmt, err := tx.PrepareContext(ctx, `DELETE FROM uoms WHERE id = ? AND company_id = ?`)
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, u.ID, ctx.Value(api.Ctx("auth")).(User).Company.ID)
+
+	return err
+}
+
+// nullID return nil for empty unit, so column referencing unit is kept NULL
+func (u *Uom) nullID() interface{} {
+	if u.ID == 0 {
+		return nil
+	}
+
+	return u.ID
+}
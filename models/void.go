@@ -0,0 +1,194 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jacky-htg/inventory/libraries/api"
+)
+
+// Void : struct of voiding of posted document, its movements are reversed in inventories on the void date
+type Void struct {
+	Date   sql.NullTime
+	Reason string
+	User   User
+}
+
+// Correction : struct of voided document which is corrected by a new document
+type Correction struct {
+	ID   uint64
+	Code string
+}
+
+// getVoid fill void of document in table and the voided document which is corrected by it
+func getVoid(ctx context.Context, tx *sql.Tx, table string, id uint64, void *Void, correction *Correction) error {
+	return tx.QueryRowContext(ctx, `
+		SELECT document.voided, document.void_reason, IFNULL(users.id, 0), IFNULL(users.username, ''),
+			IFNULL(corrected.id, 0), IFNULL(corrected.code, '')
+		FROM `+table+` document
+		LEFT JOIN users ON document.voided_by = users.id
+		LEFT JOIN `+table+` corrected ON document.correction_of = corrected.id
+		WHERE document.id = ?`, id).Scan(
+		&void.Date, &void.Reason, &void.User.ID, &void.User.Username, &correction.ID, &correction.Code)
+}
+
+// checkEditable reject changes of voided document, and of posted document when company of user login posts immutably.
+// Posted document of immutable posting company can only be voided and corrected by a new document.
+func (u *Void) checkEditable(ctx context.Context, tx *sql.Tx, document string, posted bool) error {
+	if u.Date.Valid {
+		return api.ErrBadRequest(fmt.Errorf("%s has been voided", document), "")
+	}
+
+	if !posted {
+		return nil
+	}
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	immutable, err := userLogin.Company.GetImmutablePosting(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if immutable {
+		return api.ErrBadRequest(fmt.Errorf("Posted %s can not be edited, void it and create a correction", document), "")
+	}
+
+	return nil
+}
+
+// void mark document in table as voided by user login and write inventories reversing its movements on the void date.
+// Reversals keep type and transaction of the voided movements and are flagged as reversal. They keep the cost of voided movements
+// under FIFO, and moving average is recalculated after them. Voiding is rejected when unit code of document has been moved out by following transactions.
+func (u *Void) void(ctx context.Context, tx *sql.Tx, document, table, transactionType string, id uint64) error {
+	if u.Date.Valid {
+		return api.ErrBadRequest(fmt.Errorf("%s has been voided", document), "")
+	}
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	now := time.Now()
+	err := checkClosedPeriod(ctx, tx, now)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE `+table+`
+		SET voided = ?,
+			voided_by = ?,
+			void_reason = ?,
+			updated_by = ?,
+			updated = NOW()
+		WHERE id = ?
+		AND company_id = ?
+		AND branch_id = ?`,
+		now, userLogin.ID, u.Reason, userLogin.ID, id, userLogin.Company.ID, userLogin.Branch.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO inventories (company_id, branch_id, product_id, product_code, lot, transaction_id, code, transaction_date, type, in_out, reversal, qty, cost, receipt_cost, shelve_id, created, updated)
+		SELECT company_id, branch_id, product_id, product_code, lot, transaction_id, code, ?, type, NOT in_out, 1, qty, cost, receipt_cost, shelve_id, NOW(), NOW()
+		FROM inventories
+		WHERE company_id = ? AND transaction_id = ? AND type = ? AND reversal = 0
+		ORDER BY id`,
+		now, userLogin.Company.ID, id, transactionType)
+	if err != nil {
+		return err
+	}
+
+	var code string
+	err = tx.QueryRowContext(ctx, `
+		SELECT inventories.product_code
+		FROM inventories
+		JOIN (
+			SELECT DISTINCT product_id, product_code
+			FROM inventories
+			WHERE company_id = ? AND transaction_id = ? AND type = ?
+		) units ON inventories.product_id = units.product_id AND inventories.product_code = units.product_code
+		WHERE inventories.company_id = ?
+		GROUP BY inventories.product_id, inventories.product_code
+		HAVING SUM(IF(inventories.in_out, inventories.qty, -inventories.qty)) < 0
+		LIMIT 1`,
+		userLogin.Company.ID, id, transactionType, userLogin.Company.ID).Scan(&code)
+	if err == nil {
+		return api.ErrBadRequest(fmt.Errorf("Unit code %s has been moved out, %s can not be voided", code, document), "")
+	}
+
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	// costs are replayed from the first reversal movement of every product, the earlier movements are not changed
+	reversals, err := tx.QueryContext(ctx,
+		`SELECT product_id, MIN(id) FROM inventories WHERE company_id = ? AND transaction_id = ? AND type = ? AND reversal = 1 GROUP BY product_id`,
+		userLogin.Company.ID, id, transactionType)
+	if err != nil {
+		return err
+	}
+
+	fromIDs := make(map[uint64]uint64)
+	for reversals.Next() {
+		var productID, fromID uint64
+		err = reversals.Scan(&productID, &fromID)
+		if err != nil {
+			reversals.Close()
+			return err
+		}
+
+		fromIDs[productID] = fromID
+	}
+
+	reversals.Close()
+	if err = reversals.Err(); err != nil {
+		return err
+	}
+
+	for productID, fromID := range fromIDs {
+		err = revaluate(ctx, tx, productID, fromID)
+		if err != nil {
+			return err
+		}
+	}
+
+	u.Date = sql.NullTime{Time: now, Valid: true}
+	u.User = userLogin
+	return nil
+}
+
+// check reject correction of document in table which is not voided in branch of user login
+func (u *Correction) check(ctx context.Context, tx *sql.Tx, document, table string) error {
+	if u.ID == 0 {
+		return nil
+	}
+
+	userLogin := ctx.Value(api.Ctx("auth")).(User)
+	var voided bool
+	err := tx.QueryRowContext(ctx,
+		`SELECT code, voided IS NOT NULL FROM `+table+` WHERE id = ? AND company_id = ? AND branch_id = ?`,
+		u.ID, userLogin.Company.ID, userLogin.Branch.ID).Scan(&u.Code, &voided)
+	if err == sql.ErrNoRows {
+		return api.ErrBadRequest(fmt.Errorf("Corrected %s is not found", document), "")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !voided {
+		return api.ErrBadRequest(fmt.Errorf("Corrected %s %s has not been voided", document, u.Code), "")
+	}
+
+	return nil
+}
+
+// nullID return nil for empty correction, so column referencing corrected document is kept NULL
+func (u *Correction) nullID() interface{} {
+	if u.ID == 0 {
+		return nil
+	}
+
+	return u.ID
+}
@@ -0,0 +1,65 @@
+package request
+
+import (
+	"github.com/jacky-htg/inventory/models"
+)
+
+// NewApprovalRuleRequest : format json request for new approval rule, steps is ordered list of approver role id.
+// Min amount is total price for SO and quantity for DR, RR and OP, min discount only applies to SO.
+type NewApprovalRuleRequest struct {
+	BranchID     uint32   `json:"branch"`
+	DocumentType string   `json:"document_type" validate:"required,oneof=SO DR RR OP"`
+	Name         string   `json:"name" validate:"required"`
+	MinAmount    float64  `json:"min_amount" validate:"min=0"`
+	MinDiscount  float64  `json:"min_discount" validate:"min=0,max=100"`
+	Steps        []uint32 `json:"steps" validate:"required,min=1"`
+}
+
+// Transform NewApprovalRuleRequest to ApprovalRule
+func (u *NewApprovalRuleRequest) Transform() *models.ApprovalRule {
+	var p models.ApprovalRule
+	p.Branch.ID = u.BranchID
+	p.DocumentType = u.DocumentType
+	p.Name = u.Name
+	p.MinAmount = u.MinAmount
+	p.MinDiscount = u.MinDiscount
+
+	for _, s := range u.Steps {
+		p.Steps = append(p.Steps, models.ApprovalStep{Role: models.Role{ID: s}})
+	}
+
+	return &p
+}
+
+// ApprovalRuleRequest : format json request for approval rule, min amount is total price for SO and quantity for DR, RR and OP
+type ApprovalRuleRequest struct {
+	ID           uint32   `json:"id" validate:"required"`
+	BranchID     uint32   `json:"branch"`
+	DocumentType string   `json:"document_type" validate:"required,oneof=SO DR RR OP"`
+	Name         string   `json:"name" validate:"required"`
+	MinAmount    float64  `json:"min_amount" validate:"min=0"`
+	MinDiscount  float64  `json:"min_discount" validate:"min=0,max=100"`
+	Steps        []uint32 `json:"steps" validate:"required,min=1"`
+}
+
+// Transform ApprovalRuleRequest to ApprovalRule
+func (u *ApprovalRuleRequest) Transform(p *models.ApprovalRule) *models.ApprovalRule {
+	if u.ID == p.ID {
+		p.Branch = models.Branch{ID: u.BranchID}
+		p.DocumentType = u.DocumentType
+		p.Name = u.Name
+		p.MinAmount = u.MinAmount
+		p.MinDiscount = u.MinDiscount
+
+		p.Steps = nil
+		for _, s := range u.Steps {
+			p.Steps = append(p.Steps, models.ApprovalStep{Role: models.Role{ID: s}})
+		}
+	}
+	return p
+}
+
+// ApprovalActionRequest : format json request for approving or rejecting approval
+type ApprovalActionRequest struct {
+	Remark string `json:"remark"`
+}
@@ -0,0 +1,52 @@
+package request
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// ReopenClosingStockRequest : format json request for reopening closed period
+type ReopenClosingStockRequest struct {
+	Month int `json:"month" validate:"required,min=1,max=12"`
+	Year  int `json:"year" validate:"required"`
+}
+
+// Transform ReopenClosingStockRequest to ClosingStock
+func (u *ReopenClosingStockRequest) Transform() *models.ClosingStock {
+	return &models.ClosingStock{Month: u.Month, Year: u.Year}
+}
+
+// OpeningBalanceRequest : format json request for importing opening balance
+type OpeningBalanceRequest struct {
+	Date            string                        `json:"date" validate:"required"`
+	OpeningBalances []OpeningBalanceDetailRequest `json:"opening_balances" validate:"required"`
+}
+
+// Transform OpeningBalanceRequest to OpeningBalance
+func (u *OpeningBalanceRequest) Transform() (time.Time, []models.OpeningBalance) {
+	date, _ := time.Parse("2006-01-02", u.Date)
+
+	var balances []models.OpeningBalance
+	for _, b := range u.OpeningBalances {
+		var balance models.OpeningBalance
+		balance.Product.ID = b.ProductID
+		balance.Code = b.Code
+		balance.Shelve.ID = b.ShelveID
+		balance.Qty = 1
+		if b.Qty > 0 {
+			balance.Qty = b.Qty
+		}
+		balances = append(balances, balance)
+	}
+
+	return date, balances
+}
+
+// OpeningBalanceDetailRequest : format json request for unit code of opening balance
+type OpeningBalanceDetailRequest struct {
+	ProductID uint64 `json:"product" validate:"required"`
+	Code      string `json:"code" validate:"required"`
+	ShelveID  uint64 `json:"shelve" validate:"required"`
+	Qty       uint   `json:"qty"`
+}
@@ -8,9 +8,14 @@ import (
 
 //NewCompanyRequest : format json request for new company
 type NewCompanyRequest struct {
-	Code    string `json:"code" validate:"required"`
-	Name    string `json:"name" validate:"required"`
-	Address string `json:"address,omitempty"`
+	Code                 string  `json:"code" validate:"required"`
+	Name                 string  `json:"name" validate:"required"`
+	Address              string  `json:"address,omitempty"`
+	ValuationMethod      string  `json:"valuation_method,omitempty" validate:"omitempty,oneof=FIFO AVG"`
+	ATPPolicy            string  `json:"atp_policy,omitempty" validate:"omitempty,oneof=REJECT BACKORDER"`
+	POApprovalLimit      float64 `json:"po_approval_limit,omitempty" validate:"omitempty,min=0"`
+	OverReceiptTolerance float64 `json:"over_receipt_tolerance,omitempty" validate:"omitempty,min=0"`
+	ImmutablePosting     bool    `json:"immutable_posting,omitempty"`
 }
 
 //Transform NewCompanyRequest to Company
@@ -21,15 +26,25 @@ func (u *NewCompanyRequest) Transform() *models.Company {
 	if len(u.Address) > 0 {
 		company.Address = sql.NullString{Valid: true, String: u.Address}
 	}
+	company.ValuationMethod = u.ValuationMethod
+	company.ATPPolicy = u.ATPPolicy
+	company.POApprovalLimit = u.POApprovalLimit
+	company.OverReceiptTolerance = u.OverReceiptTolerance
+	company.ImmutablePosting = u.ImmutablePosting
 	return &company
 }
 
 //CompanyRequest : format json request for company
 type CompanyRequest struct {
-	ID      uint32 `json:"id,omitempty" validate:"required"`
-	Code    string `json:"code,omitempty"`
-	Name    string `json:"name,omitempty"`
-	Address string `json:"address,omitempty"`
+	ID                   uint32   `json:"id,omitempty" validate:"required"`
+	Code                 string   `json:"code,omitempty"`
+	Name                 string   `json:"name,omitempty"`
+	Address              string   `json:"address,omitempty"`
+	ValuationMethod      string   `json:"valuation_method,omitempty" validate:"omitempty,oneof=FIFO AVG"`
+	ATPPolicy            string   `json:"atp_policy,omitempty" validate:"omitempty,oneof=REJECT BACKORDER"`
+	POApprovalLimit      *float64 `json:"po_approval_limit,omitempty" validate:"omitempty,min=0"`
+	OverReceiptTolerance *float64 `json:"over_receipt_tolerance,omitempty" validate:"omitempty,min=0"`
+	ImmutablePosting     *bool    `json:"immutable_posting,omitempty"`
 }
 
 //Transform CompanyRequest to Company
@@ -46,6 +61,26 @@ func (u *CompanyRequest) Transform(company *models.Company) *models.Company {
 		if len(u.Address) > 0 {
 			company.Address = sql.NullString{Valid: true, String: u.Address}
 		}
+
+		if len(u.ValuationMethod) > 0 {
+			company.ValuationMethod = u.ValuationMethod
+		}
+
+		if len(u.ATPPolicy) > 0 {
+			company.ATPPolicy = u.ATPPolicy
+		}
+
+		if u.POApprovalLimit != nil {
+			company.POApprovalLimit = *u.POApprovalLimit
+		}
+
+		if u.OverReceiptTolerance != nil {
+			company.OverReceiptTolerance = *u.OverReceiptTolerance
+		}
+
+		if u.ImmutablePosting != nil {
+			company.ImmutablePosting = *u.ImmutablePosting
+		}
 	}
 	return company
 }
@@ -10,8 +10,11 @@ import (
 type NewDeliveryRequest struct {
 	Date            string                     `json:"date" validate:"required"`
 	Remark          string                     `json:"remark"`
+	Picking         string                     `json:"picking"`
+	AllowExpired    bool                       `json:"allow_expired"`
 	DeliveryDetails []NewDeliveryDetailRequest `json:"delivery_details" validate:"required"`
 	SalesOrderID    uint64                     `json:"sales_order" validate:"required"`
+	CorrectionOf    uint64                     `json:"correction_of"`
 }
 
 // Transform NewDeliveryRequest to Delivery
@@ -20,6 +23,9 @@ func (u *NewDeliveryRequest) Transform() *models.Delivery {
 	p.Date, _ = time.Parse("2006-01-02", u.Date)
 	p.SalesOrder.ID = u.SalesOrderID
 	p.Remark = u.Remark
+	p.CorrectionOf.ID = u.CorrectionOf
+	p.Picking = u.Picking
+	p.AllowExpired = u.AllowExpired
 
 	for _, pd := range u.DeliveryDetails {
 		p.DeliveryDetails = append(p.DeliveryDetails, pd.Transform())
@@ -28,17 +34,28 @@ func (u *NewDeliveryRequest) Transform() *models.Delivery {
 	return &p
 }
 
-// NewDeliveryDetailRequest : format json request for Delivery detail
+// NewDeliveryDetailRequest : format json request for Delivery detail.
+// When code is empty, the unit code is picked automatically, optionally limited to the shelve.
+// Qty is only used by bulk product, serialized product is delivered per unit code.
 type NewDeliveryDetailRequest struct {
 	ProductID uint64 `json:"product" validate:"required"`
-	ShelveID  uint64 `json:"shelve" validate:"required"`
+	UomID     uint64 `json:"uom"`
+	Code      string `json:"code"`
+	ShelveID  uint64 `json:"shelve"`
+	Qty       uint   `json:"qty"`
 }
 
 // Transform NewDeliveryDetailRequest to DeliveryDetail
 func (u *NewDeliveryDetailRequest) Transform() models.DeliveryDetail {
 	var pd models.DeliveryDetail
 	pd.Qty = 1
+	if u.Qty > 0 {
+		pd.Qty = u.Qty
+	}
 	pd.Product.ID = u.ProductID
+	pd.Uom.ID = u.UomID
+	pd.UomQty = pd.Qty
+	pd.Code = u.Code
 	pd.Shelve.ID = u.ShelveID
 
 	return pd
@@ -49,6 +66,8 @@ type DeliveryRequest struct {
 	ID              uint64                  `json:"id" validate:"required"`
 	Date            string                  `json:"date"`
 	Remark          string                  `json:"remark"`
+	Picking         string                  `json:"picking"`
+	AllowExpired    bool                    `json:"allow_expired"`
 	DeliveryDetails []DeliveryDetailRequest `json:"delivery_details"`
 	SalesOrderID    uint64                  `json:"sales_order"`
 }
@@ -59,6 +78,8 @@ func (u *DeliveryRequest) Transform(p *models.Delivery) *models.Delivery {
 		p.Date, _ = time.Parse("2006-01-02", u.Date)
 		p.SalesOrder.ID = u.SalesOrderID
 		p.Remark = u.Remark
+		p.Picking = u.Picking
+		p.AllowExpired = u.AllowExpired
 
 		var details []models.DeliveryDetail
 		for _, pd := range u.DeliveryDetails {
@@ -75,7 +96,10 @@ func (u *DeliveryRequest) Transform(p *models.Delivery) *models.Delivery {
 type DeliveryDetailRequest struct {
 	ID        uint64 `json:"id"`
 	ProductID uint64 `json:"product"`
+	UomID     uint64 `json:"uom"`
+	Code      string `json:"code"`
 	ShelveID  uint64 `json:"shelve"`
+	Qty       uint   `json:"qty"`
 }
 
 // Transform DeliveryDetailRequest to DeliveryDetail
@@ -83,7 +107,13 @@ func (u *DeliveryDetailRequest) Transform() models.DeliveryDetail {
 	var pd models.DeliveryDetail
 	pd.ID = u.ID
 	pd.Qty = 1
+	if u.Qty > 0 {
+		pd.Qty = u.Qty
+	}
 	pd.Product.ID = u.ProductID
+	pd.Uom.ID = u.UomID
+	pd.UomQty = pd.Qty
+	pd.Code = u.Code
 	pd.Shelve.ID = u.ShelveID
 
 	return pd
@@ -12,6 +12,7 @@ type NewDeliveryReturnRequest struct {
 	Remark                string                           `json:"remark"`
 	DeliveryReturnDetails []NewDeliveryReturnDetailRequest `json:"delivery_return_details" validate:"required"`
 	DeliveryID            uint64                           `json:"delivery" validate:"required"`
+	CorrectionOf          uint64                           `json:"correction_of"`
 }
 
 // Transform NewDeliveryReturnRequest to Delivery return
@@ -20,6 +21,7 @@ func (u *NewDeliveryReturnRequest) Transform() *models.DeliveryReturn {
 	p.Date, _ = time.Parse("2006-01-02", u.Date)
 	p.Delivery.ID = u.DeliveryID
 	p.Remark = u.Remark
+	p.CorrectionOf.ID = u.CorrectionOf
 
 	for _, pd := range u.DeliveryReturnDetails {
 		p.DeliveryReturnDetails = append(p.DeliveryReturnDetails, pd.Transform())
@@ -32,12 +34,16 @@ func (u *NewDeliveryReturnRequest) Transform() *models.DeliveryReturn {
 type NewDeliveryReturnDetailRequest struct {
 	ProductID uint64 `json:"product" validate:"required"`
 	Code      string `json:"code" validate:"required"`
+	Qty       uint   `json:"qty"`
 }
 
 // Transform NewDeliveryDetailRequest to DeliveryDetail
 func (u *NewDeliveryReturnDetailRequest) Transform() models.DeliveryReturnDetail {
 	var pd models.DeliveryReturnDetail
 	pd.Qty = 1
+	if u.Qty > 0 {
+		pd.Qty = u.Qty
+	}
 	pd.Product.ID = u.ProductID
 	pd.Code = u.Code
 
@@ -76,6 +82,7 @@ type DeliveryReturnDetailRequest struct {
 	ID        uint64 `json:"id"`
 	ProductID uint64 `json:"product"`
 	Code      string `json:"code"`
+	Qty       uint   `json:"qty"`
 }
 
 // Transform DeliveryReturnDetailRequest to DeliveryReturnDetail
@@ -83,6 +90,9 @@ func (u *DeliveryReturnDetailRequest) Transform() models.DeliveryReturnDetail {
 	var pd models.DeliveryReturnDetail
 	pd.ID = u.ID
 	pd.Qty = 1
+	if u.Qty > 0 {
+		pd.Qty = u.Qty
+	}
 	pd.Product.ID = u.ProductID
 	pd.Code = u.Code
 
@@ -0,0 +1,98 @@
+package request
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// NewMutationRequest : format json request for new Mutation
+type NewMutationRequest struct {
+	Date            string                     `json:"date" validate:"required"`
+	Remark          string                     `json:"remark"`
+	MutationDetails []NewMutationDetailRequest `json:"mutation_details" validate:"required"`
+}
+
+// Transform NewMutationRequest to Mutation
+func (u *NewMutationRequest) Transform() *models.Mutation {
+	var p models.Mutation
+	p.Date, _ = time.Parse("2006-01-02", u.Date)
+	p.Remark = u.Remark
+
+	for _, pd := range u.MutationDetails {
+		p.MutationDetails = append(p.MutationDetails, pd.Transform())
+	}
+
+	return &p
+}
+
+// NewMutationDetailRequest : format json request for Mutation detail.
+// Qty is only used by bulk product, serialized product is moved per unit code.
+type NewMutationDetailRequest struct {
+	ProductID uint64 `json:"product" validate:"required"`
+	Code      string `json:"code" validate:"required"`
+	ShelveID  uint64 `json:"shelve" validate:"required"`
+	Qty       uint   `json:"qty"`
+}
+
+// Transform NewMutationDetailRequest to MutationDetail
+func (u *NewMutationDetailRequest) Transform() models.MutationDetail {
+	var pd models.MutationDetail
+	pd.Qty = 1
+	if u.Qty > 0 {
+		pd.Qty = u.Qty
+	}
+	pd.Product.ID = u.ProductID
+	pd.Code = u.Code
+	pd.ShelveTo.ID = u.ShelveID
+
+	return pd
+}
+
+// MutationRequest : format json request for Mutation
+type MutationRequest struct {
+	ID              uint64                  `json:"id" validate:"required"`
+	Date            string                  `json:"date"`
+	Remark          string                  `json:"remark"`
+	MutationDetails []MutationDetailRequest `json:"mutation_details"`
+}
+
+// Transform MutationRequest to Mutation
+func (u *MutationRequest) Transform(p *models.Mutation) *models.Mutation {
+	if u.ID == p.ID {
+		p.Date, _ = time.Parse("2006-01-02", u.Date)
+		p.Remark = u.Remark
+
+		var details []models.MutationDetail
+		for _, pd := range u.MutationDetails {
+			details = append(details, pd.Transform())
+		}
+
+		p.MutationDetails = details
+	}
+	return p
+}
+
+// MutationDetailRequest : format json request for Mutation detail
+type MutationDetailRequest struct {
+	ID        uint64 `json:"id"`
+	ProductID uint64 `json:"product"`
+	Code      string `json:"code"`
+	ShelveID  uint64 `json:"shelve"`
+	Qty       uint   `json:"qty"`
+}
+
+// Transform MutationDetailRequest to MutationDetail
+func (u *MutationDetailRequest) Transform() models.MutationDetail {
+	var pd models.MutationDetail
+	pd.ID = u.ID
+	pd.Qty = 1
+	if u.Qty > 0 {
+		pd.Qty = u.Qty
+	}
+	pd.Product.ID = u.ProductID
+	pd.Code = u.Code
+	pd.ShelveTo.ID = u.ShelveID
+
+	return pd
+}
@@ -14,6 +14,8 @@ type NewProductRequest struct {
 	MinimumStock      string  `json:"minimum_stock" validate:"required"`
 	BrandID           string  `json:"brand" validate:"required"`
 	ProductCategoryID string  `json:"product_category" validate:"required"`
+	UomID             string  `json:"uom"`
+	Serialized        *bool   `json:"serialized"`
 }
 
 // Transform NewProductRequest to Product
@@ -32,6 +34,14 @@ func (u *NewProductRequest) Transform() *models.Product {
 	productCategoryID, _ := strconv.Atoi(u.ProductCategoryID)
 	product.ProductCategory.ID = uint64(productCategoryID)
 
+	uomID, _ := strconv.Atoi(u.UomID)
+	product.Uom.ID = uint64(uomID)
+
+	product.Serialized = true
+	if u.Serialized != nil {
+		product.Serialized = *u.Serialized
+	}
+
 	return &product
 }
 
@@ -44,6 +54,8 @@ type ProductRequest struct {
 	MinimumStock      string  `json:"minimum_stock,omitempty"`
 	BrandID           string  `json:"brand"`
 	ProductCategoryID string  `json:"product_category"`
+	UomID             string  `json:"uom"`
+	Serialized        *bool   `json:"serialized"`
 }
 
 // Transform ProductRequest to Product
@@ -75,6 +87,15 @@ func (u *ProductRequest) Transform(product *models.Product) *models.Product {
 			productCategoryID, _ := strconv.Atoi(u.ProductCategoryID)
 			product.ProductCategory.ID = uint64(productCategoryID)
 		}
+
+		if len(u.UomID) > 0 {
+			uomID, _ := strconv.Atoi(u.UomID)
+			product.Uom.ID = uint64(uomID)
+		}
+
+		if u.Serialized != nil {
+			product.Serialized = *u.Serialized
+		}
 	}
 	return product
 }
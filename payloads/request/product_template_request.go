@@ -0,0 +1,116 @@
+package request
+
+import (
+	"strconv"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// NewProductTemplateRequest : format json request for new product template
+type NewProductTemplateRequest struct {
+	Code              string                    `json:"code" validate:"required"`
+	Name              string                    `json:"name" validate:"required"`
+	SalePrice         float64                   `json:"price" validate:"required"`
+	MinimumStock      string                    `json:"minimum_stock" validate:"required"`
+	BrandID           string                    `json:"brand" validate:"required"`
+	ProductCategoryID string                    `json:"product_category" validate:"required"`
+	Serialized        *bool                     `json:"serialized"`
+	Attributes        []ProductAttributeRequest `json:"attributes"`
+}
+
+// Transform NewProductTemplateRequest to ProductTemplate
+func (u *NewProductTemplateRequest) Transform() *models.ProductTemplate {
+	var template models.ProductTemplate
+	template.Code = u.Code
+	template.Name = u.Name
+	template.SalePrice = u.SalePrice
+
+	minStock, _ := strconv.Atoi(u.MinimumStock)
+	template.MinimumStock = uint(minStock)
+
+	brandID, _ := strconv.Atoi(u.BrandID)
+	template.Brand.ID = uint64(brandID)
+
+	productCategoryID, _ := strconv.Atoi(u.ProductCategoryID)
+	template.ProductCategory.ID = uint64(productCategoryID)
+
+	template.Serialized = true
+	if u.Serialized != nil {
+		template.Serialized = *u.Serialized
+	}
+
+	for _, a := range u.Attributes {
+		template.Attributes = append(template.Attributes, a.Transform())
+	}
+
+	return &template
+}
+
+// ProductTemplateRequest : format json request for product template
+type ProductTemplateRequest struct {
+	ID                uint64                    `json:"id,omitempty" validate:"required"`
+	Name              string                    `json:"name,omitempty"`
+	SalePrice         float64                   `json:"price,omitempty"`
+	MinimumStock      string                    `json:"minimum_stock,omitempty"`
+	BrandID           string                    `json:"brand"`
+	ProductCategoryID string                    `json:"product_category"`
+	Serialized        *bool                     `json:"serialized"`
+	Attributes        []ProductAttributeRequest `json:"attributes"`
+}
+
+// Transform ProductTemplateRequest to ProductTemplate
+func (u *ProductTemplateRequest) Transform(template *models.ProductTemplate) *models.ProductTemplate {
+	if u.ID == template.ID {
+		if len(u.Name) > 0 {
+			template.Name = u.Name
+		}
+
+		if u.SalePrice > 0 {
+			template.SalePrice = u.SalePrice
+		}
+
+		if len(u.MinimumStock) > 0 {
+			minStock, _ := strconv.Atoi(u.MinimumStock)
+			template.MinimumStock = uint(minStock)
+		}
+
+		if len(u.BrandID) > 0 {
+			brandID, _ := strconv.Atoi(u.BrandID)
+			template.Brand.ID = uint64(brandID)
+		}
+
+		if len(u.ProductCategoryID) > 0 {
+			productCategoryID, _ := strconv.Atoi(u.ProductCategoryID)
+			template.ProductCategory.ID = uint64(productCategoryID)
+		}
+
+		if u.Serialized != nil {
+			template.Serialized = *u.Serialized
+		}
+
+		var attributes []models.ProductAttribute
+		for _, a := range u.Attributes {
+			attributes = append(attributes, a.Transform())
+		}
+
+		template.Attributes = attributes
+	}
+	return template
+}
+
+// ProductAttributeRequest : format json request for attribute of product template and its values
+type ProductAttributeRequest struct {
+	Name   string   `json:"name" validate:"required"`
+	Values []string `json:"values" validate:"required"`
+}
+
+// Transform ProductAttributeRequest to ProductAttribute
+func (u *ProductAttributeRequest) Transform() models.ProductAttribute {
+	var a models.ProductAttribute
+	a.Name = u.Name
+	for _, v := range u.Values {
+		a.Values = append(a.Values, models.ProductAttributeValue{Attribute: u.Name, Value: v})
+	}
+
+	return a
+}
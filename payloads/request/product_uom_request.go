@@ -0,0 +1,19 @@
+package request
+
+import "github.com/jacky-htg/inventory/models"
+
+// ProductUomRequest is json request for unit of measure conversion of product and validation
+type ProductUomRequest struct {
+	UomID      uint64 `json:"uom" validate:"required"`
+	Conversion uint   `json:"conversion" validate:"required"`
+}
+
+// Transform ProductUomRequest to ProductUom model
+func (u *ProductUomRequest) Transform(product *models.Product) *models.ProductUom {
+	var c models.ProductUom
+	c.Product = *product
+	c.Uom.ID = u.UomID
+	c.Conversion = u.Conversion
+
+	return &c
+}
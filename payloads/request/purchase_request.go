@@ -38,6 +38,7 @@ type NewPurchaseDetailRequest struct {
 	Disc      float64 `json:"disc"`
 	Qty       uint    `json:"qty" validate:"required"`
 	ProductID uint64  `json:"product" validate:"required"`
+	UomID     uint64  `json:"uom"`
 }
 
 // Transform NewPurchaseDetailRequest to PurchaseDetail
@@ -47,6 +48,8 @@ func (u *NewPurchaseDetailRequest) Transform() models.PurchaseDetail {
 	pd.Disc = u.Disc
 	pd.Qty = u.Qty
 	pd.Product.ID = u.ProductID
+	pd.Uom.ID = u.UomID
+	pd.UomQty = pd.Qty
 
 	return pd
 }
@@ -89,6 +92,7 @@ type PurchaseDetailRequest struct {
 	Disc      float64 `json:"disc"`
 	Qty       uint    `json:"qty"`
 	ProductID uint64  `json:"product"`
+	UomID     uint64  `json:"uom"`
 }
 
 // Transform PurchaseDetailRequest to PurchaseDetail
@@ -99,6 +103,8 @@ func (u *PurchaseDetailRequest) Transform() models.PurchaseDetail {
 	pd.Disc = u.Disc
 	pd.Qty = u.Qty
 	pd.Product.ID = u.ProductID
+	pd.Uom.ID = u.UomID
+	pd.UomQty = pd.Qty
 
 	return pd
 }
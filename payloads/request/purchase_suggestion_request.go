@@ -0,0 +1,23 @@
+package request
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// NewPurchaseSuggestionRequest : format json request for creating purchase from suggestion
+type NewPurchaseSuggestionRequest struct {
+	Date       string   `json:"date" validate:"required"`
+	SupplierID uint64   `json:"supplier" validate:"required"`
+	ProductIDs []uint64 `json:"products"`
+}
+
+// Transform NewPurchaseSuggestionRequest to PurchaseSuggestion
+func (u *NewPurchaseSuggestionRequest) Transform() (*models.PurchaseSuggestion, time.Time) {
+	var p models.PurchaseSuggestion
+	p.Supplier.ID = u.SupplierID
+	date, _ := time.Parse("2006-01-02", u.Date)
+
+	return &p, date
+}
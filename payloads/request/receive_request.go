@@ -12,6 +12,7 @@ type NewReceiveRequest struct {
 	Remark         string                    `json:"remark"`
 	ReceiveDetails []NewReceiveDetailRequest `json:"receive_details" validate:"required"`
 	PurchaseID     uint64                    `json:"purchase" validate:"required"`
+	CorrectionOf   uint64                    `json:"correction_of"`
 }
 
 // Transform NewReceiveRequest to Receive
@@ -20,6 +21,7 @@ func (u *NewReceiveRequest) Transform() *models.Receive {
 	p.Date, _ = time.Parse("2006-01-02", u.Date)
 	p.Purchase.ID = u.PurchaseID
 	p.Remark = u.Remark
+	p.CorrectionOf.ID = u.CorrectionOf
 
 	for _, pd := range u.ReceiveDetails {
 		p.ReceiveDetails = append(p.ReceiveDetails, pd.Transform())
@@ -30,16 +32,35 @@ func (u *NewReceiveRequest) Transform() *models.Receive {
 
 // NewReceiveDetailRequest : format json request for Receive detail
 type NewReceiveDetailRequest struct {
-	ProductID uint64 `json:"product" validate:"required"`
-	ShelveID  uint64 `json:"shelve" validate:"required"`
+	ProductID       uint64 `json:"product" validate:"required"`
+	UomID           uint64 `json:"uom"`
+	ShelveID        uint64 `json:"shelve" validate:"required"`
+	Qty             uint   `json:"qty"`
+	Lot             string `json:"lot" validate:"max=45"`
+	ManufactureDate string `json:"manufacture_date"`
+	ExpiredDate     string `json:"expired_date"`
 }
 
 // Transform NewReceiveDetailRequest to ReceiveDetail
 func (u *NewReceiveDetailRequest) Transform() models.ReceiveDetail {
 	var pd models.ReceiveDetail
 	pd.Qty = 1
+	if u.Qty > 0 {
+		pd.Qty = u.Qty
+	}
 	pd.Product.ID = u.ProductID
+	pd.Uom.ID = u.UomID
+	pd.UomQty = pd.Qty
 	pd.Shelve.ID = u.ShelveID
+	pd.Lot = u.Lot
+	if len(u.ManufactureDate) > 0 {
+		pd.ManufactureDate.Time, _ = time.Parse("2006-01-02", u.ManufactureDate)
+		pd.ManufactureDate.Valid = true
+	}
+	if len(u.ExpiredDate) > 0 {
+		pd.ExpiredDate.Time, _ = time.Parse("2006-01-02", u.ExpiredDate)
+		pd.ExpiredDate.Valid = true
+	}
 
 	return pd
 }
@@ -73,9 +94,14 @@ func (u *ReceiveRequest) Transform(p *models.Receive) *models.Receive {
 
 // ReceiveDetailRequest : format json request for Receive detail
 type ReceiveDetailRequest struct {
-	ID        uint64 `json:"id"`
-	ProductID uint64 `json:"product"`
-	ShelveID  uint64 `json:"shelve"`
+	ID              uint64 `json:"id"`
+	ProductID       uint64 `json:"product"`
+	UomID           uint64 `json:"uom"`
+	ShelveID        uint64 `json:"shelve"`
+	Qty             uint   `json:"qty"`
+	Lot             string `json:"lot" validate:"max=45"`
+	ManufactureDate string `json:"manufacture_date"`
+	ExpiredDate     string `json:"expired_date"`
 }
 
 // Transform ReceiveDetailRequest to ReceiveDetail
@@ -83,8 +109,22 @@ func (u *ReceiveDetailRequest) Transform() models.ReceiveDetail {
 	var pd models.ReceiveDetail
 	pd.ID = u.ID
 	pd.Qty = 1
+	if u.Qty > 0 {
+		pd.Qty = u.Qty
+	}
 	pd.Product.ID = u.ProductID
+	pd.Uom.ID = u.UomID
+	pd.UomQty = pd.Qty
 	pd.Shelve.ID = u.ShelveID
+	pd.Lot = u.Lot
+	if len(u.ManufactureDate) > 0 {
+		pd.ManufactureDate.Time, _ = time.Parse("2006-01-02", u.ManufactureDate)
+		pd.ManufactureDate.Valid = true
+	}
+	if len(u.ExpiredDate) > 0 {
+		pd.ExpiredDate.Time, _ = time.Parse("2006-01-02", u.ExpiredDate)
+		pd.ExpiredDate.Valid = true
+	}
 
 	return pd
 }
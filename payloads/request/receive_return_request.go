@@ -12,6 +12,7 @@ type NewReceiveReturnRequest struct {
 	Remark               string                          `json:"remark"`
 	ReceiveReturnDetails []NewReceiveReturnDetailRequest `json:"receive_return_details" validate:"required"`
 	ReceiveID            uint64                          `json:"receive" validate:"required"`
+	CorrectionOf         uint64                          `json:"correction_of"`
 }
 
 // Transform NewReceiveReturnRequest to Receive return
@@ -20,6 +21,7 @@ func (u *NewReceiveReturnRequest) Transform() *models.ReceiveReturn {
 	p.Date, _ = time.Parse("2006-01-02", u.Date)
 	p.Receive.ID = u.ReceiveID
 	p.Remark = u.Remark
+	p.CorrectionOf.ID = u.CorrectionOf
 
 	for _, pd := range u.ReceiveReturnDetails {
 		p.ReceiveReturnDetails = append(p.ReceiveReturnDetails, pd.Transform())
@@ -32,12 +34,16 @@ func (u *NewReceiveReturnRequest) Transform() *models.ReceiveReturn {
 type NewReceiveReturnDetailRequest struct {
 	ProductID uint64 `json:"product" validate:"required"`
 	Code      string `json:"code" validate:"required"`
+	Qty       uint   `json:"qty"`
 }
 
 // Transform NewReceiveDetailRequest to ReceiveDetail
 func (u *NewReceiveReturnDetailRequest) Transform() models.ReceiveReturnDetail {
 	var pd models.ReceiveReturnDetail
 	pd.Qty = 1
+	if u.Qty > 0 {
+		pd.Qty = u.Qty
+	}
 	pd.Product.ID = u.ProductID
 	pd.Code = u.Code
 
@@ -76,6 +82,7 @@ type ReceiveReturnDetailRequest struct {
 	ID        uint64 `json:"id"`
 	ProductID uint64 `json:"product"`
 	Code      string `json:"code"`
+	Qty       uint   `json:"qty"`
 }
 
 // Transform ReceiveReturnDetailRequest to ReceiveReturnDetail
@@ -83,6 +90,9 @@ func (u *ReceiveReturnDetailRequest) Transform() models.ReceiveReturnDetail {
 	var pd models.ReceiveReturnDetail
 	pd.ID = u.ID
 	pd.Qty = 1
+	if u.Qty > 0 {
+		pd.Qty = u.Qty
+	}
 	pd.Product.ID = u.ProductID
 	pd.Code = u.Code
 
@@ -40,6 +40,7 @@ type NewSalesOrderDetailRequest struct {
 	Disc      float64 `json:"disc"`
 	Qty       uint    `json:"qty" validate:"required"`
 	ProductID uint64  `json:"product" validate:"required"`
+	UomID     uint64  `json:"uom"`
 }
 
 // Transform NewSalesOrderDetailRequest to SalesOrderDetail
@@ -49,6 +50,8 @@ func (u *NewSalesOrderDetailRequest) Transform() models.SalesOrderDetail {
 	pd.Disc = u.Disc
 	pd.Qty = u.Qty
 	pd.Product.ID = u.ProductID
+	pd.Uom.ID = u.UomID
+	pd.UomQty = pd.Qty
 
 	return pd
 }
@@ -92,6 +95,7 @@ type SalesOrderDetailRequest struct {
 	Disc      float64 `json:"disc"`
 	Qty       uint    `json:"qty"`
 	ProductID uint64  `json:"product"`
+	UomID     uint64  `json:"uom"`
 }
 
 // Transform SalesOrderDetailRequest to SalesOrderDetail
@@ -102,6 +106,8 @@ func (u *SalesOrderDetailRequest) Transform() models.SalesOrderDetail {
 	pd.Disc = u.Disc
 	pd.Qty = u.Qty
 	pd.Product.ID = u.ProductID
+	pd.Uom.ID = u.UomID
+	pd.UomQty = pd.Qty
 
 	return pd
 }
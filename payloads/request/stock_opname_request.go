@@ -0,0 +1,148 @@
+package request
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// NewStockOpnameRequest : format json request for new StockOpname
+type NewStockOpnameRequest struct {
+	Date    string   `json:"date" validate:"required"`
+	Remark  string   `json:"remark"`
+	Shelves []uint64 `json:"shelves"`
+}
+
+// Transform NewStockOpnameRequest to StockOpname
+func (u *NewStockOpnameRequest) Transform() *models.StockOpname {
+	var p models.StockOpname
+	p.Date, _ = time.Parse("2006-01-02", u.Date)
+	p.Remark = u.Remark
+
+	for _, s := range u.Shelves {
+		p.Shelves = append(p.Shelves, models.Shelve{ID: s})
+	}
+
+	return &p
+}
+
+// StockOpnameRequest : format json request for StockOpname
+type StockOpnameRequest struct {
+	ID     uint64 `json:"id" validate:"required"`
+	Date   string `json:"date"`
+	Remark string `json:"remark"`
+}
+
+// Transform StockOpnameRequest to StockOpname
+func (u *StockOpnameRequest) Transform(p *models.StockOpname) *models.StockOpname {
+	if u.ID == p.ID {
+		p.Date, _ = time.Parse("2006-01-02", u.Date)
+		p.Remark = u.Remark
+	}
+	return p
+}
+
+// StockOpnameCountRequest : format json request for counting StockOpname
+type StockOpnameCountRequest struct {
+	Counts []StockOpnameCountDetailRequest `json:"counts" validate:"required"`
+}
+
+// Transform StockOpnameCountRequest to list of StockOpnameDetail
+func (u *StockOpnameCountRequest) Transform() []models.StockOpnameDetail {
+	var list []models.StockOpnameDetail
+	for _, c := range u.Counts {
+		list = append(list, c.Transform())
+	}
+
+	return list
+}
+
+// StockOpnameCountDetailRequest : format json request for counted unit code, qty is 1 when omitted
+type StockOpnameCountDetailRequest struct {
+	ProductID uint64 `json:"product" validate:"required"`
+	Code      string `json:"code" validate:"required"`
+	Qty       *uint  `json:"qty"`
+	ShelveID  uint64 `json:"shelve"`
+}
+
+// Transform StockOpnameCountDetailRequest to StockOpnameDetail
+func (u *StockOpnameCountDetailRequest) Transform() models.StockOpnameDetail {
+	var pd models.StockOpnameDetail
+	pd.Product.ID = u.ProductID
+	pd.Code = u.Code
+	pd.CountedQty = 1
+	if u.Qty != nil {
+		pd.CountedQty = *u.Qty
+	}
+	pd.Shelve.ID = u.ShelveID
+
+	return pd
+}
+
+// StockOpnameUploadRequest : csv upload of scanned unit codes.
+// Each line is product_code,unit_code[,qty[,shelve_code]], a header line is skipped.
+type StockOpnameUploadRequest struct {
+	Body io.Reader
+}
+
+// Transform StockOpnameUploadRequest to list of StockOpnameScan
+func (u *StockOpnameUploadRequest) Transform() ([]models.StockOpnameScan, error) {
+	var list []models.StockOpnameScan
+
+	reader := csv.NewReader(u.Body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return list, err
+		}
+
+		line++
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "product_code") {
+			continue
+		}
+
+		if len(record) < 2 || len(strings.TrimSpace(record[0])) == 0 || len(strings.TrimSpace(record[1])) == 0 {
+			return list, errors.New("invalid line " + strconv.Itoa(line) + ": product_code and unit_code are required")
+		}
+
+		scan := models.StockOpnameScan{
+			ProductCode: strings.TrimSpace(record[0]),
+			Code:        strings.TrimSpace(record[1]),
+			Qty:         1,
+		}
+
+		if len(record) > 2 && len(strings.TrimSpace(record[2])) > 0 {
+			qty, err := strconv.ParseUint(strings.TrimSpace(record[2]), 10, 32)
+			if err != nil {
+				return list, errors.New("invalid qty on line " + strconv.Itoa(line))
+			}
+
+			scan.Qty = uint(qty)
+		}
+
+		if len(record) > 3 {
+			scan.ShelveCode = strings.TrimSpace(record[3])
+		}
+
+		list = append(list, scan)
+	}
+
+	if len(list) == 0 {
+		return list, errors.New("empty upload")
+	}
+
+	return list, nil
+}
@@ -0,0 +1,91 @@
+package request
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// NewTransferReceiveRequest : format json request for new TransferReceive
+type NewTransferReceiveRequest struct {
+	Date                   string                            `json:"date" validate:"required"`
+	Remark                 string                            `json:"remark"`
+	TransferID             uint64                            `json:"transfer" validate:"required"`
+	TransferReceiveDetails []NewTransferReceiveDetailRequest `json:"transfer_receive_details" validate:"required"`
+}
+
+// Transform NewTransferReceiveRequest to TransferReceive
+func (u *NewTransferReceiveRequest) Transform() *models.TransferReceive {
+	var p models.TransferReceive
+	p.Date, _ = time.Parse("2006-01-02", u.Date)
+	p.Remark = u.Remark
+	p.Transfer.ID = u.TransferID
+
+	for _, pd := range u.TransferReceiveDetails {
+		p.TransferReceiveDetails = append(p.TransferReceiveDetails, pd.Transform())
+	}
+
+	return &p
+}
+
+// NewTransferReceiveDetailRequest : format json request for TransferReceive detail
+type NewTransferReceiveDetailRequest struct {
+	ProductID uint64 `json:"product" validate:"required"`
+	Code      string `json:"code" validate:"required"`
+	ShelveID  uint64 `json:"shelve" validate:"required"`
+}
+
+// Transform NewTransferReceiveDetailRequest to TransferReceiveDetail
+func (u *NewTransferReceiveDetailRequest) Transform() models.TransferReceiveDetail {
+	var pd models.TransferReceiveDetail
+	pd.Qty = 1
+	pd.Product.ID = u.ProductID
+	pd.Code = u.Code
+	pd.Shelve.ID = u.ShelveID
+
+	return pd
+}
+
+// TransferReceiveRequest : format json request for TransferReceive
+type TransferReceiveRequest struct {
+	ID                     uint64                         `json:"id" validate:"required"`
+	Date                   string                         `json:"date"`
+	Remark                 string                         `json:"remark"`
+	TransferReceiveDetails []TransferReceiveDetailRequest `json:"transfer_receive_details"`
+}
+
+// Transform TransferReceiveRequest to TransferReceive
+func (u *TransferReceiveRequest) Transform(p *models.TransferReceive) *models.TransferReceive {
+	if u.ID == p.ID {
+		p.Date, _ = time.Parse("2006-01-02", u.Date)
+		p.Remark = u.Remark
+
+		var details []models.TransferReceiveDetail
+		for _, pd := range u.TransferReceiveDetails {
+			details = append(details, pd.Transform())
+		}
+
+		p.TransferReceiveDetails = details
+	}
+	return p
+}
+
+// TransferReceiveDetailRequest : format json request for TransferReceive detail
+type TransferReceiveDetailRequest struct {
+	ID        uint64 `json:"id"`
+	ProductID uint64 `json:"product"`
+	Code      string `json:"code"`
+	ShelveID  uint64 `json:"shelve"`
+}
+
+// Transform TransferReceiveDetailRequest to TransferReceiveDetail
+func (u *TransferReceiveDetailRequest) Transform() models.TransferReceiveDetail {
+	var pd models.TransferReceiveDetail
+	pd.ID = u.ID
+	pd.Qty = 1
+	pd.Product.ID = u.ProductID
+	pd.Code = u.Code
+	pd.Shelve.ID = u.ShelveID
+
+	return pd
+}
@@ -0,0 +1,100 @@
+package request
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// NewTransferRequest : format json request for new Transfer
+type NewTransferRequest struct {
+	Date                string                     `json:"date" validate:"required"`
+	Remark              string                     `json:"remark"`
+	DestinationBranchID uint32                     `json:"destination_branch" validate:"required"`
+	TransferDetails     []NewTransferDetailRequest `json:"transfer_details" validate:"required"`
+}
+
+// Transform NewTransferRequest to Transfer
+func (u *NewTransferRequest) Transform() *models.Transfer {
+	var p models.Transfer
+	p.Date, _ = time.Parse("2006-01-02", u.Date)
+	p.Remark = u.Remark
+	p.DestinationBranch.ID = u.DestinationBranchID
+
+	for _, pd := range u.TransferDetails {
+		p.TransferDetails = append(p.TransferDetails, pd.Transform())
+	}
+
+	return &p
+}
+
+// NewTransferDetailRequest : format json request for Transfer detail.
+// Qty is only used by bulk product, serialized product is transferred per unit code.
+type NewTransferDetailRequest struct {
+	ProductID uint64 `json:"product" validate:"required"`
+	Code      string `json:"code" validate:"required"`
+	Qty       uint   `json:"qty"`
+}
+
+// Transform NewTransferDetailRequest to TransferDetail
+func (u *NewTransferDetailRequest) Transform() models.TransferDetail {
+	var pd models.TransferDetail
+	pd.Qty = 1
+	if u.Qty > 0 {
+		pd.Qty = u.Qty
+	}
+	pd.Product.ID = u.ProductID
+	pd.Code = u.Code
+
+	return pd
+}
+
+// TransferRequest : format json request for Transfer
+type TransferRequest struct {
+	ID                  uint64                  `json:"id" validate:"required"`
+	Date                string                  `json:"date"`
+	Remark              string                  `json:"remark"`
+	DestinationBranchID uint32                  `json:"destination_branch"`
+	TransferDetails     []TransferDetailRequest `json:"transfer_details"`
+}
+
+// Transform TransferRequest to Transfer
+func (u *TransferRequest) Transform(p *models.Transfer) *models.Transfer {
+	if u.ID == p.ID {
+		p.Date, _ = time.Parse("2006-01-02", u.Date)
+		p.Remark = u.Remark
+		if u.DestinationBranchID > 0 {
+			p.DestinationBranch = models.Branch{ID: u.DestinationBranchID}
+		}
+
+		var details []models.TransferDetail
+		for _, pd := range u.TransferDetails {
+			details = append(details, pd.Transform())
+		}
+
+		p.TransferDetails = details
+	}
+	return p
+}
+
+// TransferDetailRequest : format json request for Transfer detail
+type TransferDetailRequest struct {
+	ID        uint64 `json:"id"`
+	ProductID uint64 `json:"product"`
+	Code      string `json:"code"`
+	Qty       uint   `json:"qty"`
+}
+
+// Transform TransferDetailRequest to TransferDetail
+func (u *TransferDetailRequest) Transform() models.TransferDetail {
+	var pd models.TransferDetail
+	pd.ID = u.ID
+	pd.Qty = 1
+	if u.Qty > 0 {
+		pd.Qty = u.Qty
+	}
+	pd.Product.ID = u.ProductID
+	pd.Code = u.Code
+
+	return pd
+}
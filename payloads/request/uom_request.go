@@ -0,0 +1,38 @@
+package request
+
+import "github.com/jacky-htg/inventory/models"
+
+// NewUomRequest is json request for new Uom and validation
+type NewUomRequest struct {
+	Code string `json:"code" validate:"required"`
+	Name string `json:"name" validate:"required"`
+}
+
+// Transform NewUomRequest to Uom model
+func (u *NewUomRequest) Transform() models.Uom {
+	var c models.Uom
+	c.Code = u.Code
+	c.Name = u.Name
+
+	return c
+}
+
+// UomRequest is json request for update Uom and validation
+type UomRequest struct {
+	ID   uint64 `json:"id" validate:"required"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// Transform UomRequest to Uom model
+func (u *UomRequest) Transform(c *models.Uom) *models.Uom {
+	if c.ID == u.ID {
+		if len(u.Name) > 0 {
+			c.Name = u.Name
+		}
+		if len(u.Code) > 0 {
+			c.Code = u.Code
+		}
+	}
+	return c
+}
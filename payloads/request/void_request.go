@@ -0,0 +1,16 @@
+package request
+
+import (
+	"github.com/jacky-htg/inventory/models"
+)
+
+// VoidRequest : format json request for voiding posted document
+type VoidRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+// Transform VoidRequest to Void
+func (u *VoidRequest) Transform(p *models.Void) *models.Void {
+	p.Reason = u.Reason
+	return p
+}
@@ -0,0 +1,119 @@
+package response
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// ApprovalRuleResponse : format json response for approval rule
+type ApprovalRuleResponse struct {
+	ID           uint32                 `json:"id"`
+	Branch       *BranchResponse        `json:"branch,omitempty"`
+	DocumentType string                 `json:"document_type"`
+	Name         string                 `json:"name"`
+	MinAmount    float64                `json:"min_amount"`
+	MinDiscount  float64                `json:"min_discount"`
+	Steps        []ApprovalStepResponse `json:"steps"`
+}
+
+// Transform from ApprovalRule model to ApprovalRule response
+func (u *ApprovalRuleResponse) Transform(rule *models.ApprovalRule) {
+	u.ID = rule.ID
+	u.DocumentType = rule.DocumentType
+	u.Name = rule.Name
+	u.MinAmount = rule.MinAmount
+	u.MinDiscount = rule.MinDiscount
+
+	if rule.Branch.ID > 0 {
+		var branchResponse BranchResponse
+		branchResponse.Transform(&rule.Branch)
+		u.Branch = &branchResponse
+	}
+
+	u.Steps = []ApprovalStepResponse{}
+	for _, s := range rule.Steps {
+		var p ApprovalStepResponse
+		p.Step = s.Step
+		p.Role.Transform(&s.Role)
+		u.Steps = append(u.Steps, p)
+	}
+}
+
+// ApprovalStepResponse : format json response for step of approval rule
+type ApprovalStepResponse struct {
+	Step uint8        `json:"step"`
+	Role RoleResponse `json:"role"`
+}
+
+// ApprovalResponse : format json response for approval of document
+type ApprovalResponse struct {
+	ID           uint64                    `json:"id"`
+	DocumentType string                    `json:"document_type"`
+	DocumentID   uint64                    `json:"document_id"`
+	DocumentCode string                    `json:"document_code"`
+	Amount       float64                   `json:"amount"`
+	Discount     float64                   `json:"discount"`
+	Step         uint8                     `json:"step"`
+	Status       string                    `json:"status"`
+	Created      time.Time                 `json:"created"`
+	CreatedBy    string                    `json:"created_by"`
+	Branch       BranchResponse            `json:"branch"`
+	Rule         ApprovalRuleResponse      `json:"rule"`
+	Histories    []ApprovalHistoryResponse `json:"histories"`
+}
+
+// Transform from Approval model to Approval response
+func (u *ApprovalResponse) Transform(approval *models.Approval) {
+	u.ID = approval.ID
+	u.DocumentType = approval.DocumentType
+	u.DocumentID = approval.DocumentID
+	u.DocumentCode = approval.DocumentCode
+	u.Amount = approval.Amount
+	u.Discount = approval.Discount
+	u.Step = approval.Step
+	u.Status = approval.Status
+	u.Created = approval.Created
+	u.CreatedBy = approval.CreatedBy.Username
+	u.Branch.Transform(&approval.Branch)
+	u.Rule.Transform(&approval.Rule)
+
+	u.Histories = []ApprovalHistoryResponse{}
+	for _, h := range approval.Histories {
+		var p ApprovalHistoryResponse
+		p.Transform(&h)
+		u.Histories = append(u.Histories, p)
+	}
+}
+
+// ApprovalHistoryResponse : format json response for action taken on step of approval
+type ApprovalHistoryResponse struct {
+	Step     uint8        `json:"step"`
+	Role     RoleResponse `json:"role"`
+	Username string       `json:"username"`
+	Action   string       `json:"action"`
+	Remark   string       `json:"remark"`
+	Created  time.Time    `json:"created"`
+}
+
+// Transform from ApprovalHistory model to ApprovalHistory response
+func (u *ApprovalHistoryResponse) Transform(history *models.ApprovalHistory) {
+	u.Step = history.Step
+	u.Role.Transform(&history.Role)
+	u.Username = history.User.Username
+	u.Action = history.Action
+	u.Remark = history.Remark
+	u.Created = history.Created
+}
+
+// transformApprovals return approval responses of document, empty list when document has not required approval
+func transformApprovals(approvals []models.Approval) []ApprovalResponse {
+	list := []ApprovalResponse{}
+	for _, a := range approvals {
+		var p ApprovalResponse
+		p.Transform(&a)
+		list = append(list, p)
+	}
+
+	return list
+}
@@ -0,0 +1,45 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// AuditLogResponse : format json response for audit log, before and after are entity data around the action
+type AuditLogResponse struct {
+	ID       uint64          `json:"id"`
+	UserID   uint64          `json:"user_id"`
+	Username string          `json:"username"`
+	Action   string          `json:"action"`
+	Route    string          `json:"route"`
+	Entity   string          `json:"entity"`
+	EntityID uint64          `json:"entity_id"`
+	Before   json.RawMessage `json:"before"`
+	After    json.RawMessage `json:"after"`
+	Created  time.Time       `json:"created"`
+}
+
+// Transform from AuditLog model to AuditLog response
+func (u *AuditLogResponse) Transform(auditLog *models.AuditLog) {
+	u.ID = auditLog.ID
+	u.UserID = auditLog.User.ID
+	u.Username = auditLog.User.Username
+	u.Action = auditLog.Action
+	u.Route = auditLog.Route
+	u.Entity = auditLog.Entity
+	u.EntityID = auditLog.EntityID
+	u.Before = rawJSON(auditLog.Before.String)
+	u.After = rawJSON(auditLog.After.String)
+	u.Created = auditLog.Created
+}
+
+// rawJSON return json null for empty data
+func rawJSON(data string) json.RawMessage {
+	if len(data) == 0 {
+		return json.RawMessage("null")
+	}
+
+	return json.RawMessage(data)
+}
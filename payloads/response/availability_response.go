@@ -0,0 +1,48 @@
+package response
+
+import (
+	"github.com/jacky-htg/inventory/models"
+)
+
+// ProductAvailabilityResponse : format json response for available to promise quantity of product, total and per branch
+type ProductAvailabilityResponse struct {
+	Product  ProductResponse        `json:"product"`
+	OnHand   int                    `json:"on_hand"`
+	Reserved int                    `json:"reserved"`
+	Incoming int                    `json:"incoming"`
+	ATP      int                    `json:"atp"`
+	Branches []AvailabilityResponse `json:"branches"`
+}
+
+// Transform from list of Availability model to ProductAvailability response
+func (u *ProductAvailabilityResponse) Transform(product *models.Product, list []models.Availability) {
+	u.Product.Transform(product)
+	u.Branches = []AvailabilityResponse{}
+	for _, a := range list {
+		var availabilityResponse AvailabilityResponse
+		availabilityResponse.Transform(&a)
+		u.Branches = append(u.Branches, availabilityResponse)
+		u.OnHand += a.OnHand
+		u.Reserved += a.Reserved
+		u.Incoming += a.Incoming
+		u.ATP += a.ATP()
+	}
+}
+
+// AvailabilityResponse : format json response for available to promise quantity of product in a branch
+type AvailabilityResponse struct {
+	Branch   BranchResponse `json:"branch"`
+	OnHand   int            `json:"on_hand"`
+	Reserved int            `json:"reserved"`
+	Incoming int            `json:"incoming"`
+	ATP      int            `json:"atp"`
+}
+
+// Transform from Availability model to Availability response
+func (u *AvailabilityResponse) Transform(availability *models.Availability) {
+	u.Branch.Transform(&availability.Branch)
+	u.OnHand = availability.OnHand
+	u.Reserved = availability.Reserved
+	u.Incoming = availability.Incoming
+	u.ATP = availability.ATP()
+}
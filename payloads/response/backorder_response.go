@@ -0,0 +1,67 @@
+package response
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// CustomerBackorderResponse : format json response for outstanding products of customer
+type CustomerBackorderResponse struct {
+	Customer CustomerResponse           `json:"customer"`
+	Products []ProductBackorderResponse `json:"products"`
+}
+
+// Transform from CustomerBackorder model to CustomerBackorder response
+func (u *CustomerBackorderResponse) Transform(customerBackorder *models.CustomerBackorder) {
+	u.Customer.Transform(&customerBackorder.Customer)
+	for _, p := range customerBackorder.Products {
+		var productBackorderResponse ProductBackorderResponse
+		productBackorderResponse.Transform(&p)
+		u.Products = append(u.Products, productBackorderResponse)
+	}
+}
+
+// ProductBackorderResponse : format json response for outstanding sales orders of product
+type ProductBackorderResponse struct {
+	Product     ProductResponse     `json:"product"`
+	Outstanding int                 `json:"outstanding"`
+	Backorders  []BackorderResponse `json:"sales_orders"`
+}
+
+// Transform from ProductBackorder model to ProductBackorder response
+func (u *ProductBackorderResponse) Transform(productBackorder *models.ProductBackorder) {
+	u.Product.Transform(&productBackorder.Product)
+	u.Outstanding = productBackorder.Outstanding
+	for _, b := range productBackorder.Backorders {
+		var backorderResponse BackorderResponse
+		backorderResponse.Transform(&b)
+		u.Backorders = append(u.Backorders, backorderResponse)
+	}
+}
+
+// BackorderResponse : format json response for outstanding quantity of product in sales order
+type BackorderResponse struct {
+	ID           uint64         `json:"id"`
+	Code         string         `json:"code"`
+	Date         time.Time      `json:"date"`
+	Branch       BranchResponse `json:"branch"`
+	Qty          int            `json:"qty"`
+	ReturnedQty  int            `json:"returned_qty"`
+	DeliveredQty int            `json:"delivered_qty"`
+	BackorderQty int            `json:"backorder_qty"`
+	Outstanding  int            `json:"outstanding"`
+}
+
+// Transform from Backorder model to Backorder response
+func (u *BackorderResponse) Transform(backorder *models.Backorder) {
+	u.ID = backorder.SalesOrder.ID
+	u.Code = backorder.SalesOrder.Code
+	u.Date = backorder.SalesOrder.Date
+	u.Branch.Transform(&backorder.SalesOrder.Branch)
+	u.Qty = backorder.Ordered
+	u.ReturnedQty = backorder.Returned
+	u.DeliveredQty = backorder.Delivered
+	u.BackorderQty = backorder.Backorder
+	u.Outstanding = backorder.Outstanding()
+}
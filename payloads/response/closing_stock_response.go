@@ -0,0 +1,51 @@
+package response
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// ClosingStockResponse : format json response for closing period
+type ClosingStockResponse struct {
+	Month               int                          `json:"month"`
+	Year                int                          `json:"year"`
+	Status              string                       `json:"status,omitempty"`
+	Closed              *time.Time                   `json:"closed,omitempty"`
+	ClosingStockDetails []ClosingStockDetailResponse `json:"closing_stock_details,omitempty"`
+}
+
+// Transform from ClosingStock model to ClosingStock response
+func (u *ClosingStockResponse) Transform(closingStock *models.ClosingStock) {
+	u.Month = closingStock.Month
+	u.Year = closingStock.Year
+	u.Status = closingStock.Status
+	if !closingStock.Closed.IsZero() {
+		closed := closingStock.Closed
+		u.Closed = &closed
+	}
+
+	for _, d := range closingStock.ClosingStockDetails {
+		var p ClosingStockDetailResponse
+		p.Transform(&d)
+		u.ClosingStockDetails = append(u.ClosingStockDetails, p)
+	}
+}
+
+// ClosingStockDetailResponse : format json response for product balance in closing period
+type ClosingStockDetailResponse struct {
+	Product ProductSummaryResponse `json:"product"`
+	Opening int                    `json:"opening"`
+	In      int                    `json:"in"`
+	Out     int                    `json:"out"`
+	Closing int                    `json:"closing"`
+}
+
+// Transform from ClosingStockDetail model to ClosingStockDetail response
+func (u *ClosingStockDetailResponse) Transform(d *models.ClosingStockDetail) {
+	u.Product.Transform(&d.Product)
+	u.Opening = d.Opening
+	u.In = d.In
+	u.Out = d.Out
+	u.Closing = d.Closing
+}
@@ -6,10 +6,15 @@ import (
 
 //CompanyResponse : format json response for company
 type CompanyResponse struct {
-	ID      uint32 `json:"id"`
-	Code    string `json:"code"`
-	Name    string `json:"name"`
-	Address string `json:"address"`
+	ID                   uint32  `json:"id"`
+	Code                 string  `json:"code"`
+	Name                 string  `json:"name"`
+	Address              string  `json:"address"`
+	ValuationMethod      string  `json:"valuation_method,omitempty"`
+	ATPPolicy            string  `json:"atp_policy,omitempty"`
+	POApprovalLimit      float64 `json:"po_approval_limit,omitempty"`
+	OverReceiptTolerance float64 `json:"over_receipt_tolerance,omitempty"`
+	ImmutablePosting     bool    `json:"immutable_posting,omitempty"`
 }
 
 //Transform from Company model to Company response
@@ -18,4 +23,9 @@ func (u *CompanyResponse) Transform(company *models.Company) {
 	u.Name = company.Name
 	u.Code = company.Code
 	u.Address = company.Address.String
+	u.ValuationMethod = company.ValuationMethod
+	u.ATPPolicy = company.ATPPolicy
+	u.POApprovalLimit = company.POApprovalLimit
+	u.OverReceiptTolerance = company.OverReceiptTolerance
+	u.ImmutablePosting = company.ImmutablePosting
 }
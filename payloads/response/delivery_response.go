@@ -16,6 +16,8 @@ type DeliveryResponse struct {
 	Company         CompanyResponse          `json:"company"`
 	Branch          BranchResponse           `json:"branch"`
 	DeliveryDetails []DeliveryDetailResponse `json:"delivery_details"`
+	CorrectionOf    *CorrectionResponse      `json:"correction_of,omitempty"`
+	Void            *VoidResponse            `json:"void,omitempty"`
 }
 
 // Transform from Delivery model to Delivery response
@@ -24,6 +26,8 @@ func (u *DeliveryResponse) Transform(delivery *models.Delivery) {
 	u.Code = delivery.Code
 	u.Date = delivery.Date
 	u.Remark = delivery.Remark
+	u.CorrectionOf = transformCorrection(&delivery.CorrectionOf)
+	u.Void = transformVoid(&delivery.Voided)
 	u.SalesOrder.Transform(&delivery.SalesOrder)
 	u.Company.Transform(&delivery.Company)
 	u.Branch.Transform(&delivery.Branch)
@@ -44,6 +48,7 @@ type DeliveryListResponse struct {
 	SalesOrder SalesOrderResponse `json:"sales_order"`
 	Company    CompanyResponse    `json:"company"`
 	Branch     BranchResponse     `json:"branch"`
+	Voided     *time.Time         `json:"voided,omitempty"`
 }
 
 // Transform from Delivery model to Delivery List response
@@ -52,6 +57,10 @@ func (u *DeliveryListResponse) Transform(delivery *models.Delivery) {
 	u.Code = delivery.Code
 	u.Date = delivery.Date
 	u.Remark = delivery.Remark
+	if delivery.Voided.Date.Valid {
+		voided := delivery.Voided.Date.Time
+		u.Voided = &voided
+	}
 	u.SalesOrder.Transform(&delivery.SalesOrder)
 	u.Company.Transform(&delivery.Company)
 	u.Branch.Transform(&delivery.Branch)
@@ -59,18 +68,49 @@ func (u *DeliveryListResponse) Transform(delivery *models.Delivery) {
 
 // DeliveryDetailResponse : format json response for Delivery detail
 type DeliveryDetailResponse struct {
-	ID      uint64          `json:"id"`
-	Qty     uint            `json:"qty"`
-	Product ProductResponse `json:"product"`
-	Code    string          `json:"code"`
-	Shelve  ShelveResponse  `json:"shelve"`
+	ID      uint64              `json:"id"`
+	Qty     uint                `json:"qty"`
+	UomQty  uint                `json:"uom_qty"`
+	Uom     *UomSummaryResponse `json:"uom,omitempty"`
+	Product ProductResponse     `json:"product"`
+	Code    string              `json:"code"`
+	Lot     string              `json:"lot,omitempty"`
+	Shelve  ShelveResponse      `json:"shelve"`
+	Cost    float64             `json:"cost"`
 }
 
 // Transform from DeliveryDetail model to DeliveryDetail response
 func (u *DeliveryDetailResponse) Transform(pd *models.DeliveryDetail) {
 	u.ID = pd.ID
 	u.Qty = pd.Qty
+	u.UomQty = pd.UomQty
+	if pd.Uom.ID > 0 {
+		u.Uom = new(UomSummaryResponse)
+		u.Uom.Transform(&pd.Uom)
+	}
 	u.Product.Transform(&pd.Product)
 	u.Code = pd.Code
+	u.Lot = pd.Lot
 	u.Shelve.Transform(&pd.Shelve)
+	u.Cost = pd.Cost
+}
+
+// DeliveryMarginResponse : format json response for gross margin of delivery
+type DeliveryMarginResponse struct {
+	Delivery DeliveryListResponse `json:"delivery"`
+	Revenue  float64              `json:"revenue"`
+	Cost     float64              `json:"cost"`
+	Margin   float64              `json:"margin"`
+	Percent  float64              `json:"percent"`
+}
+
+// Transform from DeliveryMargin model to DeliveryMargin response
+func (u *DeliveryMarginResponse) Transform(margin *models.DeliveryMargin) {
+	u.Delivery.Transform(&margin.Delivery)
+	u.Revenue = margin.Revenue
+	u.Cost = margin.Cost
+	u.Margin = margin.Revenue - margin.Cost
+	if margin.Revenue > 0 {
+		u.Percent = u.Margin * 100 / margin.Revenue
+	}
 }
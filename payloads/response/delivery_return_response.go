@@ -15,7 +15,11 @@ type DeliveryReturnResponse struct {
 	Delivery              DeliveryResponse               `json:"delivery"`
 	Company               CompanyResponse                `json:"company"`
 	Branch                BranchResponse                 `json:"branch"`
+	Posted                bool                           `json:"posted"`
 	DeliveryReturnDetails []DeliveryReturnDetailResponse `json:"delivery_return_details"`
+	Approvals             []ApprovalResponse             `json:"approvals"`
+	CorrectionOf          *CorrectionResponse            `json:"correction_of,omitempty"`
+	Void                  *VoidResponse                  `json:"void,omitempty"`
 }
 
 // Transform from Delivery Return model to Delivery return response
@@ -24,9 +28,13 @@ func (u *DeliveryReturnResponse) Transform(deliveryReturn *models.DeliveryReturn
 	u.Code = deliveryReturn.Code
 	u.Date = deliveryReturn.Date
 	u.Remark = deliveryReturn.Remark
+	u.CorrectionOf = transformCorrection(&deliveryReturn.CorrectionOf)
+	u.Void = transformVoid(&deliveryReturn.Voided)
 	u.Delivery.Transform(&deliveryReturn.Delivery)
 	u.Company.Transform(&deliveryReturn.Company)
 	u.Branch.Transform(&deliveryReturn.Branch)
+	u.Posted = deliveryReturn.Posted
+	u.Approvals = transformApprovals(deliveryReturn.Approvals)
 
 	for _, d := range deliveryReturn.DeliveryReturnDetails {
 		var p DeliveryReturnDetailResponse
@@ -44,6 +52,8 @@ type DeliveryReturnListResponse struct {
 	Delivery DeliveryResponse `json:"delivery"`
 	Company  CompanyResponse  `json:"company"`
 	Branch   BranchResponse   `json:"branch"`
+	Posted   bool             `json:"posted"`
+	Voided   *time.Time       `json:"voided,omitempty"`
 }
 
 // Transform from Delivery Return model to Delivery Return List response
@@ -52,9 +62,14 @@ func (u *DeliveryReturnListResponse) Transform(deliveryReturn *models.DeliveryRe
 	u.Code = deliveryReturn.Code
 	u.Date = deliveryReturn.Date
 	u.Remark = deliveryReturn.Remark
+	if deliveryReturn.Voided.Date.Valid {
+		voided := deliveryReturn.Voided.Date.Time
+		u.Voided = &voided
+	}
 	u.Delivery.Transform(&deliveryReturn.Delivery)
 	u.Company.Transform(&deliveryReturn.Company)
 	u.Branch.Transform(&deliveryReturn.Branch)
+	u.Posted = deliveryReturn.Posted
 }
 
 // DeliveryReturnDetailResponse : format json response for Delivery Return detail
@@ -63,6 +78,7 @@ type DeliveryReturnDetailResponse struct {
 	Qty     uint            `json:"qty"`
 	Product ProductResponse `json:"product"`
 	Code    string          `json:"code"`
+	Lot     string          `json:"lot,omitempty"`
 }
 
 // Transform from DeliveryReturnDetail model to DeliveryReturnDetail response
@@ -71,4 +87,5 @@ func (u *DeliveryReturnDetailResponse) Transform(pd *models.DeliveryReturnDetail
 	u.Qty = pd.Qty
 	u.Product.Transform(&pd.Product)
 	u.Code = pd.Code
+	u.Lot = pd.Lot
 }
@@ -0,0 +1,68 @@
+package response
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// LotTraceResponse : format json response for customers who received units of a lot
+type LotTraceResponse struct {
+	Lot       string                      `json:"lot"`
+	Customers []*LotTraceCustomerResponse `json:"customers"`
+}
+
+// Transform from list of LotTrace model to LotTrace response
+func (u *LotTraceResponse) Transform(lot string, list []models.LotTrace) {
+	u.Lot = lot
+	u.Customers = []*LotTraceCustomerResponse{}
+
+	var customer *LotTraceCustomerResponse
+	for _, t := range list {
+		if customer == nil || customer.Customer.ID != t.SalesOrder.Customer.ID {
+			customer = new(LotTraceCustomerResponse)
+			customer.Customer.Transform(&t.SalesOrder.Customer)
+			u.Customers = append(u.Customers, customer)
+		}
+
+		var delivery LotTraceDeliveryResponse
+		delivery.Transform(&t)
+		customer.Deliveries = append(customer.Deliveries, delivery)
+		customer.Qty += int(t.Qty) - int(t.Returned)
+	}
+}
+
+// LotTraceCustomerResponse : format json response for customer in lot trace
+type LotTraceCustomerResponse struct {
+	Customer   CustomerResponse           `json:"customer"`
+	Qty        int                        `json:"qty"`
+	Deliveries []LotTraceDeliveryResponse `json:"deliveries"`
+}
+
+// LotTraceDeliveryResponse : format json response for delivery of lot units to customer
+type LotTraceDeliveryResponse struct {
+	ID             uint64                 `json:"id"`
+	Code           string                 `json:"code"`
+	Date           time.Time              `json:"date"`
+	SalesOrderID   uint64                 `json:"sales_order_id"`
+	SalesOrderCode string                 `json:"sales_order_code"`
+	Branch         BranchResponse         `json:"branch"`
+	Product        ProductSummaryResponse `json:"product"`
+	ProductCode    string                 `json:"product_code"`
+	Qty            uint                   `json:"qty"`
+	Returned       uint                   `json:"returned"`
+}
+
+// Transform from LotTrace model to LotTraceDelivery response
+func (u *LotTraceDeliveryResponse) Transform(t *models.LotTrace) {
+	u.ID = t.Delivery.ID
+	u.Code = t.Delivery.Code
+	u.Date = t.Delivery.Date
+	u.SalesOrderID = t.SalesOrder.ID
+	u.SalesOrderCode = t.SalesOrder.Code
+	u.Branch.Transform(&t.Branch)
+	u.Product.Transform(&t.Product)
+	u.ProductCode = t.ProductCode
+	u.Qty = t.Qty
+	u.Returned = t.Returned
+}
@@ -0,0 +1,74 @@
+package response
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// MutationResponse : format json response for Mutation
+type MutationResponse struct {
+	ID              uint64                   `json:"id"`
+	Code            string                   `json:"code"`
+	Date            time.Time                `json:"date"`
+	Remark          string                   `json:"remark"`
+	Company         CompanyResponse          `json:"company"`
+	Branch          BranchResponse           `json:"branch"`
+	MutationDetails []MutationDetailResponse `json:"mutation_details"`
+}
+
+// Transform from Mutation model to Mutation response
+func (u *MutationResponse) Transform(mutation *models.Mutation) {
+	u.ID = mutation.ID
+	u.Code = mutation.Code
+	u.Date = mutation.Date
+	u.Remark = mutation.Remark
+	u.Company.Transform(&mutation.Company)
+	u.Branch.Transform(&mutation.Branch)
+
+	for _, d := range mutation.MutationDetails {
+		var p MutationDetailResponse
+		p.Transform(&d)
+		u.MutationDetails = append(u.MutationDetails, p)
+	}
+}
+
+// MutationListResponse : format json response for Mutation list
+type MutationListResponse struct {
+	ID      uint64          `json:"id"`
+	Code    string          `json:"code"`
+	Date    time.Time       `json:"date"`
+	Remark  string          `json:"remark"`
+	Company CompanyResponse `json:"company"`
+	Branch  BranchResponse  `json:"branch"`
+}
+
+// Transform from Mutation model to Mutation List response
+func (u *MutationListResponse) Transform(mutation *models.Mutation) {
+	u.ID = mutation.ID
+	u.Code = mutation.Code
+	u.Date = mutation.Date
+	u.Remark = mutation.Remark
+	u.Company.Transform(&mutation.Company)
+	u.Branch.Transform(&mutation.Branch)
+}
+
+// MutationDetailResponse : format json response for Mutation detail
+type MutationDetailResponse struct {
+	ID         uint64          `json:"id"`
+	Qty        uint            `json:"qty"`
+	Product    ProductResponse `json:"product"`
+	Code       string          `json:"code"`
+	ShelveFrom ShelveResponse  `json:"shelve_from"`
+	ShelveTo   ShelveResponse  `json:"shelve_to"`
+}
+
+// Transform from MutationDetail model to MutationDetail response
+func (u *MutationDetailResponse) Transform(pd *models.MutationDetail) {
+	u.ID = pd.ID
+	u.Qty = pd.Qty
+	u.Product.Transform(&pd.Product)
+	u.Code = pd.Code
+	u.ShelveFrom.Transform(&pd.ShelveFrom)
+	u.ShelveTo.Transform(&pd.ShelveTo)
+}
@@ -6,14 +6,17 @@ import (
 
 // ProductResponse : format json response for product
 type ProductResponse struct {
-	ID              uint64                  `json:"id"`
-	Code            string                  `json:"code"`
-	Name            string                  `json:"name"`
-	SalePrice       float64                 `json:"price"`
-	MinimumStock    uint                    `json:"minimum_stock"`
-	Company         CompanyResponse         `json:"company"`
-	Brand           BrandResponse           `json:"brand"`
-	ProductCategory ProductCategoryResponse `json:"product_category"`
+	ID              uint64                          `json:"id"`
+	Code            string                          `json:"code"`
+	Name            string                          `json:"name"`
+	SalePrice       float64                         `json:"price"`
+	MinimumStock    uint                            `json:"minimum_stock"`
+	Serialized      bool                            `json:"serialized"`
+	Company         CompanyResponse                 `json:"company"`
+	Brand           BrandResponse                   `json:"brand"`
+	ProductCategory ProductCategoryResponse         `json:"product_category"`
+	Uom             *UomSummaryResponse             `json:"uom,omitempty"`
+	Template        *ProductTemplateSummaryResponse `json:"template,omitempty"`
 }
 
 // Transform from Product model to Product response
@@ -23,7 +26,32 @@ func (u *ProductResponse) Transform(product *models.Product) {
 	u.Name = product.Name
 	u.SalePrice = product.SalePrice
 	u.MinimumStock = product.MinimumStock
+	u.Serialized = product.Serialized
 	u.Company.Transform(&product.Company)
 	u.Brand.Transform(&product.Brand)
 	u.ProductCategory.Transform(&product.ProductCategory)
+
+	if product.Uom.ID > 0 {
+		u.Uom = new(UomSummaryResponse)
+		u.Uom.Transform(&product.Uom)
+	}
+
+	if product.Template.ID > 0 {
+		u.Template = new(ProductTemplateSummaryResponse)
+		u.Template.Transform(&product.Template)
+	}
+}
+
+// ProductSummaryResponse : format json response for product referenced by report
+type ProductSummaryResponse struct {
+	ID   uint64 `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// Transform from Product model to ProductSummary response
+func (u *ProductSummaryResponse) Transform(product *models.Product) {
+	u.ID = product.ID
+	u.Code = product.Code
+	u.Name = product.Name
 }
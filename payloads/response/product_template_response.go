@@ -0,0 +1,102 @@
+package response
+
+import (
+	"github.com/jacky-htg/inventory/models"
+)
+
+// ProductTemplateResponse : format json response for product template
+type ProductTemplateResponse struct {
+	ID              uint64                     `json:"id"`
+	Code            string                     `json:"code"`
+	Name            string                     `json:"name"`
+	SalePrice       float64                    `json:"price"`
+	MinimumStock    uint                       `json:"minimum_stock"`
+	Serialized      bool                       `json:"serialized"`
+	Company         CompanyResponse            `json:"company"`
+	Brand           BrandResponse              `json:"brand"`
+	ProductCategory ProductCategoryResponse    `json:"product_category"`
+	Attributes      []ProductAttributeResponse `json:"attributes,omitempty"`
+	Variants        []ProductVariantResponse   `json:"variants,omitempty"`
+}
+
+// Transform from ProductTemplate model to ProductTemplate response
+func (u *ProductTemplateResponse) Transform(template *models.ProductTemplate) {
+	u.ID = template.ID
+	u.Code = template.Code
+	u.Name = template.Name
+	u.SalePrice = template.SalePrice
+	u.MinimumStock = template.MinimumStock
+	u.Serialized = template.Serialized
+	u.Company.Transform(&template.Company)
+	u.Brand.Transform(&template.Brand)
+	u.ProductCategory.Transform(&template.ProductCategory)
+
+	for _, a := range template.Attributes {
+		var attribute ProductAttributeResponse
+		attribute.Transform(&a)
+		u.Attributes = append(u.Attributes, attribute)
+	}
+
+	for _, v := range template.Variants {
+		var variant ProductVariantResponse
+		variant.Transform(&v)
+		u.Variants = append(u.Variants, variant)
+	}
+}
+
+// ProductTemplateSummaryResponse : format json response for product template referenced by variant and report
+type ProductTemplateSummaryResponse struct {
+	ID   uint64 `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// Transform from ProductTemplate model to ProductTemplateSummary response
+func (u *ProductTemplateSummaryResponse) Transform(template *models.ProductTemplate) {
+	u.ID = template.ID
+	u.Code = template.Code
+	u.Name = template.Name
+}
+
+// ProductAttributeResponse : format json response for attribute of product template
+type ProductAttributeResponse struct {
+	ID     uint64                          `json:"id"`
+	Name   string                          `json:"name"`
+	Values []ProductAttributeValueResponse `json:"values"`
+}
+
+// Transform from ProductAttribute model to ProductAttribute response
+func (u *ProductAttributeResponse) Transform(attribute *models.ProductAttribute) {
+	u.ID = attribute.ID
+	u.Name = attribute.Name
+	for _, v := range attribute.Values {
+		u.Values = append(u.Values, ProductAttributeValueResponse{ID: v.ID, Value: v.Value})
+	}
+}
+
+// ProductAttributeValueResponse : format json response for value of attribute
+type ProductAttributeValueResponse struct {
+	ID        uint64 `json:"id"`
+	Attribute string `json:"attribute,omitempty"`
+	Value     string `json:"value"`
+}
+
+// ProductVariantResponse : format json response for variant of product template
+type ProductVariantResponse struct {
+	ID        uint64                          `json:"id"`
+	Code      string                          `json:"code"`
+	Name      string                          `json:"name"`
+	SalePrice float64                         `json:"price"`
+	Values    []ProductAttributeValueResponse `json:"values"`
+}
+
+// Transform from ProductVariant model to ProductVariant response
+func (u *ProductVariantResponse) Transform(variant *models.ProductVariant) {
+	u.ID = variant.Product.ID
+	u.Code = variant.Product.Code
+	u.Name = variant.Product.Name
+	u.SalePrice = variant.Product.SalePrice
+	for _, v := range variant.Values {
+		u.Values = append(u.Values, ProductAttributeValueResponse{ID: v.ID, Attribute: v.Attribute, Value: v.Value})
+	}
+}
@@ -8,17 +8,19 @@ import (
 
 // PurchaseResponse : format json response for purchase
 type PurchaseResponse struct {
-	ID              uint64                   `json:"id"`
-	Code            string                   `json:"code"`
-	Date            time.Time                `json:"name"`
-	Price           float64                  `json:"price"`
-	Disc            float64                  `json:"disc"`
-	AdditionalDisc  float64                  `json:"additional_disc"`
-	Total           float64                  `json:"total"`
-	Supplier        SupplierResponse         `json:"supplier"`
-	Company         CompanyResponse          `json:"company"`
-	Branch          BranchResponse           `json:"branch"`
-	PurchaseDetails []PurchaseDetailResponse `json:"purchase_details"`
+	ID              uint64                     `json:"id"`
+	Code            string                     `json:"code"`
+	Date            time.Time                  `json:"name"`
+	Price           float64                    `json:"price"`
+	Disc            float64                    `json:"disc"`
+	AdditionalDisc  float64                    `json:"additional_disc"`
+	Total           float64                    `json:"total"`
+	Supplier        SupplierResponse           `json:"supplier"`
+	Company         CompanyResponse            `json:"company"`
+	Branch          BranchResponse             `json:"branch"`
+	Status          string                     `json:"status"`
+	Approvals       []PurchaseApprovalResponse `json:"approvals"`
+	PurchaseDetails []PurchaseDetailResponse   `json:"purchase_details"`
 }
 
 // Transform from Purchase model to Purchase response
@@ -33,6 +35,12 @@ func (u *PurchaseResponse) Transform(purchase *models.Purchase) {
 	u.Supplier.Transform(&purchase.Supplier)
 	u.Company.Transform(&purchase.Company)
 	u.Branch.Transform(&purchase.Branch)
+	u.Status = purchase.Status
+
+	u.Approvals = []PurchaseApprovalResponse{}
+	for _, a := range purchase.Approvals {
+		u.Approvals = append(u.Approvals, PurchaseApprovalResponse{ID: a.User.ID, Username: a.User.Username, Approved: a.Created})
+	}
 
 	for _, d := range purchase.PurchaseDetails {
 		var p PurchaseDetailResponse
@@ -53,6 +61,7 @@ type PurchaseListResponse struct {
 	Supplier       SupplierResponse `json:"supplier"`
 	Company        CompanyResponse  `json:"company"`
 	Branch         BranchResponse   `json:"branch"`
+	Status         string           `json:"status"`
 }
 
 // Transform from Purchase model to Purchase List response
@@ -67,15 +76,28 @@ func (u *PurchaseListResponse) Transform(purchase *models.Purchase) {
 	u.Supplier.Transform(&purchase.Supplier)
 	u.Company.Transform(&purchase.Company)
 	u.Branch.Transform(&purchase.Branch)
+	u.Status = purchase.Status
+}
+
+// PurchaseApprovalResponse : format json response for user approving purchase
+type PurchaseApprovalResponse struct {
+	ID       uint64    `json:"id"`
+	Username string    `json:"username"`
+	Approved time.Time `json:"approved"`
 }
 
 // PurchaseDetailResponse : format json response for purchase detail
 type PurchaseDetailResponse struct {
-	ID      uint64          `json:"id"`
-	Price   float64         `json:"price"`
-	Disc    float64         `json:"disc"`
-	Qty     uint            `json:"qty"`
-	Product ProductResponse `json:"product"`
+	ID           uint64              `json:"id"`
+	Price        float64             `json:"price"`
+	Disc         float64             `json:"disc"`
+	Qty          uint                `json:"qty"`
+	UomQty       uint                `json:"uom_qty"`
+	Uom          *UomSummaryResponse `json:"uom,omitempty"`
+	Product      ProductResponse     `json:"product"`
+	ReturnedQty  uint                `json:"returned_qty"`
+	ReceivedQty  uint                `json:"received_qty"`
+	RemainingQty uint                `json:"remaining_qty"`
 }
 
 // Transform from PurchaseDetail model to PurchaseDetail response
@@ -84,5 +106,13 @@ func (u *PurchaseDetailResponse) Transform(pd *models.PurchaseDetail) {
 	u.Price = pd.Price
 	u.Disc = pd.Disc
 	u.Qty = pd.Qty
+	u.UomQty = pd.UomQty
+	u.ReturnedQty = pd.ReturnedQty
+	u.ReceivedQty = pd.ReceivedQty
+	u.RemainingQty = pd.RemainingQty
+	if pd.Uom.ID > 0 {
+		u.Uom = new(UomSummaryResponse)
+		u.Uom.Transform(&pd.Uom)
+	}
 	u.Product.Transform(&pd.Product)
 }
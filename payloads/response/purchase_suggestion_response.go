@@ -0,0 +1,47 @@
+package response
+
+import (
+	"github.com/jacky-htg/inventory/models"
+)
+
+// PurchaseSuggestionResponse : format json response for purchase suggestion of supplier
+type PurchaseSuggestionResponse struct {
+	Supplier                  *SupplierResponse                  `json:"supplier"`
+	PurchaseSuggestionDetails []PurchaseSuggestionDetailResponse `json:"purchase_suggestion_details"`
+}
+
+// Transform from PurchaseSuggestion model to PurchaseSuggestion response
+func (u *PurchaseSuggestionResponse) Transform(s *models.PurchaseSuggestion) {
+	if s.Supplier.ID > 0 {
+		u.Supplier = new(SupplierResponse)
+		u.Supplier.Transform(&s.Supplier)
+	}
+
+	for _, d := range s.PurchaseSuggestionDetails {
+		var p PurchaseSuggestionDetailResponse
+		p.Transform(&d)
+		u.PurchaseSuggestionDetails = append(u.PurchaseSuggestionDetails, p)
+	}
+}
+
+// PurchaseSuggestionDetailResponse : format json response for suggested product
+type PurchaseSuggestionDetailResponse struct {
+	Product      ProductResponse `json:"product"`
+	MinimumStock uint            `json:"minimum_stock"`
+	Stock        int             `json:"stock"`
+	OnOrder      int             `json:"on_order"`
+	Qty          uint            `json:"qty"`
+	UnitPrice    float64         `json:"unit_price"`
+	Price        float64         `json:"price"`
+}
+
+// Transform from PurchaseSuggestionDetail model to PurchaseSuggestionDetail response
+func (u *PurchaseSuggestionDetailResponse) Transform(d *models.PurchaseSuggestionDetail) {
+	u.Product.Transform(&d.Product)
+	u.MinimumStock = d.Product.MinimumStock
+	u.Stock = d.Stock
+	u.OnOrder = d.OnOrder
+	u.Qty = d.Qty
+	u.UnitPrice = d.UnitPrice
+	u.Price = d.UnitPrice * float64(d.Qty)
+}
@@ -16,6 +16,8 @@ type ReceiveResponse struct {
 	Company        CompanyResponse         `json:"company"`
 	Branch         BranchResponse          `json:"branch"`
 	ReceiveDetails []ReceiveDetailResponse `json:"receive_details"`
+	CorrectionOf   *CorrectionResponse     `json:"correction_of,omitempty"`
+	Void           *VoidResponse           `json:"void,omitempty"`
 }
 
 // Transform from Receive model to Receive response
@@ -24,6 +26,8 @@ func (u *ReceiveResponse) Transform(receive *models.Receive) {
 	u.Code = receive.Code
 	u.Date = receive.Date
 	u.Remark = receive.Remark
+	u.CorrectionOf = transformCorrection(&receive.CorrectionOf)
+	u.Void = transformVoid(&receive.Voided)
 	u.Purchase.Transform(&receive.Purchase)
 	u.Company.Transform(&receive.Company)
 	u.Branch.Transform(&receive.Branch)
@@ -44,6 +48,7 @@ type ReceiveListResponse struct {
 	Purchase PurchaseResponse `json:"purchase"`
 	Company  CompanyResponse  `json:"company"`
 	Branch   BranchResponse   `json:"branch"`
+	Voided   *time.Time       `json:"voided,omitempty"`
 }
 
 // Transform from Receive model to Receive List response
@@ -52,6 +57,10 @@ func (u *ReceiveListResponse) Transform(receive *models.Receive) {
 	u.Code = receive.Code
 	u.Date = receive.Date
 	u.Remark = receive.Remark
+	if receive.Voided.Date.Valid {
+		voided := receive.Voided.Date.Time
+		u.Voided = &voided
+	}
 	u.Purchase.Transform(&receive.Purchase)
 	u.Company.Transform(&receive.Company)
 	u.Branch.Transform(&receive.Branch)
@@ -59,18 +68,37 @@ func (u *ReceiveListResponse) Transform(receive *models.Receive) {
 
 // ReceiveDetailResponse : format json response for Receive detail
 type ReceiveDetailResponse struct {
-	ID      uint64          `json:"id"`
-	Qty     uint            `json:"qty"`
-	Product ProductResponse `json:"product"`
-	Code    string          `json:"code"`
-	Shelve  ShelveResponse  `json:"shelve"`
+	ID              uint64              `json:"id"`
+	Qty             uint                `json:"qty"`
+	UomQty          uint                `json:"uom_qty"`
+	Uom             *UomSummaryResponse `json:"uom,omitempty"`
+	Product         ProductResponse     `json:"product"`
+	Code            string              `json:"code"`
+	Shelve          ShelveResponse      `json:"shelve"`
+	Lot             string              `json:"lot,omitempty"`
+	ManufactureDate *time.Time          `json:"manufacture_date,omitempty"`
+	ExpiredDate     *time.Time          `json:"expired_date,omitempty"`
 }
 
 // Transform from ReceiveDetail model to ReceiveDetail response
 func (u *ReceiveDetailResponse) Transform(pd *models.ReceiveDetail) {
 	u.ID = pd.ID
 	u.Qty = pd.Qty
+	u.UomQty = pd.UomQty
+	if pd.Uom.ID > 0 {
+		u.Uom = new(UomSummaryResponse)
+		u.Uom.Transform(&pd.Uom)
+	}
 	u.Product.Transform(&pd.Product)
 	u.Code = pd.Code
 	u.Shelve.Transform(&pd.Shelve)
+	u.Lot = pd.Lot
+	if pd.ManufactureDate.Valid {
+		manufactureDate := pd.ManufactureDate.Time
+		u.ManufactureDate = &manufactureDate
+	}
+	if pd.ExpiredDate.Valid {
+		expiredDate := pd.ExpiredDate.Time
+		u.ExpiredDate = &expiredDate
+	}
 }
@@ -15,7 +15,11 @@ type ReceiveReturnResponse struct {
 	Receive              ReceiveResponse               `json:"receive"`
 	Company              CompanyResponse               `json:"company"`
 	Branch               BranchResponse                `json:"branch"`
+	Posted               bool                          `json:"posted"`
 	ReceiveReturnDetails []ReceiveReturnDetailResponse `json:"receive_return_details"`
+	Approvals            []ApprovalResponse            `json:"approvals"`
+	CorrectionOf         *CorrectionResponse           `json:"correction_of,omitempty"`
+	Void                 *VoidResponse                 `json:"void,omitempty"`
 }
 
 // Transform from Receive Return model to Receive return response
@@ -24,9 +28,13 @@ func (u *ReceiveReturnResponse) Transform(receiveReturn *models.ReceiveReturn) {
 	u.Code = receiveReturn.Code
 	u.Date = receiveReturn.Date
 	u.Remark = receiveReturn.Remark
+	u.CorrectionOf = transformCorrection(&receiveReturn.CorrectionOf)
+	u.Void = transformVoid(&receiveReturn.Voided)
 	u.Receive.Transform(&receiveReturn.Receive)
 	u.Company.Transform(&receiveReturn.Company)
 	u.Branch.Transform(&receiveReturn.Branch)
+	u.Posted = receiveReturn.Posted
+	u.Approvals = transformApprovals(receiveReturn.Approvals)
 
 	for _, d := range receiveReturn.ReceiveReturnDetails {
 		var p ReceiveReturnDetailResponse
@@ -44,6 +52,8 @@ type ReceiveReturnListResponse struct {
 	Receive ReceiveResponse `json:"receive"`
 	Company CompanyResponse `json:"company"`
 	Branch  BranchResponse  `json:"branch"`
+	Posted  bool            `json:"posted"`
+	Voided  *time.Time      `json:"voided,omitempty"`
 }
 
 // Transform from Receive Return model to Receive Return List response
@@ -52,9 +62,14 @@ func (u *ReceiveReturnListResponse) Transform(receiveReturn *models.ReceiveRetur
 	u.Code = receiveReturn.Code
 	u.Date = receiveReturn.Date
 	u.Remark = receiveReturn.Remark
+	if receiveReturn.Voided.Date.Valid {
+		voided := receiveReturn.Voided.Date.Time
+		u.Voided = &voided
+	}
 	u.Receive.Transform(&receiveReturn.Receive)
 	u.Company.Transform(&receiveReturn.Company)
 	u.Branch.Transform(&receiveReturn.Branch)
+	u.Posted = receiveReturn.Posted
 }
 
 // ReceiveReturnDetailResponse : format json response for Receive Return detail
@@ -63,6 +78,7 @@ type ReceiveReturnDetailResponse struct {
 	Qty     uint            `json:"qty"`
 	Product ProductResponse `json:"product"`
 	Code    string          `json:"code"`
+	Lot     string          `json:"lot,omitempty"`
 }
 
 // Transform from ReceiveReturnDetail model to ReceiveReturnDetail response
@@ -71,4 +87,5 @@ func (u *ReceiveReturnDetailResponse) Transform(pd *models.ReceiveReturnDetail)
 	u.Qty = pd.Qty
 	u.Product.Transform(&pd.Product)
 	u.Code = pd.Code
+	u.Lot = pd.Lot
 }
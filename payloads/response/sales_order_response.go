@@ -19,7 +19,11 @@ type SalesOrderResponse struct {
 	Customer          CustomerResponse           `json:"customer"`
 	Company           CompanyResponse            `json:"company"`
 	Branch            BranchResponse             `json:"branch"`
+	Backorder         bool                       `json:"backorder"`
+	Status            string                     `json:"status"`
+	Cancelled         *time.Time                 `json:"cancelled,omitempty"`
 	SalesOrderDetails []SalesOrderDetailResponse `json:"sales_order_details"`
+	Approvals         []ApprovalResponse         `json:"approvals"`
 }
 
 // Transform from SalesOrder model to SalesOrderResponse
@@ -35,11 +39,19 @@ func (u *SalesOrderResponse) Transform(salesOrder *models.SalesOrder) {
 	u.Customer.Transform(&salesOrder.Customer)
 	u.Company.Transform(&salesOrder.Company)
 	u.Branch.Transform(&salesOrder.Branch)
+	u.Status = salesOrder.Status
+	u.Approvals = transformApprovals(salesOrder.Approvals)
+
+	if salesOrder.Cancelled.Valid {
+		cancelled := salesOrder.Cancelled.Time
+		u.Cancelled = &cancelled
+	}
 
 	for _, d := range salesOrder.SalesOrderDetails {
 		var s SalesOrderDetailResponse
 		s.Transform(&d)
 		u.SalesOrderDetails = append(u.SalesOrderDetails, s)
+		u.Backorder = u.Backorder || d.BackorderQty > 0
 	}
 }
 
@@ -56,6 +68,8 @@ type SalesOrderListResponse struct {
 	Customer       CustomerResponse `json:"customer"`
 	Company        CompanyResponse  `json:"company"`
 	Branch         BranchResponse   `json:"branch"`
+	Status         string           `json:"status"`
+	Cancelled      *time.Time       `json:"cancelled,omitempty"`
 }
 
 // Transform from SalesOrder model to Sales Order List response
@@ -71,15 +85,26 @@ func (u *SalesOrderListResponse) Transform(salesOrder *models.SalesOrder) {
 	u.Customer.Transform(&salesOrder.Customer)
 	u.Company.Transform(&salesOrder.Company)
 	u.Branch.Transform(&salesOrder.Branch)
+	u.Status = salesOrder.Status
+	if salesOrder.Cancelled.Valid {
+		cancelled := salesOrder.Cancelled.Time
+		u.Cancelled = &cancelled
+	}
 }
 
 // SalesOrderDetailResponse : format json response for sales order detail
 type SalesOrderDetailResponse struct {
-	ID      uint64          `json:"id"`
-	Price   float64         `json:"price"`
-	Disc    float64         `json:"disc"`
-	Qty     uint            `json:"qty"`
-	Product ProductResponse `json:"product"`
+	ID           uint64              `json:"id"`
+	Price        float64             `json:"price"`
+	Disc         float64             `json:"disc"`
+	Qty          uint                `json:"qty"`
+	UomQty       uint                `json:"uom_qty"`
+	BackorderQty uint                `json:"backorder_qty"`
+	DeliveredQty uint                `json:"delivered_qty"`
+	ReturnedQty  uint                `json:"returned_qty"`
+	Status       string              `json:"status"`
+	Uom          *UomSummaryResponse `json:"uom,omitempty"`
+	Product      ProductResponse     `json:"product"`
 }
 
 // Transform from SalesOrderDetail model to SalesOrderDetailResponse
@@ -88,5 +113,14 @@ func (u *SalesOrderDetailResponse) Transform(sod *models.SalesOrderDetail) {
 	u.Price = sod.Price
 	u.Disc = sod.Disc
 	u.Qty = sod.Qty
+	u.UomQty = sod.UomQty
+	u.BackorderQty = sod.BackorderQty
+	u.DeliveredQty = sod.DeliveredQty
+	u.ReturnedQty = sod.ReturnedQty
+	u.Status = sod.Status
+	if sod.Uom.ID > 0 {
+		u.Uom = new(UomSummaryResponse)
+		u.Uom.Transform(&sod.Uom)
+	}
 	u.Product.Transform(&sod.Product)
 }
@@ -0,0 +1,97 @@
+package response
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// scanPaths map type of scanned entity to its endpoint
+var scanPaths = map[string]string{
+	models.ScanUnit:             "/units",
+	models.ScanProduct:          "/products",
+	models.ScanProductTemplate:  "/product-templates",
+	models.ScanPurchase:         "/purchases",
+	models.ScanPurchaseReturn:   "/purchase-returns",
+	models.ScanReceive:          "/receives",
+	models.ScanReceiveReturn:    "/receive-returns",
+	models.ScanSalesOrder:       "/sales-orders",
+	models.ScanSalesOrderReturn: "/sales-order-returns",
+	models.ScanDelivery:         "/deliveries",
+	models.ScanDeliveryReturn:   "/delivery-returns",
+	models.ScanMutation:         "/mutations",
+	models.ScanTransfer:         "/transfers",
+	models.ScanTransferReceive:  "/transfer-receives",
+	models.ScanStockOpname:      "/stock-opnames",
+}
+
+// ScanResponse : format json response for entity resolved from scanned code
+type ScanResponse struct {
+	Type    string                  `json:"type"`
+	ID      uint64                  `json:"id,omitempty"`
+	Code    string                  `json:"code"`
+	Href    string                  `json:"href"`
+	Status  string                  `json:"status,omitempty"`
+	Qty     *int                    `json:"qty,omitempty"`
+	Date    *time.Time              `json:"date,omitempty"`
+	Product *ProductSummaryResponse `json:"product,omitempty"`
+	Branch  *BranchResponse         `json:"branch,omitempty"`
+	Shelve  *ShelveResponse         `json:"shelve,omitempty"`
+	Links   []ScanLinkResponse      `json:"links"`
+}
+
+// Transform from Scan model to Scan response
+func (u *ScanResponse) Transform(scan *models.Scan) {
+	u.Type = scan.Type
+	u.ID = scan.ID
+	u.Code = scan.Code
+	u.Status = scan.Status
+
+	switch scan.Type {
+	case models.ScanUnit:
+		u.Href = scanPaths[scan.Type] + "/" + scan.Code + "/history"
+	default:
+		u.Href = fmt.Sprintf("%s/%d", scanPaths[scan.Type], scan.ID)
+	}
+
+	if scan.Type == models.ScanUnit || scan.Type == models.ScanProduct {
+		qty := scan.Qty
+		u.Qty = &qty
+		u.Product = new(ProductSummaryResponse)
+		u.Product.Transform(&scan.Product)
+	}
+
+	if !scan.Date.IsZero() {
+		date := scan.Date
+		u.Date = &date
+	}
+
+	if scan.Branch.ID > 0 {
+		u.Branch = new(BranchResponse)
+		u.Branch.Transform(&scan.Branch)
+	}
+
+	if scan.Shelve.ID > 0 {
+		u.Shelve = new(ShelveResponse)
+		u.Shelve.Transform(&scan.Shelve)
+	}
+
+	u.Links = []ScanLinkResponse{}
+	for _, l := range scan.Links {
+		u.Links = append(u.Links, ScanLinkResponse{
+			Type: l.Type,
+			ID:   l.ID,
+			Code: l.Code,
+			Href: fmt.Sprintf("%s/%d", scanPaths[l.Type], l.ID),
+		})
+	}
+}
+
+// ScanLinkResponse : format json response for document related to scanned code
+type ScanLinkResponse struct {
+	Type string `json:"type"`
+	ID   uint64 `json:"id"`
+	Code string `json:"code"`
+	Href string `json:"href"`
+}
@@ -0,0 +1,148 @@
+package response
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// StockOpnameResponse : format json response for StockOpname
+type StockOpnameResponse struct {
+	ID                 uint64                      `json:"id"`
+	Code               string                      `json:"code"`
+	Date               time.Time                   `json:"date"`
+	Remark             string                      `json:"remark"`
+	Status             string                      `json:"status"`
+	Approved           *time.Time                  `json:"approved"`
+	Company            CompanyResponse             `json:"company"`
+	Branch             BranchResponse              `json:"branch"`
+	Shelves            []ShelveResponse            `json:"shelves"`
+	StockOpnameDetails []StockOpnameDetailResponse `json:"stock_opname_details"`
+	Approvals          []ApprovalResponse          `json:"approvals"`
+}
+
+// Transform from StockOpname model to StockOpname response
+func (u *StockOpnameResponse) Transform(stockOpname *models.StockOpname) {
+	u.ID = stockOpname.ID
+	u.Code = stockOpname.Code
+	u.Date = stockOpname.Date
+	u.Remark = stockOpname.Remark
+	u.Status = stockOpname.Status
+	if stockOpname.Approved.Valid {
+		u.Approved = &stockOpname.Approved.Time
+	}
+	u.Company.Transform(&stockOpname.Company)
+	u.Branch.Transform(&stockOpname.Branch)
+	u.Approvals = transformApprovals(stockOpname.Approvals)
+
+	u.Shelves = []ShelveResponse{}
+	for _, s := range stockOpname.Shelves {
+		var p ShelveResponse
+		p.Transform(&s)
+		u.Shelves = append(u.Shelves, p)
+	}
+
+	u.StockOpnameDetails = []StockOpnameDetailResponse{}
+	for _, d := range stockOpname.StockOpnameDetails {
+		var p StockOpnameDetailResponse
+		p.Transform(&d)
+		u.StockOpnameDetails = append(u.StockOpnameDetails, p)
+	}
+}
+
+// StockOpnameListResponse : format json response for StockOpname list
+type StockOpnameListResponse struct {
+	ID       uint64          `json:"id"`
+	Code     string          `json:"code"`
+	Date     time.Time       `json:"date"`
+	Remark   string          `json:"remark"`
+	Status   string          `json:"status"`
+	Approved *time.Time      `json:"approved"`
+	Company  CompanyResponse `json:"company"`
+	Branch   BranchResponse  `json:"branch"`
+}
+
+// Transform from StockOpname model to StockOpname List response
+func (u *StockOpnameListResponse) Transform(stockOpname *models.StockOpname) {
+	u.ID = stockOpname.ID
+	u.Code = stockOpname.Code
+	u.Date = stockOpname.Date
+	u.Remark = stockOpname.Remark
+	u.Status = stockOpname.Status
+	if stockOpname.Approved.Valid {
+		u.Approved = &stockOpname.Approved.Time
+	}
+	u.Company.Transform(&stockOpname.Company)
+	u.Branch.Transform(&stockOpname.Branch)
+}
+
+// StockOpnameDetailResponse : format json response for StockOpname detail
+type StockOpnameDetailResponse struct {
+	ID          uint64          `json:"id"`
+	Product     ProductResponse `json:"product"`
+	Code        string          `json:"code"`
+	Shelve      ShelveResponse  `json:"shelve"`
+	ExpectedQty uint            `json:"expected_qty"`
+	CountedQty  uint            `json:"counted_qty"`
+	Variance    int             `json:"variance"`
+}
+
+// Transform from StockOpnameDetail model to StockOpnameDetail response
+func (u *StockOpnameDetailResponse) Transform(pd *models.StockOpnameDetail) {
+	u.ID = pd.ID
+	u.Product.Transform(&pd.Product)
+	u.Code = pd.Code
+	u.Shelve.Transform(&pd.Shelve)
+	u.ExpectedQty = pd.ExpectedQty
+	u.CountedQty = pd.CountedQty
+	u.Variance = pd.Variance()
+}
+
+// StockOpnameReportResponse : format json response for report of StockOpname
+type StockOpnameReportResponse struct {
+	StockOpname StockOpnameListResponse      `json:"stock_opname"`
+	Products    []StockOpnameSummaryResponse `json:"products"`
+	Missing     []StockOpnameDetailResponse  `json:"missing"`
+	Found       []StockOpnameDetailResponse  `json:"found"`
+}
+
+// Transform from StockOpname model to StockOpname report response
+func (u *StockOpnameReportResponse) Transform(stockOpname *models.StockOpname) {
+	u.StockOpname.Transform(stockOpname)
+
+	u.Products = []StockOpnameSummaryResponse{}
+	for _, s := range stockOpname.Summary() {
+		var p StockOpnameSummaryResponse
+		p.Transform(&s)
+		u.Products = append(u.Products, p)
+	}
+
+	u.Missing = []StockOpnameDetailResponse{}
+	u.Found = []StockOpnameDetailResponse{}
+	for _, d := range stockOpname.StockOpnameDetails {
+		var p StockOpnameDetailResponse
+		p.Transform(&d)
+		switch {
+		case p.Variance < 0:
+			u.Missing = append(u.Missing, p)
+		case p.Variance > 0:
+			u.Found = append(u.Found, p)
+		}
+	}
+}
+
+// StockOpnameSummaryResponse : format json response for variance of StockOpname per product
+type StockOpnameSummaryResponse struct {
+	Product     ProductResponse `json:"product"`
+	ExpectedQty uint            `json:"expected_qty"`
+	CountedQty  uint            `json:"counted_qty"`
+	Variance    int             `json:"variance"`
+}
+
+// Transform from StockOpnameSummary model to StockOpnameSummary response
+func (u *StockOpnameSummaryResponse) Transform(s *models.StockOpnameSummary) {
+	u.Product.Transform(&s.Product)
+	u.ExpectedQty = s.ExpectedQty
+	u.CountedQty = s.CountedQty
+	u.Variance = s.Variance()
+}
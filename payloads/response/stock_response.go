@@ -0,0 +1,313 @@
+package response
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// StockResponse : format json response for stock on hand and available to promise
+type StockResponse struct {
+	Product  ProductResponse `json:"product"`
+	Qty      int             `json:"qty"`
+	Reserved int             `json:"reserved"`
+	Incoming int             `json:"incoming"`
+	ATP      int             `json:"atp"`
+}
+
+// Transform from Stock model to Stock response
+func (u *StockResponse) Transform(stock *models.Stock) {
+	u.Product.Transform(&stock.Product)
+	u.Qty = stock.Qty
+	u.Reserved = stock.Reserved
+	u.Incoming = stock.Incoming
+	u.ATP = stock.ATP()
+}
+
+// BranchStockResponse : format json response for stock on hand and available to promise in a branch
+type BranchStockResponse struct {
+	Branch   BranchResponse  `json:"branch"`
+	Product  ProductResponse `json:"product"`
+	Qty      int             `json:"qty"`
+	Reserved int             `json:"reserved"`
+	Incoming int             `json:"incoming"`
+	ATP      int             `json:"atp"`
+}
+
+// Transform from Stock model to BranchStock response
+func (u *BranchStockResponse) Transform(stock *models.Stock) {
+	u.Branch.Transform(&stock.Branch)
+	u.Product.Transform(&stock.Product)
+	u.Qty = stock.Qty
+	u.Reserved = stock.Reserved
+	u.Incoming = stock.Incoming
+	u.ATP = stock.ATP()
+}
+
+// TemplateStockResponse : format json response for stock on hand rolled up to product template
+type TemplateStockResponse struct {
+	Template *ProductTemplateSummaryResponse `json:"template,omitempty"`
+	Product  *ProductResponse                `json:"product,omitempty"`
+	Qty      int                             `json:"qty"`
+	Reserved int                             `json:"reserved"`
+	Incoming int                             `json:"incoming"`
+	ATP      int                             `json:"atp"`
+	Variants []StockResponse                 `json:"variants,omitempty"`
+}
+
+// Transform from TemplateStock model to TemplateStock response
+func (u *TemplateStockResponse) Transform(stock *models.TemplateStock) {
+	u.Qty = stock.Qty
+	u.Reserved = stock.Reserved
+	u.Incoming = stock.Incoming
+	u.ATP = stock.ATP()
+	if stock.Template.ID == 0 {
+		u.Product = new(ProductResponse)
+		u.Product.Transform(&stock.Variants[0].Product)
+		return
+	}
+
+	u.Template = new(ProductTemplateSummaryResponse)
+	u.Template.Transform(&stock.Template)
+	for _, s := range stock.Variants {
+		var stockResponse StockResponse
+		stockResponse.Transform(&s)
+		u.Variants = append(u.Variants, stockResponse)
+	}
+}
+
+// BranchTemplateStockResponse : format json response for stock on hand in a branch rolled up to product template
+type BranchTemplateStockResponse struct {
+	Branch   BranchResponse                  `json:"branch"`
+	Template *ProductTemplateSummaryResponse `json:"template,omitempty"`
+	Product  *ProductResponse                `json:"product,omitempty"`
+	Qty      int                             `json:"qty"`
+	Reserved int                             `json:"reserved"`
+	Incoming int                             `json:"incoming"`
+	ATP      int                             `json:"atp"`
+	Variants []StockResponse                 `json:"variants,omitempty"`
+}
+
+// Transform from TemplateStock model to BranchTemplateStock response
+func (u *BranchTemplateStockResponse) Transform(stock *models.TemplateStock) {
+	var templateResponse TemplateStockResponse
+	templateResponse.Transform(stock)
+	u.Branch.Transform(&stock.Branch)
+	u.Template = templateResponse.Template
+	u.Product = templateResponse.Product
+	u.Qty = templateResponse.Qty
+	u.Reserved = templateResponse.Reserved
+	u.Incoming = templateResponse.Incoming
+	u.ATP = templateResponse.ATP
+	u.Variants = templateResponse.Variants
+}
+
+// StockUnitResponse : format json response for unit code on hand
+type StockUnitResponse struct {
+	Code        string         `json:"code"`
+	Qty         int            `json:"qty"`
+	Shelve      ShelveResponse `json:"shelve"`
+	ExpiredDate *time.Time     `json:"expired_date,omitempty"`
+}
+
+// Transform from StockUnit model to StockUnit response
+func (u *StockUnitResponse) Transform(unit *models.StockUnit) {
+	u.Code = unit.Code
+	u.Qty = unit.Qty
+	u.Shelve.Transform(&unit.Shelve)
+	if unit.ExpiredDate.Valid {
+		expiredDate := unit.ExpiredDate.Time
+		u.ExpiredDate = &expiredDate
+	}
+}
+
+// StockUnitsResponse : format json response for unit codes on hand in a branch
+type StockUnitsResponse struct {
+	Branch  BranchResponse      `json:"branch"`
+	Product ProductResponse     `json:"product"`
+	Qty     int                 `json:"qty"`
+	Units   []StockUnitResponse `json:"units"`
+}
+
+// Transform from Stock model to StockUnits response
+func (u *StockUnitsResponse) Transform(stock *models.Stock) {
+	u.Branch.Transform(&stock.Branch)
+	u.Product.Transform(&stock.Product)
+	u.Qty = stock.Qty
+	u.Units = []StockUnitResponse{}
+	for _, unit := range stock.Units {
+		var unitResponse StockUnitResponse
+		unitResponse.Transform(&unit)
+		u.Units = append(u.Units, unitResponse)
+	}
+}
+
+// ExpiringStockResponse : format json response for expiring unit codes in a shelve of branch
+type ExpiringStockResponse struct {
+	Branch BranchResponse         `json:"branch"`
+	Shelve ShelveResponse         `json:"shelve"`
+	Units  []ExpiringUnitResponse `json:"units"`
+}
+
+// Transform from ExpiringStock model to ExpiringStock response
+func (u *ExpiringStockResponse) Transform(stock *models.ExpiringStock) {
+	u.Branch.Transform(&stock.Branch)
+	u.Shelve.Transform(&stock.Shelve)
+	for _, unit := range stock.Units {
+		var unitResponse ExpiringUnitResponse
+		unitResponse.Transform(&unit)
+		u.Units = append(u.Units, unitResponse)
+	}
+}
+
+// ExpiringUnitResponse : format json response for expiring unit code
+type ExpiringUnitResponse struct {
+	Product     ProductResponse `json:"product"`
+	Code        string          `json:"code"`
+	ExpiredDate time.Time       `json:"expired_date"`
+	Expired     bool            `json:"expired"`
+}
+
+// Transform from ExpiringUnit model to ExpiringUnit response
+func (u *ExpiringUnitResponse) Transform(unit *models.ExpiringUnit) {
+	u.Product.Transform(&unit.Product)
+	u.Code = unit.Code
+	u.ExpiredDate = unit.ExpiredDate
+	u.Expired = unit.ExpiredDate.Before(time.Now().Truncate(24 * time.Hour))
+}
+
+// ShelveUtilizationResponse : format json response for shelves usage in a branch
+type ShelveUtilizationResponse struct {
+	Branch   BranchResponse        `json:"branch"`
+	Capacity uint                  `json:"capacity"`
+	Used     uint                  `json:"used"`
+	Percent  float64               `json:"percent"`
+	Shelves  []ShelveUsageResponse `json:"shelves"`
+}
+
+// Transform from ShelveUtilization model to ShelveUtilization response
+func (u *ShelveUtilizationResponse) Transform(utilization *models.ShelveUtilization) {
+	u.Branch.Transform(&utilization.Branch)
+	for _, usage := range utilization.Shelves {
+		var usageResponse ShelveUsageResponse
+		usageResponse.Transform(&usage)
+		u.Shelves = append(u.Shelves, usageResponse)
+		u.Capacity += usage.Shelve.Capacity
+		u.Used += usage.Used
+	}
+
+	if u.Capacity > 0 {
+		u.Percent = float64(u.Used) * 100 / float64(u.Capacity)
+	}
+}
+
+// ShelveUsageResponse : format json response for quantity on hand compared to capacity of shelve
+type ShelveUsageResponse struct {
+	Shelve  ShelveResponse `json:"shelve"`
+	Used    uint           `json:"used"`
+	Percent float64        `json:"percent"`
+}
+
+// Transform from ShelveUsage model to ShelveUsage response
+func (u *ShelveUsageResponse) Transform(usage *models.ShelveUsage) {
+	u.Shelve.Transform(&usage.Shelve)
+	u.Used = usage.Used
+	if usage.Shelve.Capacity > 0 {
+		u.Percent = float64(usage.Used) * 100 / float64(usage.Shelve.Capacity)
+	}
+}
+
+// StockValuationResponse : format json response for stock value per product in a branch
+type StockValuationResponse struct {
+	Branch   BranchResponse             `json:"branch"`
+	Method   string                     `json:"valuation_method"`
+	Qty      int                        `json:"qty"`
+	Value    float64                    `json:"value"`
+	Products []ProductValuationResponse `json:"products"`
+}
+
+// Transform from StockValuation model to StockValuation response
+func (u *StockValuationResponse) Transform(valuation *models.StockValuation) {
+	u.Branch.Transform(&valuation.Branch)
+	u.Method = valuation.Method
+	for _, p := range valuation.Products {
+		var productResponse ProductValuationResponse
+		productResponse.Transform(&p)
+		u.Products = append(u.Products, productResponse)
+		u.Qty += p.Qty
+		u.Value += p.Value
+	}
+}
+
+// TemplateStockValuationResponse : format json response for stock value per product template in a branch
+type TemplateStockValuationResponse struct {
+	Branch    BranchResponse              `json:"branch"`
+	Method    string                      `json:"valuation_method"`
+	Qty       int                         `json:"qty"`
+	Value     float64                     `json:"value"`
+	Templates []TemplateValuationResponse `json:"templates"`
+}
+
+// Transform from StockValuation model to TemplateStockValuation response
+func (u *TemplateStockValuationResponse) Transform(valuation *models.StockValuation) {
+	u.Branch.Transform(&valuation.Branch)
+	u.Method = valuation.Method
+	for _, t := range valuation.RollupTemplates() {
+		var templateResponse TemplateValuationResponse
+		templateResponse.Transform(&t)
+		u.Templates = append(u.Templates, templateResponse)
+		u.Qty += t.Qty
+		u.Value += t.Value
+	}
+}
+
+// TemplateValuationResponse : format json response for quantity on hand and its value rolled up to product template
+type TemplateValuationResponse struct {
+	Template *ProductTemplateSummaryResponse `json:"template,omitempty"`
+	Product  *ProductSummaryResponse         `json:"product,omitempty"`
+	Qty      int                             `json:"qty"`
+	UnitCost float64                         `json:"unit_cost"`
+	Value    float64                         `json:"value"`
+	Variants []ProductValuationResponse      `json:"variants,omitempty"`
+}
+
+// Transform from TemplateValuation model to TemplateValuation response
+func (u *TemplateValuationResponse) Transform(valuation *models.TemplateValuation) {
+	u.Qty = valuation.Qty
+	u.Value = valuation.Value
+	if valuation.Qty > 0 {
+		u.UnitCost = valuation.Value / float64(valuation.Qty)
+	}
+
+	if valuation.Template.ID == 0 {
+		u.Product = new(ProductSummaryResponse)
+		u.Product.Transform(&valuation.Variants[0].Product)
+		return
+	}
+
+	u.Template = new(ProductTemplateSummaryResponse)
+	u.Template.Transform(&valuation.Template)
+	for _, p := range valuation.Variants {
+		var productResponse ProductValuationResponse
+		productResponse.Transform(&p)
+		u.Variants = append(u.Variants, productResponse)
+	}
+}
+
+// ProductValuationResponse : format json response for quantity on hand and its value
+type ProductValuationResponse struct {
+	Product  ProductSummaryResponse `json:"product"`
+	Qty      int                    `json:"qty"`
+	UnitCost float64                `json:"unit_cost"`
+	Value    float64                `json:"value"`
+}
+
+// Transform from ProductValuation model to ProductValuation response
+func (u *ProductValuationResponse) Transform(valuation *models.ProductValuation) {
+	u.Product.Transform(&valuation.Product)
+	u.Qty = valuation.Qty
+	u.Value = valuation.Value
+	if valuation.Qty > 0 {
+		u.UnitCost = valuation.Value / float64(valuation.Qty)
+	}
+}
@@ -0,0 +1,76 @@
+package response
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// TransferReceiveResponse : format json response for TransferReceive
+type TransferReceiveResponse struct {
+	ID                     uint64                          `json:"id"`
+	Code                   string                          `json:"code"`
+	Date                   time.Time                       `json:"date"`
+	Remark                 string                          `json:"remark"`
+	Transfer               TransferListResponse            `json:"transfer"`
+	Company                CompanyResponse                 `json:"company"`
+	Branch                 BranchResponse                  `json:"branch"`
+	TransferReceiveDetails []TransferReceiveDetailResponse `json:"transfer_receive_details"`
+}
+
+// Transform from TransferReceive model to TransferReceive response
+func (u *TransferReceiveResponse) Transform(transferReceive *models.TransferReceive) {
+	u.ID = transferReceive.ID
+	u.Code = transferReceive.Code
+	u.Date = transferReceive.Date
+	u.Remark = transferReceive.Remark
+	u.Transfer.Transform(&transferReceive.Transfer)
+	u.Company.Transform(&transferReceive.Company)
+	u.Branch.Transform(&transferReceive.Branch)
+
+	for _, d := range transferReceive.TransferReceiveDetails {
+		var p TransferReceiveDetailResponse
+		p.Transform(&d)
+		u.TransferReceiveDetails = append(u.TransferReceiveDetails, p)
+	}
+}
+
+// TransferReceiveListResponse : format json response for TransferReceive list
+type TransferReceiveListResponse struct {
+	ID       uint64               `json:"id"`
+	Code     string               `json:"code"`
+	Date     time.Time            `json:"date"`
+	Remark   string               `json:"remark"`
+	Transfer TransferListResponse `json:"transfer"`
+	Company  CompanyResponse      `json:"company"`
+	Branch   BranchResponse       `json:"branch"`
+}
+
+// Transform from TransferReceive model to TransferReceive List response
+func (u *TransferReceiveListResponse) Transform(transferReceive *models.TransferReceive) {
+	u.ID = transferReceive.ID
+	u.Code = transferReceive.Code
+	u.Date = transferReceive.Date
+	u.Remark = transferReceive.Remark
+	u.Transfer.Transform(&transferReceive.Transfer)
+	u.Company.Transform(&transferReceive.Company)
+	u.Branch.Transform(&transferReceive.Branch)
+}
+
+// TransferReceiveDetailResponse : format json response for TransferReceive detail
+type TransferReceiveDetailResponse struct {
+	ID      uint64          `json:"id"`
+	Qty     uint            `json:"qty"`
+	Product ProductResponse `json:"product"`
+	Code    string          `json:"code"`
+	Shelve  ShelveResponse  `json:"shelve"`
+}
+
+// Transform from TransferReceiveDetail model to TransferReceiveDetail response
+func (u *TransferReceiveDetailResponse) Transform(pd *models.TransferReceiveDetail) {
+	u.ID = pd.ID
+	u.Qty = pd.Qty
+	u.Product.Transform(&pd.Product)
+	u.Code = pd.Code
+	u.Shelve.Transform(&pd.Shelve)
+}
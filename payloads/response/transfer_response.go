@@ -0,0 +1,98 @@
+package response
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// TransferResponse : format json response for Transfer
+type TransferResponse struct {
+	ID                uint64                   `json:"id"`
+	Code              string                   `json:"code"`
+	Date              time.Time                `json:"date"`
+	Remark            string                   `json:"remark"`
+	Company           CompanyResponse          `json:"company"`
+	Branch            BranchResponse           `json:"branch"`
+	DestinationBranch BranchResponse           `json:"destination_branch"`
+	TransferDetails   []TransferDetailResponse `json:"transfer_details"`
+}
+
+// Transform from Transfer model to Transfer response
+func (u *TransferResponse) Transform(transfer *models.Transfer) {
+	u.ID = transfer.ID
+	u.Code = transfer.Code
+	u.Date = transfer.Date
+	u.Remark = transfer.Remark
+	u.Company.Transform(&transfer.Company)
+	u.Branch.Transform(&transfer.Branch)
+	u.DestinationBranch.Transform(&transfer.DestinationBranch)
+
+	for _, d := range transfer.TransferDetails {
+		var p TransferDetailResponse
+		p.Transform(&d)
+		u.TransferDetails = append(u.TransferDetails, p)
+	}
+}
+
+// TransferListResponse : format json response for Transfer list
+type TransferListResponse struct {
+	ID                uint64          `json:"id"`
+	Code              string          `json:"code"`
+	Date              time.Time       `json:"date"`
+	Remark            string          `json:"remark"`
+	Company           CompanyResponse `json:"company"`
+	Branch            BranchResponse  `json:"branch"`
+	DestinationBranch BranchResponse  `json:"destination_branch"`
+}
+
+// Transform from Transfer model to Transfer List response
+func (u *TransferListResponse) Transform(transfer *models.Transfer) {
+	u.ID = transfer.ID
+	u.Code = transfer.Code
+	u.Date = transfer.Date
+	u.Remark = transfer.Remark
+	u.Company.Transform(&transfer.Company)
+	u.Branch.Transform(&transfer.Branch)
+	u.DestinationBranch.Transform(&transfer.DestinationBranch)
+}
+
+// TransferDetailResponse : format json response for Transfer detail
+type TransferDetailResponse struct {
+	ID      uint64          `json:"id"`
+	Qty     uint            `json:"qty"`
+	Product ProductResponse `json:"product"`
+	Code    string          `json:"code"`
+	Shelve  ShelveResponse  `json:"shelve"`
+}
+
+// Transform from TransferDetail model to TransferDetail response
+func (u *TransferDetailResponse) Transform(pd *models.TransferDetail) {
+	u.ID = pd.ID
+	u.Qty = pd.Qty
+	u.Product.Transform(&pd.Product)
+	u.Code = pd.Code
+	u.Shelve.Transform(&pd.Shelve)
+}
+
+// TransferDiscrepancyResponse : format json response for units of Transfer which have not been received
+type TransferDiscrepancyResponse struct {
+	Transfer  TransferListResponse     `json:"transfer"`
+	Sent      int                      `json:"sent"`
+	Received  int                      `json:"received"`
+	InTransit []TransferDetailResponse `json:"in_transit"`
+}
+
+// Transform from Transfer model and in transit units to TransferDiscrepancy response
+func (u *TransferDiscrepancyResponse) Transform(transfer *models.Transfer, inTransit []models.TransferDetail) {
+	u.Transfer.Transform(transfer)
+	u.Sent = len(transfer.TransferDetails)
+	u.Received = u.Sent - len(inTransit)
+	u.InTransit = []TransferDetailResponse{}
+
+	for _, d := range inTransit {
+		var p TransferDetailResponse
+		p.Transform(&d)
+		u.InTransit = append(u.InTransit, p)
+	}
+}
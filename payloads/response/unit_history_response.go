@@ -0,0 +1,85 @@
+package response
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// UnitHistoryResponse : format json response for movement of unit code
+type UnitHistoryResponse struct {
+	Type            string                         `json:"type"`
+	InOut           bool                           `json:"in_out"`
+	Reversal        bool                           `json:"reversal,omitempty"`
+	Qty             uint                           `json:"qty"`
+	TransactionID   uint64                         `json:"transaction_id,omitempty"`
+	TransactionCode string                         `json:"transaction_code,omitempty"`
+	TransactionDate time.Time                      `json:"transaction_date"`
+	Code            string                         `json:"code"`
+	Product         ProductSummaryResponse         `json:"product"`
+	Branch          BranchResponse                 `json:"branch"`
+	Shelve          *ShelveResponse                `json:"shelve,omitempty"`
+	Purchase        *UnitHistoryPurchaseResponse   `json:"purchase,omitempty"`
+	SalesOrder      *UnitHistorySalesOrderResponse `json:"sales_order,omitempty"`
+	CreatedBy       *UnitHistoryUserResponse       `json:"created_by,omitempty"`
+	Created         *time.Time                     `json:"created,omitempty"`
+}
+
+// Transform from UnitHistory model to UnitHistory response
+func (u *UnitHistoryResponse) Transform(h *models.UnitHistory) {
+	u.Type = h.Type
+	u.InOut = h.InOut
+	u.Reversal = h.Reversal
+	u.Qty = h.Qty
+	u.TransactionID = h.TransactionID
+	u.TransactionCode = h.TransactionCode
+	u.TransactionDate = h.TransactionDate
+	u.Code = h.ProductCode
+	u.Product.Transform(&h.Product)
+	u.Branch.Transform(&h.Branch)
+
+	if h.Shelve.ID > 0 {
+		u.Shelve = new(ShelveResponse)
+		u.Shelve.Transform(&h.Shelve)
+	}
+
+	if h.Purchase.ID > 0 {
+		u.Purchase = &UnitHistoryPurchaseResponse{ID: h.Purchase.ID, Code: h.Purchase.Code, Date: h.Purchase.Date}
+		u.Purchase.Supplier.Transform(&h.Purchase.Supplier)
+	}
+
+	if h.SalesOrder.ID > 0 {
+		u.SalesOrder = &UnitHistorySalesOrderResponse{ID: h.SalesOrder.ID, Code: h.SalesOrder.Code, Date: h.SalesOrder.Date}
+		u.SalesOrder.Customer.Transform(&h.SalesOrder.Customer)
+	}
+
+	if h.CreatedBy.ID > 0 {
+		u.CreatedBy = &UnitHistoryUserResponse{ID: h.CreatedBy.ID, Username: h.CreatedBy.Username}
+	}
+
+	if !h.Created.IsZero() {
+		u.Created = &h.Created
+	}
+}
+
+// UnitHistoryPurchaseResponse : format json response for purchase linked to unit history
+type UnitHistoryPurchaseResponse struct {
+	ID       uint64           `json:"id"`
+	Code     string           `json:"code"`
+	Date     time.Time        `json:"date"`
+	Supplier SupplierResponse `json:"supplier"`
+}
+
+// UnitHistorySalesOrderResponse : format json response for sales order linked to unit history
+type UnitHistorySalesOrderResponse struct {
+	ID       uint64           `json:"id"`
+	Code     string           `json:"code"`
+	Date     time.Time        `json:"date"`
+	Customer CustomerResponse `json:"customer"`
+}
+
+// UnitHistoryUserResponse : format json response for user who created the movement
+type UnitHistoryUserResponse struct {
+	ID       uint64 `json:"id"`
+	Username string `json:"username"`
+}
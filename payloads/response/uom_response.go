@@ -0,0 +1,45 @@
+package response
+
+import "github.com/jacky-htg/inventory/models"
+
+// UomResponse json
+type UomResponse struct {
+	ID      uint64          `json:"id"`
+	Company CompanyResponse `json:"company"`
+	Code    string          `json:"code"`
+	Name    string          `json:"name"`
+}
+
+// Transform Uom models to Uom response
+func (u *UomResponse) Transform(c *models.Uom) {
+	u.ID = c.ID
+	u.Code = c.Code
+	u.Name = c.Name
+	u.Company.Transform(&c.Company)
+}
+
+// UomSummaryResponse : format json response for unit of measure referenced by product and transaction detail
+type UomSummaryResponse struct {
+	ID   uint64 `json:"id"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// Transform from Uom model to UomSummary response
+func (u *UomSummaryResponse) Transform(c *models.Uom) {
+	u.ID = c.ID
+	u.Code = c.Code
+	u.Name = c.Name
+}
+
+// ProductUomResponse : format json response for unit of measure conversion of product
+type ProductUomResponse struct {
+	Uom        UomSummaryResponse `json:"uom"`
+	Conversion uint               `json:"conversion"`
+}
+
+// Transform from ProductUom model to ProductUom response
+func (u *ProductUomResponse) Transform(c *models.ProductUom) {
+	u.Uom.Transform(&c.Uom)
+	u.Conversion = c.Conversion
+}
@@ -0,0 +1,38 @@
+package response
+
+import (
+	"time"
+
+	"github.com/jacky-htg/inventory/models"
+)
+
+// VoidResponse : format json response for voiding of posted document
+type VoidResponse struct {
+	Date     time.Time `json:"date"`
+	Reason   string    `json:"reason"`
+	VoidedBy string    `json:"voided_by"`
+}
+
+// CorrectionResponse : format json response for voided document which is corrected by a document
+type CorrectionResponse struct {
+	ID   uint64 `json:"id"`
+	Code string `json:"code"`
+}
+
+// transformVoid return void response of document, nil when document has not been voided
+func transformVoid(void *models.Void) *VoidResponse {
+	if !void.Date.Valid {
+		return nil
+	}
+
+	return &VoidResponse{Date: void.Date.Time, Reason: void.Reason, VoidedBy: void.User.Username}
+}
+
+// transformCorrection return response of voided document corrected by document, nil when document is not a correction
+func transformCorrection(correction *models.Correction) *CorrectionResponse {
+	if correction.ID == 0 {
+		return nil
+	}
+
+	return &CorrectionResponse{ID: correction.ID, Code: correction.Code}
+}
@@ -0,0 +1,347 @@
+package routing
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jacky-htg/inventory/middleware"
+	"github.com/jacky-htg/inventory/models"
+	"github.com/jacky-htg/inventory/payloads/response"
+)
+
+// auditGetters return getter of entity before audited request by entity of audit log, the entity is rendered as by its view route.
+// Entity of nested route is the resource of its last parameter, such as branches of /regions/:id/branches/:branch_id.
+func auditGetters(db *sql.DB) map[string]middleware.AuditGetter {
+	return map[string]middleware.AuditGetter{
+		"access": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			access := models.Access{ID: uint32(id)}
+			err := access.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.AccessResponse
+			res.Transform(&access)
+			return res, nil
+		},
+		"approval-rules": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			approvalRule := models.ApprovalRule{ID: uint32(id)}
+			err := approvalRule.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.ApprovalRuleResponse
+			res.Transform(&approvalRule)
+			return res, nil
+		},
+		"approvals": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			approval := models.Approval{ID: id}
+			err := approval.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.ApprovalResponse
+			res.Transform(&approval)
+			return res, nil
+		},
+		"branches": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			branch := models.Branch{ID: uint32(id)}
+			err := branch.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.BranchResponse
+			res.Transform(&branch)
+			return res, nil
+		},
+		"brands": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			brand := models.Brand{ID: id}
+			err := brand.View(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.BrandResponse
+			res.Transform(&brand)
+			return res, nil
+		},
+		"companies": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			company := models.Company{ID: uint32(id)}
+			err := company.Get(ctx, db)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.CompanyResponse
+			res.Transform(&company)
+			return res, nil
+		},
+		"customers": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			customer := models.Customer{ID: id}
+			err := customer.View(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.CustomerResponse
+			res.Transform(&customer)
+			return res, nil
+		},
+		"deliveries": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			delivery := models.Delivery{ID: id}
+			err := delivery.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.DeliveryResponse
+			res.Transform(&delivery)
+			return res, nil
+		},
+		"delivery-returns": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			deliveryReturn := models.DeliveryReturn{ID: id}
+			err := deliveryReturn.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.DeliveryReturnResponse
+			res.Transform(&deliveryReturn)
+			return res, nil
+		},
+		"mutations": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			mutation := models.Mutation{ID: id}
+			err := mutation.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.MutationResponse
+			res.Transform(&mutation)
+			return res, nil
+		},
+		"product-categories": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			productCategory := models.ProductCategory{ID: id}
+			err := productCategory.View(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.ProductCategoryResponse
+			res.Transform(&productCategory)
+			return res, nil
+		},
+		"product-templates": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			template := models.ProductTemplate{ID: id}
+			err := template.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.ProductTemplateResponse
+			res.Transform(&template)
+			return res, nil
+		},
+		"products": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			product := models.Product{ID: id}
+			err := product.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.ProductResponse
+			res.Transform(&product)
+			return res, nil
+		},
+		"purchase-approvals": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			purchase := models.Purchase{ID: id}
+			err := purchase.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.PurchaseResponse
+			res.Transform(&purchase)
+			return res, nil
+		},
+		"purchase-returns": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			purchaseReturn := models.PurchaseReturn{ID: id}
+			err := purchaseReturn.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.PurchaseReturnResponse
+			res.Transform(&purchaseReturn)
+			return res, nil
+		},
+		"purchases": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			purchase := models.Purchase{ID: id}
+			err := purchase.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.PurchaseResponse
+			res.Transform(&purchase)
+			return res, nil
+		},
+		"receive-returns": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			receiveReturn := models.ReceiveReturn{ID: id}
+			err := receiveReturn.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.ReceiveReturnResponse
+			res.Transform(&receiveReturn)
+			return res, nil
+		},
+		"receives": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			receive := models.Receive{ID: id}
+			err := receive.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.ReceiveResponse
+			res.Transform(&receive)
+			return res, nil
+		},
+		"regions": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			region := models.Region{ID: uint32(id)}
+			err := region.Get(ctx, db)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.RegionResponse
+			res.Transform(&region)
+			return res, nil
+		},
+		"roles": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			role := models.Role{ID: uint32(id)}
+			err := role.Get(ctx, db)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.RoleResponse
+			res.Transform(&role)
+			return res, nil
+		},
+		"sales-order-returns": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			salesOrderReturn := models.SalesOrderReturn{ID: id}
+			err := salesOrderReturn.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.SalesOrderReturnResponse
+			res.Transform(&salesOrderReturn)
+			return res, nil
+		},
+		"sales-orders": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			salesOrder := models.SalesOrder{ID: id}
+			err := salesOrder.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.SalesOrderResponse
+			res.Transform(&salesOrder)
+			return res, nil
+		},
+		"salesmen": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			salesman := models.Salesman{ID: id}
+			err := salesman.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.SalesmanResponse
+			res.Transform(&salesman)
+			return res, nil
+		},
+		"shelves": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			shelve := models.Shelve{ID: id}
+			err := shelve.View(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.ShelveResponse
+			res.Transform(&shelve)
+			return res, nil
+		},
+		"stock-opnames": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			stockOpname := models.StockOpname{ID: id}
+			err := stockOpname.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.StockOpnameResponse
+			res.Transform(&stockOpname)
+			return res, nil
+		},
+		"suppliers": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			supplier := models.Supplier{ID: id}
+			err := supplier.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.SupplierResponse
+			res.Transform(&supplier)
+			return res, nil
+		},
+		"transfer-receives": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			transferReceive := models.TransferReceive{ID: id}
+			err := transferReceive.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.TransferReceiveResponse
+			res.Transform(&transferReceive)
+			return res, nil
+		},
+		"transfers": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			transfer := models.Transfer{ID: id}
+			err := transfer.Get(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.TransferResponse
+			res.Transform(&transfer)
+			return res, nil
+		},
+		"uoms": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			uom := models.Uom{ID: id}
+			err := uom.View(ctx, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.UomResponse
+			res.Transform(&uom)
+			return res, nil
+		},
+		"users": func(ctx context.Context, tx *sql.Tx, id uint64) (interface{}, error) {
+			user := models.User{ID: id}
+			err := user.Get(ctx, db)
+			if err != nil {
+				return nil, err
+			}
+
+			var res response.UserResponse
+			res.Transform(&user)
+			return res, nil
+		},
+	}
+}
@@ -16,6 +16,7 @@ func API(db *sql.DB, log *log.Logger) http.Handler {
 		log,
 		middleware.Auths(db, log, []string{"/login", "/health"}),
 	)
+	app.Use(middleware.Audits(db, log, auditGetters(db)))
 
 	// Health Routing
 	{
@@ -86,6 +87,34 @@ func API(db *sql.DB, log *log.Logger) http.Handler {
 		app.Handle(http.MethodPost, "/products", products.Create)
 		app.Handle(http.MethodPut, "/products/:id", products.Update)
 		app.Handle(http.MethodDelete, "/products/:id", products.Delete)
+		app.Handle(http.MethodGet, "/products/:id/availability", products.Availability)
+	}
+
+	// Product Uoms Routing
+	{
+		productUoms := controllers.ProductUoms{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/products/:id/uoms", productUoms.List)
+		app.Handle(http.MethodPost, "/products/:id/uoms", productUoms.Save)
+		app.Handle(http.MethodDelete, "/products/:id/uoms/:uom_id", productUoms.Delete)
+	}
+
+	// Labels Routing
+	{
+		labels := controllers.Labels{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/products/:id/barcode", labels.Product)
+		app.Handle(http.MethodGet, "/units/:code/barcode", labels.Unit)
+		app.Handle(http.MethodGet, "/receives/:id/labels", labels.Receive)
+	}
+
+	// Product Templates Routing
+	{
+		productTemplates := controllers.ProductTemplates{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/product-templates", productTemplates.List)
+		app.Handle(http.MethodGet, "/product-templates/:id", productTemplates.View)
+		app.Handle(http.MethodPost, "/product-templates", productTemplates.Create)
+		app.Handle(http.MethodPut, "/product-templates/:id", productTemplates.Update)
+		app.Handle(http.MethodDelete, "/product-templates/:id", productTemplates.Delete)
+		app.Handle(http.MethodPost, "/product-templates/:id/variants", productTemplates.GenerateVariants)
 	}
 
 	// Purchases Routing
@@ -95,6 +124,16 @@ func API(db *sql.DB, log *log.Logger) http.Handler {
 		app.Handle(http.MethodGet, "/purchases/:id", purchases.View)
 		app.Handle(http.MethodPost, "/purchases", purchases.Create)
 		app.Handle(http.MethodPut, "/purchases/:id", purchases.Update)
+		app.Handle(http.MethodPost, "/purchases/:id/submit", purchases.Submit)
+		app.Handle(http.MethodPost, "/purchases/:id/close", purchases.Close)
+		app.Handle(http.MethodPost, "/purchases/:id/cancel", purchases.Cancel)
+	}
+
+	// Purchase Approvals Routing
+	{
+		purchaseApprovals := controllers.PurchaseApprovals{Db: db, Log: log}
+		app.Handle(http.MethodPost, "/purchase-approvals/:id/approve", purchaseApprovals.Approve)
+		app.Handle(http.MethodPost, "/purchase-approvals/:id/reject", purchaseApprovals.Reject)
 	}
 
 	// Purchase Returns Routing
@@ -109,7 +148,10 @@ func API(db *sql.DB, log *log.Logger) http.Handler {
 	// Closing Stock Routing
 	{
 		closingStock := controllers.ClosingStocks{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/closing_stocks", closingStock.List)
 		app.Handle(http.MethodPost, "/closing_stocks", closingStock.Closing)
+		app.Handle(http.MethodPost, "/closing_stocks/opening-balances", closingStock.OpeningBalances)
+		app.Handle(http.MethodPost, "/closing_stocks/reopen", closingStock.Reopen)
 	}
 
 	// Customers Routing
@@ -162,6 +204,16 @@ func API(db *sql.DB, log *log.Logger) http.Handler {
 		app.Handle(http.MethodDelete, "/brands/:id", brands.Delete)
 	}
 
+	// Uoms Routing
+	{
+		uoms := controllers.Uoms{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/uoms", uoms.List)
+		app.Handle(http.MethodPost, "/uoms", uoms.Create)
+		app.Handle(http.MethodGet, "/uoms/:id", uoms.View)
+		app.Handle(http.MethodPut, "/uoms/:id", uoms.Update)
+		app.Handle(http.MethodDelete, "/uoms/:id", uoms.Delete)
+	}
+
 	// ProductCategories Routing
 	{
 		productCategories := controllers.ProductCategories{Db: db, Log: log}
@@ -179,6 +231,7 @@ func API(db *sql.DB, log *log.Logger) http.Handler {
 		app.Handle(http.MethodGet, "/receives/:id", receives.View)
 		app.Handle(http.MethodPost, "/receives", receives.Create)
 		app.Handle(http.MethodPut, "/receives/:id", receives.Update)
+		app.Handle(http.MethodPost, "/receives/:id/void", receives.Void)
 	}
 
 	// Shelves Routing
@@ -198,6 +251,8 @@ func API(db *sql.DB, log *log.Logger) http.Handler {
 		app.Handle(http.MethodGet, "/receive-returns/:id", receiveReturns.View)
 		app.Handle(http.MethodPost, "/receive-returns", receiveReturns.Create)
 		app.Handle(http.MethodPut, "/receive-returns/:id", receiveReturns.Update)
+		app.Handle(http.MethodPost, "/receive-returns/:id/post", receiveReturns.Post)
+		app.Handle(http.MethodPost, "/receive-returns/:id/void", receiveReturns.Void)
 	}
 
 	// SalesOrder Routing
@@ -207,6 +262,7 @@ func API(db *sql.DB, log *log.Logger) http.Handler {
 		app.Handle(http.MethodGet, "/sales-orders/:id", salesOrders.View)
 		app.Handle(http.MethodPost, "/sales-orders", salesOrders.Create)
 		app.Handle(http.MethodPut, "/sales-orders/:id", salesOrders.Update)
+		app.Handle(http.MethodPost, "/sales-orders/:id/cancel", salesOrders.Cancel)
 	}
 
 	// SalesOrderReturn Routing
@@ -225,6 +281,7 @@ func API(db *sql.DB, log *log.Logger) http.Handler {
 		app.Handle(http.MethodGet, "/deliveries/:id", deliveries.View)
 		app.Handle(http.MethodPost, "/deliveries", deliveries.Create)
 		app.Handle(http.MethodPut, "/deliveries/:id", deliveries.Update)
+		app.Handle(http.MethodPost, "/deliveries/:id/void", deliveries.Void)
 	}
 
 	// Deliveries Return Routing
@@ -234,6 +291,118 @@ func API(db *sql.DB, log *log.Logger) http.Handler {
 		app.Handle(http.MethodGet, "/delivery-returns/:id", deliveryReturns.View)
 		app.Handle(http.MethodPost, "/delivery-returns", deliveryReturns.Create)
 		app.Handle(http.MethodPut, "/delivery-returns/:id", deliveryReturns.Update)
+		app.Handle(http.MethodPost, "/delivery-returns/:id/post", deliveryReturns.Post)
+		app.Handle(http.MethodPost, "/delivery-returns/:id/void", deliveryReturns.Void)
+	}
+
+	// Stocks Routing
+	{
+		stocks := controllers.Stocks{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/stocks", stocks.List)
+		app.Handle(http.MethodGet, "/stocks/:product_id", stocks.View)
+		app.Handle(http.MethodGet, "/branches/:id/stocks", stocks.ListByBranch)
+		app.Handle(http.MethodGet, "/branches/:id/stocks/:product_id/units", stocks.Units)
+	}
+
+	// Mutations Routing
+	{
+		mutations := controllers.Mutations{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/mutations", mutations.List)
+		app.Handle(http.MethodGet, "/mutations/:id", mutations.View)
+		app.Handle(http.MethodPost, "/mutations", mutations.Create)
+		app.Handle(http.MethodPut, "/mutations/:id", mutations.Update)
+	}
+
+	// Transfers Routing
+	{
+		transfers := controllers.Transfers{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/transfers", transfers.List)
+		app.Handle(http.MethodGet, "/transfers/:id", transfers.View)
+		app.Handle(http.MethodGet, "/transfers/:id/discrepancies", transfers.Discrepancies)
+		app.Handle(http.MethodPost, "/transfers", transfers.Create)
+		app.Handle(http.MethodPut, "/transfers/:id", transfers.Update)
+	}
+
+	// Transfer Receives Routing
+	{
+		transferReceives := controllers.TransferReceives{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/transfer-receives", transferReceives.List)
+		app.Handle(http.MethodGet, "/transfer-receives/:id", transferReceives.View)
+		app.Handle(http.MethodPost, "/transfer-receives", transferReceives.Create)
+		app.Handle(http.MethodPut, "/transfer-receives/:id", transferReceives.Update)
+	}
+
+	// Stock Opnames Routing
+	{
+		stockOpnames := controllers.StockOpnames{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/stock-opnames", stockOpnames.List)
+		app.Handle(http.MethodGet, "/stock-opnames/:id", stockOpnames.View)
+		app.Handle(http.MethodGet, "/stock-opnames/:id/report", stockOpnames.Report)
+		app.Handle(http.MethodPost, "/stock-opnames", stockOpnames.Create)
+		app.Handle(http.MethodPut, "/stock-opnames/:id", stockOpnames.Update)
+		app.Handle(http.MethodPut, "/stock-opnames/:id/counts", stockOpnames.Count)
+		app.Handle(http.MethodPost, "/stock-opnames/:id/uploads", stockOpnames.Upload)
+		app.Handle(http.MethodPost, "/stock-opnames/:id/approve", stockOpnames.Approve)
+	}
+
+	// Purchase Suggestions Routing
+	{
+		purchaseSuggestions := controllers.PurchaseSuggestions{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/purchase-suggestions", purchaseSuggestions.List)
+		app.Handle(http.MethodPost, "/purchase-suggestions", purchaseSuggestions.Create)
+	}
+
+	// Units Routing
+	{
+		units := controllers.Units{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/units/:code/history", units.History)
+	}
+
+	// Lots Routing
+	{
+		lots := controllers.Lots{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/lots/:lot/trace", lots.Trace)
+	}
+
+	// Scan Routing
+	{
+		scans := controllers.Scans{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/scan/:code", scans.Lookup)
+	}
+
+	// Approval Rules Routing
+	{
+		approvalRules := controllers.ApprovalRules{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/approval-rules", approvalRules.List)
+		app.Handle(http.MethodGet, "/approval-rules/:id", approvalRules.View)
+		app.Handle(http.MethodPost, "/approval-rules", approvalRules.Create)
+		app.Handle(http.MethodPut, "/approval-rules/:id", approvalRules.Update)
+		app.Handle(http.MethodDelete, "/approval-rules/:id", approvalRules.Delete)
+	}
+
+	// Approvals Routing
+	{
+		approvals := controllers.Approvals{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/approvals", approvals.List)
+		app.Handle(http.MethodGet, "/approvals/:id", approvals.View)
+		app.Handle(http.MethodPost, "/approvals/:id/approve", approvals.Approve)
+		app.Handle(http.MethodPost, "/approvals/:id/reject", approvals.Reject)
+	}
+
+	// Audit Logs Routing
+	{
+		auditLogs := controllers.AuditLogs{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/audit-logs", auditLogs.List)
+	}
+
+	// Reports Routing
+	{
+		reports := controllers.Reports{Db: db, Log: log}
+		app.Handle(http.MethodGet, "/reports/expiring", reports.Expiring)
+		app.Handle(http.MethodGet, "/reports/shelves", reports.Shelves)
+		app.Handle(http.MethodGet, "/reports/stock-valuation", reports.StockValuation)
+		app.Handle(http.MethodGet, "/reports/gross-margin", reports.GrossMargin)
+		app.Handle(http.MethodGet, "/reports/backorders", reports.Backorders)
 	}
 
 	return app
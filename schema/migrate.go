@@ -1104,6 +1104,1046 @@ CREATE TABLE delivery_return_details (
 	UNIQUE KEY delivery_return_details_code (code, product_id),
 	CONSTRAINT fk_delivery_return_details_to_delivery_returns FOREIGN KEY (delivery_return_id) REFERENCES delivery_returns(id) ON DELETE CASCADE ON UPDATE CASCADE,
 	CONSTRAINT fk_delivery_return_details_to_products FOREIGN KEY (product_id) REFERENCES products(id)
+);`,
+	},
+	{
+		Version:     45,
+		Description: "Drop Branch Stock details Procedure",
+		Script:      `DROP PROCEDURE IF EXISTS branch_stock_details;`,
+	},
+	{
+		Version:     46,
+		Description: "Add Branch Stock details Procedure with product filter and shelve",
+		Script: `
+CREATE PROCEDURE branch_stock_details(companyID int, branchID int, productID int)
+BEGIN
+
+declare curYear int;
+declare curMonth int;
+
+SET curYear = year(now());
+SET curMonth = month(now());
+
+select stocks.product_id, stocks.code, 
+	ifnull((SELECT last_inventories.shelve_id 
+		FROM inventories last_inventories 
+		WHERE last_inventories.company_id = companyID AND last_inventories.product_id = stocks.product_id AND last_inventories.product_code = stocks.code 
+		ORDER BY last_inventories.id DESC LIMIT 1), 0) shelve_id
+from ( 
+	SELECT 
+		ifnull(inventories.company_id, group_inventories.company_id) company_id,
+		ifnull(inventories.product_id, group_inventories.product_id) product_id,
+		ifnull(inventories.branch_id, group_inventories.branch_id) branch_id,
+		ifnull(inventories.product_code, group_inventories.product_code) code
+	FROM (
+		SELECT 
+			MAX(union_inventories.id) id, 
+			MAX(union_inventories.company_id) company_id, 
+			MAX(union_inventories.branch_id) branch_id, 
+			MAX(union_inventories.product_id) product_id, 
+			MAX(union_inventories.product_code) product_code, 
+			SUM(union_inventories.qty) qty
+		FROM (
+			(SELECT 
+				0 id,
+				saldo_stocks.company_id, 
+				saldo_stock_details.branch_id,
+				saldo_stocks.product_id, 
+				saldo_stock_details.code product_code,
+				1 qty  
+			FROM saldo_stocks
+			JOIN saldo_stock_details ON saldo_stocks.id = saldo_stock_details.saldo_stock_id
+			WHERE saldo_stocks.year = curYear AND saldo_stocks.month = curMonth and saldo_stocks.company_id = companyID)
+			union all
+			(SELECT 
+				inventories.id,
+				inventories.company_id,
+				inventories.branch_id,
+				inventories.product_id,
+				inventories.product_code,
+				if(inventories.in_out, qty, -qty) as qty
+			FROM inventories
+			where month(inventories.transaction_date)=curMonth and year(inventories.transaction_date)=curYear and inventories.company_id = companyID)
+		) union_inventories
+		GROUP BY union_inventories.company_id, union_inventories.product_id, union_inventories.product_code
+	) group_inventories
+	left join inventories ON group_inventories.id = inventories.id and inventories.company_id = companyID
+		WHERE group_inventories.qty > 0
+) stocks
+where stocks.company_id = companyID and stocks.branch_id = branchID and (productID = 0 or stocks.product_id = productID);
+
+END;`,
+	},
+	{
+		Version:     47,
+		Description: "Add Mutations",
+		Script: `
+CREATE TABLE mutations (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	company_id	INT(10) UNSIGNED NOT NULL,
+	branch_id INT(10) UNSIGNED NOT NULL,
+	date DATE NOT NULL,
+	code CHAR(13) NOT NULL,
+	remark VARCHAR(255) NOT NULL,
+	created TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated TIMESTAMP NOT NULL DEFAULT NOW(),
+	created_by BIGINT(20) UNSIGNED NOT NULL,
+	updated_by BIGINT(20) UNSIGNED NOT NULL, 
+	PRIMARY KEY (id),
+	KEY mutations_company_id (company_id),
+	KEY mutations_branch_id (branch_id),
+	KEY mutations_created_by (created_by),
+	KEY mutations_updated_by (updated_by),
+	UNIQUE KEY mutations_code (code, company_id),
+	CONSTRAINT fk_mutations_to_companies FOREIGN KEY (company_id) REFERENCES companies(id),
+	CONSTRAINT fk_mutations_to_branches FOREIGN KEY (branch_id) REFERENCES branches(id),
+	CONSTRAINT fk_mutations_to_users_created_by FOREIGN KEY (created_by) REFERENCES users(id),
+	CONSTRAINT fk_mutations_to_users_updated_by FOREIGN KEY (updated_by) REFERENCES users(id)
+);`,
+	},
+	{
+		Version:     48,
+		Description: "Add Mutation Details",
+		Script: `
+CREATE TABLE mutation_details (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	mutation_id	BIGINT(20) UNSIGNED NOT NULL,
+	product_id BIGINT(20) UNSIGNED NOT NULL,
+	code CHAR(20) NOT NULL,
+	qty MEDIUMINT(8) UNSIGNED NOT NULL,
+	shelve_from_id BIGINT(20) UNSIGNED NOT NULL,
+	shelve_to_id BIGINT(20) UNSIGNED NOT NULL,
+	PRIMARY KEY (id),
+	KEY mutation_details_mutation_id (mutation_id),
+	KEY mutation_details_product_id (product_id),
+	KEY mutation_details_shelve_from_id (shelve_from_id),
+	KEY mutation_details_shelve_to_id (shelve_to_id),
+	CONSTRAINT fk_mutation_details_to_mutations FOREIGN KEY (mutation_id) REFERENCES mutations(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_mutation_details_to_products FOREIGN KEY (product_id) REFERENCES products(id),
+	CONSTRAINT fk_mutation_details_to_shelves_from FOREIGN KEY (shelve_from_id) REFERENCES shelves(id),
+	CONSTRAINT fk_mutation_details_to_shelves_to FOREIGN KEY (shelve_to_id) REFERENCES shelves(id)
+);`,
+	},
+	{
+		Version:     49,
+		Description: "Add Transfers",
+		Script: `
+CREATE TABLE transfers (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	company_id	INT(10) UNSIGNED NOT NULL,
+	branch_id INT(10) UNSIGNED NOT NULL,
+	destination_branch_id INT(10) UNSIGNED NOT NULL,
+	date DATE NOT NULL,
+	code CHAR(13) NOT NULL,
+	remark VARCHAR(255) NOT NULL,
+	created TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated TIMESTAMP NOT NULL DEFAULT NOW(),
+	created_by BIGINT(20) UNSIGNED NOT NULL,
+	updated_by BIGINT(20) UNSIGNED NOT NULL, 
+	PRIMARY KEY (id),
+	KEY transfers_company_id (company_id),
+	KEY transfers_branch_id (branch_id),
+	KEY transfers_destination_branch_id (destination_branch_id),
+	KEY transfers_created_by (created_by),
+	KEY transfers_updated_by (updated_by),
+	UNIQUE KEY transfers_code (code, company_id),
+	CONSTRAINT fk_transfers_to_companies FOREIGN KEY (company_id) REFERENCES companies(id),
+	CONSTRAINT fk_transfers_to_branches FOREIGN KEY (branch_id) REFERENCES branches(id),
+	CONSTRAINT fk_transfers_to_destination_branches FOREIGN KEY (destination_branch_id) REFERENCES branches(id),
+	CONSTRAINT fk_transfers_to_users_created_by FOREIGN KEY (created_by) REFERENCES users(id),
+	CONSTRAINT fk_transfers_to_users_updated_by FOREIGN KEY (updated_by) REFERENCES users(id)
+);`,
+	},
+	{
+		Version:     50,
+		Description: "Add Transfer Details",
+		Script: `
+CREATE TABLE transfer_details (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	transfer_id	BIGINT(20) UNSIGNED NOT NULL,
+	product_id BIGINT(20) UNSIGNED NOT NULL,
+	code CHAR(20) NOT NULL,
+	qty MEDIUMINT(8) UNSIGNED NOT NULL,
+	shelve_id BIGINT(20) UNSIGNED NOT NULL,
+	PRIMARY KEY (id),
+	KEY transfer_details_transfer_id (transfer_id),
+	KEY transfer_details_product_id (product_id),
+	KEY transfer_details_shelve_id (shelve_id),
+	CONSTRAINT fk_transfer_details_to_transfers FOREIGN KEY (transfer_id) REFERENCES transfers(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_transfer_details_to_products FOREIGN KEY (product_id) REFERENCES products(id),
+	CONSTRAINT fk_transfer_details_to_shelves FOREIGN KEY (shelve_id) REFERENCES shelves(id)
+);`,
+	},
+	{
+		Version:     51,
+		Description: "Add Transfer Receives",
+		Script: `
+CREATE TABLE transfer_receives (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	company_id	INT(10) UNSIGNED NOT NULL,
+	branch_id INT(10) UNSIGNED NOT NULL,
+	transfer_id BIGINT(20) UNSIGNED NOT NULL,
+	date DATE NOT NULL,
+	code CHAR(13) NOT NULL,
+	remark VARCHAR(255) NOT NULL,
+	created TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated TIMESTAMP NOT NULL DEFAULT NOW(),
+	created_by BIGINT(20) UNSIGNED NOT NULL,
+	updated_by BIGINT(20) UNSIGNED NOT NULL, 
+	PRIMARY KEY (id),
+	KEY transfer_receives_company_id (company_id),
+	KEY transfer_receives_branch_id (branch_id),
+	KEY transfer_receives_transfer_id (transfer_id),
+	KEY transfer_receives_created_by (created_by),
+	KEY transfer_receives_updated_by (updated_by),
+	UNIQUE KEY transfer_receives_code (code, company_id),
+	CONSTRAINT fk_transfer_receives_to_companies FOREIGN KEY (company_id) REFERENCES companies(id),
+	CONSTRAINT fk_transfer_receives_to_branches FOREIGN KEY (branch_id) REFERENCES branches(id),
+	CONSTRAINT fk_transfer_receives_to_transfers FOREIGN KEY (transfer_id) REFERENCES transfers(id),
+	CONSTRAINT fk_transfer_receives_to_users_created_by FOREIGN KEY (created_by) REFERENCES users(id),
+	CONSTRAINT fk_transfer_receives_to_users_updated_by FOREIGN KEY (updated_by) REFERENCES users(id)
+);`,
+	},
+	{
+		Version:     52,
+		Description: "Add Transfer Receive Details",
+		Script: `
+CREATE TABLE transfer_receive_details (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	transfer_receive_id	BIGINT(20) UNSIGNED NOT NULL,
+	product_id BIGINT(20) UNSIGNED NOT NULL,
+	code CHAR(20) NOT NULL,
+	qty MEDIUMINT(8) UNSIGNED NOT NULL,
+	shelve_id BIGINT(20) UNSIGNED NOT NULL,
+	PRIMARY KEY (id),
+	KEY transfer_receive_details_transfer_receive_id (transfer_receive_id),
+	KEY transfer_receive_details_product_id (product_id),
+	KEY transfer_receive_details_shelve_id (shelve_id),
+	CONSTRAINT fk_transfer_receive_details_to_transfer_receives FOREIGN KEY (transfer_receive_id) REFERENCES transfer_receives(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_transfer_receive_details_to_products FOREIGN KEY (product_id) REFERENCES products(id),
+	CONSTRAINT fk_transfer_receive_details_to_shelves FOREIGN KEY (shelve_id) REFERENCES shelves(id)
+);`,
+	},
+	{
+		Version:     53,
+		Description: "Add Stock Opnames",
+		Script: `
+CREATE TABLE stock_opnames (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	company_id	INT(10) UNSIGNED NOT NULL,
+	branch_id INT(10) UNSIGNED NOT NULL,
+	date DATE NOT NULL,
+	code CHAR(13) NOT NULL,
+	remark VARCHAR(255) NOT NULL,
+	status CHAR(1) NOT NULL DEFAULT 'O',
+	approved_by BIGINT(20) UNSIGNED NULL,
+	approved TIMESTAMP NULL,
+	created TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated TIMESTAMP NOT NULL DEFAULT NOW(),
+	created_by BIGINT(20) UNSIGNED NOT NULL,
+	updated_by BIGINT(20) UNSIGNED NOT NULL, 
+	PRIMARY KEY (id),
+	KEY stock_opnames_company_id (company_id),
+	KEY stock_opnames_branch_id (branch_id),
+	KEY stock_opnames_created_by (created_by),
+	KEY stock_opnames_updated_by (updated_by),
+	UNIQUE KEY stock_opnames_code (code, company_id),
+	CONSTRAINT fk_stock_opnames_to_companies FOREIGN KEY (company_id) REFERENCES companies(id),
+	CONSTRAINT fk_stock_opnames_to_branches FOREIGN KEY (branch_id) REFERENCES branches(id),
+	CONSTRAINT fk_stock_opnames_to_users_approved_by FOREIGN KEY (approved_by) REFERENCES users(id),
+	CONSTRAINT fk_stock_opnames_to_users_created_by FOREIGN KEY (created_by) REFERENCES users(id),
+	CONSTRAINT fk_stock_opnames_to_users_updated_by FOREIGN KEY (updated_by) REFERENCES users(id)
+);`,
+	},
+	{
+		Version:     54,
+		Description: "Add Stock Opname Shelves",
+		Script: `
+CREATE TABLE stock_opname_shelves (
+	stock_opname_id	BIGINT(20) UNSIGNED NOT NULL,
+	shelve_id BIGINT(20) UNSIGNED NOT NULL,
+	PRIMARY KEY (stock_opname_id, shelve_id),
+	KEY stock_opname_shelves_shelve_id (shelve_id),
+	CONSTRAINT fk_stock_opname_shelves_to_stock_opnames FOREIGN KEY (stock_opname_id) REFERENCES stock_opnames(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_stock_opname_shelves_to_shelves FOREIGN KEY (shelve_id) REFERENCES shelves(id)
+);`,
+	},
+	{
+		Version:     55,
+		Description: "Add Stock Opname Details",
+		Script: `
+CREATE TABLE stock_opname_details (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	stock_opname_id	BIGINT(20) UNSIGNED NOT NULL,
+	product_id BIGINT(20) UNSIGNED NOT NULL,
+	code CHAR(20) NOT NULL,
+	shelve_id BIGINT(20) UNSIGNED NOT NULL,
+	expected_qty MEDIUMINT(8) UNSIGNED NOT NULL,
+	counted_qty MEDIUMINT(8) UNSIGNED NOT NULL DEFAULT 0,
+	PRIMARY KEY (id),
+	KEY stock_opname_details_stock_opname_id (stock_opname_id),
+	KEY stock_opname_details_product_id (product_id),
+	KEY stock_opname_details_shelve_id (shelve_id),
+	UNIQUE KEY stock_opname_details_code (stock_opname_id, code, product_id),
+	CONSTRAINT fk_stock_opname_details_to_stock_opnames FOREIGN KEY (stock_opname_id) REFERENCES stock_opnames(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_stock_opname_details_to_products FOREIGN KEY (product_id) REFERENCES products(id),
+	CONSTRAINT fk_stock_opname_details_to_shelves FOREIGN KEY (shelve_id) REFERENCES shelves(id)
+);`,
+	},
+	{
+		Version:     56,
+		Description: "Add Closed Periods",
+		Script: `
+CREATE TABLE closed_periods (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	company_id	INT(10) UNSIGNED NOT NULL,
+	year YEAR(4) NOT NULL,
+	month TINYINT(2) NOT NULL,
+	status CHAR(1) NOT NULL DEFAULT 'C',
+	closed TIMESTAMP NOT NULL DEFAULT NOW(),
+	closed_by BIGINT(20) UNSIGNED NOT NULL,
+	reopened TIMESTAMP NULL,
+	reopened_by BIGINT(20) UNSIGNED NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY closed_periods_period (company_id, year, month),
+	KEY closed_periods_closed_by (closed_by),
+	KEY closed_periods_reopened_by (reopened_by),
+	CONSTRAINT fk_closed_periods_to_companies FOREIGN KEY (company_id) REFERENCES companies(id),
+	CONSTRAINT fk_closed_periods_to_users_closed_by FOREIGN KEY (closed_by) REFERENCES users(id),
+	CONSTRAINT fk_closed_periods_to_users_reopened_by FOREIGN KEY (reopened_by) REFERENCES users(id)
+);`,
+	},
+	{
+		Version:     57,
+		Description: "Add Valuation Method to Companies",
+		Script: `
+ALTER TABLE companies ADD COLUMN valuation_method CHAR(4) NOT NULL DEFAULT 'FIFO' AFTER address;`,
+	},
+	{
+		Version:     58,
+		Description: "Add Cost to Inventories",
+		Script: `
+ALTER TABLE inventories ADD COLUMN cost DOUBLE NOT NULL DEFAULT 0 AFTER qty;`,
+	},
+	{
+		Version:     59,
+		Description: "Add Cost to Delivery Details",
+		Script: `
+ALTER TABLE delivery_details ADD COLUMN cost DOUBLE NOT NULL DEFAULT 0;`,
+	},
+	{
+		Version:     60,
+		Description: "Add Serialized to Products",
+		Script: `
+ALTER TABLE products ADD COLUMN serialized TINYINT(1) UNSIGNED NOT NULL DEFAULT 1 AFTER minimum_stock;`,
+	},
+	{
+		Version:     61,
+		Description: "Add Qty to Saldo Stock Details",
+		Script: `
+ALTER TABLE saldo_stock_details ADD COLUMN qty MEDIUMINT(8) UNSIGNED NOT NULL DEFAULT 1;`,
+	},
+	{
+		Version:     62,
+		Description: "Allow Bulk Code in Delivery Details",
+		Script: `
+ALTER TABLE delivery_details DROP INDEX delivery_details_code, ADD KEY delivery_details_code (code, product_id);`,
+	},
+	{
+		Version:     63,
+		Description: "Allow Bulk Code in Receiving Return Details",
+		Script: `
+ALTER TABLE receiving_return_details DROP INDEX receiving_return_details_code, ADD KEY receiving_return_details_code (code, product_id);`,
+	},
+	{
+		Version:     64,
+		Description: "Allow Bulk Code in Delivery Return Details",
+		Script: `
+ALTER TABLE delivery_return_details DROP INDEX delivery_return_details_code, ADD KEY delivery_return_details_code (code, product_id);`,
+	},
+	{
+		Version:     65,
+		Description: "Drop Closing Stock Details Procedure",
+		Script:      `DROP PROCEDURE IF EXISTS closing_stock_details;`,
+	},
+	{
+		Version:     66,
+		Description: "Add Closing Stock Details Procedure with quantity",
+		Script: `
+CREATE PROCEDURE closing_stock_details(companyID int, curYear int, curMonth int)
+BEGIN
+
+	DECLARE nextYear int;
+	DECLARE nextMonth int;
+	
+	IF curYear = 0 THEN
+		SET curYear = year(now());
+	END IF;
+	
+	IF curMonth = 0 THEN 
+		SET curMonth = month(now());
+	END IF;
+	
+	SET nextYear = curYear;
+	SET nextMonth = curMonth + 1;
+	
+	IF curMonth = 12 THEN 
+		SET nextYear = curYear+1;
+		SET nextMonth = 1;
+	END IF;
+	
+	INSERT INTO saldo_stock_details (saldo_stock_id, branch_id, code, qty)
+	SELECT 
+		saldo_stocks.id saldo_stock_id,
+		ifnull(inventories.branch_id, group_inventories.branch_id) branch_id,
+		ifnull(inventories.product_code, group_inventories.product_code) code,
+		group_inventories.qty
+	FROM (
+		SELECT 
+			MAX(union_inventories.id) id, 
+			MAX(union_inventories.company_id) company_id, 
+			MAX(union_inventories.branch_id) branch_id, 
+			MAX(union_inventories.product_id) product_id, 
+			MAX(union_inventories.product_code) product_code, 
+			SUM(union_inventories.qty) qty
+		FROM (
+			(SELECT 
+				0 id,
+				saldo_stocks.company_id, 
+				saldo_stock_details.branch_id,
+				saldo_stocks.product_id, 
+				saldo_stock_details.code product_code,
+				saldo_stock_details.qty  
+			FROM saldo_stocks
+			JOIN saldo_stock_details ON saldo_stocks.id = saldo_stock_details.saldo_stock_id
+			WHERE saldo_stocks.year = curYear AND saldo_stocks.month = curMonth and saldo_stocks.company_id = companyID)
+			union all
+			(SELECT 
+				inventories.id,
+				inventories.company_id,
+				inventories.branch_id,
+				inventories.product_id,
+				inventories.product_code,
+				if(inventories.in_out, qty, -qty) as qty
+			FROM inventories
+			where month(inventories.transaction_date)=curMonth and year(inventories.transaction_date)=curYear and inventories.company_id = companyID)
+		) union_inventories
+		GROUP BY union_inventories.company_id, union_inventories.product_id, union_inventories.product_code
+	) group_inventories
+	left join inventories ON group_inventories.id = inventories.id and inventories.company_id = companyID
+	join saldo_stocks ON ifnull(inventories.company_id, group_inventories.company_id)=saldo_stocks.company_id and ifnull(inventories.product_id, group_inventories.product_id)=saldo_stocks.product_id and saldo_stocks.year = nextYear and saldo_stocks.month = nextMonth and saldo_stocks.company_id = companyID
+	WHERE group_inventories.qty > 0;
+
+END;`,
+	},
+	{
+		Version:     67,
+		Description: "Drop Stock Branch function",
+		Script:      `DROP FUNCTION IF EXISTS stock_branch;`,
+	},
+	{
+		Version:     68,
+		Description: "Add Stock Branch function with quantity",
+		Script: `
+CREATE FUNCTION stock_branch(companyID int, branchID int, productID int) RETURNS int(11)
+BEGIN
+
+declare stock int;
+declare curYear int;
+declare curMonth int;
+
+SET curYear = year(now());
+SET curMonth = month(now());
+
+select sum(stocks.qty) into stock
+from ( 
+	SELECT 
+		ifnull(inventories.company_id, group_inventories.company_id) company_id,
+		ifnull(inventories.product_id, group_inventories.product_id) product_id,
+		ifnull(inventories.branch_id, group_inventories.branch_id) branch_id,
+		ifnull(inventories.product_code, group_inventories.product_code) code,
+		group_inventories.qty
+	FROM (
+		SELECT 
+			MAX(union_inventories.id) id, 
+			MAX(union_inventories.company_id) company_id, 
+			MAX(union_inventories.branch_id) branch_id, 
+			MAX(union_inventories.product_id) product_id, 
+			MAX(union_inventories.product_code) product_code, 
+			SUM(union_inventories.qty) qty
+		FROM (
+			(SELECT 
+				0 id,
+				saldo_stocks.company_id, 
+				saldo_stock_details.branch_id,
+				saldo_stocks.product_id, 
+				saldo_stock_details.code product_code,
+				saldo_stock_details.qty  
+			FROM saldo_stocks
+			JOIN saldo_stock_details ON saldo_stocks.id = saldo_stock_details.saldo_stock_id
+			WHERE saldo_stocks.year = curYear AND saldo_stocks.month = curMonth and saldo_stocks.company_id = companyID)
+			union all
+			(SELECT 
+				inventories.id,
+				inventories.company_id,
+				inventories.branch_id,
+				inventories.product_id,
+				inventories.product_code,
+				if(inventories.in_out, qty, -qty) as qty
+			FROM inventories
+			where month(inventories.transaction_date)=curMonth and year(inventories.transaction_date)=curYear and inventories.company_id = companyID)
+		) union_inventories
+		GROUP BY union_inventories.company_id, union_inventories.product_id, union_inventories.product_code
+	) group_inventories
+	left join inventories ON group_inventories.id = inventories.id and inventories.company_id = companyID
+		WHERE group_inventories.qty > 0
+) stocks
+where stocks.company_id = companyID and stocks.branch_id = branchID and stocks.product_id = productID
+group by stocks.company_id, stocks.branch_id, stocks.product_id;
+
+RETURN stock;
+END;`,
+	},
+	{
+		Version:     69,
+		Description: "Drop Branch Stocks Procedure",
+		Script:      `DROP PROCEDURE IF EXISTS branch_stocks;`,
+	},
+	{
+		Version:     70,
+		Description: "Add Branch Stocks Procedure with quantity",
+		Script: `
+CREATE PROCEDURE branch_stocks(companyID int, branchID int)
+BEGIN
+
+declare curYear int;
+declare curMonth int;
+
+SET curYear = year(now());
+SET curMonth = month(now());
+
+select stocks.product_id, sum(stocks.qty) stock
+from ( 
+	SELECT 
+		ifnull(inventories.company_id, group_inventories.company_id) company_id,
+		ifnull(inventories.product_id, group_inventories.product_id) product_id,
+		ifnull(inventories.branch_id, group_inventories.branch_id) branch_id,
+		ifnull(inventories.product_code, group_inventories.product_code) code,
+		group_inventories.qty
+	FROM (
+		SELECT 
+			MAX(union_inventories.id) id, 
+			MAX(union_inventories.company_id) company_id, 
+			MAX(union_inventories.branch_id) branch_id, 
+			MAX(union_inventories.product_id) product_id, 
+			MAX(union_inventories.product_code) product_code, 
+			SUM(union_inventories.qty) qty
+		FROM (
+			(SELECT 
+				0 id,
+				saldo_stocks.company_id, 
+				saldo_stock_details.branch_id,
+				saldo_stocks.product_id, 
+				saldo_stock_details.code product_code,
+				saldo_stock_details.qty  
+			FROM saldo_stocks
+			JOIN saldo_stock_details ON saldo_stocks.id = saldo_stock_details.saldo_stock_id
+			WHERE saldo_stocks.year = curYear AND saldo_stocks.month = curMonth and saldo_stocks.company_id = companyID)
+			union all
+			(SELECT 
+				inventories.id,
+				inventories.company_id,
+				inventories.branch_id,
+				inventories.product_id,
+				inventories.product_code,
+				if(inventories.in_out, qty, -qty) as qty
+			FROM inventories
+			where month(inventories.transaction_date)=curMonth and year(inventories.transaction_date)=curYear and inventories.company_id = companyID)
+		) union_inventories
+		GROUP BY union_inventories.company_id, union_inventories.product_id, union_inventories.product_code
+	) group_inventories
+	left join inventories ON group_inventories.id = inventories.id and inventories.company_id = companyID
+		WHERE group_inventories.qty > 0
+) stocks
+where stocks.company_id = companyID and stocks.branch_id = branchID
+group by stocks.company_id, stocks.branch_id, stocks.product_id;
+
+END;`,
+	},
+	{
+		Version:     71,
+		Description: "Drop Branch Stock details Procedure with shelve",
+		Script:      `DROP PROCEDURE IF EXISTS branch_stock_details;`,
+	},
+	{
+		Version:     72,
+		Description: "Add Branch Stock details Procedure with quantity",
+		Script: `
+CREATE PROCEDURE branch_stock_details(companyID int, branchID int, productID int)
+BEGIN
+
+declare curYear int;
+declare curMonth int;
+
+SET curYear = year(now());
+SET curMonth = month(now());
+
+select stocks.product_id, stocks.code, stocks.qty,
+	ifnull((SELECT last_inventories.shelve_id 
+		FROM inventories last_inventories 
+		WHERE last_inventories.company_id = companyID AND last_inventories.product_id = stocks.product_id AND last_inventories.product_code = stocks.code 
+		ORDER BY last_inventories.id DESC LIMIT 1), 0) shelve_id
+from ( 
+	SELECT 
+		ifnull(inventories.company_id, group_inventories.company_id) company_id,
+		ifnull(inventories.product_id, group_inventories.product_id) product_id,
+		ifnull(inventories.branch_id, group_inventories.branch_id) branch_id,
+		ifnull(inventories.product_code, group_inventories.product_code) code,
+		group_inventories.qty
+	FROM (
+		SELECT 
+			MAX(union_inventories.id) id, 
+			MAX(union_inventories.company_id) company_id, 
+			MAX(union_inventories.branch_id) branch_id, 
+			MAX(union_inventories.product_id) product_id, 
+			MAX(union_inventories.product_code) product_code, 
+			SUM(union_inventories.qty) qty
+		FROM (
+			(SELECT 
+				0 id,
+				saldo_stocks.company_id, 
+				saldo_stock_details.branch_id,
+				saldo_stocks.product_id, 
+				saldo_stock_details.code product_code,
+				saldo_stock_details.qty  
+			FROM saldo_stocks
+			JOIN saldo_stock_details ON saldo_stocks.id = saldo_stock_details.saldo_stock_id
+			WHERE saldo_stocks.year = curYear AND saldo_stocks.month = curMonth and saldo_stocks.company_id = companyID)
+			union all
+			(SELECT 
+				inventories.id,
+				inventories.company_id,
+				inventories.branch_id,
+				inventories.product_id,
+				inventories.product_code,
+				if(inventories.in_out, qty, -qty) as qty
+			FROM inventories
+			where month(inventories.transaction_date)=curMonth and year(inventories.transaction_date)=curYear and inventories.company_id = companyID)
+		) union_inventories
+		GROUP BY union_inventories.company_id, union_inventories.product_id, union_inventories.product_code
+	) group_inventories
+	left join inventories ON group_inventories.id = inventories.id and inventories.company_id = companyID
+		WHERE group_inventories.qty > 0
+) stocks
+where stocks.company_id = companyID and stocks.branch_id = branchID and (productID = 0 or stocks.product_id = productID);
+
+END;`,
+	},
+	{
+		Version:     73,
+		Description: "Add Receipt Cost to Inventories",
+		Script: `
+ALTER TABLE inventories ADD COLUMN receipt_cost DOUBLE NULL AFTER cost;`,
+	},
+	{
+		Version:     74,
+		Description: "Fill Receipt Cost of Good Receiving Inventories",
+		Script: `
+UPDATE inventories
+JOIN good_receivings ON inventories.transaction_id = good_receivings.id
+SET inventories.receipt_cost = (
+	SELECT SUM(purchase_details.price - purchase_details.disc) / SUM(purchase_details.qty)
+	FROM purchase_details
+	WHERE purchase_details.purchase_id = good_receivings.purchase_id
+	AND purchase_details.product_id = inventories.product_id
+)
+WHERE inventories.type = 'GR';`,
+	},
+	{
+		Version:     75,
+		Description: "Add Lot to Good Receiving Details",
+		Script: `
+ALTER TABLE good_receiving_details ADD COLUMN lot VARCHAR(45) NOT NULL DEFAULT '' AFTER shelve_id, ADD COLUMN manufacture_date DATE NULL AFTER lot;`,
+	},
+	{
+		Version:     76,
+		Description: "Add Lot to Inventories",
+		Script: `
+ALTER TABLE inventories ADD COLUMN lot VARCHAR(45) NOT NULL DEFAULT '' AFTER product_code, ADD KEY inventories_lot (company_id, lot);`,
+	},
+	{
+		Version:     77,
+		Description: "Add Lot to Delivery Details",
+		Script: `
+ALTER TABLE delivery_details ADD COLUMN lot VARCHAR(45) NOT NULL DEFAULT '' AFTER code, ADD KEY delivery_details_lot (lot);`,
+	},
+	{
+		Version:     78,
+		Description: "Add Lot to Delivery Return Details",
+		Script: `
+ALTER TABLE delivery_return_details ADD COLUMN lot VARCHAR(45) NOT NULL DEFAULT '' AFTER code;`,
+	},
+	{
+		Version:     79,
+		Description: "Add Lot to Receiving Return Details",
+		Script: `
+ALTER TABLE receiving_return_details ADD COLUMN lot VARCHAR(45) NOT NULL DEFAULT '' AFTER code;`,
+	},
+	{
+		Version:     80,
+		Description: "Add Uoms",
+		Script: `
+CREATE TABLE uoms (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	company_id	INT(10) UNSIGNED NOT NULL,
+	code CHAR(10) NOT NULL,
+	name VARCHAR(45) NOT NULL,
+	created TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated TIMESTAMP NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (id),
+	UNIQUE KEY uoms_code (company_id, code),
+	KEY uoms_company_id (company_id),
+	CONSTRAINT fk_uoms_to_companies FOREIGN KEY (company_id) REFERENCES companies(id)
+);`,
+	},
+	{
+		Version:     81,
+		Description: "Add Base Uom to Products",
+		Script: `
+ALTER TABLE products ADD COLUMN uom_id BIGINT(20) UNSIGNED NULL AFTER product_category_id, ADD CONSTRAINT fk_products_to_uoms FOREIGN KEY (uom_id) REFERENCES uoms(id);`,
+	},
+	{
+		Version:     82,
+		Description: "Add Product Uoms",
+		Script: `
+CREATE TABLE product_uoms (
+	product_id BIGINT(20) UNSIGNED NOT NULL,
+	uom_id BIGINT(20) UNSIGNED NOT NULL,
+	conversion MEDIUMINT(8) UNSIGNED NOT NULL,
+	PRIMARY KEY (product_id, uom_id),
+	KEY product_uoms_uom_id (uom_id),
+	CONSTRAINT fk_product_uoms_to_products FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_product_uoms_to_uoms FOREIGN KEY (uom_id) REFERENCES uoms(id)
+);`,
+	},
+	{
+		Version:     83,
+		Description: "Add Uom to Purchase Details",
+		Script: `
+ALTER TABLE purchase_details ADD COLUMN uom_id BIGINT(20) UNSIGNED NULL AFTER qty, ADD COLUMN uom_qty MEDIUMINT(8) UNSIGNED NULL AFTER uom_id;`,
+	},
+	{
+		Version:     84,
+		Description: "Add Uom to Sales Order Details",
+		Script: `
+ALTER TABLE sales_order_details ADD COLUMN uom_id BIGINT(20) UNSIGNED NULL AFTER qty, ADD COLUMN uom_qty MEDIUMINT(8) UNSIGNED NULL AFTER uom_id;`,
+	},
+	{
+		Version:     85,
+		Description: "Add Uom to Good Receiving Details",
+		Script: `
+ALTER TABLE good_receiving_details ADD COLUMN uom_id BIGINT(20) UNSIGNED NULL AFTER qty, ADD COLUMN uom_qty MEDIUMINT(8) UNSIGNED NULL AFTER uom_id;`,
+	},
+	{
+		Version:     86,
+		Description: "Add Uom to Delivery Details",
+		Script: `
+ALTER TABLE delivery_details ADD COLUMN uom_id BIGINT(20) UNSIGNED NULL AFTER qty, ADD COLUMN uom_qty MEDIUMINT(8) UNSIGNED NULL AFTER uom_id;`,
+	},
+	{
+		Version:     87,
+		Description: "Add Product Templates",
+		Script: `
+CREATE TABLE product_templates (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	company_id	INT(10) UNSIGNED NOT NULL,
+	brand_id BIGINT(20) UNSIGNED NOT NULL,
+	product_category_id BIGINT(20) UNSIGNED NOT NULL,
+	code	VARCHAR(20) NOT NULL,
+	name	VARCHAR(255) NOT NULL,
+	sale_price	DOUBLE NOT NULL,
+	minimum_stock MEDIUMINT(8) UNSIGNED NOT NULL,
+	serialized TINYINT(1) UNSIGNED NOT NULL DEFAULT 1,
+	created TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated TIMESTAMP NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (id),
+	KEY product_templates_company_id (company_id),
+	KEY product_templates_brand_id (brand_id),
+	KEY product_templates_product_category_id (product_category_id),
+	UNIQUE KEY product_templates_code (company_id, code),
+	CONSTRAINT fk_product_templates_to_companies FOREIGN KEY (company_id) REFERENCES companies(id),
+	CONSTRAINT fk_product_templates_to_brands FOREIGN KEY (brand_id) REFERENCES brands(id),
+	CONSTRAINT fk_product_templates_to_product_categories FOREIGN KEY (product_category_id) REFERENCES product_categories(id)
+);`,
+	},
+	{
+		Version:     88,
+		Description: "Add Product Attributes",
+		Script: `
+CREATE TABLE product_attributes (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	product_template_id BIGINT(20) UNSIGNED NOT NULL,
+	name VARCHAR(45) NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY product_attributes_name (product_template_id, name),
+	CONSTRAINT fk_product_attributes_to_product_templates FOREIGN KEY (product_template_id) REFERENCES product_templates(id) ON DELETE CASCADE ON UPDATE CASCADE
+);`,
+	},
+	{
+		Version:     89,
+		Description: "Add Product Attribute Values",
+		Script: `
+CREATE TABLE product_attribute_values (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	product_attribute_id BIGINT(20) UNSIGNED NOT NULL,
+	value VARCHAR(45) NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY product_attribute_values_value (product_attribute_id, value),
+	CONSTRAINT fk_product_attribute_values_to_product_attributes FOREIGN KEY (product_attribute_id) REFERENCES product_attributes(id) ON DELETE CASCADE ON UPDATE CASCADE
+);`,
+	},
+	{
+		Version:     90,
+		Description: "Add Product Template to Products",
+		Script: `
+ALTER TABLE products MODIFY code VARCHAR(45) NOT NULL, ADD COLUMN product_template_id BIGINT(20) UNSIGNED NULL AFTER product_category_id, ADD CONSTRAINT fk_products_to_product_templates FOREIGN KEY (product_template_id) REFERENCES product_templates(id);`,
+	},
+	{
+		Version:     91,
+		Description: "Add Product Variant Values",
+		Script: `
+CREATE TABLE product_variant_values (
+	product_id BIGINT(20) UNSIGNED NOT NULL,
+	product_attribute_value_id BIGINT(20) UNSIGNED NOT NULL,
+	PRIMARY KEY (product_id, product_attribute_value_id),
+	KEY product_variant_values_product_attribute_value_id (product_attribute_value_id),
+	CONSTRAINT fk_product_variant_values_to_products FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_product_variant_values_to_product_attribute_values FOREIGN KEY (product_attribute_value_id) REFERENCES product_attribute_values(id)
+);`,
+	},
+	{
+		Version:     92,
+		Description: "Add ATP Policy to Companies",
+		Script: `
+ALTER TABLE companies ADD COLUMN atp_policy CHAR(9) NOT NULL DEFAULT 'BACKORDER' AFTER valuation_method;`,
+	},
+	{
+		Version:     93,
+		Description: "Add Cancellation to Sales Orders",
+		Script: `
+ALTER TABLE sales_orders ADD COLUMN cancelled TIMESTAMP NULL AFTER disc, ADD COLUMN cancelled_by BIGINT(20) UNSIGNED NULL AFTER cancelled;`,
+	},
+	{
+		Version:     94,
+		Description: "Add Backorder Qty to Sales Order Details",
+		Script: `
+ALTER TABLE sales_order_details ADD COLUMN backorder_qty INT(10) UNSIGNED NOT NULL DEFAULT 0 AFTER uom_qty;`,
+	},
+	{
+		Version:     95,
+		Description: "Add Status to Purchases",
+		Script: `
+ALTER TABLE purchases ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'approved' AFTER disc;`,
+	},
+	{
+		Version:     96,
+		Description: "Set Draft as Default Status of Purchases",
+		Script: `
+ALTER TABLE purchases ALTER COLUMN status SET DEFAULT 'draft';`,
+	},
+	{
+		Version:     97,
+		Description: "Add Purchase Approvals",
+		Script: `
+CREATE TABLE purchase_approvals (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	purchase_id BIGINT(20) UNSIGNED NOT NULL,
+	user_id BIGINT(20) UNSIGNED NOT NULL,
+	created TIMESTAMP NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (id),
+	KEY purchase_approvals_purchase_id (purchase_id),
+	KEY purchase_approvals_user_id (user_id),
+	UNIQUE KEY purchase_approvals_purchase_user (purchase_id, user_id),
+	CONSTRAINT fk_purchase_approvals_to_purchases FOREIGN KEY (purchase_id) REFERENCES purchases(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_purchase_approvals_to_users FOREIGN KEY (user_id) REFERENCES users(id)
+);`,
+	},
+	{
+		Version:     98,
+		Description: "Add Purchase Approval Limit to Companies",
+		Script: `
+ALTER TABLE companies ADD COLUMN po_approval_limit DOUBLE NOT NULL DEFAULT 0 AFTER atp_policy;`,
+	},
+	{
+		Version:     99,
+		Description: "Add Approval Rules",
+		Script: `
+CREATE TABLE approval_rules (
+	id   INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	company_id	INT(10) UNSIGNED NOT NULL,
+	branch_id INT(10) UNSIGNED NULL,
+	document_type CHAR(2) NOT NULL,
+	name VARCHAR(45) NOT NULL,
+	min_amount DOUBLE NOT NULL DEFAULT 0,
+	min_discount DOUBLE NOT NULL DEFAULT 0,
+	created TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated TIMESTAMP NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (id),
+	KEY approval_rules_company_id (company_id),
+	KEY approval_rules_branch_id (branch_id),
+	CONSTRAINT fk_approval_rules_to_companies FOREIGN KEY (company_id) REFERENCES companies(id),
+	CONSTRAINT fk_approval_rules_to_branches FOREIGN KEY (branch_id) REFERENCES branches(id)
+);`,
+	},
+	{
+		Version:     100,
+		Description: "Add Approval Rule Steps",
+		Script: `
+CREATE TABLE approval_rule_steps (
+	id   INT(10) UNSIGNED NOT NULL AUTO_INCREMENT,
+	approval_rule_id INT(10) UNSIGNED NOT NULL,
+	step TINYINT(3) UNSIGNED NOT NULL,
+	role_id INT(10) UNSIGNED NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY approval_rule_steps_step (approval_rule_id, step),
+	KEY approval_rule_steps_role_id (role_id),
+	CONSTRAINT fk_approval_rule_steps_to_approval_rules FOREIGN KEY (approval_rule_id) REFERENCES approval_rules(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_approval_rule_steps_to_roles FOREIGN KEY (role_id) REFERENCES roles(id)
+);`,
+	},
+	{
+		Version:     101,
+		Description: "Add Approvals",
+		Script: `
+CREATE TABLE approvals (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	company_id	INT(10) UNSIGNED NOT NULL,
+	branch_id INT(10) UNSIGNED NOT NULL,
+	approval_rule_id INT(10) UNSIGNED NOT NULL,
+	document_type CHAR(2) NOT NULL,
+	document_id BIGINT(20) UNSIGNED NOT NULL,
+	document_code CHAR(13) NOT NULL,
+	amount DOUBLE NOT NULL DEFAULT 0,
+	discount DOUBLE NOT NULL DEFAULT 0,
+	step TINYINT(3) UNSIGNED NOT NULL DEFAULT 1,
+	status VARCHAR(10) NOT NULL DEFAULT 'pending',
+	created TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated TIMESTAMP NOT NULL DEFAULT NOW(),
+	created_by BIGINT(20) UNSIGNED NOT NULL,
+	PRIMARY KEY (id),
+	KEY approvals_company_id (company_id),
+	KEY approvals_branch_id (branch_id),
+	KEY approvals_document (document_type, document_id),
+	KEY approvals_status (status),
+	CONSTRAINT fk_approvals_to_companies FOREIGN KEY (company_id) REFERENCES companies(id),
+	CONSTRAINT fk_approvals_to_branches FOREIGN KEY (branch_id) REFERENCES branches(id),
+	CONSTRAINT fk_approvals_to_approval_rules FOREIGN KEY (approval_rule_id) REFERENCES approval_rules(id)
+);`,
+	},
+	{
+		Version:     102,
+		Description: "Add Approval Histories",
+		Script: `
+CREATE TABLE approval_histories (
+	id   BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	approval_id BIGINT(20) UNSIGNED NOT NULL,
+	step TINYINT(3) UNSIGNED NOT NULL,
+	role_id INT(10) UNSIGNED NOT NULL,
+	user_id BIGINT(20) UNSIGNED NOT NULL,
+	action VARCHAR(10) NOT NULL,
+	remark VARCHAR(255) NOT NULL DEFAULT '',
+	created TIMESTAMP NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (id),
+	KEY approval_histories_approval_id (approval_id),
+	CONSTRAINT fk_approval_histories_to_approvals FOREIGN KEY (approval_id) REFERENCES approvals(id) ON DELETE CASCADE ON UPDATE CASCADE,
+	CONSTRAINT fk_approval_histories_to_roles FOREIGN KEY (role_id) REFERENCES roles(id),
+	CONSTRAINT fk_approval_histories_to_users FOREIGN KEY (user_id) REFERENCES users(id)
+);`,
+	},
+	{
+		Version:     103,
+		Description: "Add Posted Flag to Delivery Returns",
+		Script: `
+ALTER TABLE delivery_returns ADD COLUMN posted TINYINT(1) NOT NULL DEFAULT 1 AFTER remark;`,
+	},
+	{
+		Version:     104,
+		Description: "Add Posted Flag to Receiving Returns",
+		Script: `
+ALTER TABLE receiving_returns ADD COLUMN posted TINYINT(1) NOT NULL DEFAULT 1 AFTER remark;`,
+	},
+	{
+		Version:     105,
+		Description: "Add Over Receipt Tolerance to Companies",
+		Script: `
+ALTER TABLE companies ADD COLUMN over_receipt_tolerance DOUBLE NOT NULL DEFAULT 0 AFTER po_approval_limit;`,
+	},
+	{
+		Version:     106,
+		Description: "Add Immutable Posting to Companies",
+		Script: `
+ALTER TABLE companies ADD COLUMN immutable_posting TINYINT(1) NOT NULL DEFAULT 0 AFTER over_receipt_tolerance;`,
+	},
+	{
+		Version:     107,
+		Description: "Add Void to Good Receivings",
+		Script: `
+ALTER TABLE good_receivings ADD COLUMN correction_of BIGINT(20) UNSIGNED NULL AFTER remark, ADD COLUMN voided TIMESTAMP NULL AFTER correction_of, ADD COLUMN voided_by BIGINT(20) UNSIGNED NULL AFTER voided, ADD COLUMN void_reason VARCHAR(255) NOT NULL DEFAULT '' AFTER voided_by;`,
+	},
+	{
+		Version:     108,
+		Description: "Add Void to Deliveries",
+		Script: `
+ALTER TABLE deliveries ADD COLUMN correction_of BIGINT(20) UNSIGNED NULL AFTER remark, ADD COLUMN voided TIMESTAMP NULL AFTER correction_of, ADD COLUMN voided_by BIGINT(20) UNSIGNED NULL AFTER voided, ADD COLUMN void_reason VARCHAR(255) NOT NULL DEFAULT '' AFTER voided_by;`,
+	},
+	{
+		Version:     109,
+		Description: "Add Void to Delivery Returns",
+		Script: `
+ALTER TABLE delivery_returns ADD COLUMN correction_of BIGINT(20) UNSIGNED NULL AFTER posted, ADD COLUMN voided TIMESTAMP NULL AFTER correction_of, ADD COLUMN voided_by BIGINT(20) UNSIGNED NULL AFTER voided, ADD COLUMN void_reason VARCHAR(255) NOT NULL DEFAULT '' AFTER voided_by;`,
+	},
+	{
+		Version:     110,
+		Description: "Add Void to Receiving Returns",
+		Script: `
+ALTER TABLE receiving_returns ADD COLUMN correction_of BIGINT(20) UNSIGNED NULL AFTER posted, ADD COLUMN voided TIMESTAMP NULL AFTER correction_of, ADD COLUMN voided_by BIGINT(20) UNSIGNED NULL AFTER voided, ADD COLUMN void_reason VARCHAR(255) NOT NULL DEFAULT '' AFTER voided_by;`,
+	},
+	{
+		Version:     111,
+		Description: "Add Reversal to Inventories",
+		Script: `
+ALTER TABLE inventories ADD COLUMN reversal TINYINT(1) NOT NULL DEFAULT 0 AFTER in_out;`,
+	},
+	{
+		Version:     112,
+		Description: "Mark Reversal Inventories of Voided Documents",
+		Script: `
+UPDATE inventories
+SET reversal = 1
+WHERE (type = 'GR' AND in_out = 0 AND transaction_id IN (SELECT id FROM good_receivings WHERE voided IS NOT NULL))
+OR (type = 'RR' AND in_out = 1 AND transaction_id IN (SELECT id FROM receiving_returns WHERE voided IS NOT NULL))
+OR (type = 'DO' AND in_out = 1 AND transaction_id IN (SELECT id FROM deliveries WHERE voided IS NOT NULL))
+OR (type = 'DR' AND in_out = 0 AND transaction_id IN (SELECT id FROM delivery_returns WHERE voided IS NOT NULL));`,
+	},
+	{
+		Version:     113,
+		Description: "Add Audit Logs",
+		Script: `
+CREATE TABLE audit_logs (
+	id BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT,
+	company_id INT(10) UNSIGNED NULL,
+	user_id BIGINT(20) UNSIGNED NULL,
+	username VARCHAR(45) NOT NULL DEFAULT '',
+	action VARCHAR(20) NOT NULL,
+	route VARCHAR(255) NOT NULL,
+	entity VARCHAR(45) NOT NULL,
+	entity_id BIGINT(20) UNSIGNED NULL,
+	before_data JSON NULL,
+	after_data JSON NULL,
+	created TIMESTAMP NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (id),
+	KEY audit_logs_company_id (company_id),
+	KEY audit_logs_entity (entity, entity_id)
 );`,
 	},
 }